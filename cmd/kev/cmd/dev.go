@@ -33,6 +33,12 @@ Examples:
    ### Use a custom directory to render manifests
    $ kev dev -d my-manifests
 
+   ### Re-render once file changes have settled for 2 seconds
+   $ kev dev --debounce 2s
+
+   ### Only re-render a specific service's manifests on changes
+   $ kev dev --filter-service web
+
    ### Activate the Skaffold dev loop to build, push and deploy your project
    $ kev dev --skaffold
 
@@ -67,7 +73,7 @@ func init() {
 		"format",
 		"f",
 		"kubernetes", // default: native kubernetes manifests
-		"Deployment files format. Default: Kubernetes manifests.",
+		"Deployment files format, one of: kubernetes, helm, kustomize, kpt. Default: Kubernetes manifests.",
 	)
 
 	flags.BoolP(
@@ -84,6 +90,18 @@ func init() {
 		"Override default Kubernetes manifests output directory. Default: k8s/<env>",
 	)
 
+	flags.Duration(
+		"debounce",
+		kev.DefaultDevDebounce,
+		"Quiet period collapsing a burst of file changes into a single re-render. Set to 0 to re-render on every change.",
+	)
+
+	flags.StringSlice(
+		"filter-service",
+		[]string{}, // default: render all services
+		"Only re-render the named service on changes, repeat for multiple services. Default: all services",
+	)
+
 	flags.StringSlice("environment", []string{}, "")
 	_ = flags.MarkHidden("environment")
 
@@ -134,6 +152,8 @@ func runDevCmd(cmd *cobra.Command, _ []string) error {
 	kevenv, _ := cmd.Flags().GetString("kev-env")
 	tail, _ := cmd.Flags().GetBool("tail")
 	manualTrigger, _ := cmd.Flags().GetBool("manual-trigger")
+	debounce, _ := cmd.Flags().GetDuration("debounce")
+	filterServices, _ := cmd.Flags().GetStringSlice("filter-service")
 	verbose, _ := cmd.Root().Flags().GetBool("verbose")
 
 	eventHandler := func(e kev.RunnerEvent, r kev.Runner) error { return nil }
@@ -158,6 +178,8 @@ func runDevCmd(cmd *cobra.Command, _ []string) error {
 		kev.WithSkaffoldManualTriggerEnabled(manualTrigger),
 		kev.WithSkaffoldVerboseEnabled(verbose),
 		kev.WithEnvs(envs),
+		kev.WithFilterServices(filterServices),
+		kev.WithDebounce(debounce),
 		kev.WithLogVerbose(verbose),
 	)
 }
@@ -0,0 +1,97 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"github.com/appvia/kev/pkg/kev"
+	"github.com/spf13/cobra"
+)
+
+var diffLongDesc = `(diff) render Kubernetes manifests in memory and compare them with the manifests on disk.
+
+Environments are reconciled in memory only, no files are modified. The command exits with
+a non-zero status when rendered manifests differ from the ones on disk. Manifests are rendered
+with the same options as render, pass the options they were rendered with to compare like for like.
+
+Examples:
+
+  ### Compare rendered manifests for all environments with the ones in k8s/<env>
+  $ kev diff
+
+  ### Compare rendered manifests for a specific environment(s)
+  $ kev diff -e staging [-e production ...]
+
+  ### Compare rendered manifests with the ones in a custom output directory, rendered as a single file
+  $ kev diff --dir manifests --single`
+
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Shows the changes rendering would make to the application's Kubernetes manifests for a given environment (ALL environments by default).",
+	Long:  diffLongDesc,
+	RunE:  runDiffCmd,
+}
+
+func init() {
+	flags := diffCmd.Flags()
+	flags.SortFlags = false
+
+	flags.BoolP(
+		"single",
+		"s",
+		false, // default: compare multiple files.
+		"Compare with a single file output. Default: false",
+	)
+
+	flags.StringP(
+		"dir",
+		"d",
+		"", // default: compare with kubernetes manifests in k8s/<env>...
+		"Override default Kubernetes manifests output directory. Default: k8s/<env>",
+	)
+
+	flags.StringSliceP(
+		"environment",
+		"e",
+		[]string{},
+		"Target environment for which deployment files should be compared",
+	)
+
+	addRenderOptionsFlags(diffCmd)
+
+	rootCmd.AddCommand(diffCmd)
+}
+
+func runDiffCmd(cmd *cobra.Command, _ []string) error {
+	singleFile, _ := cmd.Flags().GetBool("single")
+	dir, _ := cmd.Flags().GetString("dir")
+	envs, _ := cmd.Flags().GetStringSlice("environment")
+	verbose, _ := cmd.Root().Flags().GetBool("verbose")
+
+	// The working directory is always the current directory.
+	wd := "."
+
+	opts := []kev.Options{
+		kev.WithAppName(rootCmd.Use),
+		kev.WithManifestsAsSingleFile(singleFile),
+		kev.WithOutputDir(dir),
+		kev.WithEnvs(envs),
+	}
+	opts = append(opts, renderOptions(cmd)...)
+	opts = append(opts, kev.WithLogVerbose(verbose))
+
+	return kev.DiffProjectWithOptions(wd, opts...)
+}
@@ -0,0 +1,56 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"github.com/appvia/kev/pkg/kev"
+	"github.com/spf13/cobra"
+)
+
+var doctorLongDesc = `(doctor) check the project's working directory for common setup problems.
+
+Checks the manifest presence, the compose sources, each environment override
+and the Skaffold setup (if configured), reporting how to fix any problems found.
+
+Examples:
+
+  ### Diagnose the project in the current directory
+  $ kev doctor`
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnoses common setup problems in the application's working directory.",
+	Long:  doctorLongDesc,
+	Args:  cobra.NoArgs,
+	RunE:  runDoctorCmd,
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
+
+func runDoctorCmd(cmd *cobra.Command, _ []string) error {
+	verbose, _ := cmd.Root().Flags().GetBool("verbose")
+
+	// The working directory is always the current directory.
+	wd := "."
+
+	return kev.DoctorProjectWithOptions(wd,
+		kev.WithAppName(rootCmd.Use),
+		kev.WithLogVerbose(verbose),
+	)
+}
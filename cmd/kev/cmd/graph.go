@@ -0,0 +1,73 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"github.com/appvia/kev/pkg/kev"
+	"github.com/spf13/cobra"
+)
+
+var graphLongDesc = `(graph) output a graph of the project's services, volumes, configs, secrets and their relationships.
+
+Services are linked to the volumes, configs & secrets they mount and to the services they depend on.
+No cluster is required as the graph is derived from the project's compose sources.
+
+Examples:
+
+  ### Output a Graphviz DOT graph of the project's compose sources
+  $ kev graph
+
+  ### Output a graph of a specific environment and render it as an image
+  $ kev graph staging | dot -Tpng -o staging.png
+
+  ### Output a mermaid flowchart, e.g. for embedding in markdown docs
+  $ kev graph --format mermaid`
+
+var graphCmd = &cobra.Command{
+	Use:   "graph [env]",
+	Short: "Outputs a graph of the application's services and their dependencies, optionally merged with an environment.",
+	Long:  graphLongDesc,
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runGraphCmd,
+}
+
+func init() {
+	flags := graphCmd.Flags()
+	flags.SortFlags = false
+
+	flags.StringP(
+		"format",
+		"f",
+		kev.GraphFormatDOT,
+		"Graph format, one of: dot, mermaid.",
+	)
+
+	rootCmd.AddCommand(graphCmd)
+}
+
+func runGraphCmd(cmd *cobra.Command, args []string) error {
+	format, _ := cmd.Flags().GetString("format")
+
+	// The working directory is always the current directory.
+	wd := "."
+
+	return kev.GraphProjectWithOptions(wd, cmd.OutOrStdout(),
+		kev.WithAppName(rootCmd.Use),
+		kev.WithEnvs(args),
+		kev.WithGraphFormat(format),
+	)
+}
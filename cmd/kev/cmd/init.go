@@ -41,7 +41,16 @@ Examples:
   $ kev init -e staging -e production
 
   ### Prepare project for use with Skaffold.
-  $ kev init -e staging --skaffold`
+  $ kev init -e staging --skaffold
+
+  ### Expose services publishing ports using NodePort services by default.
+  $ kev init --default-service-type NodePort
+
+  ### Expose services via ingress translated from their traefik compose labels.
+  $ kev init --traefik-labels
+
+  ### Prepare project for Skaffold, building images in cluster with Kaniko and pushing them to a registry.
+  $ kev init --skaffold --build-strategy kaniko --default-repo gcr.io/my-project`
 
 var initCmd = &cobra.Command{
 	Use:   "init",
@@ -70,6 +79,20 @@ func init() {
 
 	flags.BoolP("skaffold", "s", false, "prepare the project for Skaffold")
 
+	flags.String(
+		"default-service-type",
+		"",
+		"Service type for services publishing ports, one of: None, ClusterIP, NodePort, LoadBalancer, Headless\n(default: ClusterIP)",
+	)
+
+	flags.Bool(
+		"traefik-labels",
+		false,
+		"Expose services via ingress inferred from their traefik compose labels",
+	)
+
+	addSkaffoldBuildFlags(initCmd)
+
 	rootCmd.AddCommand(initCmd)
 }
 
@@ -77,6 +100,11 @@ func runInitCmd(cmd *cobra.Command, _ []string) error {
 	files, _ := cmd.Flags().GetStringSlice("file")
 	envs, _ := cmd.Flags().GetStringSlice("environment")
 	skaffold, _ := cmd.Flags().GetBool("skaffold")
+	defaultServiceType, _ := cmd.Flags().GetString("default-service-type")
+	traefikLabels, _ := cmd.Flags().GetBool("traefik-labels")
+	buildStrategy, _ := cmd.Flags().GetString("build-strategy")
+	tagPolicy, _ := cmd.Flags().GetString("tag-policy")
+	defaultRepo, _ := cmd.Flags().GetString("default-repo")
 	verbose, _ := cmd.Root().Flags().GetBool("verbose")
 
 	// The working directory is always the current directory.
@@ -87,6 +115,11 @@ func runInitCmd(cmd *cobra.Command, _ []string) error {
 		kev.WithComposeSources(files),
 		kev.WithEnvs(envs),
 		kev.WithSkaffold(skaffold),
+		kev.WithDefaultServiceType(defaultServiceType),
+		kev.WithTraefikLabels(traefikLabels),
+		kev.WithSkaffoldBuildStrategy(buildStrategy),
+		kev.WithSkaffoldTagPolicy(tagPolicy),
+		kev.WithSkaffoldDefaultRepo(defaultRepo),
 		kev.WithLogVerbose(verbose),
 	)
 }
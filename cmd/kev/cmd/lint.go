@@ -0,0 +1,79 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/appvia/kev/pkg/kev"
+	"github.com/appvia/kev/pkg/kev/converter/kubernetes"
+	"github.com/spf13/cobra"
+)
+
+var lintLongDesc = fmt.Sprintf(`(lint) render Kubernetes manifests in memory and report best-practice issues.
+
+Supported checks: %s.
+
+Examples:
+
+  ### Lint rendered output for all environments
+  $ kev lint
+
+  ### Lint rendered output for a specific environment(s)
+  $ kev lint staging [production ...]
+
+  ### Fail when images with the latest tag or containers running as root are found
+  $ kev lint --error-on latest-image-tag,run-as-root
+
+  ### Fail on any finding
+  $ kev lint --error-on all`, strings.Join(kubernetes.LintChecks, ", "))
+
+var lintCmd = &cobra.Command{
+	Use:   "lint [env...]",
+	Short: "Reports Kubernetes best-practice issues in the application's rendered output for given environments (ALL environments by default).",
+	Long:  lintLongDesc,
+	RunE:  runLintCmd,
+}
+
+func init() {
+	flags := lintCmd.Flags()
+	flags.SortFlags = false
+
+	flags.StringSlice(
+		"error-on",
+		[]string{}, // default: all findings are warnings
+		"Lint checks whose findings are reported as errors, or 'all'. Default: all findings are warnings",
+	)
+
+	rootCmd.AddCommand(lintCmd)
+}
+
+func runLintCmd(cmd *cobra.Command, args []string) error {
+	errorOn, _ := cmd.Flags().GetStringSlice("error-on")
+	verbose, _ := cmd.Root().Flags().GetBool("verbose")
+
+	// The working directory is always the current directory.
+	wd := "."
+
+	return kev.LintProjectWithOptions(wd,
+		kev.WithAppName(rootCmd.Use),
+		kev.WithEnvs(args),
+		kev.WithLintErrorOn(errorOn),
+		kev.WithLogVerbose(verbose),
+	)
+}
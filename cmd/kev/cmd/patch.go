@@ -0,0 +1,120 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/appvia/kev/pkg/kev"
+	"github.com/appvia/kev/pkg/kev/config"
+	"github.com/spf13/cobra"
+)
+
+var patchLongDesc = `(patch) add a patch to an environment's rendered Kubernetes objects.
+
+Patches are an escape hatch for configuration kev doesn't support. They are recorded in the environment's
+override file under the top level x-k8s patches and applied to the targeted object each time the environment is rendered.
+Rendering fails when a patch targets an object that isn't rendered.
+
+Examples:
+
+  ### Merge a strategic merge patch into the dev environment's web Deployment
+  $ kev patch dev --target Deployment/web --strategic-merge web-patch.yaml
+
+  ### Apply JSON6902 patch operations to the prod environment's web Service
+  $ kev patch prod --target Service/web --json6902 web-service-ops.yaml`
+
+var patchCmd = &cobra.Command{
+	Use:   "patch <env>",
+	Short: "Adds a strategic merge or JSON6902 patch to an environment's rendered Kubernetes objects.",
+	Long:  patchLongDesc,
+	Args:  cobra.ExactArgs(1),
+	RunE:  runPatchCmd,
+}
+
+func init() {
+	flags := patchCmd.Flags()
+	flags.SortFlags = false
+
+	flags.StringP(
+		"target",
+		"t",
+		"",
+		"Rendered object to patch as <kind>/<name>, e.g. Deployment/web.",
+	)
+
+	flags.String(
+		"strategic-merge",
+		"",
+		"File containing a strategic merge patch.",
+	)
+
+	flags.String(
+		"json6902",
+		"",
+		"File containing JSON6902 patch operations.",
+	)
+
+	_ = patchCmd.MarkFlagRequired("target")
+
+	rootCmd.AddCommand(patchCmd)
+}
+
+func runPatchCmd(cmd *cobra.Command, args []string) error {
+	target, _ := cmd.Flags().GetString("target")
+	strategicMergeFile, _ := cmd.Flags().GetString("strategic-merge")
+	json6902File, _ := cmd.Flags().GetString("json6902")
+
+	parts := strings.SplitN(target, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return fmt.Errorf("invalid target %q, expected <kind>/<name>, e.g. Deployment/web", target)
+	}
+
+	if (strategicMergeFile == "") == (json6902File == "") {
+		return errors.New("exactly one of --strategic-merge or --json6902 is required")
+	}
+
+	patch := config.Patch{
+		Target: config.PatchTarget{Kind: parts[0], Name: parts[1]},
+	}
+
+	if strategicMergeFile != "" {
+		content, err := ioutil.ReadFile(strategicMergeFile)
+		if err != nil {
+			return err
+		}
+		patch.StrategicMerge = string(content)
+	} else {
+		content, err := ioutil.ReadFile(json6902File)
+		if err != nil {
+			return err
+		}
+		patch.JSON6902 = string(content)
+	}
+
+	// The working directory is always the current directory.
+	wd := "."
+
+	return kev.PatchProjectWithOptions(wd, cmd.OutOrStdout(),
+		kev.WithAppName(rootCmd.Use),
+		kev.WithEnvs(args),
+		kev.WithPatch(patch),
+	)
+}
@@ -18,6 +18,7 @@ package cmd
 
 import (
 	"github.com/appvia/kev/pkg/kev"
+	"github.com/appvia/kev/pkg/kev/converter/kubernetes"
 	"github.com/spf13/cobra"
 )
 
@@ -29,7 +30,58 @@ Examples:
   $ kev render
 
   ### Render an app Kubernetes manifests (default) for a specific environment(s)
-  $ kev render -e staging [-e production ...]`
+  $ kev render -e staging [-e production ...]
+
+  ### Render only a specific service(s) Kubernetes manifests
+  $ kev render --filter-service web [--filter-service worker ...]
+
+  ### Render an app as a kpt package for each environment, with a Kptfile and workload setters
+  $ kev render --format kpt
+
+  ### Render an app Kubernetes manifests encoded as JSON into a single file for each environment
+  $ kev render --output json --single
+
+  ### Render all environments into a single Kubernetes manifests bundle
+  $ kev render --all-envs-single-file
+
+  ### Render an app Kubernetes manifests annotated with a field manager for server-side apply
+  $ kev render --field-manager kev
+
+  ### Render an app Kubernetes manifests with a resource quota & limit range for each environment namespace, allowing 50% headroom
+  $ kev render --resource-quota --quota-headroom 1.5
+
+  ### Render an app Kubernetes manifests exposing services with Traefik IngressRoute custom resources
+  $ kev render --expose-api traefik
+
+  ### Render an app Kubernetes manifests using apiVersions served by Kubernetes 1.24, e.g. policy/v1 PodDisruptionBudgets
+  $ kev render --k8s-version 1.24
+
+  ### Render an app Kubernetes manifests validating NodePort services node ports against a custom cluster node port range
+  $ kev render --node-port-range 20000-22767
+
+  ### Render an app Kubernetes manifests omitting empty objects, e.g. services without ports
+  $ kev render --prune-empty
+
+  ### Render an app Kubernetes manifests along with a MANIFEST.md index of rendered objects for each environment
+  $ kev render --index
+
+  ### Render an app Kubernetes manifests annotated with the conversion warnings of the objects they concern
+  $ kev render --warning-annotations
+
+  ### Render an app Kubernetes manifests failing when any warnings are emitted, e.g. to block CI merges
+  $ kev render --fail-on-warn
+
+  ### Render an app Kubernetes manifests interpolating values from KEV_ prefixed env vars, e.g. KEV_IMAGE_TAG as ${IMAGE_TAG}
+  $ kev render --values-from-env KEV_
+
+  ### Render an app Kubernetes manifests overriding services configuration with values from a file
+  $ kev render --values-from ci-values.yaml
+
+  ### Render an app Kubernetes manifests and run them through an organisation-wide kustomize overlay
+  $ kev render --kustomize-overlay ../platform/overlay
+
+  ### Render an app environments' Kubernetes manifests 4 at a time
+  $ kev render --concurrency 4`
 
 var renderCmd = &cobra.Command{
 	Use:   "render",
@@ -46,7 +98,7 @@ func init() {
 		"format",
 		"f",
 		"kubernetes", // default: native kubernetes manifests
-		"Deployment files format. Default: Kubernetes manifests.",
+		"Deployment files format, one of: kubernetes, helm, kustomize, kpt. Default: Kubernetes manifests.",
 	)
 
 	flags.BoolP(
@@ -56,6 +108,12 @@ func init() {
 		"Controls whether to produce individual manifests or a single file output. Default: false",
 	)
 
+	flags.Bool(
+		"all-envs-single-file",
+		false, // default: render each environment separately
+		"Render all environments into a single multi-document bundle, with objects namespaced and labelled by environment. Default: false",
+	)
+
 	flags.StringP(
 		"dir",
 		"d",
@@ -70,26 +128,185 @@ func init() {
 		"Target environment for which deployment files should be rendered",
 	)
 
+	addRenderOptionsFlags(renderCmd)
+
+	flags.Bool(
+		"fail-on-warn",
+		false, // default: warnings don't fail render
+		"Fail with a consolidated list of warnings when any are emitted during reconcile or render, e.g. in CI. Default: false",
+	)
+
+	flags.String(
+		"kustomize-overlay",
+		"", // default: no kustomize post-processing
+		"Kustomization directory rendered manifests are run through as a final step, emitting the kustomized result. Default: disabled",
+	)
+
+	flags.Int(
+		"concurrency",
+		1, // default: render environments sequentially
+		"Maximum number of environments rendered concurrently. Service conversion steps are only reported when rendering sequentially. Default: 1",
+	)
+
 	rootCmd.AddCommand(renderCmd)
 }
 
+// addRenderOptionsFlags registers flags configuring how Kubernetes manifests are rendered.
+// Commands comparing rendered manifests share them with render so that they compare the same output.
+func addRenderOptionsFlags(cmd *cobra.Command) {
+	flags := cmd.Flags()
+
+	flags.StringP(
+		"output",
+		"o",
+		kubernetes.OutputYAML,
+		"Kubernetes manifests encoding, one of: yaml, json. Only applies to the kubernetes format. Default: yaml",
+	)
+
+	flags.StringSlice(
+		"filter-service",
+		[]string{}, // default: render all services
+		"Only render the named service, repeat for multiple services. Project secrets are rendered regardless. Default: all services",
+	)
+
+	flags.Bool(
+		"annotate-source",
+		false, // default: no source file annotations
+		"Annotate rendered objects with the compose source file their service originated from. Default: false",
+	)
+
+	flags.String(
+		"field-manager",
+		"", // default: no field manager annotation
+		"Annotate rendered objects with the field manager name to use when applying them server-side. Default: disabled",
+	)
+
+	flags.Bool(
+		"resource-quota",
+		false, // default: no resource quota & limit range
+		"Render a resource quota & limit range for each environment namespace derived from its workloads resources. Default: false",
+	)
+
+	flags.Float64(
+		"quota-headroom",
+		kubernetes.DefaultQuotaHeadroom,
+		"Factor applied to environment workloads resources when rendering a resource quota. Default: 1.2",
+	)
+
+	flags.String(
+		"expose-api",
+		kubernetes.ExposeAPIIngress,
+		"API used to expose services, one of: ingress, traefik (Traefik v2 IngressRoute). Default: ingress",
+	)
+
+	flags.String(
+		"k8s-version",
+		kubernetes.DefaultKubeVersion,
+		"Target Kubernetes version selecting apiVersions of rendered Ingress, PodDisruptionBudget, CronJob & HorizontalPodAutoscaler objects. Default: 1.19",
+	)
+
+	flags.String(
+		"node-port-range",
+		kubernetes.DefaultNodePortRange,
+		"Range NodePort services node ports must be within, matching the cluster's --service-node-port-range. Default: 30000-32767",
+	)
+
+	flags.Bool(
+		"prune-empty",
+		false, // default: render all objects
+		"Omit rendered objects carrying no meaningful configuration, e.g. services without ports. Default: false",
+	)
+
+	flags.Bool(
+		"index",
+		false, // default: no manifests index
+		"Write a MANIFEST.md index summarising rendered objects and their key configuration next to the manifests. Default: false",
+	)
+
+	flags.Bool(
+		"warning-annotations",
+		false, // default: warnings are only logged
+		"Record service conversion warnings as kev.appvia.io/warning-<n> annotations on the rendered objects they concern. Default: false",
+	)
+
+	flags.String(
+		"values-from-env",
+		"", // default: no values injected from env vars
+		"Inject env vars with the given prefix (stripped) into compose files interpolation. Default: disabled",
+	)
+
+	flags.String(
+		"values-from",
+		"", // default: no values file
+		"YAML file of services x-k8s configuration values merged into each environment, taking precedence. Default: disabled",
+	)
+}
+
+// renderOptions returns the project options set by the render options flags
+func renderOptions(cmd *cobra.Command) []kev.Options {
+	output, _ := cmd.Flags().GetString("output")
+	filterServices, _ := cmd.Flags().GetStringSlice("filter-service")
+	annotateSource, _ := cmd.Flags().GetBool("annotate-source")
+	fieldManager, _ := cmd.Flags().GetString("field-manager")
+	resourceQuota, _ := cmd.Flags().GetBool("resource-quota")
+	quotaHeadroom, _ := cmd.Flags().GetFloat64("quota-headroom")
+	exposeAPI, _ := cmd.Flags().GetString("expose-api")
+	kubeVersion, _ := cmd.Flags().GetString("k8s-version")
+	nodePortRange, _ := cmd.Flags().GetString("node-port-range")
+	pruneEmpty, _ := cmd.Flags().GetBool("prune-empty")
+	index, _ := cmd.Flags().GetBool("index")
+	warningAnnotations, _ := cmd.Flags().GetBool("warning-annotations")
+	valuesPrefix, _ := cmd.Flags().GetString("values-from-env")
+	valuesFile, _ := cmd.Flags().GetString("values-from")
+
+	return []kev.Options{
+		kev.WithOutput(output),
+		kev.WithFilterServices(filterServices),
+		kev.WithAnnotateSourceFile(annotateSource),
+		kev.WithFieldManager(fieldManager),
+		kev.WithResourceQuota(resourceQuota),
+		kev.WithQuotaHeadroom(quotaHeadroom),
+		kev.WithExposeAPI(exposeAPI),
+		kev.WithKubeVersion(kubeVersion),
+		kev.WithNodePortRange(nodePortRange),
+		kev.WithPruneEmpty(pruneEmpty),
+		kev.WithIndex(index),
+		kev.WithWarningAnnotations(warningAnnotations),
+		kev.WithValuesFromEnv(valuesPrefix),
+		kev.WithValuesFile(valuesFile),
+	}
+}
+
 func runRenderCmd(cmd *cobra.Command, _ []string) error {
 	format, _ := cmd.Flags().GetString("format")
 	singleFile, _ := cmd.Flags().GetBool("single")
+	allEnvsSingleFile, _ := cmd.Flags().GetBool("all-envs-single-file")
 	dir, _ := cmd.Flags().GetString("dir")
 	envs, _ := cmd.Flags().GetStringSlice("environment")
+	failOnWarn, _ := cmd.Flags().GetBool("fail-on-warn")
+	kustomizeOverlay, _ := cmd.Flags().GetString("kustomize-overlay")
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
 	verbose, _ := cmd.Root().Flags().GetBool("verbose")
 
 	// The working directory is always the current directory.
 	// This ensures created manifest yaml entries are portable between users and require no path fixing.
 	wd := "."
 
-	return kev.RenderProjectWithOptions(wd,
+	opts := []kev.Options{
 		kev.WithAppName(rootCmd.Use),
 		kev.WithManifestFormat(format),
 		kev.WithManifestsAsSingleFile(singleFile),
+		kev.WithAllEnvsSingleFile(allEnvsSingleFile),
 		kev.WithOutputDir(dir),
 		kev.WithEnvs(envs),
+	}
+	opts = append(opts, renderOptions(cmd)...)
+	opts = append(opts,
+		kev.WithFailOnWarn(failOnWarn),
+		kev.WithKustomizeOverlay(kustomizeOverlay),
+		kev.WithConcurrency(concurrency),
 		kev.WithLogVerbose(verbose),
 	)
+
+	return kev.RenderProjectWithOptions(wd, opts...)
 }
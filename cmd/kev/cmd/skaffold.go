@@ -0,0 +1,91 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"github.com/appvia/kev/pkg/kev"
+	"github.com/spf13/cobra"
+)
+
+var skaffoldLongDesc = `(skaffold) creates or updates the Skaffold config of an initialised project.
+
+Adds a Skaffold profile for every deployment environment tracked by the project.
+Profiles already defined in an existing Skaffold config are skipped.
+
+Examples:
+
+  ### Generate Skaffold config after adding deployment environments
+  $ kev skaffold
+
+  ### Build images with Cloud Native Buildpacks, tagging them with their content digest
+  $ kev skaffold --build-strategy buildpacks --tag-policy sha256`
+
+var skaffoldCmd = &cobra.Command{
+	Use:   "skaffold",
+	Short: "Creates or updates the Skaffold config with profiles for the project's deployment environments.",
+	Long:  skaffoldLongDesc,
+	Args:  cobra.NoArgs,
+	RunE:  runSkaffoldCmd,
+}
+
+func init() {
+	skaffoldCmd.Flags().SortFlags = false
+	addSkaffoldBuildFlags(skaffoldCmd)
+
+	rootCmd.AddCommand(skaffoldCmd)
+}
+
+// addSkaffoldBuildFlags registers flags configuring how Skaffold builds, tags and pushes images.
+func addSkaffoldBuildFlags(cmd *cobra.Command) {
+	flags := cmd.Flags()
+
+	flags.String(
+		"build-strategy",
+		"",
+		"Skaffold build strategy, one of: local, kaniko, buildpacks\n(default: local)",
+	)
+
+	flags.String(
+		"tag-policy",
+		"",
+		"Skaffold image tagging policy, one of: git, sha256, datetime\n(default: git)",
+	)
+
+	flags.String(
+		"default-repo",
+		"",
+		"Default image registry Skaffold pushes built images to",
+	)
+}
+
+func runSkaffoldCmd(cmd *cobra.Command, _ []string) error {
+	buildStrategy, _ := cmd.Flags().GetString("build-strategy")
+	tagPolicy, _ := cmd.Flags().GetString("tag-policy")
+	defaultRepo, _ := cmd.Flags().GetString("default-repo")
+	verbose, _ := cmd.Root().Flags().GetBool("verbose")
+
+	// The working directory is always the current directory.
+	wd := "."
+
+	return kev.SkaffoldProjectWithOptions(wd,
+		kev.WithAppName(rootCmd.Use),
+		kev.WithSkaffoldBuildStrategy(buildStrategy),
+		kev.WithSkaffoldTagPolicy(tagPolicy),
+		kev.WithSkaffoldDefaultRepo(defaultRepo),
+		kev.WithLogVerbose(verbose),
+	)
+}
@@ -0,0 +1,61 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"github.com/appvia/kev/pkg/kev"
+	"github.com/spf13/cobra"
+)
+
+var validateLongDesc = `(validate) reconcile and transform environments in memory and report configuration errors.
+
+No environment files or manifests are written. All environments are validated and their errors
+reported together, the command exits with a non-zero status when any environment is invalid.
+The compose profiles declared by the compose sources are listed with the services they enable,
+and environments enabling profiles no service declares are reported as invalid.
+
+Examples:
+
+  ### Validate all environments, e.g. in a CI pre-commit hook
+  $ kev validate
+
+  ### Validate a specific environment(s)
+  $ kev validate staging [production ...]`
+
+var validateCmd = &cobra.Command{
+	Use:   "validate [env...]",
+	Short: "Reports configuration errors in the application's environments without writing any files (ALL environments by default).",
+	Long:  validateLongDesc,
+	RunE:  runValidateCmd,
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+}
+
+func runValidateCmd(cmd *cobra.Command, args []string) error {
+	verbose, _ := cmd.Root().Flags().GetBool("verbose")
+
+	// The working directory is always the current directory.
+	wd := "."
+
+	return kev.ValidateProjectWithOptions(wd,
+		kev.WithAppName(rootCmd.Use),
+		kev.WithEnvs(args),
+		kev.WithLogVerbose(verbose),
+	)
+}
@@ -0,0 +1,83 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kev
+
+import (
+	"strings"
+
+	"github.com/appvia/kev/pkg/kev/config"
+	"github.com/appvia/kev/pkg/kev/log"
+	"github.com/pkg/errors"
+)
+
+// reservedAnnotationPrefix prefixes the annotations kev sets on rendered objects, which can't be set by users
+const reservedAnnotationPrefix = "kev.appvia.io/"
+
+// mergeServicesAnnotations merges compose services annotations into the matching project services
+// x-k8s workload annotations, rendered as pod template annotations.
+// Workload annotations configured via x-k8s take precedence. Reserved kev annotations are skipped.
+func mergeServicesAnnotations(p *ComposeProject, files []string, env map[string]string) error {
+	annotations, err := getComposeServicesAnnotations(files, env)
+	if err != nil {
+		return errors.Wrap(err, "cannot read services annotations")
+	}
+
+	for i, svc := range p.Services {
+		if len(annotations[svc.Name]) == 0 {
+			continue
+		}
+
+		var svcK8sConfig config.SvcK8sConfig
+		if _, ok := svc.Extensions[config.K8SExtensionKey]; ok {
+			parsed, err := config.ParseSvcK8sConfigFromMap(svc.Extensions, config.SkipValidation())
+			if err != nil {
+				return errors.Wrapf(err, "service %s", svc.Name)
+			}
+			svcK8sConfig = parsed
+		}
+
+		if svcK8sConfig.Workload.Annotations == nil {
+			svcK8sConfig.Workload.Annotations = map[string]string{}
+		}
+
+		for key, value := range annotations[svc.Name] {
+			if strings.HasPrefix(key, reservedAnnotationPrefix) {
+				log.WarnfWithFields(log.Fields{
+					"service":    svc.Name,
+					"annotation": key,
+				}, "Annotations prefixed with %s are reserved by kev. Skipping ...", reservedAnnotationPrefix)
+				continue
+			}
+
+			if _, ok := svcK8sConfig.Workload.Annotations[key]; !ok {
+				svcK8sConfig.Workload.Annotations[key] = value
+			}
+		}
+
+		m, err := svcK8sConfig.Map()
+		if err != nil {
+			return err
+		}
+
+		if p.Services[i].Extensions == nil {
+			p.Services[i].Extensions = map[string]interface{}{}
+		}
+		p.Services[i].Extensions[config.K8SExtensionKey] = m
+	}
+
+	return nil
+}
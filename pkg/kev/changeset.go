@@ -19,9 +19,11 @@ package kev
 import (
 	"fmt"
 	"reflect"
+	"sort"
 
 	"github.com/appvia/kev/pkg/kev/config"
 	"github.com/appvia/kev/pkg/kev/log"
+	kmd "github.com/appvia/komando"
 )
 
 const (
@@ -30,6 +32,16 @@ const (
 	DELETE = "delete"
 )
 
+// summaryGroups maps change types to their reconcile summary groups, in display order.
+var summaryGroups = []struct {
+	changeType string
+	name       string
+}{
+	{CREATE, "Added"},
+	{UPDATE, "Updated"},
+	{DELETE, "Deleted"},
+}
+
 // changes returns a flat list of all available changes
 func (cset changeset) changes() []change {
 	var out []change
@@ -51,16 +63,20 @@ func (cset changeset) applyVersionPatchesIfAny(o *composeOverride) string {
 	if reflect.DeepEqual(chg, change{}) {
 		return ""
 	}
-	return chg.patchVersion(o)
+	msg := chg.patchVersion(o)
+	o.recordChange(chg.Type, "version", msg)
+	return msg
 }
 
 func (cset changeset) applyServicesPatchesIfAny(o *composeOverride) ([]string, error) {
 	var out []string
 	for _, change := range cset.services {
+		object := change.serviceObject(o)
 		patchDetails, err := change.patchService(o)
 		if err != nil {
 			return nil, err
 		}
+		o.recordChange(change.Type, object, patchDetails)
 		out = append(out, patchDetails)
 	}
 	return out, nil
@@ -73,11 +89,20 @@ func (cset changeset) applyVolumesPatchesIfAny(o *composeOverride) ([]string, er
 		if err != nil {
 			return nil, err
 		}
+		o.recordChange(change.Type, fmt.Sprintf("volume %s", change.Index.(string)), patchDetails)
 		out = append(out, patchDetails)
 	}
 	return out, nil
 }
 
+// serviceObject returns the name of the service object targeted by a change, it must be called before the change is applied.
+func (chg change) serviceObject(override *composeOverride) string {
+	if chg.Type == CREATE {
+		return fmt.Sprintf("service %s", chg.Value.(ServiceConfig).Name)
+	}
+	return fmt.Sprintf("service %s", override.Services[chg.Index.(int)].Name)
+}
+
 func (chg change) patchVersion(override *composeOverride) string {
 	if chg.Type != UPDATE {
 		return ""
@@ -140,6 +165,25 @@ func (chg change) patchService(override *composeOverride) (string, error) {
 
 			svc.Extensions[config.K8SExtensionKey] = newValue
 			log.Debugf("service [%s] extensions updated to %+v", svcName, newValue)
+			return fmt.Sprintf("updated extensions in service %s", svcName), nil
+		case "labels":
+			svc := override.Services[chg.Index.(int)]
+			current := chg.Value.(string)
+
+			value := svc.Labels[chg.Target]
+			delete(svc.Labels, chg.Target)
+
+			// a label already using the current key takes precedence over its deprecated equivalent
+			if _, ok := svc.Labels[current]; ok {
+				msg := fmt.Sprintf("removed deprecated label: %s from service %s, superseded by %s", chg.Target, svc.Name, current)
+				log.Debugf(msg)
+				return msg, nil
+			}
+
+			svc.Labels[current] = value
+			msg := fmt.Sprintf("migrated label: %s to %s in service %s", chg.Target, current, svc.Name)
+			log.Debugf(msg)
+			return msg, nil
 		}
 	}
 	return "", nil
@@ -170,3 +214,80 @@ func (chg change) patchVolume(override *composeOverride) (string, error) {
 	}
 	return "", nil
 }
+
+// recordChange tracks a change applied to an override so that it can be included in a reconcile summary.
+func (o *composeOverride) recordChange(changeType, object, detail string) {
+	if detail == "" {
+		return
+	}
+	o.applied = append(o.applied, appliedChange{Type: changeType, Object: object, Detail: detail})
+}
+
+// add includes the changes applied to an environment in the summary.
+func (s reconcileSummary) add(env string, applied []appliedChange) {
+	for _, chg := range applied {
+		if s[chg.Type] == nil {
+			s[chg.Type] = map[string]map[string][]string{}
+		}
+		if s[chg.Type][env] == nil {
+			s[chg.Type][env] = map[string][]string{}
+		}
+		s[chg.Type][env][chg.Object] = append(s[chg.Type][env][chg.Object], chg.Detail)
+	}
+}
+
+// count returns the number of changes of a type, optionally narrowed down to an environment.
+func (s reconcileSummary) count(changeType string, envs ...string) int {
+	var out int
+	for env, objects := range s[changeType] {
+		if len(envs) > 0 && env != envs[0] {
+			continue
+		}
+		for _, details := range objects {
+			out += len(details)
+		}
+	}
+	return out
+}
+
+// output displays the summary grouped by change type, then environment and object with counts per group.
+func (s reconcileSummary) output(ui kmd.UI) {
+	if len(s) == 0 {
+		return
+	}
+
+	ui.Header("Reconcile summary...")
+	for _, group := range summaryGroups {
+		envs := s[group.changeType]
+		if len(envs) == 0 {
+			continue
+		}
+		ui.Output(fmt.Sprintf("%s (%d)", group.name, s.count(group.changeType)))
+
+		var envNames []string
+		for env := range envs {
+			envNames = append(envNames, env)
+		}
+		sort.Strings(envNames)
+
+		for _, env := range envNames {
+			ui.Output(fmt.Sprintf("%s (%d)", env, s.count(group.changeType, env)), kmd.WithIndent(2))
+
+			objects := envs[env]
+			var objectNames []string
+			for object := range objects {
+				objectNames = append(objectNames, object)
+			}
+			sort.Strings(objectNames)
+
+			for _, object := range objectNames {
+				ui.Output(fmt.Sprintf("%s (%d)", object, len(objects[object])), kmd.WithIndent(4))
+				for _, detail := range objects[object] {
+					ui.Output(detail, kmd.WithStyle(kmd.LogStyle),
+						kmd.WithIndentChar(kmd.LogIndentChar),
+						kmd.WithIndent(6))
+				}
+			}
+		}
+	}
+}
@@ -20,15 +20,30 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/appvia/kev/pkg/kev/log"
 	"github.com/compose-spec/compose-go/cli"
+	"github.com/compose-spec/compose-go/loader"
+	"github.com/compose-spec/compose-go/template"
 	composego "github.com/compose-spec/compose-go/types"
+	"github.com/joho/godotenv"
 	"github.com/pkg/errors"
 	"gopkg.in/yaml.v3"
 )
 
+// EnvFilePrefix is the prefix of environment specific env files, e.g. `.env.staging` for the staging environment
+const EnvFilePrefix = ".env."
+
+// variablePattern matches compose files variable references: $$ escapes, $VAR, ${VAR} and ${VAR} with a
+// default value or a required error modifier
+var variablePattern = regexp.MustCompile(`\$(?:(\$)|([_a-zA-Z][_a-zA-Z0-9]*)|\{([_a-zA-Z][_a-zA-Z0-9]*)(:?[-?][^}]*)?\})`)
+
+// projectNameUnsafeChars matches characters compose strips from project names derived from the working directory
+var projectNameUnsafeChars = regexp.MustCompile(`[^a-z0-9\\-_]+`)
+
 // defaultComposeFileNames defines the Compose file names for auto-discovery (in order of preference)
 var defaultComposeFileNames = []string{
 	"compose.yaml",
@@ -49,7 +64,13 @@ type ComposeOpts func(project *ComposeProject) (*ComposeProject, error)
 
 // NewComposeProject loads and parses a set of input compose files and returns a ComposeProject object
 func NewComposeProject(paths []string, opts ...ComposeOpts) (*ComposeProject, error) {
-	raw, err := rawProjectFromSources(paths)
+	return newEnvComposeProject(paths, "", nil, opts...)
+}
+
+// newEnvComposeProject loads and parses a set of input compose files interpolated with the supplied
+// interpolation values and the variables of an environment specific env file, if any, and returns a ComposeProject object
+func newEnvComposeProject(paths []string, envFile string, values map[string]string, opts ...ComposeOpts) (*ComposeProject, error) {
+	raw, err := rawProjectFromSources(paths, envFile, values)
 	if err != nil {
 		return nil, err
 	}
@@ -92,13 +113,206 @@ func (p *ComposeProject) GetVersion() string {
 }
 
 // rawProjectFromSources loads and parses a compose-go project from multiple docker-compose source files.
-func rawProjectFromSources(paths []string) (*composego.Project, error) {
-	projectOptions, err := cli.NewProjectOptions(paths, cli.WithOsEnv, cli.WithDotEnv, cli.WithDiscardEnvFile)
+// Variables are interpolated from the OS environment, the supplied interpolation values, the environment specific
+// env file (when supplied) and the shared .env file, in that order of precedence.
+func rawProjectFromSources(paths []string, envFile string, values map[string]string) (*composego.Project, error) {
+	projectOptions, err := newProjectOptions(paths, envFile, values)
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := projectFromOptions(projectOptions)
 	if err != nil {
 		return nil, err
 	}
 
-	return cli.ProjectFromOptions(projectOptions)
+	if err := interpolateFilePaths(project, projectOptions.Environment); err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
+
+// newProjectOptions returns the options compose files are loaded with, including the environment they're interpolated with
+func newProjectOptions(paths []string, envFile string, values map[string]string) (*cli.ProjectOptions, error) {
+	return cli.NewProjectOptions(paths, cli.WithOsEnv, withValues(values), withEnvFile(envFile), cli.WithDotEnv, cli.WithDiscardEnvFile)
+}
+
+// projectFromOptions loads a compose-go project the way cli.ProjectFromOptions does, except that services
+// `profiles` and `annotations` are stripped from the compose files first. The pinned compose-go schema predates
+// both and rejects them, they're read from the raw compose files instead, see getComposeServicesProfiles
+// and getComposeServicesAnnotations.
+func projectFromOptions(options *cli.ProjectOptions) (*composego.Project, error) {
+	workingDir, err := options.GetWorkingDir()
+	if err != nil {
+		return nil, err
+	}
+	absWorkingDir, err := filepath.Abs(workingDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var configs []composego.ConfigFile
+	for _, path := range options.ConfigPaths {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(workingDir, path)
+		}
+
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		config, err := loader.ParseYAML(data)
+		if err != nil {
+			return nil, err
+		}
+		stripUnsupportedServicesKeys(config)
+
+		configs = append(configs, composego.ConfigFile{Filename: path, Config: config})
+	}
+
+	name := func(o *loader.Options) {
+		if nameFromEnv, ok := os.LookupEnv(cli.ComposeProjectName); ok {
+			o.Name = nameFromEnv
+			return
+		}
+		o.Name = projectNameUnsafeChars.ReplaceAllString(strings.ToLower(filepath.Base(absWorkingDir)), "")
+	}
+
+	return loader.Load(composego.ConfigDetails{
+		ConfigFiles: configs,
+		WorkingDir:  workingDir,
+		Environment: options.Environment,
+	}, loader.WithDiscardEnvFiles, name)
+}
+
+// unsupportedServiceKeys are the services keys unknown to the pinned compose-go schema
+var unsupportedServiceKeys = []string{"profiles", "annotations"}
+
+// stripUnsupportedServicesKeys removes the keys unknown to the compose-go schema from services in a parsed compose file
+func stripUnsupportedServicesKeys(config map[string]interface{}) {
+	services, _ := config["services"].(map[string]interface{})
+	for _, svc := range services {
+		if svcConfig, ok := svc.(map[string]interface{}); ok {
+			for _, key := range unsupportedServiceKeys {
+				delete(svcConfig, key)
+			}
+		}
+	}
+}
+
+// withValues adds interpolation values to the project options environment, overriding the OS environment.
+// The process environment itself is left untouched.
+func withValues(values map[string]string) cli.ProjectOptionsFn {
+	return func(o *cli.ProjectOptions) error {
+		for name, value := range values {
+			o.Environment[name] = value
+		}
+		return nil
+	}
+}
+
+// withEnvFile adds the variables of an environment specific env file to the project options environment.
+// Variables already set, e.g. in the OS environment, take precedence. Missing env files are ignored.
+func withEnvFile(file string) cli.ProjectOptionsFn {
+	return func(o *cli.ProjectOptions) error {
+		if file == "" || !fileExists(file) {
+			return nil
+		}
+
+		env, err := godotenv.Read(file)
+		if err != nil {
+			return errors.Wrapf(err, "cannot read env file %s", file)
+		}
+
+		for name, value := range env {
+			if _, set := o.Environment[name]; set {
+				continue
+			}
+			o.Environment[name] = value
+		}
+
+		log.Debugf("Loaded env file [%s]", file)
+		return nil
+	}
+}
+
+// unresolvedVariables returns the variables referenced by a compose file without a default value that aren't set
+// in the environment, as they will be interpolated with a blank string. Comments aren't interpolated and are ignored.
+func unresolvedVariables(path string, env map[string]string) ([]string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	unresolved := map[string]bool{}
+	var walk func(n *yaml.Node)
+	walk = func(n *yaml.Node) {
+		if n.Kind == yaml.ScalarNode {
+			for _, m := range variablePattern.FindAllStringSubmatch(n.Value, -1) {
+				escaped, name, modifier := m[1], m[2]+m[3], m[4]
+				if escaped != "" || modifier != "" {
+					continue
+				}
+				if _, ok := env[name]; !ok {
+					unresolved[name] = true
+				}
+			}
+		}
+		for _, c := range n.Content {
+			walk(c)
+		}
+	}
+	walk(&doc)
+
+	var names []string
+	for name := range unresolved {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// interpolateFilePaths interpolates variables left in configs & secrets file paths, e.g. ./configs/${ENV}/app.conf,
+// using the same environment as the rest of the project so that the files can be accessed when rendering.
+func interpolateFilePaths(project *composego.Project, env map[string]string) error {
+	mapping := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+
+	for name, cfg := range project.Configs {
+		if !strings.Contains(cfg.File, "$") {
+			continue
+		}
+		file, err := template.Substitute(cfg.File, mapping)
+		if err != nil {
+			return errors.Wrapf(err, "cannot interpolate config %s file path", name)
+		}
+		cfg.File = file
+		project.Configs[name] = cfg
+	}
+
+	for name, secret := range project.Secrets {
+		if !strings.Contains(secret.File, "$") {
+			continue
+		}
+		file, err := template.Substitute(secret.File, mapping)
+		if err != nil {
+			return errors.Wrapf(err, "cannot interpolate secret %s file path", name)
+		}
+		secret.File = file
+		project.Secrets[name] = secret
+	}
+
+	return nil
 }
 
 // getComposeVersion extracts version from compose file and returns a string
@@ -118,6 +332,132 @@ func getComposeVersion(file string) (string, error) {
 	return version.Version, nil
 }
 
+// getComposeServicesProfiles extracts the compose profiles of services from compose files, keyed by service name.
+// Services profiles set in later files take precedence.
+func getComposeServicesProfiles(files []string) (map[string][]string, error) {
+	out := map[string][]string{}
+
+	for _, file := range files {
+		content := struct {
+			Services map[string]struct {
+				Profiles []string `yaml:"profiles"`
+			} `yaml:"services"`
+		}{}
+
+		compose, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = yaml.Unmarshal(compose, &content); err != nil {
+			return nil, err
+		}
+
+		for name, svc := range content.Services {
+			if len(svc.Profiles) > 0 {
+				out[name] = svc.Profiles
+			}
+		}
+	}
+	return out, nil
+}
+
+// composeAnnotations are compose service annotations, set either as a map or as a list of `key=value` items
+type composeAnnotations map[string]string
+
+// UnmarshalYAML decodes compose service annotations in both their map and list forms
+func (a *composeAnnotations) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.SequenceNode {
+		var items []string
+		if err := value.Decode(&items); err != nil {
+			return err
+		}
+
+		out := composeAnnotations{}
+		for _, item := range items {
+			parts := strings.SplitN(item, "=", 2)
+			if len(parts) == 1 {
+				parts = append(parts, "")
+			}
+			out[parts[0]] = parts[1]
+		}
+		*a = out
+		return nil
+	}
+
+	var out map[string]string
+	if err := value.Decode(&out); err != nil {
+		return err
+	}
+	*a = out
+	return nil
+}
+
+// getComposeServicesAnnotations extracts the annotations of services from compose files, keyed by service name.
+// Annotation values are interpolated with the supplied environment. Annotations set in later files take precedence.
+func getComposeServicesAnnotations(files []string, env map[string]string) (map[string]map[string]string, error) {
+	mapping := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+
+	out := map[string]map[string]string{}
+
+	for _, file := range files {
+		content := struct {
+			Services map[string]struct {
+				Annotations composeAnnotations `yaml:"annotations"`
+			} `yaml:"services"`
+		}{}
+
+		compose, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = yaml.Unmarshal(compose, &content); err != nil {
+			return nil, err
+		}
+
+		for name, svc := range content.Services {
+			for key, value := range svc.Annotations {
+				interpolated, err := template.Substitute(value, mapping)
+				if err != nil {
+					return nil, errors.Wrapf(err, "cannot interpolate service %s annotation %s", name, key)
+				}
+
+				if out[name] == nil {
+					out[name] = map[string]string{}
+				}
+				out[name][key] = interpolated
+			}
+		}
+	}
+	return out, nil
+}
+
+// getComposeExtensions extracts the top level `x-` prefixed extensions from a compose file
+func getComposeExtensions(file string) (map[string]interface{}, error) {
+	var content map[string]interface{}
+
+	compose, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = yaml.Unmarshal(compose, &content); err != nil {
+		return nil, err
+	}
+
+	extensions := map[string]interface{}{}
+	for key, value := range content {
+		if strings.HasPrefix(key, "x-") {
+			extensions[key] = value
+		}
+	}
+	return extensions, nil
+}
+
 // findDefaultComposeFiles scans the workingDir to find a root docker-compose file
 // and its optional override file.
 func findDefaultComposeFiles(workingDir string) ([]string, error) {
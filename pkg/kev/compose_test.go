@@ -0,0 +1,75 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kev_test
+
+import (
+	"io/ioutil"
+	"os"
+
+	"github.com/appvia/kev/pkg/kev"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ComposeProject", func() {
+	Describe("NewComposeProject", func() {
+		Context("with variables in configs & secrets file paths", func() {
+			var project *kev.ComposeProject
+
+			BeforeEach(func() {
+				Expect(os.Setenv("KEV_TEST_CONFIG_ENV", "dev")).To(Succeed())
+
+				var err error
+				project, err = kev.NewComposeProject([]string{"testdata/interpolated-config/docker-compose.yaml"})
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			AfterEach(func() {
+				Expect(os.Unsetenv("KEV_TEST_CONFIG_ENV")).To(Succeed())
+			})
+
+			It("interpolates config file paths", func() {
+				file := project.Configs["app"].File
+				Expect(file).To(HaveSuffix("configs/dev/app.conf"))
+
+				content, err := ioutil.ReadFile(file)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(ContainSubstring("listen 8080"))
+			})
+
+			It("interpolates secret file paths", func() {
+				Expect(project.Secrets["app-secret"].File).To(HaveSuffix("configs/dev/secret.txt"))
+			})
+		})
+
+		Context("with services profiles", func() {
+			It("loads compose files using profiles unsupported by the compose schema", func() {
+				project, err := kev.NewComposeProject([]string{"testdata/profiles/docker-compose.yaml"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(project.ServiceNames()).To(ConsistOf("db", "web", "worker"))
+			})
+		})
+
+		Context("with services annotations", func() {
+			It("loads compose files using annotations unsupported by the compose schema", func() {
+				project, err := kev.NewComposeProject([]string{"testdata/annotations/docker-compose.yaml"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(project.ServiceNames()).To(ConsistOf("web", "worker"))
+			})
+		})
+	})
+})
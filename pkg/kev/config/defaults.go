@@ -16,6 +16,8 @@
 
 package config
 
+import "time"
+
 const (
 	// DefaultVolumeSize default value PV class
 	DefaultVolumeSize = "100Mi"
@@ -35,14 +37,38 @@ const (
 	// DefaultServiceAccountName is a default SA to be used
 	DefaultServiceAccountName = "default"
 
+	// DefaultIngressAPIVersion default Ingress API version
+	DefaultIngressAPIVersion = "networking.k8s.io/v1"
+
+	// LegacyIngressAPIVersion Ingress API version for clusters older than K8s 1.19
+	LegacyIngressAPIVersion = "networking.k8s.io/v1beta1"
+
+	// SeccompProfileRuntimeDefault selects the container runtime default seccomp profile
+	SeccompProfileRuntimeDefault = "RuntimeDefault"
+
+	// SeccompProfileLocalhostPrefix prefixes a seccomp profile path relative to the kubelet's seccomp profile location
+	SeccompProfileLocalhostPrefix = "localhost/"
+
+	// DefaultMonitorPath default path metrics are scraped from
+	DefaultMonitorPath = "/metrics"
+
 	// DefaultImagePullPolicy default image pull policy
 	DefaultImagePullPolicy = "IfNotPresent"
 
 	// DefaultImagePullSecret default image pull credentials secret name
 	DefaultImagePullSecret = ""
 
-	// JobWorkload workload type
-	JobWorkload = "Job"
+	// NodeAntiAffinityTopology spreads workload pods across nodes
+	NodeAntiAffinityTopology = "node"
+
+	// ZoneAntiAffinityTopology spreads workload pods across zones
+	ZoneAntiAffinityTopology = "zone"
+
+	// DefaultTopologySpreadMaxSkew default maximum pod count difference between topology domains
+	DefaultTopologySpreadMaxSkew = 1
+
+	// DefaultTopologySpreadWhenUnsatisfiable default handling of pods not satisfying the spread constraint
+	DefaultTopologySpreadWhenUnsatisfiable = "DoNotSchedule"
 
 	// DefaultReplicaNumber default number of replicas per workload
 	DefaultReplicaNumber = 1
@@ -96,6 +122,15 @@ const (
 
 	// DefaultProbeDisable default false. Enabled by default
 	DefaultProbeDisable = false
+
+	// DefaultHealthcheckProbePeriod default 10s. Kubernetes probe period used for a healthcheck test without an interval
+	DefaultHealthcheckProbePeriod = 10 * time.Second
+
+	// DefaultHealthcheckProbeTimeout default 1s. Kubernetes probe timeout used for a healthcheck test without a timeout
+	DefaultHealthcheckProbeTimeout = 1 * time.Second
+
+	// DefaultHealthcheckProbeFailureThreshold default 3. Kubernetes probe failure threshold used for a healthcheck test without retries
+	DefaultHealthcheckProbeFailureThreshold = 3
 )
 
 var (
@@ -0,0 +1,165 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+var namespaceNameRegex = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// EnvironmentExtension represents the root of the top level docker-compose extensions of an environment override
+type EnvironmentExtension struct {
+	K8S EnvK8sConfig `yaml:"x-k8s"`
+}
+
+// EnvK8sConfig represents the environment wide k8s specific fields supported by kev.
+type EnvK8sConfig struct {
+	Namespace NamespaceConfig `yaml:"namespace,omitempty"`
+	// Profiles are the compose profiles enabled in the environment
+	Profiles []string `yaml:"profiles,omitempty"`
+	// Patches are applied in order to the environment's rendered objects before they're written out
+	Patches []Patch `yaml:"patches,omitempty"`
+}
+
+// Patch is a strategic merge or JSON6902 patch applied to one of an environment's rendered objects.
+// Patches are YAML documents, e.g. a partial Deployment for a strategic merge patch or a list of operations for JSON6902.
+type Patch struct {
+	// Target is the rendered object the patch applies to
+	Target PatchTarget `yaml:"target"`
+	// StrategicMerge is a strategic merge patch, merged into the target object
+	StrategicMerge string `yaml:"strategicMerge,omitempty"`
+	// JSON6902 is a list of JSON patch operations applied to the target object
+	JSON6902 string `yaml:"json6902,omitempty"`
+}
+
+// PatchTarget identifies a rendered object by kind and name, e.g. Deployment web
+type PatchTarget struct {
+	Kind string `yaml:"kind"`
+	Name string `yaml:"name"`
+}
+
+// String returns the target as kind/name
+func (pt PatchTarget) String() string {
+	return pt.Kind + "/" + pt.Name
+}
+
+// NamespaceConfig defines the namespace of an environment's objects and its resource guardrails.
+// Resources are keyed by resource name, e.g. `requests.cpu: 4` for quotas or `memory: 512Mi` for container defaults.
+type NamespaceConfig struct {
+	// Name is the namespace the environment's objects are placed in
+	Name string `yaml:"name,omitempty"`
+	// Create renders the named Namespace object along with the environment's objects
+	Create bool `yaml:"create,omitempty"`
+	// Quota caps the aggregate resources of the namespace
+	Quota map[string]string `yaml:"quota,omitempty"`
+	// DefaultRequests are assigned to containers not requesting resources
+	DefaultRequests map[string]string `yaml:"defaultRequests,omitempty"`
+	// DefaultLimits are assigned to containers not limiting resources
+	DefaultLimits map[string]string `yaml:"defaultLimits,omitempty"`
+}
+
+// IsEmpty returns true when no namespace configuration is set
+func (nc NamespaceConfig) IsEmpty() bool {
+	return nc.Name == "" && len(nc.Quota) == 0 && len(nc.DefaultRequests) == 0 && len(nc.DefaultLimits) == 0
+}
+
+// Validate validates an environment's K8s config
+func (ekc EnvK8sConfig) Validate() error {
+	if name := ekc.Namespace.Name; name != "" && (len(name) > 63 || !namespaceNameRegex.MatchString(name)) {
+		return fmt.Errorf("namespace.name %q is invalid, use a DNS label, e.g. my-app-dev", name)
+	}
+
+	if ekc.Namespace.Create && ekc.Namespace.Name == "" {
+		return errors.New("namespace.create requires namespace.name to be set")
+	}
+
+	fields := []struct {
+		name      string
+		resources map[string]string
+	}{
+		{"namespace.quota", ekc.Namespace.Quota},
+		{"namespace.defaultRequests", ekc.Namespace.DefaultRequests},
+		{"namespace.defaultLimits", ekc.Namespace.DefaultLimits},
+	}
+
+	for _, f := range fields {
+		var names []string
+		for name := range f.resources {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			if !resourceQuantityRegex.MatchString(f.resources[name]) {
+				return fmt.Errorf(
+					"%s.%s is invalid, use a resource quantity format, e.g. 500m, 2, 10Gi",
+					f.name, name,
+				)
+			}
+		}
+	}
+
+	for i, p := range ekc.Patches {
+		if p.Target.Kind == "" || p.Target.Name == "" {
+			return fmt.Errorf("patches[%d].target requires both a kind and a name", i)
+		}
+		if (p.StrategicMerge == "") == (p.JSON6902 == "") {
+			return fmt.Errorf("patches[%d] targeting %s requires exactly one of strategicMerge or json6902", i, p.Target)
+		}
+	}
+
+	return nil
+}
+
+// ParseEnvK8sConfigFromMap parses an environment extension from the environment override's top level extensions.
+// It returns an empty config when the extension is missing.
+func ParseEnvK8sConfigFromMap(m map[string]interface{}, opts ...K8sExtensionOption) (EnvK8sConfig, error) {
+	var options extensionOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	if _, ok := m[K8SExtensionKey]; !ok {
+		return EnvK8sConfig{}, nil
+	}
+
+	var ext EnvironmentExtension
+
+	var buf bytes.Buffer
+	if err := yaml.NewEncoder(&buf).Encode(m); err != nil {
+		return EnvK8sConfig{}, err
+	}
+
+	if err := yaml.NewDecoder(&buf).Decode(&ext); err != nil {
+		return EnvK8sConfig{}, err
+	}
+
+	if !options.skipValidation {
+		if err := ext.K8S.Validate(); err != nil {
+			return EnvK8sConfig{}, err
+		}
+	}
+
+	return ext.K8S, nil
+}
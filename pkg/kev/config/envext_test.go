@@ -0,0 +1,120 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_test
+
+import (
+	"github.com/appvia/kev/pkg/kev/config"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Environment Extension", func() {
+	var extensions map[string]interface{}
+
+	BeforeEach(func() {
+		extensions = map[string]interface{}{
+			config.K8SExtensionKey: map[string]interface{}{
+				"namespace": map[string]interface{}{
+					"quota":         map[string]interface{}{"requests.cpu": "4", "pods": 20},
+					"defaultLimits": map[string]interface{}{"memory": "512Mi"},
+				},
+			},
+		}
+	})
+
+	It("loads the namespace config from an environment override's extensions", func() {
+		cfg, err := config.ParseEnvK8sConfigFromMap(extensions)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.Namespace.Quota).To(Equal(map[string]string{"requests.cpu": "4", "pods": "20"}))
+		Expect(cfg.Namespace.DefaultLimits).To(Equal(map[string]string{"memory": "512Mi"}))
+		Expect(cfg.Namespace.DefaultRequests).To(BeEmpty())
+	})
+
+	It("returns an empty config when the extension is missing", func() {
+		cfg, err := config.ParseEnvK8sConfigFromMap(map[string]interface{}{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.Namespace.IsEmpty()).To(BeTrue())
+	})
+
+	It("validates values", func() {
+		extensions[config.K8SExtensionKey].(map[string]interface{})["namespace"] = map[string]interface{}{
+			"defaultRequests": map[string]interface{}{"cpu": "lots"},
+		}
+
+		_, err := config.ParseEnvK8sConfigFromMap(extensions)
+		Expect(err).To(MatchError("namespace.defaultRequests.cpu is invalid, use a resource quantity format, e.g. 500m, 2, 10Gi"))
+	})
+
+	It("validates the namespace name", func() {
+		extensions[config.K8SExtensionKey].(map[string]interface{})["namespace"] = map[string]interface{}{
+			"name": "My_App",
+		}
+
+		_, err := config.ParseEnvK8sConfigFromMap(extensions)
+		Expect(err).To(MatchError(ContainSubstring(`namespace.name "My_App" is invalid`)))
+	})
+
+	It("requires a namespace name to create the namespace", func() {
+		extensions[config.K8SExtensionKey].(map[string]interface{})["namespace"] = map[string]interface{}{
+			"create": true,
+		}
+
+		_, err := config.ParseEnvK8sConfigFromMap(extensions)
+		Expect(err).To(MatchError("namespace.create requires namespace.name to be set"))
+	})
+
+	It("loads patches", func() {
+		extensions[config.K8SExtensionKey].(map[string]interface{})["patches"] = []interface{}{
+			map[string]interface{}{
+				"target":         map[string]interface{}{"kind": "Deployment", "name": "web"},
+				"strategicMerge": "spec:\n  minReadySeconds: 10\n",
+			},
+		}
+
+		cfg, err := config.ParseEnvK8sConfigFromMap(extensions)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.Patches).To(Equal([]config.Patch{{
+			Target:         config.PatchTarget{Kind: "Deployment", Name: "web"},
+			StrategicMerge: "spec:\n  minReadySeconds: 10\n",
+		}}))
+	})
+
+	It("requires a patch target kind and name", func() {
+		extensions[config.K8SExtensionKey].(map[string]interface{})["patches"] = []interface{}{
+			map[string]interface{}{
+				"target":         map[string]interface{}{"kind": "Deployment"},
+				"strategicMerge": "spec: {}",
+			},
+		}
+
+		_, err := config.ParseEnvK8sConfigFromMap(extensions)
+		Expect(err).To(MatchError("patches[0].target requires both a kind and a name"))
+	})
+
+	It("requires exactly one patch type", func() {
+		extensions[config.K8SExtensionKey].(map[string]interface{})["patches"] = []interface{}{
+			map[string]interface{}{
+				"target":         map[string]interface{}{"kind": "Deployment", "name": "web"},
+				"strategicMerge": "spec: {}",
+				"json6902":       "[]",
+			},
+		}
+
+		_, err := config.ParseEnvK8sConfigFromMap(extensions)
+		Expect(err).To(MatchError("patches[0] targeting Deployment/web requires exactly one of strategicMerge or json6902"))
+	})
+})
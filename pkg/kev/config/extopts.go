@@ -17,7 +17,9 @@
 package config
 
 type extensionOptions struct {
-	skipValidation bool
+	skipValidation     bool
+	defaultServiceType ServiceType
+	traefikLabels      bool
 }
 
 // K8sExtensionOption will modify parsing behaviour of the k8s extension.
@@ -29,3 +31,19 @@ func SkipValidation() K8sExtensionOption {
 		extOpts.skipValidation = true
 	}
 }
+
+// WithDefaultServiceType sets the service type inferred for services publishing ports.
+// It replaces the ClusterIP default, while a service's own k8s extension still takes precedence.
+func WithDefaultServiceType(t ServiceType) K8sExtensionOption {
+	return func(extOpts *extensionOptions) {
+		extOpts.defaultServiceType = t
+	}
+}
+
+// WithTraefikLabels infers the service expose configuration from traefik compose service labels.
+// A service's own k8s extension still takes precedence.
+func WithTraefikLabels() K8sExtensionOption {
+	return func(extOpts *extensionOptions) {
+		extOpts.traefikLabels = true
+	}
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"time"
 )
 
@@ -83,7 +84,7 @@ func DefaultLivenessProbe() LivenessProbe {
 // ReadinessProbe holds all the settings for the same k8s probe.
 type ReadinessProbe struct {
 	// TODO: find a decent way of using ProbeType here that validates the content of the string
-	Type        string `yaml:"type,omitempty"`
+	Type        string `yaml:"type,omitempty" validate:"omitempty,oneof=none exec tcp http"`
 	ProbeConfig `yaml:",inline,omitempty"`
 }
 
@@ -105,6 +106,13 @@ func DefaultReadinessProbe() ReadinessProbe {
 	}
 }
 
+// StartupProbe holds all the settings for the same k8s probe.
+// It's not defined by default, in which case no startup probe is configured for the workload.
+type StartupProbe struct {
+	Type        string `yaml:"type,omitempty" validate:"omitempty,oneof=none exec tcp http"`
+	ProbeConfig `yaml:",inline,omitempty"`
+}
+
 // ProbeConfig holds all the shared properties between liveness and readiness probe.
 type ProbeConfig struct {
 	HTTP HTTPProbe `yaml:"http,omitempty"`
@@ -118,6 +126,36 @@ type ProbeConfig struct {
 	Timeout          time.Duration `yaml:"timeout,omitempty"`
 }
 
+// Validate checks that the probe timings aren't negative and its thresholds, when set, are positive integers.
+// Probes which must succeed once to be considered successful, e.g. liveness & startup probes, only accept a success threshold of 1.
+func (pc ProbeConfig) Validate(probe string, singleSuccess bool) error {
+	durations := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"InitialDelay", pc.InitialDelay},
+		{"Period", pc.Period},
+		{"Timeout", pc.Timeout},
+	}
+	for _, d := range durations {
+		if d.value < 0 {
+			return fmt.Errorf("SvcK8sConfig.Workload.%s.%s `%s` must not be negative", probe, d.name, d.value)
+		}
+	}
+
+	if pc.FailureThreshold < 0 {
+		return fmt.Errorf("SvcK8sConfig.Workload.%s.FailureThreshold `%d` must be a positive integer", probe, pc.FailureThreshold)
+	}
+	if pc.SuccessThreshold < 0 {
+		return fmt.Errorf("SvcK8sConfig.Workload.%s.SuccessThreshold `%d` must be a positive integer", probe, pc.SuccessThreshold)
+	}
+	if singleSuccess && pc.SuccessThreshold > 1 {
+		return fmt.Errorf("SvcK8sConfig.Workload.%s.SuccessThreshold `%d` must be 1", probe, pc.SuccessThreshold)
+	}
+
+	return nil
+}
+
 // HTTPProbe holds the necessary properties to define the http check on the k8s probe.
 type HTTPProbe struct {
 	Port int    `yaml:"port"`
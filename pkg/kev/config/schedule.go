@@ -0,0 +1,136 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// cronField describes the allowed range and names of a single cron schedule field
+type cronField struct {
+	min, max int
+	names    map[string]int
+}
+
+var (
+	// cronMacros are the predefined schedules supported by K8s CronJobs
+	cronMacros = map[string]bool{
+		"@yearly":   true,
+		"@annually": true,
+		"@monthly":  true,
+		"@weekly":   true,
+		"@daily":    true,
+		"@midnight": true,
+		"@hourly":   true,
+	}
+
+	// cronFields lists the standard cron schedule fields in order:
+	// minute, hour, day of month, month, day of week
+	cronFields = []cronField{
+		{min: 0, max: 59},
+		{min: 0, max: 23},
+		{min: 1, max: 31},
+		{min: 1, max: 12, names: map[string]int{
+			"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+			"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+		}},
+		{min: 0, max: 7, names: map[string]int{
+			"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+		}},
+	}
+)
+
+// IsValidCronSchedule checks whether the supplied value is a valid cron schedule
+// in the standard five field format, or one of the predefined macros, e.g. @daily.
+func IsValidCronSchedule(schedule string) bool {
+	schedule = strings.TrimSpace(schedule)
+
+	if strings.HasPrefix(schedule, "@") {
+		return cronMacros[strings.ToLower(schedule)]
+	}
+
+	fields := strings.Fields(schedule)
+	if len(fields) != len(cronFields) {
+		return false
+	}
+
+	for i, f := range fields {
+		if !cronFields[i].valid(f) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// valid checks a single cron field expression, e.g. `*/5`, `1-5` or `mon,wed,fri`
+func (c cronField) valid(expr string) bool {
+	for _, item := range strings.Split(expr, ",") {
+		rng, step := item, ""
+		if i := strings.Index(item, "/"); i >= 0 {
+			rng, step = item[:i], item[i+1:]
+			if n, err := strconv.Atoi(step); err != nil || n <= 0 {
+				return false
+			}
+		}
+
+		if rng == "*" || rng == "?" {
+			continue
+		}
+
+		bounds := strings.SplitN(rng, "-", 2)
+		lo, ok := c.value(bounds[0])
+		if !ok {
+			return false
+		}
+
+		if len(bounds) == 2 {
+			hi, ok := c.value(bounds[1])
+			if !ok || hi < lo {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// value parses a single numeric or named cron field value and checks it's within range
+func (c cronField) value(s string) (int, bool) {
+	if n, ok := c.names[strings.ToLower(s)]; ok {
+		return n, true
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil || n < c.min || n > c.max {
+		return 0, false
+	}
+
+	return n, true
+}
+
+// validateCronScheduleIfAny validator to validate a cron schedule when one is provided
+func validateCronScheduleIfAny(fl validator.FieldLevel) bool {
+	schedule := fl.Field().String()
+	if len(schedule) == 0 {
+		return true
+	}
+	return IsValidCronSchedule(schedule)
+}
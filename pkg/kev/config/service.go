@@ -42,6 +42,14 @@ const (
 	HeadlessService ServiceType = "Headless"
 )
 
+const (
+	// SessionAffinityNone disables client session stickiness
+	SessionAffinityNone = "None"
+
+	// SessionAffinityClientIP routes requests from the same client IP to the same pod
+	SessionAffinityClientIP = "ClientIP"
+)
+
 // String converts a service type to a string value
 func (s ServiceType) String() string {
 	return string(s)
@@ -97,3 +105,16 @@ func inferServiceTypeFromComposeValue(v string) (ServiceType, error) {
 		return "", fmt.Errorf("unknown value %s, supported values are 'none, nodeport, clusterip, headless or loadbalancer'", v)
 	}
 }
+
+// DefaultServiceTypeFromValue validates a project's default service type returning its canonical value
+func DefaultServiceTypeFromValue(v string) (ServiceType, error) {
+	if strings.TrimSpace(v) == "" {
+		return "", fmt.Errorf("default service type cannot be blank")
+	}
+
+	serviceType, err := inferServiceTypeFromComposeValue(v)
+	if err != nil {
+		return "", fmt.Errorf("default service type: %s", err.Error())
+	}
+	return serviceType, nil
+}
@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -30,14 +31,20 @@ import (
 	"github.com/imdario/mergo"
 	"gopkg.in/yaml.v3"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 const (
 	K8SExtensionKey         = "x-k8s"
 	dnsSubdomainNamePattern = `^[a-zA-Z]([a-zA-Z0-9\-]+[\.]?)*[a-zA-Z0-9]$`
+	intOrPercentPattern     = `^[0-9]+%?$`
 )
 
-var dnsSubdomainNameRegex = regexp.MustCompile(dnsSubdomainNamePattern)
+var (
+	dnsSubdomainNameRegex = regexp.MustCompile(dnsSubdomainNamePattern)
+	intOrPercentRegex     = regexp.MustCompile(intOrPercentPattern)
+)
 
 // ServiceExtension represents the root of the docker-compose extensions for a service
 type ServiceExtension struct {
@@ -95,6 +102,14 @@ func (skc SvcK8sConfig) Validate() error {
 		return err
 	}
 
+	if err := validate.RegisterValidation("cronScheduleIfAny", validateCronScheduleIfAny); err != nil {
+		return err
+	}
+
+	if err := validate.RegisterValidation("intOrPercentIfAny", validateIntOrPercentIfAny); err != nil {
+		return err
+	}
+
 	err := validate.Struct(skc)
 	if err != nil {
 		validationErrors := err.(validator.ValidationErrors)
@@ -102,11 +117,76 @@ func (skc SvcK8sConfig) Validate() error {
 			if e.Tag() == "required" {
 				return fmt.Errorf("%s is required", e.StructNamespace())
 			}
+			if e.Tag() == "cronScheduleIfAny" {
+				return fmt.Errorf("%s `%v` is not a valid cron schedule", e.StructNamespace(), e.Value())
+			}
 		}
 
 		return errors.New(validationErrors[0].Error())
 	}
 
+	if WorkloadTypesEqual(skc.Workload.Type, CronJobWorkload) && skc.Workload.Schedule == "" {
+		return fmt.Errorf("SvcK8sConfig.Workload.Schedule is required for %s workload", CronJobWorkload)
+	}
+
+	if skc.Workload.BackoffLimit != nil && *skc.Workload.BackoffLimit < 0 {
+		return fmt.Errorf("SvcK8sConfig.Workload.BackoffLimit `%d` must not be negative", *skc.Workload.BackoffLimit)
+	}
+
+	if skc.Workload.TTLSecondsAfterFinished != nil && *skc.Workload.TTLSecondsAfterFinished < 0 {
+		return fmt.Errorf("SvcK8sConfig.Workload.TTLSecondsAfterFinished `%d` must not be negative", *skc.Workload.TTLSecondsAfterFinished)
+	}
+
+	if err := skc.Workload.LivenessProbe.Validate("LivenessProbe", true); err != nil {
+		return err
+	}
+
+	if err := skc.Workload.ReadinessProbe.Validate("ReadinessProbe", false); err != nil {
+		return err
+	}
+
+	if err := skc.Workload.StartupProbe.Validate("StartupProbe", true); err != nil {
+		return err
+	}
+
+	if err := skc.Workload.PodSecurity.Validate(); err != nil {
+		return err
+	}
+
+	if err := skc.Workload.Resource.Validate(); err != nil {
+		return err
+	}
+
+	if err := skc.Workload.PodDisruptionBudget.Validate(); err != nil {
+		return err
+	}
+
+	if skc.Workload.NodeAffinity != "" {
+		if _, err := labels.Parse(skc.Workload.NodeAffinity); err != nil {
+			return fmt.Errorf("SvcK8sConfig.Workload.NodeAffinity `%s` is not a valid node affinity expression: %s", skc.Workload.NodeAffinity, err.Error())
+		}
+	}
+
+	if _, err := ParseTolerations(skc.Workload.Tolerations); err != nil {
+		return fmt.Errorf("SvcK8sConfig.Workload.Tolerations %s", err.Error())
+	}
+
+	if err := skc.Workload.TopologySpread.Validate(); err != nil {
+		return err
+	}
+
+	if err := validateInitContainers(skc.Workload.InitContainers); err != nil {
+		return err
+	}
+
+	if err := validateSidecars(skc.Workload.Sidecars); err != nil {
+		return err
+	}
+
+	if err := skc.Service.Validate(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -136,17 +216,23 @@ func DefaultSvcK8sConfig() SvcK8sConfig {
 
 // SvcK8sConfigFromCompose creates a K8s service extension from a compose-go service.
 // It extracts and infers values based on rules applied to the compose-go service.
-func SvcK8sConfigFromCompose(svc *composego.ServiceConfig) (SvcK8sConfig, error) {
+func SvcK8sConfigFromCompose(svc *composego.ServiceConfig, opts ...K8sExtensionOption) (SvcK8sConfig, error) {
 	var (
-		cfg    SvcK8sConfig
-		k8sExt SvcK8sConfig
+		cfg     SvcK8sConfig
+		k8sExt  SvcK8sConfig
+		options extensionOptions
 	)
 
+	for _, o := range opts {
+		o(&options)
+	}
+
 	cfg.Workload.ServiceAccountName = DefaultServiceAccountName
 	cfg.Workload.Type = WorkloadTypeFromCompose(svc)
 	cfg.Workload.Replicas = WorkloadReplicasFromCompose(svc)
 	cfg.Workload.RollingUpdateMaxSurge = WorkloadRollingUpdateMaxSurgeFromCompose(svc)
 	cfg.Workload.RestartPolicy = WorkloadRestartPolicyFromCompose(svc)
+	cfg.Workload.AntiAffinity = AntiAffinityFromCompose(svc)
 	cfg.Workload.LivenessProbe = LivenessProbeFromCompose(svc)
 	cfg.Workload.ReadinessProbe = DefaultReadinessProbe()
 	cfg.Workload.ImagePull = ImagePullWithDefaults()
@@ -159,12 +245,16 @@ func SvcK8sConfigFromCompose(svc *composego.ServiceConfig) (SvcK8sConfig, error)
 	}
 	cfg.Workload.Resource = svcResource
 
-	svcType, err := ServiceTypeFromCompose(svc)
+	svcType, err := ServiceTypeFromCompose(svc, opts...)
 	if err != nil {
 		return SvcK8sConfig{}, err
 	}
 	cfg.Service.Type = svcType
 
+	if options.traefikLabels {
+		cfg.Service.Expose = ExposeFromTraefikLabels(svc)
+	}
+
 	if _, ok := svc.Extensions[K8SExtensionKey]; ok {
 		if k8sExt, err = ParseSvcK8sConfigFromMap(svc.Extensions, SkipValidation()); err != nil {
 			return SvcK8sConfig{}, err
@@ -191,18 +281,37 @@ func WorkloadRollingUpdateMaxSurgeFromCompose(svc *composego.ServiceConfig) int
 	return int(*svc.Deploy.UpdateConfig.Parallelism)
 }
 
+// ResourceFromCompose extracts workload resources from a compose-go service deploy block.
+// Compose reservations map to k8s resource requests, while compose limits map to k8s resource limits.
+// Service level (compose v2 style) mem_limit, mem_reservation and cpus are used
+// unless overridden in the deploy block.
 func ResourceFromCompose(svc *composego.ServiceConfig) (Resource, error) {
 	var memLimit string
 	var cpuLimit string
+	if b := int64(svc.MemLimit); b > 0 {
+		memLimit = getMemoryQuantity(b)
+	}
+	if svc.CPUS > 0 {
+		cpuLimit = strconv.FormatFloat(float64(svc.CPUS), 'f', -1, 32)
+	}
 	if svc.Deploy != nil && svc.Deploy.Resources.Limits != nil {
-		memLimit = getMemoryQuantity(int64(svc.Deploy.Resources.Limits.MemoryBytes))
-		cpuLimit = svc.Deploy.Resources.Limits.NanoCPUs
+		if b := int64(svc.Deploy.Resources.Limits.MemoryBytes); b > 0 {
+			memLimit = getMemoryQuantity(b)
+		}
+		if cpus := svc.Deploy.Resources.Limits.NanoCPUs; cpus != "" {
+			cpuLimit = cpus
+		}
 	}
 
 	var memRequest string
 	var cpuRequest string
+	if b := int64(svc.MemReservation); b > 0 {
+		memRequest = getMemoryQuantity(b)
+	}
 	if svc.Deploy != nil && svc.Deploy.Resources.Reservations != nil {
-		memRequest = getMemoryQuantity(int64(svc.Deploy.Resources.Reservations.MemoryBytes))
+		if b := int64(svc.Deploy.Resources.Reservations.MemoryBytes); b > 0 {
+			memRequest = getMemoryQuantity(b)
+		}
 		cpuRequest = svc.Deploy.Resources.Reservations.NanoCPUs
 	}
 
@@ -267,15 +376,27 @@ func PodSecurityWithDefaults() PodSecurity {
 	}
 }
 
-func ServiceTypeFromCompose(svc *composego.ServiceConfig) (ServiceType, error) {
+// ServiceTypeFromCompose infers a service type from a compose-go service.
+// Services publishing ports default to ClusterIP, unless a default service type option is supplied.
+func ServiceTypeFromCompose(svc *composego.ServiceConfig, opts ...K8sExtensionOption) (ServiceType, error) {
+	var options extensionOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
 	var candidate = "none"
 
 	if len(svc.Ports) > 0 {
 		candidate = "clusterip"
+		if options.defaultServiceType != "" {
+			candidate = options.defaultServiceType.String()
+		}
 	}
 
-	if svc.Deploy != nil && svc.Deploy.EndpointMode == "vip" {
-		candidate = "nodeport"
+	// swarm endpoint mode: `dnsrr` (DNS round-robin) maps to a headless service.
+	// `vip` is the swarm default and keeps the inferred virtual IP service type.
+	if svc.Deploy != nil && strings.EqualFold(svc.Deploy.EndpointMode, "dnsrr") {
+		candidate = "headless"
 	}
 
 	serviceType, err := inferServiceTypeFromComposeValue(candidate)
@@ -314,9 +435,25 @@ func WorkloadReplicasFromCompose(svc *composego.ServiceConfig) int {
 	return int(*svc.Deploy.Replicas)
 }
 
+// AntiAffinityFromCompose infers the workload's pod anti-affinity from a compose-go service deploy mode.
+// K8s Jobs can't target all nodes, so a `global-job` is approximated by a Job whose pods are required to run on distinct nodes.
+func AntiAffinityFromCompose(svc *composego.ServiceConfig) AntiAffinity {
+	if svc.Deploy != nil && svc.Deploy.Mode == "global-job" {
+		return AntiAffinity{Topology: NodeAntiAffinityTopology, Required: true}
+	}
+	return AntiAffinity{}
+}
+
+// WorkloadTypeFromCompose infers a workload type from a compose-go service deploy mode and volumes.
+// Both `replicated-job` and `global-job` deploy modes run as a Job.
 func WorkloadTypeFromCompose(svc *composego.ServiceConfig) WorkloadType {
-	if svc.Deploy != nil && svc.Deploy.Mode == "global" {
-		return DaemonSetWorkload
+	if svc.Deploy != nil {
+		switch svc.Deploy.Mode {
+		case "global":
+			return DaemonSetWorkload
+		case "replicated-job", "global-job":
+			return JobWorkload
+		}
 	}
 
 	if len(svc.Volumes) != 0 {
@@ -363,6 +500,19 @@ func LivenessProbeFromCompose(svc *composego.ServiceConfig) LivenessProbe {
 		res.Period = time.Duration(*healthcheck.Interval)
 	}
 
+	// a probe without timings is rejected by Kubernetes, default these when only a test is present
+	if len(test) > 0 {
+		if res.Period == 0 {
+			res.Period = DefaultHealthcheckProbePeriod
+		}
+		if res.Timeout == 0 {
+			res.Timeout = DefaultHealthcheckProbeTimeout
+		}
+		if res.FailureThreshold == 0 {
+			res.FailureThreshold = DefaultHealthcheckProbeFailureThreshold
+		}
+	}
+
 	return res
 }
 
@@ -405,6 +555,14 @@ func ParseSvcK8sConfigFromMap(m map[string]interface{}, opts ...K8sExtensionOpti
 	return extensions.K8S, nil
 }
 
+func validateIntOrPercentIfAny(fl validator.FieldLevel) bool {
+	target := fl.Field().String()
+	if len(target) == 0 {
+		return true
+	}
+	return intOrPercentRegex.MatchString(target)
+}
+
 func validateDNSSubdomainNameIfAny(fl validator.FieldLevel) bool {
 	target := fl.Field().String()
 	if len(target) == 0 {
@@ -415,20 +573,34 @@ func validateDNSSubdomainNameIfAny(fl validator.FieldLevel) bool {
 
 // Workload holds all the workload-related k8s configurations.
 type Workload struct {
-	Type                  WorkloadType      `yaml:"type,omitempty" validate:"workloadType"`
-	Replicas              int               `yaml:"replicas" validate:""`
-	ServiceAccountName    string            `yaml:"serviceAccountName,omitempty" validate:"subdomainIfAny"`
-	RollingUpdateMaxSurge int               `yaml:"rollingUpdateMaxSurge,omitempty" validate:""`
-	Annotations           map[string]string `yaml:"annotations,omitempty"`
-	LivenessProbe         LivenessProbe     `yaml:"livenessProbe,omitempty"`
-	ReadinessProbe        ReadinessProbe    `yaml:"readinessProbe,omitempty"`
-	RestartPolicy         RestartPolicy     `yaml:"restartPolicy,omitempty" validate:"restartPolicy"`
-	ImagePull             ImagePull         `yaml:"imagePull,omitempty"`
-	Resource              Resource          `yaml:"resource,omitempty"`
-	Autoscale             Autoscale         `yaml:"autoscale,omitempty"`
-	PodSecurity           PodSecurity       `yaml:"podSecurity,omitempty"`
-	Command               []string          `yaml:"command,omitempty"`
-	CommandArgs           []string          `yaml:"commandArgs,omitempty"`
+	Type                    WorkloadType             `yaml:"type,omitempty" validate:"workloadType"`
+	Replicas                int                      `yaml:"replicas" validate:""`
+	ServiceAccountName      string                   `yaml:"serviceAccountName,omitempty" validate:"subdomainIfAny"`
+	RollingUpdateMaxSurge   int                      `yaml:"rollingUpdateMaxSurge,omitempty" validate:""`
+	Annotations             map[string]string        `yaml:"annotations,omitempty"`
+	LivenessProbe           LivenessProbe            `yaml:"livenessProbe,omitempty"`
+	ReadinessProbe          ReadinessProbe           `yaml:"readinessProbe,omitempty"`
+	StartupProbe            StartupProbe             `yaml:"startupProbe,omitempty"`
+	RestartPolicy           RestartPolicy            `yaml:"restartPolicy,omitempty" validate:"restartPolicy"`
+	ImagePull               ImagePull                `yaml:"imagePull,omitempty"`
+	Resource                Resource                 `yaml:"resource,omitempty"`
+	Autoscale               Autoscale                `yaml:"autoscale,omitempty"`
+	PodSecurity             PodSecurity              `yaml:"podSecurity,omitempty"`
+	ContainerSecurity       ContainerSecurity        `yaml:"containerSecurity,omitempty"`
+	Command                 []string                 `yaml:"command,omitempty"`
+	CommandArgs             []string                 `yaml:"commandArgs,omitempty"`
+	Schedule                string                   `yaml:"schedule,omitempty" validate:"cronScheduleIfAny"`
+	BackoffLimit            *int32                   `yaml:"backoffLimit,omitempty"`
+	TTLSecondsAfterFinished *int32                   `yaml:"ttlSecondsAfterFinished,omitempty"`
+	PodDisruptionBudget     PodDisruptionBudget      `yaml:"podDisruptionBudget,omitempty"`
+	AntiAffinity            AntiAffinity             `yaml:"antiAffinity,omitempty"`
+	TopologySpread          TopologySpread           `yaml:"topologySpread,omitempty"`
+	TerminationMessage      TerminationMessage       `yaml:"terminationMessage,omitempty"`
+	NodeSelector            map[string]string        `yaml:"nodeSelector,omitempty"`
+	NodeAffinity            string                   `yaml:"nodeAffinity,omitempty"`
+	Tolerations             []string                 `yaml:"tolerations,omitempty"`
+	InitContainers          map[string]InitContainer `yaml:"initContainers,omitempty"`
+	Sidecars                map[string]Sidecar       `yaml:"sidecars,omitempty"`
 }
 
 type Resource struct {
@@ -440,6 +612,31 @@ type Resource struct {
 	MaxStorage string `yaml:"maxStorage,omitempty"`
 }
 
+// Validate checks that all configured resource values are valid K8s quantities
+func (r Resource) Validate() error {
+	quantities := []struct {
+		name  string
+		value string
+	}{
+		{"Memory", r.Memory},
+		{"MaxMemory", r.MaxMemory},
+		{"CPU", r.CPU},
+		{"MaxCPU", r.MaxCPU},
+		{"Storage", r.Storage},
+		{"MaxStorage", r.MaxStorage},
+	}
+
+	for _, q := range quantities {
+		if q.value == "" {
+			continue
+		}
+		if _, err := resource.ParseQuantity(q.value); err != nil {
+			return fmt.Errorf("SvcK8sConfig.Workload.Resource.%s `%s` is not a valid quantity", q.name, q.value)
+		}
+	}
+	return nil
+}
+
 type ImagePull struct {
 	Policy string `yaml:"policy,omitempty" validate:"oneof='' IfNotPresent Never Always"`
 	Secret string `yaml:"secret,omitempty"`
@@ -451,21 +648,223 @@ type Autoscale struct {
 	MemoryThreshold int `yaml:"memThreshold,omitempty"`
 }
 
+// PodDisruptionBudget holds the workload's pod disruption budget configuration.
+// Values are either an absolute number of pods or a percentage, e.g. "1" or "50%".
+type PodDisruptionBudget struct {
+	MinAvailable   string `yaml:"minAvailable,omitempty" validate:"intOrPercentIfAny"`
+	MaxUnavailable string `yaml:"maxUnavailable,omitempty" validate:"intOrPercentIfAny"`
+}
+
+// Validate checks that at most one of min available or max unavailable has been configured
+func (pdb PodDisruptionBudget) Validate() error {
+	if pdb.MinAvailable != "" && pdb.MaxUnavailable != "" {
+		return errors.New("SvcK8sConfig.Workload.PodDisruptionBudget accepts only one of minAvailable or maxUnavailable")
+	}
+	return nil
+}
+
+// InitContainer holds the configuration of an init container run to completion before the workload's container starts.
+// It inherits the workload container's environment, overridden by Env, and volume mounts unless InheritVolumeMounts is false.
+type InitContainer struct {
+	Image               string            `yaml:"image,omitempty"`
+	Command             []string          `yaml:"command,omitempty"`
+	Args                []string          `yaml:"args,omitempty"`
+	Env                 map[string]string `yaml:"env,omitempty"`
+	InheritVolumeMounts *bool             `yaml:"inheritVolumeMounts,omitempty"`
+}
+
+// validateInitContainers checks that init containers have a valid name and an image
+func validateInitContainers(initContainers map[string]InitContainer) error {
+	for name, c := range initContainers {
+		if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+			return fmt.Errorf("SvcK8sConfig.Workload.InitContainers `%s` is not a valid name: %s", name, strings.Join(errs, ", "))
+		}
+		if strings.TrimSpace(c.Image) == "" {
+			return fmt.Errorf("SvcK8sConfig.Workload.InitContainers.%s.Image is required", name)
+		}
+	}
+	return nil
+}
+
+// Sidecar holds the configuration of an additional container running alongside the workload's container, e.g. a logging or proxy agent.
+// It shares the pod's volumes, mounted as they are in the workload's container.
+type Sidecar struct {
+	Image    string   `yaml:"image,omitempty"`
+	Command  []string `yaml:"command,omitempty"`
+	Args     []string `yaml:"args,omitempty"`
+	Ports    []int    `yaml:"ports,omitempty"`
+	Resource Resource `yaml:"resource,omitempty"`
+}
+
+// validateSidecars checks that sidecars have a valid name, an image, valid ports and resource quantities
+func validateSidecars(sidecars map[string]Sidecar) error {
+	for name, c := range sidecars {
+		if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+			return fmt.Errorf("SvcK8sConfig.Workload.Sidecars `%s` is not a valid name: %s", name, strings.Join(errs, ", "))
+		}
+		if strings.TrimSpace(c.Image) == "" {
+			return fmt.Errorf("SvcK8sConfig.Workload.Sidecars.%s.Image is required", name)
+		}
+		for _, port := range c.Ports {
+			if port < 1 || port > 65535 {
+				return fmt.Errorf("SvcK8sConfig.Workload.Sidecars.%s.Ports `%d` is not a valid port", name, port)
+			}
+		}
+		if err := c.Resource.Validate(); err != nil {
+			return fmt.Errorf("SvcK8sConfig.Workload.Sidecars.%s: %s", name, err.Error())
+		}
+	}
+	return nil
+}
+
+// AntiAffinity holds the workload's pod anti-affinity configuration,
+// spreading the workload's pods across nodes or zones.
+type AntiAffinity struct {
+	Topology string `yaml:"topology,omitempty" validate:"omitempty,oneof=node zone"`
+	Required bool   `yaml:"required,omitempty"`
+}
+
+// TopologySpread holds the workload's pod topology spread constraint configuration,
+// evenly spreading the workload's pods across the supplied topology domains.
+type TopologySpread struct {
+	MaxSkew           int    `yaml:"maxSkew,omitempty" validate:"omitempty,min=1"`
+	TopologyKey       string `yaml:"topologyKey,omitempty"`
+	WhenUnsatisfiable string `yaml:"whenUnsatisfiable,omitempty" validate:"omitempty,oneof=DoNotSchedule ScheduleAnyway"`
+}
+
+// Validate checks that a topology key is present when other topology spread settings are configured
+func (ts TopologySpread) Validate() error {
+	if ts.TopologyKey == "" && (ts.MaxSkew != 0 || ts.WhenUnsatisfiable != "") {
+		return errors.New("SvcK8sConfig.Workload.TopologySpread.TopologyKey is required when topology spread is configured")
+	}
+	return nil
+}
+
+// TerminationMessage holds the workload container's termination message configuration,
+// controlling how the reason for a container's termination is surfaced.
+type TerminationMessage struct {
+	Policy string `yaml:"policy,omitempty" validate:"omitempty,oneof=File FallbackToLogsOnError"`
+	Path   string `yaml:"path,omitempty" validate:"omitempty,startswith=/"`
+}
+
 type PodSecurity struct {
-	RunAsUser  *int64 `yaml:"runAsUser,omitempty"`
-	RunAsGroup *int64 `yaml:"runAsGroup,omitempty"`
-	FsGroup    *int64 `yaml:"fsGroup,omitempty"`
+	RunAsUser      *int64 `yaml:"runAsUser,omitempty"`
+	RunAsGroup     *int64 `yaml:"runAsGroup,omitempty"`
+	FsGroup        *int64 `yaml:"fsGroup,omitempty"`
+	RunAsNonRoot   *bool  `yaml:"runAsNonRoot,omitempty"`
+	SeccompProfile string `yaml:"seccompProfile,omitempty"`
+	// FsGroupChangePolicy defines how volumes ownership & permissions are changed to match fsGroup
+	FsGroupChangePolicy string `yaml:"fsGroupChangePolicy,omitempty" validate:"omitempty,oneof=Always OnRootMismatch"`
+}
+
+// ContainerSecurity holds the container security context hardening flags.
+// Flags are kept as strings so that non boolean values get skipped with a warning at render time.
+type ContainerSecurity struct {
+	ReadOnlyRootFilesystem   string `yaml:"readOnlyRootFilesystem,omitempty"`
+	AllowPrivilegeEscalation string `yaml:"allowPrivilegeEscalation,omitempty"`
+}
+
+// Validate checks that the seccomp profile is either the runtime default or a localhost profile
+func (ps PodSecurity) Validate() error {
+	if ps.SeccompProfile == "" || ps.SeccompProfile == SeccompProfileRuntimeDefault {
+		return nil
+	}
+	if strings.HasPrefix(ps.SeccompProfile, SeccompProfileLocalhostPrefix) &&
+		len(ps.SeccompProfile) > len(SeccompProfileLocalhostPrefix) {
+		return nil
+	}
+	return fmt.Errorf("SvcK8sConfig.Workload.PodSecurity.SeccompProfile `%s` must be either %s or %s<profile path>",
+		ps.SeccompProfile, SeccompProfileRuntimeDefault, SeccompProfileLocalhostPrefix)
 }
 
 // Service will hold the service specific extensions in the future.
 type Service struct {
-	Type     ServiceType `yaml:"type" validate:"serviceType"`
-	NodePort int         `yaml:"nodeport,omitempty"`
-	Expose   Expose      `yaml:"expose,omitempty"`
+	Type                   ServiceType       `yaml:"type" validate:"serviceType"`
+	NodePort               int               `yaml:"nodeport,omitempty"`
+	LoadBalancerIP         string            `yaml:"loadBalancerIP,omitempty" validate:"omitempty,ip"`
+	SessionAffinity        string            `yaml:"sessionAffinity,omitempty" validate:"omitempty,oneof=None ClientIP"`
+	SessionAffinityTimeout int               `yaml:"sessionAffinityTimeout,omitempty" validate:"omitempty,min=1,max=86400"`
+	Annotations            map[string]string `yaml:"annotations,omitempty"`
+	Expose                 Expose            `yaml:"expose,omitempty"`
+	Monitor                Monitor           `yaml:"monitor,omitempty"`
+	// PortGroups maps group names to the service ports split out of the service into a dedicated ClusterIP service
+	PortGroups map[string][]int `yaml:"portGroups,omitempty"`
+}
+
+// Validate checks that a session affinity timeout is only configured for ClientIP session affinity
+func (s Service) Validate() error {
+	if s.SessionAffinityTimeout != 0 && s.SessionAffinity != SessionAffinityClientIP {
+		return fmt.Errorf("SvcK8sConfig.Service.SessionAffinityTimeout is only applicable to %s session affinity", SessionAffinityClientIP)
+	}
+
+	grouped := map[int]string{}
+	for group, ports := range s.PortGroups {
+		if errs := validation.IsDNS1123Label(group); len(errs) > 0 {
+			return fmt.Errorf("SvcK8sConfig.Service.PortGroups group `%s` is not a valid name: %s", group, strings.Join(errs, ", "))
+		}
+		for _, port := range ports {
+			if port < 1 || port > 65535 {
+				return fmt.Errorf("SvcK8sConfig.Service.PortGroups group `%s` port `%d` is not a valid port", group, port)
+			}
+			if other, ok := grouped[port]; ok && other != group {
+				return fmt.Errorf("SvcK8sConfig.Service.PortGroups port `%d` is in more than one group", port)
+			}
+			grouped[port] = group
+		}
+	}
+
+	return s.Expose.Validate()
+}
+
+// Monitor defines a Prometheus operator ServiceMonitor scraping the service metrics
+type Monitor struct {
+	Enabled bool   `yaml:"enabled,omitempty"`
+	Path    string `yaml:"path,omitempty"`
+	Port    string `yaml:"port,omitempty"`
 }
 
 type Expose struct {
 	Domain             string            `yaml:"domain,omitempty"`
 	TlsSecret          string            `yaml:"tlsSecret,omitempty"`
 	IngressAnnotations map[string]string `yaml:"ingressAnnotations,omitempty"`
+	IngressAPIVersion  string            `yaml:"ingressApiVersion,omitempty" validate:"omitempty,oneof=networking.k8s.io/v1 networking.k8s.io/v1beta1"`
+	// Groups expose the service on additional ingress controllers, producing an Ingress per group
+	Groups []ExposeGroup `yaml:"groups,omitempty"`
+}
+
+// ExposeGroup exposes the service on an ingress controller selected by its ingress class
+type ExposeGroup struct {
+	Name               string            `yaml:"name"`
+	IngressClass       string            `yaml:"ingressClass,omitempty"`
+	Domain             string            `yaml:"domain"`
+	TlsSecret          string            `yaml:"tlsSecret,omitempty"`
+	IngressAnnotations map[string]string `yaml:"ingressAnnotations,omitempty"`
+}
+
+// Validate checks expose groups are uniquely named and that their hosts are unique within each group
+func (e Expose) Validate() error {
+	names := map[string]bool{}
+	for _, g := range e.Groups {
+		if errs := validation.IsDNS1123Label(g.Name); len(errs) > 0 {
+			return fmt.Errorf("SvcK8sConfig.Service.Expose.Groups group `%s` is not a valid name: %s", g.Name, strings.Join(errs, ", "))
+		}
+		if names[g.Name] {
+			return fmt.Errorf("SvcK8sConfig.Service.Expose.Groups group `%s` is defined more than once", g.Name)
+		}
+		names[g.Name] = true
+
+		if strings.TrimSpace(g.Domain) == "" {
+			return fmt.Errorf("SvcK8sConfig.Service.Expose.Groups group `%s` must have a domain", g.Name)
+		}
+
+		hosts := map[string]bool{}
+		for _, host := range regexp.MustCompile("[ ,]*,[ ,]*").Split(strings.TrimSpace(g.Domain), -1) {
+			if hosts[host] {
+				return fmt.Errorf("SvcK8sConfig.Service.Expose.Groups group `%s` host `%s` is defined more than once", g.Name, host)
+			}
+			hosts[host] = true
+		}
+	}
+
+	return nil
 }
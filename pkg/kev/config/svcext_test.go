@@ -18,6 +18,7 @@ package config_test
 
 import (
 	"bytes"
+	"time"
 
 	"github.com/appvia/kev/pkg/kev/config"
 	composego "github.com/compose-spec/compose-go/types"
@@ -213,6 +214,65 @@ var _ = Describe("Service Extension", func() {
 						})
 					})
 				})
+
+				Context("service type", func() {
+					BeforeEach(func() {
+						svc.Ports = []composego.ServicePortConfig{{Target: 8080}}
+					})
+
+					AfterEach(func() {
+						svc.Ports = nil
+					})
+
+					When("deploy endpoint mode is vip", func() {
+						BeforeEach(func() {
+							svc.Deploy = &composego.DeployConfig{EndpointMode: "vip"}
+						})
+
+						It("infers ClusterIP service", func() {
+							Expect(parsedK8sCfg.Service.Type).To(Equal(config.ClusterIPService))
+						})
+					})
+
+					When("deploy endpoint mode is dnsrr", func() {
+						BeforeEach(func() {
+							svc.Deploy = &composego.DeployConfig{EndpointMode: "dnsrr"}
+						})
+
+						It("infers Headless service", func() {
+							Expect(parsedK8sCfg.Service.Type).To(Equal(config.HeadlessService))
+						})
+					})
+				})
+
+				Context("workload type", func() {
+					When("deploy mode is replicated-job", func() {
+						BeforeEach(func() {
+							replicas := uint64(3)
+							svc.Deploy = &composego.DeployConfig{Mode: "replicated-job", Replicas: &replicas}
+						})
+
+						It("infers a Job with completions from replicas", func() {
+							Expect(parsedK8sCfg.Workload.Type).To(Equal(config.JobWorkload))
+							Expect(parsedK8sCfg.Workload.Replicas).To(Equal(3))
+							Expect(parsedK8sCfg.Workload.AntiAffinity).To(Equal(config.AntiAffinity{}))
+						})
+					})
+
+					When("deploy mode is global-job", func() {
+						BeforeEach(func() {
+							svc.Deploy = &composego.DeployConfig{Mode: "global-job"}
+						})
+
+						It("infers a Job with pods required on distinct nodes", func() {
+							Expect(parsedK8sCfg.Workload.Type).To(Equal(config.JobWorkload))
+							Expect(parsedK8sCfg.Workload.AntiAffinity).To(Equal(config.AntiAffinity{
+								Topology: config.NodeAntiAffinityTopology,
+								Required: true,
+							}))
+						})
+					})
+				})
 			})
 
 			Context("when running validate", func() {
@@ -237,6 +297,372 @@ var _ = Describe("Service Extension", func() {
 						Expect(err.Error()).To(ContainSubstring("SvcK8sConfig.Workload.Type"))
 					})
 				})
+
+				Context("with an unknown liveness probe type", func() {
+					It("returns error", func() {
+						svcK8sConfig := config.DefaultSvcK8sConfig()
+						svcK8sConfig.Workload.LivenessProbe.Type = "grpc"
+
+						err = svcK8sConfig.Validate()
+						Expect(err).To(HaveOccurred())
+						Expect(err.Error()).To(ContainSubstring("SvcK8sConfig.Workload.LivenessProbe.Type"))
+					})
+				})
+
+				Context("with an unknown readiness probe type", func() {
+					It("returns error", func() {
+						svcK8sConfig := config.DefaultSvcK8sConfig()
+						svcK8sConfig.Workload.ReadinessProbe.Type = "grpc"
+
+						err = svcK8sConfig.Validate()
+						Expect(err).To(HaveOccurred())
+						Expect(err.Error()).To(ContainSubstring("SvcK8sConfig.Workload.ReadinessProbe.Type"))
+					})
+				})
+
+				Context("with http and tcp probe types", func() {
+					It("passes validation", func() {
+						svcK8sConfig := config.DefaultSvcK8sConfig()
+						svcK8sConfig.Workload.LivenessProbe.Type = config.ProbeTypeHTTP.String()
+						svcK8sConfig.Workload.LivenessProbe.HTTP = config.HTTPProbe{Port: 8080, Path: "/status"}
+						svcK8sConfig.Workload.ReadinessProbe.Type = config.ProbeTypeTCP.String()
+						svcK8sConfig.Workload.ReadinessProbe.TCP = config.TCPProbe{Port: 8080}
+
+						Expect(svcK8sConfig.Validate()).To(Succeed())
+					})
+				})
+
+				Context("with both pod disruption budget min available and max unavailable", func() {
+					It("returns error", func() {
+						svcK8sConfig := config.DefaultSvcK8sConfig()
+						svcK8sConfig.Workload.PodDisruptionBudget.MinAvailable = "1"
+						svcK8sConfig.Workload.PodDisruptionBudget.MaxUnavailable = "50%"
+
+						err = svcK8sConfig.Validate()
+						Expect(err).To(MatchError("SvcK8sConfig.Workload.PodDisruptionBudget accepts only one of minAvailable or maxUnavailable"))
+					})
+				})
+
+				Context("with invalid seccomp profile", func() {
+					It("returns error", func() {
+						svcK8sConfig := config.DefaultSvcK8sConfig()
+						svcK8sConfig.Workload.PodSecurity.SeccompProfile = "Unconfined"
+
+						err = svcK8sConfig.Validate()
+						Expect(err).To(HaveOccurred())
+						Expect(err.Error()).To(ContainSubstring("SvcK8sConfig.Workload.PodSecurity.SeccompProfile"))
+					})
+				})
+
+				Context("with runtime default seccomp profile", func() {
+					It("succeeds", func() {
+						svcK8sConfig := config.DefaultSvcK8sConfig()
+						svcK8sConfig.Workload.PodSecurity.SeccompProfile = config.SeccompProfileRuntimeDefault
+
+						Expect(svcK8sConfig.Validate()).To(Succeed())
+					})
+				})
+
+				Context("with negative probe failure threshold", func() {
+					It("returns error", func() {
+						svcK8sConfig := config.DefaultSvcK8sConfig()
+						svcK8sConfig.Workload.ReadinessProbe.FailureThreshold = -1
+
+						err = svcK8sConfig.Validate()
+						Expect(err).To(MatchError("SvcK8sConfig.Workload.ReadinessProbe.FailureThreshold `-1` must be a positive integer"))
+					})
+				})
+
+				Context("with negative probe period", func() {
+					It("returns error", func() {
+						svcK8sConfig := config.DefaultSvcK8sConfig()
+						svcK8sConfig.Workload.LivenessProbe.Period = -time.Second
+
+						err = svcK8sConfig.Validate()
+						Expect(err).To(MatchError("SvcK8sConfig.Workload.LivenessProbe.Period `-1s` must not be negative"))
+					})
+				})
+
+				Context("with liveness probe success threshold other than 1", func() {
+					It("returns error", func() {
+						svcK8sConfig := config.DefaultSvcK8sConfig()
+						svcK8sConfig.Workload.LivenessProbe.SuccessThreshold = 3
+
+						err = svcK8sConfig.Validate()
+						Expect(err).To(MatchError("SvcK8sConfig.Workload.LivenessProbe.SuccessThreshold `3` must be 1"))
+					})
+				})
+
+				Context("with readiness probe success threshold greater than 1", func() {
+					It("succeeds", func() {
+						svcK8sConfig := config.DefaultSvcK8sConfig()
+						svcK8sConfig.Workload.ReadinessProbe.SuccessThreshold = 3
+
+						Expect(svcK8sConfig.Validate()).To(Succeed())
+					})
+				})
+
+				Context("with an init container without image", func() {
+					It("returns error", func() {
+						svcK8sConfig := config.DefaultSvcK8sConfig()
+						svcK8sConfig.Workload.InitContainers = map[string]config.InitContainer{
+							"migrate": {Command: []string{"./migrate"}},
+						}
+
+						err = svcK8sConfig.Validate()
+						Expect(err).To(MatchError("SvcK8sConfig.Workload.InitContainers.migrate.Image is required"))
+					})
+				})
+
+				Context("with a sidecar with an invalid port", func() {
+					It("returns error", func() {
+						svcK8sConfig := config.DefaultSvcK8sConfig()
+						svcK8sConfig.Workload.Sidecars = map[string]config.Sidecar{
+							"proxy": {Image: "envoyproxy/envoy", Ports: []int{70000}},
+						}
+
+						err = svcK8sConfig.Validate()
+						Expect(err).To(MatchError("SvcK8sConfig.Workload.Sidecars.proxy.Ports `70000` is not a valid port"))
+					})
+				})
+
+				Context("with a port in more than one port group", func() {
+					It("returns error", func() {
+						svcK8sConfig := config.DefaultSvcK8sConfig()
+						svcK8sConfig.Service.PortGroups = map[string][]int{"metrics": {9090}}
+						svcK8sConfig.Service.PortGroups["admin"] = []int{9090}
+
+						err = svcK8sConfig.Validate()
+						Expect(err).To(MatchError("SvcK8sConfig.Service.PortGroups port `9090` is in more than one group"))
+					})
+				})
+
+				Context("with an invalid port group name", func() {
+					It("returns error", func() {
+						svcK8sConfig := config.DefaultSvcK8sConfig()
+						svcK8sConfig.Service.PortGroups = map[string][]int{"Metrics_Port": {9090}}
+
+						err = svcK8sConfig.Validate()
+						Expect(err).To(HaveOccurred())
+						Expect(err.Error()).To(ContainSubstring("SvcK8sConfig.Service.PortGroups group `Metrics_Port` is not a valid name"))
+					})
+				})
+
+				Context("with a host defined more than once in an expose group", func() {
+					It("returns error", func() {
+						svcK8sConfig := config.DefaultSvcK8sConfig()
+						svcK8sConfig.Service.Expose.Groups = []config.ExposeGroup{
+							{Name: "internal", IngressClass: "nginx-internal", Domain: "app.internal, app.internal"},
+						}
+
+						err = svcK8sConfig.Validate()
+						Expect(err).To(MatchError("SvcK8sConfig.Service.Expose.Groups group `internal` host `app.internal` is defined more than once"))
+					})
+				})
+
+				Context("with the same host in different expose groups", func() {
+					It("doesn't return error", func() {
+						svcK8sConfig := config.DefaultSvcK8sConfig()
+						svcK8sConfig.Service.Expose.Groups = []config.ExposeGroup{
+							{Name: "internal", IngressClass: "nginx-internal", Domain: "app.example.com"},
+							{Name: "external", IngressClass: "nginx-external", Domain: "app.example.com"},
+						}
+
+						Expect(svcK8sConfig.Validate()).To(Succeed())
+					})
+				})
+
+				Context("with an expose group defined more than once", func() {
+					It("returns error", func() {
+						svcK8sConfig := config.DefaultSvcK8sConfig()
+						svcK8sConfig.Service.Expose.Groups = []config.ExposeGroup{
+							{Name: "internal", Domain: "a.internal"},
+							{Name: "internal", Domain: "b.internal"},
+						}
+
+						err = svcK8sConfig.Validate()
+						Expect(err).To(MatchError("SvcK8sConfig.Service.Expose.Groups group `internal` is defined more than once"))
+					})
+				})
+
+				Context("with an expose group without a domain", func() {
+					It("returns error", func() {
+						svcK8sConfig := config.DefaultSvcK8sConfig()
+						svcK8sConfig.Service.Expose.Groups = []config.ExposeGroup{{Name: "internal"}}
+
+						err = svcK8sConfig.Validate()
+						Expect(err).To(MatchError("SvcK8sConfig.Service.Expose.Groups group `internal` must have a domain"))
+					})
+				})
+
+				Context("with invalid fsGroup change policy", func() {
+					It("returns error", func() {
+						svcK8sConfig := config.DefaultSvcK8sConfig()
+						svcK8sConfig.Workload.PodSecurity.FsGroupChangePolicy = "Never"
+
+						err = svcK8sConfig.Validate()
+						Expect(err).To(HaveOccurred())
+						Expect(err.Error()).To(ContainSubstring("FsGroupChangePolicy"))
+					})
+				})
+
+				Context("with invalid ephemeral storage limit", func() {
+					It("returns error", func() {
+						svcK8sConfig := config.DefaultSvcK8sConfig()
+						svcK8sConfig.Workload.Resource.MaxStorage = "lots"
+
+						err = svcK8sConfig.Validate()
+						Expect(err).To(MatchError("SvcK8sConfig.Workload.Resource.MaxStorage `lots` is not a valid quantity"))
+					})
+				})
+
+				Context("with invalid pod disruption budget value", func() {
+					It("returns error", func() {
+						svcK8sConfig := config.DefaultSvcK8sConfig()
+						svcK8sConfig.Workload.PodDisruptionBudget.MinAvailable = "half"
+
+						err = svcK8sConfig.Validate()
+						Expect(err).To(HaveOccurred())
+						Expect(err.Error()).To(ContainSubstring("SvcK8sConfig.Workload.PodDisruptionBudget.MinAvailable"))
+					})
+				})
+
+				Context("with invalid anti-affinity topology", func() {
+					It("returns error", func() {
+						svcK8sConfig := config.DefaultSvcK8sConfig()
+						svcK8sConfig.Workload.AntiAffinity.Topology = "rack"
+
+						err = svcK8sConfig.Validate()
+						Expect(err).To(HaveOccurred())
+						Expect(err.Error()).To(ContainSubstring("SvcK8sConfig.Workload.AntiAffinity.Topology"))
+					})
+				})
+
+				Context("with invalid node affinity expression", func() {
+					It("returns error", func() {
+						svcK8sConfig := config.DefaultSvcK8sConfig()
+						svcK8sConfig.Workload.NodeAffinity = "disktype in ssd"
+
+						err = svcK8sConfig.Validate()
+						Expect(err).To(HaveOccurred())
+						Expect(err.Error()).To(ContainSubstring("SvcK8sConfig.Workload.NodeAffinity `disktype in ssd` is not a valid node affinity expression"))
+					})
+				})
+
+				Context("with invalid topology spread whenUnsatisfiable value", func() {
+					It("returns error", func() {
+						svcK8sConfig := config.DefaultSvcK8sConfig()
+						svcK8sConfig.Workload.TopologySpread.TopologyKey = "topology.kubernetes.io/zone"
+						svcK8sConfig.Workload.TopologySpread.WhenUnsatisfiable = "Sometimes"
+
+						err = svcK8sConfig.Validate()
+						Expect(err).To(HaveOccurred())
+						Expect(err.Error()).To(ContainSubstring("SvcK8sConfig.Workload.TopologySpread.WhenUnsatisfiable"))
+					})
+				})
+
+				Context("with topology spread missing topology key", func() {
+					It("returns error", func() {
+						svcK8sConfig := config.DefaultSvcK8sConfig()
+						svcK8sConfig.Workload.TopologySpread.MaxSkew = 2
+
+						err = svcK8sConfig.Validate()
+						Expect(err).To(MatchError("SvcK8sConfig.Workload.TopologySpread.TopologyKey is required when topology spread is configured"))
+					})
+				})
+
+				Context("with invalid service session affinity", func() {
+					It("returns error", func() {
+						svcK8sConfig := config.DefaultSvcK8sConfig()
+						svcK8sConfig.Service.SessionAffinity = "Cookie"
+
+						err = svcK8sConfig.Validate()
+						Expect(err).To(HaveOccurred())
+						Expect(err.Error()).To(ContainSubstring("SvcK8sConfig.Service.SessionAffinity"))
+					})
+				})
+
+				Context("with service session affinity timeout", func() {
+					It("returns error when session affinity isn't ClientIP", func() {
+						svcK8sConfig := config.DefaultSvcK8sConfig()
+						svcK8sConfig.Service.SessionAffinityTimeout = 600
+
+						err = svcK8sConfig.Validate()
+						Expect(err).To(MatchError("SvcK8sConfig.Service.SessionAffinityTimeout is only applicable to ClientIP session affinity"))
+					})
+
+					It("passes validation for ClientIP session affinity", func() {
+						svcK8sConfig := config.DefaultSvcK8sConfig()
+						svcK8sConfig.Service.SessionAffinity = config.SessionAffinityClientIP
+						svcK8sConfig.Service.SessionAffinityTimeout = 600
+
+						Expect(svcK8sConfig.Validate()).To(Succeed())
+					})
+				})
+
+				Context("with a CronJob workload type", func() {
+					var svcK8sConfig config.SvcK8sConfig
+
+					BeforeEach(func() {
+						svcK8sConfig = config.DefaultSvcK8sConfig()
+						svcK8sConfig.Workload.Type = config.CronJobWorkload
+						svcK8sConfig.Workload.RestartPolicy = config.RestartPolicyOnFailure
+					})
+
+					It("returns error when schedule is missing", func() {
+						err = svcK8sConfig.Validate()
+						Expect(err).To(MatchError("SvcK8sConfig.Workload.Schedule is required for CronJob workload"))
+					})
+
+					It("returns error when schedule is not a valid cron expression", func() {
+						svcK8sConfig.Workload.Schedule = "61 * * * *"
+
+						err = svcK8sConfig.Validate()
+						Expect(err).To(MatchError("SvcK8sConfig.Workload.Schedule `61 * * * *` is not a valid cron schedule"))
+					})
+
+					It("accepts a valid cron expression", func() {
+						for _, s := range []string{"*/5 * * * *", "0 2 1-15 jan,jun mon-fri", "@daily"} {
+							svcK8sConfig.Workload.Schedule = s
+							Expect(svcK8sConfig.Validate()).To(Succeed())
+						}
+					})
+				})
+
+				Context("with a Job workload type", func() {
+					var svcK8sConfig config.SvcK8sConfig
+
+					BeforeEach(func() {
+						svcK8sConfig = config.DefaultSvcK8sConfig()
+						svcK8sConfig.Workload.Type = config.JobWorkload
+						svcK8sConfig.Workload.RestartPolicy = config.RestartPolicyOnFailure
+					})
+
+					It("accepts a schedule, backoff limit and time to live", func() {
+						backoffLimit, ttl := int32(3), int32(3600)
+						svcK8sConfig.Workload.Schedule = "0 2 * * *"
+						svcK8sConfig.Workload.BackoffLimit = &backoffLimit
+						svcK8sConfig.Workload.TTLSecondsAfterFinished = &ttl
+
+						Expect(svcK8sConfig.Validate()).To(Succeed())
+					})
+
+					It("returns error when backoff limit is negative", func() {
+						backoffLimit := int32(-1)
+						svcK8sConfig.Workload.BackoffLimit = &backoffLimit
+
+						err = svcK8sConfig.Validate()
+						Expect(err).To(MatchError("SvcK8sConfig.Workload.BackoffLimit `-1` must not be negative"))
+					})
+
+					It("returns error when time to live is negative", func() {
+						ttl := int32(-5)
+						svcK8sConfig.Workload.TTLSecondsAfterFinished = &ttl
+
+						err = svcK8sConfig.Validate()
+						Expect(err).To(MatchError("SvcK8sConfig.Workload.TTLSecondsAfterFinished `-5` must not be negative"))
+					})
+				})
 			})
 		})
 	})
@@ -272,6 +698,323 @@ var _ = Describe("Service Extension", func() {
 		})
 	})
 
+	Describe("LivenessProbeFromCompose", func() {
+		Context("with a minimal healthcheck defining only a test", func() {
+			BeforeEach(func() {
+				svc.HealthCheck = &composego.HealthCheckConfig{
+					Test: composego.HealthCheckTest{"CMD", "curl", "-f", "http://localhost"},
+				}
+			})
+
+			AfterEach(func() {
+				svc.HealthCheck = nil
+			})
+
+			It("defaults probe timings to valid Kubernetes values", func() {
+				probe := config.LivenessProbeFromCompose(&svc)
+				Expect(probe.Type).To(Equal(config.ProbeTypeExec.String()))
+				Expect(probe.Exec.Command).To(Equal([]string{"curl", "-f", "http://localhost"}))
+				Expect(probe.Period).To(Equal(config.DefaultHealthcheckProbePeriod))
+				Expect(probe.Timeout).To(Equal(config.DefaultHealthcheckProbeTimeout))
+				Expect(probe.FailureThreshold).To(Equal(config.DefaultHealthcheckProbeFailureThreshold))
+			})
+		})
+	})
+
+	Describe("ParseTolerations", func() {
+		It("uses Equal operator for entries with a value and Exists operator otherwise", func() {
+			tolerations, err := config.ParseTolerations([]string{"dedicated=gpu:NoSchedule", "spot:NoExecute", "example.com/maintenance"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tolerations).To(Equal([]config.Toleration{
+				{Key: "dedicated", Operator: config.TolerationOpEqual, Value: "gpu", Effect: "NoSchedule"},
+				{Key: "spot", Operator: config.TolerationOpExists, Effect: "NoExecute"},
+				{Key: "example.com/maintenance", Operator: config.TolerationOpExists},
+			}))
+		})
+
+		It("returns descriptive errors for malformed entries", func() {
+			_, err := config.ParseTolerations([]string{"dedicated=gpu:NoRun"})
+			Expect(err).To(MatchError(ContainSubstring("toleration `dedicated=gpu:NoRun` has unsupported effect `NoRun`")))
+
+			_, err = config.ParseTolerations([]string{"=gpu:NoSchedule"})
+			Expect(err).To(MatchError("toleration `=gpu:NoSchedule` is missing a key"))
+
+			_, err = config.ParseTolerations([]string{"dedicated=:NoSchedule"})
+			Expect(err).To(MatchError("toleration `dedicated=:NoSchedule` is missing a value after `=`"))
+
+			_, err = config.ParseTolerations([]string{"dedicated:"})
+			Expect(err).To(MatchError("toleration `dedicated:` is missing an effect after `:`"))
+		})
+
+		It("fails service extension validation for malformed entries", func() {
+			svcK8sConfig := config.DefaultSvcK8sConfig()
+			svcK8sConfig.Workload.Tolerations = []string{":NoSchedule"}
+
+			Expect(svcK8sConfig.Validate()).To(MatchError("SvcK8sConfig.Workload.Tolerations toleration `:NoSchedule` is missing a key"))
+		})
+	})
+
+	Describe("ResourceFromCompose", func() {
+		Context("with deploy resources reservations and limits", func() {
+			BeforeEach(func() {
+				svc.Deploy = &composego.DeployConfig{
+					Resources: composego.Resources{
+						Reservations: &composego.Resource{
+							NanoCPUs:    "0.25",
+							MemoryBytes: composego.UnitBytes(64 * 1024 * 1024),
+						},
+						Limits: &composego.Resource{
+							NanoCPUs:    "0.5",
+							MemoryBytes: composego.UnitBytes(128 * 1024 * 1024),
+						},
+					},
+				}
+			})
+
+			It("maps reservations to requests and limits to limits", func() {
+				res, err := config.ResourceFromCompose(&svc)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(res.CPU).To(Equal("0.25"))
+				Expect(res.Memory).To(Equal("64Mi"))
+				Expect(res.MaxCPU).To(Equal("0.5"))
+				Expect(res.MaxMemory).To(Equal("128Mi"))
+			})
+		})
+
+		Context("with only deploy resources cpu reservation", func() {
+			BeforeEach(func() {
+				svc.Deploy = &composego.DeployConfig{
+					Resources: composego.Resources{
+						Reservations: &composego.Resource{NanoCPUs: "0.25"},
+					},
+				}
+			})
+
+			It("leaves memory request and all limits unset", func() {
+				res, err := config.ResourceFromCompose(&svc)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(res.CPU).To(Equal("0.25"))
+				Expect(res.Memory).To(BeEmpty())
+				Expect(res.MaxCPU).To(BeEmpty())
+				Expect(res.MaxMemory).To(BeEmpty())
+			})
+		})
+
+		Context("with service level mem_limit, mem_reservation and cpus", func() {
+			BeforeEach(func() {
+				svc.MemLimit = composego.UnitBytes(256 * 1024 * 1024)
+				svc.MemReservation = composego.UnitBytes(32 * 1024 * 1024)
+				svc.CPUS = 1.5
+			})
+
+			AfterEach(func() {
+				svc.MemLimit = 0
+				svc.MemReservation = 0
+				svc.CPUS = 0
+			})
+
+			It("maps them to requests and limits", func() {
+				res, err := config.ResourceFromCompose(&svc)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(res.Memory).To(Equal("32Mi"))
+				Expect(res.MaxMemory).To(Equal("256Mi"))
+				Expect(res.MaxCPU).To(Equal("1.5"))
+			})
+
+			Context("and deploy resources limits", func() {
+				BeforeEach(func() {
+					svc.Deploy = &composego.DeployConfig{
+						Resources: composego.Resources{
+							Limits: &composego.Resource{NanoCPUs: "0.5"},
+						},
+					}
+				})
+
+				It("gives precedence to the deploy block", func() {
+					res, err := config.ResourceFromCompose(&svc)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(res.MaxCPU).To(Equal("0.5"))
+					Expect(res.MaxMemory).To(Equal("256Mi"))
+				})
+			})
+		})
+	})
+
+	Describe("ServiceTypeFromCompose", func() {
+		Context("with a service publishing ports", func() {
+			BeforeEach(func() {
+				svc.Ports = []composego.ServicePortConfig{{Target: 8080, Published: 8080}}
+			})
+
+			AfterEach(func() {
+				svc.Ports = nil
+			})
+
+			It("defaults to a ClusterIP service", func() {
+				svcType, err := config.ServiceTypeFromCompose(&svc)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(svcType).To(Equal(config.ClusterIPService))
+			})
+
+			It("uses the supplied default service type", func() {
+				svcType, err := config.ServiceTypeFromCompose(&svc, config.WithDefaultServiceType(config.NodePortService))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(svcType).To(Equal(config.NodePortService))
+			})
+
+			It("keeps the supplied default service type for vip endpoint mode", func() {
+				svc.Deploy = &composego.DeployConfig{EndpointMode: "vip"}
+
+				svcType, err := config.ServiceTypeFromCompose(&svc, config.WithDefaultServiceType(config.NodePortService))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(svcType).To(Equal(config.NodePortService))
+			})
+
+			It("infers a Headless service for dnsrr endpoint mode regardless of the default service type", func() {
+				svc.Deploy = &composego.DeployConfig{EndpointMode: "dnsrr"}
+
+				svcType, err := config.ServiceTypeFromCompose(&svc, config.WithDefaultServiceType(config.NodePortService))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(svcType).To(Equal(config.HeadlessService))
+			})
+
+			It("lets the service k8s extension override the default service type", func() {
+				svc.Extensions = map[string]interface{}{
+					config.K8SExtensionKey: map[string]interface{}{
+						"service": map[string]interface{}{"type": "LoadBalancer"},
+					},
+				}
+
+				cfg, err := config.SvcK8sConfigFromCompose(&svc, config.WithDefaultServiceType(config.NodePortService))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cfg.Service.Type).To(Equal(config.LoadBalancerService))
+			})
+		})
+
+		Context("with a service not publishing ports", func() {
+			It("ignores the supplied default service type", func() {
+				svcType, err := config.ServiceTypeFromCompose(&svc, config.WithDefaultServiceType(config.NodePortService))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(svcType).To(Equal(config.NoService))
+			})
+		})
+	})
+
+	Describe("DefaultServiceTypeFromValue", func() {
+		It("accepts supported service types case insensitively", func() {
+			svcType, err := config.DefaultServiceTypeFromValue("loadbalancer")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(svcType).To(Equal(config.LoadBalancerService))
+		})
+
+		It("rejects unsupported service types", func() {
+			_, err := config.DefaultServiceTypeFromValue("ExternalName")
+			Expect(err).To(MatchError(ContainSubstring("default service type")))
+		})
+
+		It("rejects a blank service type", func() {
+			_, err := config.DefaultServiceTypeFromValue("")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("ExposeFromTraefikLabels", func() {
+		AfterEach(func() {
+			svc.Labels = nil
+		})
+
+		Context("with a traefik router", func() {
+			BeforeEach(func() {
+				svc.Labels = composego.Labels{
+					"traefik.enable":                            "true",
+					"traefik.http.routers.web.rule":             "Host(`example.com`, `www.example.com`) && PathPrefix(`/api/`)",
+					"traefik.http.routers.web.entrypoints":      "websecure",
+					"traefik.http.routers.web.tls.certresolver": "le",
+				}
+			})
+
+			It("translates router rule hosts and path into the expose domain", func() {
+				expose := config.ExposeFromTraefikLabels(&svc)
+				Expect(expose.Domain).To(Equal("example.com/api,www.example.com/api"))
+			})
+
+			It("translates router options into ingress annotations", func() {
+				expose := config.ExposeFromTraefikLabels(&svc)
+				Expect(expose.IngressAnnotations).To(Equal(map[string]string{
+					"traefik.ingress.kubernetes.io/router.entrypoints":      "websecure",
+					"traefik.ingress.kubernetes.io/router.tls.certresolver": "le",
+				}))
+			})
+
+			It("is only applied when the traefik labels option is supplied", func() {
+				Expect(parsedK8sCfg.Service.Expose.Domain).To(BeEmpty())
+
+				cfg, err := config.SvcK8sConfigFromCompose(&svc, config.WithTraefikLabels())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cfg.Service.Expose.Domain).To(Equal("example.com/api,www.example.com/api"))
+			})
+
+			Context("and a k8s extension expose configuration", func() {
+				BeforeEach(func() {
+					svc.Extensions = map[string]interface{}{
+						config.K8SExtensionKey: map[string]interface{}{
+							"service": map[string]interface{}{
+								"expose": map[string]interface{}{
+									"domain": "override.com",
+								},
+							},
+						},
+					}
+				})
+
+				It("gives precedence to the k8s extension", func() {
+					cfg, err := config.SvcK8sConfigFromCompose(&svc, config.WithTraefikLabels())
+					Expect(err).NotTo(HaveOccurred())
+					Expect(cfg.Service.Expose.Domain).To(Equal("override.com"))
+				})
+			})
+		})
+
+		Context("with traefik disabled", func() {
+			BeforeEach(func() {
+				svc.Labels = composego.Labels{
+					"traefik.enable":                "false",
+					"traefik.http.routers.web.rule": "Host(`example.com`)",
+				}
+			})
+
+			It("doesn't expose the service", func() {
+				Expect(config.ExposeFromTraefikLabels(&svc)).To(Equal(config.Expose{}))
+			})
+		})
+
+		Context("with multiple traefik routers", func() {
+			BeforeEach(func() {
+				svc.Labels = composego.Labels{
+					"traefik.http.routers.b.rule": "Host(`b.example.com`)",
+					"traefik.http.routers.a.rule": "Host(`a.example.com`)",
+				}
+			})
+
+			It("translates the first router sorted by name", func() {
+				Expect(config.ExposeFromTraefikLabels(&svc).Domain).To(Equal("a.example.com"))
+			})
+		})
+
+		Context("with a router rule matching no hosts", func() {
+			BeforeEach(func() {
+				svc.Labels = composego.Labels{
+					"traefik.http.routers.web.rule":        "PathPrefix(`/api`)",
+					"traefik.http.routers.web.entrypoints": "web",
+				}
+			})
+
+			It("doesn't expose the service", func() {
+				Expect(config.ExposeFromTraefikLabels(&svc)).To(Equal(config.Expose{}))
+			})
+		})
+	})
+
 	Describe("Merge", func() {
 		It("merges target into base", func() {
 			k8sBase := config.DefaultSvcK8sConfig()
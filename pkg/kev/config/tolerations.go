@@ -0,0 +1,99 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// TolerationOpExists tolerates a taint with the given key regardless of its value
+	TolerationOpExists = "Exists"
+
+	// TolerationOpEqual tolerates a taint with the given key and value
+	TolerationOpEqual = "Equal"
+)
+
+// taintEffects are the only supported taint effects, blank matches all effects
+var taintEffects = map[string]bool{
+	"":                 true,
+	"NoSchedule":       true,
+	"PreferNoSchedule": true,
+	"NoExecute":        true,
+}
+
+// Toleration is a parsed workload toleration
+type Toleration struct {
+	Key      string
+	Operator string
+	Value    string
+	Effect   string
+}
+
+// ParseTolerations parses workload tolerations expressed as `key[=value][:effect]`.
+// Tolerations without a value use the Exists operator, those with a value use the Equal operator.
+func ParseTolerations(entries []string) ([]Toleration, error) {
+	var tolerations []Toleration
+
+	for _, entry := range entries {
+		t, err := parseToleration(entry)
+		if err != nil {
+			return nil, err
+		}
+		tolerations = append(tolerations, t)
+	}
+
+	return tolerations, nil
+}
+
+// parseToleration parses a single `key[=value][:effect]` toleration entry
+func parseToleration(entry string) (Toleration, error) {
+	var t Toleration
+
+	spec := strings.TrimSpace(entry)
+	if i := strings.LastIndex(spec, ":"); i >= 0 {
+		t.Effect = strings.TrimSpace(spec[i+1:])
+		spec = strings.TrimSpace(spec[:i])
+
+		if t.Effect == "" {
+			return Toleration{}, fmt.Errorf("toleration `%s` is missing an effect after `:`", entry)
+		}
+	}
+
+	if !taintEffects[t.Effect] {
+		return Toleration{}, fmt.Errorf("toleration `%s` has unsupported effect `%s`, supported effects are 'NoSchedule, PreferNoSchedule or NoExecute'", entry, t.Effect)
+	}
+
+	t.Key = spec
+	t.Operator = TolerationOpExists
+	if i := strings.Index(spec, "="); i >= 0 {
+		t.Key = strings.TrimSpace(spec[:i])
+		t.Value = strings.TrimSpace(spec[i+1:])
+		t.Operator = TolerationOpEqual
+
+		if t.Value == "" {
+			return Toleration{}, fmt.Errorf("toleration `%s` is missing a value after `=`", entry)
+		}
+	}
+
+	if t.Key == "" {
+		return Toleration{}, fmt.Errorf("toleration `%s` is missing a key", entry)
+	}
+
+	return t, nil
+}
@@ -0,0 +1,180 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/appvia/kev/pkg/kev/log"
+	composego "github.com/compose-spec/compose-go/types"
+	"github.com/spf13/cast"
+)
+
+const (
+	traefikLabelPrefix        = "traefik."
+	traefikRouterLabelPrefix  = "traefik.http.routers."
+	traefikServiceLabelPrefix = "traefik.http.services."
+	traefikAnnotationPrefix   = "traefik.ingress.kubernetes.io/router."
+)
+
+var (
+	// traefikRuleMatcherRegex matches traefik router rule matchers, e.g. Host(`example.com`)
+	traefikRuleMatcherRegex = regexp.MustCompile(`(\w+)\(([^)]*)\)`)
+
+	// traefikRouterAnnotations maps traefik router label options to their k8s ingress annotation counterparts
+	traefikRouterAnnotations = map[string]string{
+		"entrypoints":      traefikAnnotationPrefix + "entrypoints",
+		"middlewares":      traefikAnnotationPrefix + "middlewares",
+		"priority":         traefikAnnotationPrefix + "priority",
+		"tls":              traefikAnnotationPrefix + "tls",
+		"tls.certresolver": traefikAnnotationPrefix + "tls.certresolver",
+	}
+)
+
+// ExposeFromTraefikLabels infers the service expose configuration from traefik v2 compose service labels.
+// Hosts and path of the router rule are translated into the expose domain, while other recognised
+// router options are translated into traefik ingress annotations. Unrecognised traefik labels are reported.
+// Only the first router, sorted by name, is translated.
+func ExposeFromTraefikLabels(svc *composego.ServiceConfig) Expose {
+	var expose Expose
+
+	if enabled, ok := svc.Labels["traefik.enable"]; ok && !cast.ToBool(enabled) {
+		return expose
+	}
+
+	routers := map[string]map[string]string{}
+	for key, value := range svc.Labels {
+		if !strings.HasPrefix(key, traefikLabelPrefix) {
+			continue
+		}
+
+		switch {
+		case key == "traefik.enable", key == "traefik.docker.network":
+			// docker provider settings, not applicable in k8s
+		case strings.HasPrefix(key, traefikRouterLabelPrefix):
+			parts := strings.SplitN(strings.TrimPrefix(key, traefikRouterLabelPrefix), ".", 2)
+			if len(parts) != 2 {
+				warnUnrecognisedTraefikLabel(svc.Name, key)
+				continue
+			}
+			if routers[parts[0]] == nil {
+				routers[parts[0]] = map[string]string{}
+			}
+			routers[parts[0]][parts[1]] = value
+		case strings.HasPrefix(key, traefikServiceLabelPrefix) && strings.HasSuffix(key, ".loadbalancer.server.port"):
+			// ingress always routes traffic to the first port of the k8s service
+			log.DebugfWithFields(log.Fields{
+				"service-name": svc.Name,
+			}, "Traefik label %s ignored, ingress routes to the service's first port", key)
+		default:
+			warnUnrecognisedTraefikLabel(svc.Name, key)
+		}
+	}
+
+	if len(routers) == 0 {
+		return expose
+	}
+
+	var names []string
+	for name := range routers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if len(names) > 1 {
+		log.WarnfWithFields(log.Fields{
+			"service-name": svc.Name,
+		}, "Multiple traefik routers defined, only router %q will be translated into an ingress", names[0])
+	}
+
+	router := names[0]
+	for option, value := range routers[router] {
+		if option == "rule" {
+			expose.Domain = domainFromTraefikRule(svc.Name, value)
+			continue
+		}
+
+		annotation, ok := traefikRouterAnnotations[option]
+		if !ok {
+			warnUnrecognisedTraefikLabel(svc.Name, traefikRouterLabelPrefix+router+"."+option)
+			continue
+		}
+
+		if expose.IngressAnnotations == nil {
+			expose.IngressAnnotations = map[string]string{}
+		}
+		expose.IngressAnnotations[annotation] = value
+	}
+
+	if expose.Domain == "" {
+		expose.IngressAnnotations = nil
+	}
+
+	return expose
+}
+
+// domainFromTraefikRule translates the Host and Path/PathPrefix matchers of a traefik router rule
+// into a comma separated list of exposed domains, e.g. "example.com/api,www.example.com/api"
+func domainFromTraefikRule(svcName, rule string) string {
+	var hosts []string
+	var path string
+
+	for _, m := range traefikRuleMatcherRegex.FindAllStringSubmatch(rule, -1) {
+		var args []string
+		for _, a := range strings.Split(m[2], ",") {
+			if a = strings.Trim(strings.TrimSpace(a), "`\"'"); a != "" {
+				args = append(args, a)
+			}
+		}
+
+		switch m[1] {
+		case "Host":
+			hosts = append(hosts, args...)
+		case "Path", "PathPrefix":
+			if path == "" && len(args) > 0 {
+				path = args[0]
+			}
+		default:
+			log.WarnfWithFields(log.Fields{
+				"service-name": svcName,
+			}, "Traefik rule matcher %s isn't supported and will be ignored", m[1])
+		}
+	}
+
+	if len(hosts) == 0 {
+		log.WarnfWithFields(log.Fields{
+			"service-name": svcName,
+		}, "Traefik rule %q doesn't match any hosts, service won't be exposed", rule)
+		return ""
+	}
+
+	var domains []string
+	for _, h := range hosts {
+		domains = append(domains, h+strings.TrimSuffix(path, "/"))
+	}
+
+	return strings.Join(domains, ",")
+}
+
+// warnUnrecognisedTraefikLabel reports a traefik label that can't be translated into k8s configuration
+func warnUnrecognisedTraefikLabel(svcName, key string) {
+	log.WarnfWithFields(log.Fields{
+		"service-name": svcName,
+	}, "Traefik label %s isn't recognised and will be ignored", key)
+}
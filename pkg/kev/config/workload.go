@@ -33,6 +33,12 @@ const (
 
 	// StatefulSetWorkload workload type
 	StatefulSetWorkload WorkloadType = "StatefulSet"
+
+	// JobWorkload workload type
+	JobWorkload WorkloadType = "Job"
+
+	// CronJobWorkload workload type
+	CronJobWorkload WorkloadType = "CronJob"
 )
 
 // String converts a workload type to a string value
@@ -45,6 +51,8 @@ var workloadTypes = map[WorkloadType]bool{
 	DeploymentWorkload:  true,
 	DaemonSetWorkload:   true,
 	StatefulSetWorkload: true,
+	JobWorkload:         true,
+	CronJobWorkload:     true,
 }
 
 // WorkloadTypeFromValue returns a Workload Type for a given case insensitive value.
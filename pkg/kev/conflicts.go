@@ -0,0 +1,295 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kev
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/appvia/kev/pkg/kev/config"
+	"github.com/appvia/kev/pkg/kev/log"
+	kmd "github.com/appvia/komando"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ConflictOverrideWins keeps an environment override value conflicting with its compose sources.
+	ConflictOverrideWins = "override-wins"
+	// ConflictSourceWins replaces an environment override value conflicting with its compose sources.
+	ConflictSourceWins = "source-wins"
+)
+
+// conflictCategories maps a conflict category to the k8s extension attributes it covers.
+var conflictCategories = map[string][]string{
+	"replicas":  {"workload.replicas"},
+	"resources": {"workload.resource"},
+	"probes":    {"workload.livenessProbe", "workload.readinessProbe", "workload.startupProbe"},
+	"restart":   {"workload.restartPolicy"},
+	"workload":  {"workload.type"},
+	"service":   {"service.type"},
+}
+
+// conflictStrategies holds the conflict resolution strategy for each conflict category.
+type conflictStrategies map[string]string
+
+// conflictStrategiesFromValues validates the configured strategies and
+// defaults all unconfigured categories to override-wins.
+func conflictStrategiesFromValues(values map[string]string) (conflictStrategies, error) {
+	out := conflictStrategies{}
+	for category := range conflictCategories {
+		out[category] = ConflictOverrideWins
+	}
+
+	for category, strategy := range values {
+		if _, ok := conflictCategories[category]; !ok {
+			return nil, errors.Errorf("unknown reconcile conflict category %q, use one of: %s", category, strings.Join(conflictCategoryNames(), ", "))
+		}
+		if strategy != ConflictOverrideWins && strategy != ConflictSourceWins {
+			return nil, errors.Errorf("unknown reconcile conflict strategy %q for %s, use one of: %s, %s", strategy, category, ConflictOverrideWins, ConflictSourceWins)
+		}
+		out[category] = strategy
+	}
+
+	return out, nil
+}
+
+// conflictCategoryNames returns the sorted list of supported conflict categories.
+func conflictCategoryNames() []string {
+	var out []string
+	for category := range conflictCategories {
+		out = append(out, category)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// sourceValues are the compose sources values of conflict prone k8s extension attributes,
+// keyed by service name then attribute path, e.g. db: {workload.replicas: "2"}.
+// Values are kept in their string form as that's how they're compared.
+type sourceValues map[string]map[string]string
+
+// detectAndPatchServicesConflicts detects k8s extension values changed in the sources since the environment's
+// last reconcile that an environment override also customised, resolving each conflict using the strategy
+// configured for its category. Overrides customising values the sources didn't change are left alone.
+// It returns the sources values the environment got reconciled against, to compare with on the next reconcile.
+func (o *composeOverride) detectAndPatchServicesConflicts(dst *composeOverride, strategies conflictStrategies, previous sourceValues) (sourceValues, error) {
+	sg := o.UI.StepGroup()
+	defer sg.Done()
+	step := sg.Add("Detecting extension conflicts")
+
+	current := sourceValues{}
+	cset := changeset{}
+	var msgs []string
+	srcSvcMapping := o.Services.Map()
+	for index, dstSvc := range dst.Services {
+		srcSvc, ok := srcSvcMapping[dstSvc.Name]
+		if !ok {
+			continue
+		}
+
+		srcExt, _ := srcSvc.Extensions[config.K8SExtensionKey].(map[string]interface{})
+		if srcExt == nil {
+			continue
+		}
+
+		for _, paths := range conflictCategories {
+			for _, path := range paths {
+				for leaf, value := range extensionLeaves(srcExt, strings.Split(path, ".")) {
+					if current[dstSvc.Name] == nil {
+						current[dstSvc.Name] = map[string]string{}
+					}
+					current[dstSvc.Name][leaf] = fmt.Sprint(value)
+				}
+			}
+		}
+
+		dstExt, _ := dstSvc.Extensions[config.K8SExtensionKey].(map[string]interface{})
+		if dstExt == nil {
+			continue
+		}
+
+		patched := copyExtension(dstExt)
+		var sourceWon bool
+		for _, category := range conflictCategoryNames() {
+			strategy := strategies[category]
+			for _, path := range conflictCategories[category] {
+				for _, c := range extensionConflicts(srcExt, dstExt, strings.Split(path, ".")) {
+					// only values the sources changed since the last reconcile conflict,
+					// an override differing from an unchanged source value is a customisation
+					prev, ok := previous[dstSvc.Name][strings.Join(c.path, ".")]
+					if !ok || prev == fmt.Sprint(c.source) || prev == fmt.Sprint(c.override) {
+						continue
+					}
+
+					if strategy == ConflictSourceWins {
+						setExtensionValue(patched, c.path, c.source)
+						sourceWon = true
+					}
+					msg := fmt.Sprintf("%s conflict on %s in service %s: %s (previous source: %s, source: %v, override: %v)",
+						category, strings.Join(c.path, "."), dstSvc.Name, conflictWinner(strategy), prev, c.source, c.override)
+					log.Debugf(msg)
+					msgs = append(msgs, msg)
+				}
+			}
+		}
+
+		if sourceWon {
+			cset.services = append(cset.services, change{
+				Type:   UPDATE,
+				Index:  index,
+				Parent: "extensions",
+				Value:  patched,
+			})
+		}
+	}
+
+	if len(msgs) == 0 {
+		step.Success("No extension conflicts detected")
+		return current, nil
+	}
+
+	if _, err := cset.applyServicesPatchesIfAny(dst); err != nil {
+		step.Error()
+		return nil, err
+	}
+
+	step.Success("Resolved extension conflicts")
+	for _, msg := range msgs {
+		o.UI.Output(msg, kmd.WithStyle(kmd.LogStyle),
+			kmd.WithIndentChar(kmd.LogIndentChar),
+			kmd.WithIndent(3))
+	}
+	return current, nil
+}
+
+// conflictWinner describes which side won a conflict for a strategy.
+func conflictWinner(strategy string) string {
+	if strategy == ConflictSourceWins {
+		return "source won"
+	}
+	return "override kept"
+}
+
+// extensionConflict is a single k8s extension value differing between the sources and an override.
+type extensionConflict struct {
+	path     []string
+	source   interface{}
+	override interface{}
+}
+
+// extensionConflicts returns the leaf values found under path in both extensions that differ.
+// Values only set in the override are not conflicts, as the sources have no opinion on them.
+func extensionConflicts(src, dst map[string]interface{}, path []string) []extensionConflict {
+	srcValue, srcOk := extensionValue(src, path)
+	dstValue, dstOk := extensionValue(dst, path)
+	if !srcOk || !dstOk {
+		return nil
+	}
+
+	if dstMap, ok := dstValue.(map[string]interface{}); ok {
+		srcMap, ok := srcValue.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+
+		keys := make([]string, 0, len(dstMap))
+		for key := range dstMap {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		var out []extensionConflict
+		for _, key := range keys {
+			out = append(out, extensionConflicts(srcMap, dstMap, []string{key})...)
+		}
+		for i := range out {
+			out[i].path = append(append([]string{}, path...), out[i].path...)
+		}
+		return out
+	}
+
+	// values are compared in their string form as yaml decoding of overrides
+	// and sources may yield different types for the same scalar, e.g. 0.5 and "0.5".
+	if fmt.Sprint(srcValue) == fmt.Sprint(dstValue) {
+		return nil
+	}
+
+	return []extensionConflict{{path: path, source: srcValue, override: dstValue}}
+}
+
+// extensionLeaves returns the leaf values found under path in an extension keyed by their dotted path.
+func extensionLeaves(ext map[string]interface{}, path []string) map[string]interface{} {
+	value, ok := extensionValue(ext, path)
+	if !ok {
+		return nil
+	}
+
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return map[string]interface{}{strings.Join(path, "."): value}
+	}
+
+	out := map[string]interface{}{}
+	for key := range m {
+		for leaf, v := range extensionLeaves(m, []string{key}) {
+			out[strings.Join(path, ".")+"."+leaf] = v
+		}
+	}
+	return out
+}
+
+// extensionValue looks up a nested value in a k8s extension.
+func extensionValue(ext map[string]interface{}, path []string) (interface{}, bool) {
+	var current interface{} = ext
+	for _, key := range path {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = m[key]; !ok || current == nil {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// setExtensionValue sets a nested value in a k8s extension.
+func setExtensionValue(ext map[string]interface{}, path []string, value interface{}) {
+	m := ext
+	for _, key := range path[:len(path)-1] {
+		next, ok := m[key].(map[string]interface{})
+		if !ok {
+			return
+		}
+		m = next
+	}
+	m[path[len(path)-1]] = value
+}
+
+// copyExtension returns a deep copy of a k8s extension's nested maps.
+func copyExtension(ext map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(ext))
+	for k, v := range ext {
+		if m, ok := v.(map[string]interface{}); ok {
+			out[k] = copyExtension(m)
+			continue
+		}
+		out[k] = v
+	}
+	return out
+}
@@ -18,7 +18,10 @@ package converter
 
 import (
 	"github.com/appvia/kev/pkg/kev/converter/dummy"
+	"github.com/appvia/kev/pkg/kev/converter/helm"
+	"github.com/appvia/kev/pkg/kev/converter/kpt"
 	"github.com/appvia/kev/pkg/kev/converter/kubernetes"
+	"github.com/appvia/kev/pkg/kev/converter/kustomize"
 	kmd "github.com/appvia/komando"
 	composego "github.com/compose-spec/compose-go/types"
 )
@@ -34,17 +37,36 @@ type Converter interface {
 		excluded map[string][]string) (map[string]string, error)
 }
 
-// Factory returns a converter
-func Factory(name string, ui kmd.UI) Converter {
+// Factory returns a converter.
+// Options are applicable to all converters building on the Kubernetes manifests converter.
+func Factory(name string, ui kmd.UI, opts ...kubernetes.Option) Converter {
 	switch name {
 	case "dummy":
 		// Dummy converter example
 		return dummy.New()
+	case helm.Name:
+		// Helm chart converter
+		if ui == nil {
+			return helm.New(opts...)
+		}
+		return helm.NewWithUI(ui, opts...)
+	case kustomize.Name:
+		// Kustomize base & overlays converter
+		if ui == nil {
+			return kustomize.New(opts...)
+		}
+		return kustomize.NewWithUI(ui, opts...)
+	case kpt.Name:
+		// Kpt packages converter
+		if ui == nil {
+			return kpt.New(opts...)
+		}
+		return kpt.NewWithUI(ui, opts...)
 	default:
 		// Kubernetes manifests converter by default
 		if ui == nil {
-			return kubernetes.New()
+			return kubernetes.New(opts...)
 		}
-		return kubernetes.NewWithUI(ui)
+		return kubernetes.NewWithUI(ui, opts...)
 	}
 }
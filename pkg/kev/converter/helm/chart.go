@@ -0,0 +1,164 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helm
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/appvia/kev/pkg/kev/converter/kubernetes"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// chart holds a chart's name, values and manifest templates keyed by file name
+type chart struct {
+	name      string
+	values    []byte
+	templates map[string][]byte
+}
+
+// valueRef is a placeholder in a marshalled manifest to be replaced with a chart value expression
+type valueRef struct {
+	placeholder string
+	expr        string
+}
+
+// newChart builds a chart from Kubernetes objects. Workload image, replicas and
+// resource requests & limits are extracted into chart values keyed by workload name.
+func newChart(name string, objects []runtime.Object) (*chart, error) {
+	values := map[string]interface{}{}
+	templates := map[string][]byte{}
+
+	for _, o := range objects {
+		u, err := kubernetes.ToUnstructured(o)
+		if err != nil {
+			return nil, err
+		}
+
+		refs, err := parameterise(u, values)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := marshal(u)
+		if err != nil {
+			return nil, err
+		}
+
+		// escape template delimiters present in the manifest, e.g. in environment variables
+		data = bytes.ReplaceAll(data, []byte("{{"), []byte(`{{ "{{" }}`))
+		for _, r := range refs {
+			data = bytes.ReplaceAll(data, []byte(r.placeholder), []byte(r.expr))
+		}
+
+		obj := unstructured.Unstructured{Object: u}
+		templates[fmt.Sprintf("%s-%s.yaml", obj.GetName(), strings.ToLower(obj.GetKind()))] = data
+	}
+
+	v, err := marshal(values)
+	if err != nil {
+		return nil, err
+	}
+
+	return &chart{name: name, values: v, templates: templates}, nil
+}
+
+// parameterise replaces a workload's image, replicas and main container resources with
+// placeholders, recording their original values in the chart values.
+func parameterise(u map[string]interface{}, values map[string]interface{}) ([]valueRef, error) {
+	obj := unstructured.Unstructured{Object: u}
+	path, ok := kubernetes.PodSpecPath(obj.GetKind())
+	if !ok {
+		return nil, nil
+	}
+
+	workload := obj.GetName()
+	workloadValues := map[string]interface{}{}
+	var refs []valueRef
+
+	// ref swaps the value at the supplied path in a manifest section with a placeholder
+	ref := func(section map[string]interface{}, valuePath []string, fields ...string) error {
+		v, found, err := unstructured.NestedFieldNoCopy(section, fields...)
+		if err != nil || !found {
+			return err
+		}
+
+		placeholder := fmt.Sprintf("__kev_helm_value_%d__", len(refs))
+		if err := unstructured.SetNestedField(section, placeholder, fields...); err != nil {
+			return err
+		}
+		if err := unstructured.SetNestedField(workloadValues, v, valuePath...); err != nil {
+			return err
+		}
+
+		expr := fmt.Sprintf("{{ index .Values %q", workload)
+		for _, p := range valuePath {
+			expr += fmt.Sprintf(" %q", p)
+		}
+		if _, isString := v.(string); isString {
+			expr += " | quote"
+		}
+		refs = append(refs, valueRef{placeholder: placeholder, expr: expr + " }}"})
+		return nil
+	}
+
+	if err := ref(u, []string{"replicas"}, "spec", "replicas"); err != nil {
+		return nil, err
+	}
+
+	containers, found, err := unstructured.NestedFieldNoCopy(u, append(path, "containers")...)
+	if err != nil {
+		return nil, err
+	}
+
+	if c, ok := containers.([]interface{}); found && ok && len(c) > 0 {
+		// main container is always the first one
+		if main, ok := c[0].(map[string]interface{}); ok {
+			if err := ref(main, []string{"image"}, "image"); err != nil {
+				return nil, err
+			}
+
+			for _, kind := range []string{"requests", "limits"} {
+				for _, res := range []string{"cpu", "memory"} {
+					if err := ref(main, []string{"resources", kind, res}, "resources", kind, res); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+	}
+
+	if len(workloadValues) > 0 {
+		values[workload] = workloadValues
+	}
+
+	return refs, nil
+}
+
+// marshal marshals a value as YAML
+func marshal(v interface{}) ([]byte, error) {
+	var b bytes.Buffer
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
@@ -0,0 +1,107 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helm
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v3"
+	v1apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var _ = Describe("Chart", func() {
+
+	var objects []runtime.Object
+
+	BeforeEach(func() {
+		replicas := int32(2)
+		objects = []runtime.Object{
+			&v1apps.Deployment{
+				TypeMeta:   meta.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+				ObjectMeta: meta.ObjectMeta{Name: "web"},
+				Spec: v1apps.DeploymentSpec{
+					Replicas: &replicas,
+					Template: v1.PodTemplateSpec{
+						Spec: v1.PodSpec{
+							Containers: []v1.Container{
+								{
+									Name:  "web",
+									Image: "nginx:latest",
+									Env:   []v1.EnvVar{{Name: "GREETING", Value: "{{ hello }}"}},
+									Resources: v1.ResourceRequirements{
+										Requests: v1.ResourceList{
+											v1.ResourceMemory: resource.MustParse("10Mi"),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			&v1.Service{
+				TypeMeta:   meta.TypeMeta{Kind: "Service", APIVersion: "v1"},
+				ObjectMeta: meta.ObjectMeta{Name: "web"},
+			},
+		}
+	})
+
+	It("extracts workload settings into chart values", func() {
+		c, err := newChart("dev", objects)
+		Expect(err).NotTo(HaveOccurred())
+
+		var values map[string]interface{}
+		Expect(yaml.Unmarshal(c.values, &values)).To(Succeed())
+		Expect(values).To(HaveKeyWithValue("web", map[string]interface{}{
+			"replicas": 2,
+			"image":    "nginx:latest",
+			"resources": map[string]interface{}{
+				"requests": map[string]interface{}{"memory": "10Mi"},
+			},
+		}))
+	})
+
+	It("references chart values in workload templates", func() {
+		c, err := newChart("dev", objects)
+		Expect(err).NotTo(HaveOccurred())
+
+		tmpl := string(c.templates["web-deployment.yaml"])
+		Expect(tmpl).To(ContainSubstring(`replicas: {{ index .Values "web" "replicas" }}`))
+		Expect(tmpl).To(ContainSubstring(`image: {{ index .Values "web" "image" | quote }}`))
+		Expect(tmpl).To(ContainSubstring(`memory: {{ index .Values "web" "resources" "requests" "memory" | quote }}`))
+		Expect(tmpl).NotTo(ContainSubstring("nginx:latest"))
+	})
+
+	It("escapes template delimiters present in manifests", func() {
+		c, err := newChart("dev", objects)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(string(c.templates["web-deployment.yaml"])).To(ContainSubstring(`{{ "{{" }} hello }}`))
+	})
+
+	It("keeps non workload objects as they are", func() {
+		c, err := newChart("dev", objects)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(c.templates).To(HaveKey("web-service.yaml"))
+		Expect(string(c.templates["web-service.yaml"])).NotTo(ContainSubstring(".Values"))
+	})
+})
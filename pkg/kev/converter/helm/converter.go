@@ -0,0 +1,158 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helm
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/appvia/kev/pkg/kev/converter/kubernetes"
+	"github.com/appvia/kev/pkg/kev/log"
+	kmd "github.com/appvia/komando"
+	composego "github.com/compose-spec/compose-go/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	// Name of the converter
+	Name = "helm"
+
+	// MultiFileSubDir is default output directory name for helm charts
+	MultiFileSubDir = "helm"
+
+	// ChartVersion is the version of generated charts
+	ChartVersion = "0.1.0"
+
+	chartFileName     = "Chart.yaml"
+	valuesFileName    = "values.yaml"
+	templatesSubDir   = "templates"
+	chartFileTemplate = `apiVersion: v2
+name: %s
+description: A Helm chart for the %s environment generated by kev
+type: application
+version: %s
+`
+)
+
+// Helm is a Helm chart converter.
+// It renders a minimal chart for each environment, surfacing workload settings as chart values.
+type Helm struct {
+	UI   kmd.UI
+	opts []kubernetes.Option
+}
+
+// New return a Helm chart converter
+func New(opts ...kubernetes.Option) *Helm {
+	return &Helm{UI: kmd.NoOpUI(), opts: opts}
+}
+
+// NewWithUI return a Helm chart converter using the supplied UI
+func NewWithUI(ui kmd.UI, opts ...kubernetes.Option) *Helm {
+	return &Helm{UI: ui, opts: opts}
+}
+
+// Render generates a Helm chart for each environment.
+// Charts are placed in <dir>/<env> or <workDir>/helm/<env> when no output directory is specified.
+// The singleFile option isn't applicable as charts follow the Helm directory structure.
+func (c *Helm) Render(singleFile bool,
+	dir, workDir string,
+	projects map[string]*composego.Project,
+	files map[string][]string,
+	rendered map[string][]byte,
+	excluded map[string][]string) (map[string]string, error) {
+
+	renderOutputPaths := map[string]string{}
+	k := kubernetes.NewWithUI(c.UI, c.opts...)
+
+	for _, env := range sortedEnvs(projects) {
+		log.Debugf("Rendering environment [%s] as a Helm chart", env)
+
+		envFile := files[env][len(files[env])-1]
+		c.UI.Output(fmt.Sprintf("%s: %s", env, envFile))
+
+		// @step establish chart directory
+		chartDir := filepath.Join(workDir, MultiFileSubDir, env)
+		if dir != "" {
+			chartDir = filepath.Join(dir, env)
+		}
+
+		// @step transform the project to the Kubernetes objects the manifests converter renders
+		objects, err := k.TransformEnv(env, projects[env], files[env], excluded[env])
+		if err != nil {
+			return nil, err
+		}
+
+		// @step extract chart values & templates
+		chart, err := newChart(env, objects)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Could not build %s chart for environment %s", Name, env)
+		}
+
+		if err := chart.write(chartDir, rendered); err != nil {
+			return nil, errors.Wrapf(err, "Could not render %s chart to disk, details:\n", Name)
+		}
+
+		renderOutputPaths[env] = chartDir
+	}
+
+	return renderOutputPaths, nil
+}
+
+// write writes the chart to the supplied directory, replacing any previously rendered templates
+func (c *chart) write(chartDir string, rendered map[string][]byte) error {
+	templatesDir := filepath.Join(chartDir, templatesSubDir)
+
+	if err := os.RemoveAll(templatesDir); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(templatesDir, os.ModePerm); err != nil {
+		return err
+	}
+
+	files := map[string][]byte{
+		filepath.Join(chartDir, chartFileName):  []byte(fmt.Sprintf(chartFileTemplate, c.name, c.name, ChartVersion)),
+		filepath.Join(chartDir, valuesFileName): c.values,
+	}
+	for name, data := range c.templates {
+		files[filepath.Join(templatesDir, name)] = data
+	}
+
+	for file, data := range files {
+		if err := ioutil.WriteFile(file, data, 0644); err != nil {
+			log.ErrorWithFields(log.Fields{
+				"file": file,
+			}, "Failed to write content to a file")
+			return err
+		}
+		rendered[file] = data
+	}
+
+	return nil
+}
+
+func sortedEnvs(projects map[string]*composego.Project) []string {
+	var out []string
+	for env := range projects {
+		out = append(out, env)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,70 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helm
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/appvia/kev/pkg/kev/converter/kubernetes"
+	composego "github.com/compose-spec/compose-go/types"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Converter", func() {
+
+	var (
+		dir      string
+		projects map[string]*composego.Project
+		files    map[string][]string
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "kev-helm")
+		Expect(err).NotTo(HaveOccurred())
+
+		projects = map[string]*composego.Project{
+			"dev": {
+				Services: composego.Services{
+					{Name: "web", Image: "web:dev"},
+				},
+			},
+		}
+		files = map[string][]string{"dev": {"docker-compose.kev.dev.yaml"}}
+	})
+
+	AfterEach(func() {
+		_ = os.RemoveAll(dir)
+	})
+
+	It("builds charts from the objects the manifests converter renders", func() {
+		rendered := map[string][]byte{}
+		c := New(kubernetes.WithFieldManager("kev"))
+
+		paths, err := c.Render(false, dir, dir, projects, files, rendered, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(paths).To(HaveKeyWithValue("dev", filepath.Join(dir, "dev")))
+
+		template := filepath.Join(dir, "dev", templatesSubDir, "web-deployment.yaml")
+		Expect(rendered).To(HaveKey(template))
+		Expect(string(rendered[template])).To(ContainSubstring(kubernetes.EnvironmentLabel + ": dev"))
+		Expect(string(rendered[template])).To(ContainSubstring(kubernetes.FieldManagerAnnotation + ": kev"))
+	})
+})
@@ -0,0 +1,172 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kpt
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/appvia/kev/pkg/kev/converter/kubernetes"
+	"github.com/appvia/kev/pkg/kev/log"
+	kmd "github.com/appvia/komando"
+	composego "github.com/compose-spec/compose-go/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	// Name of the converter
+	Name = "kpt"
+
+	// MultiFileSubDir is default output directory name for kpt packages
+	MultiFileSubDir = "kpt"
+)
+
+// Kpt is a kpt packages converter.
+// It renders each environment's Kubernetes manifests as a kpt package,
+// along with the package Kptfile and a setters config for its workloads.
+type Kpt struct {
+	UI   kmd.UI
+	opts []kubernetes.Option
+}
+
+// New return a Kpt converter
+func New(opts ...kubernetes.Option) *Kpt {
+	return &Kpt{UI: kmd.NoOpUI(), opts: opts}
+}
+
+// NewWithUI return a Kpt converter using the supplied UI
+func NewWithUI(ui kmd.UI, opts ...kubernetes.Option) *Kpt {
+	return &Kpt{UI: ui, opts: opts}
+}
+
+// Render generates a kpt package for each environment.
+// These are placed in <dir>/<env> or <workDir>/kpt/<env> when no output directory is specified.
+// Manifests are rendered by the Kubernetes manifests converter and marked with kpt setters.
+func (c *Kpt) Render(singleFile bool,
+	dir, workDir string,
+	projects map[string]*composego.Project,
+	files map[string][]string,
+	rendered map[string][]byte,
+	excluded map[string][]string) (map[string]string, error) {
+
+	outDir := filepath.Join(workDir, MultiFileSubDir)
+	if dir != "" {
+		outDir = dir
+	}
+
+	k := kubernetes.NewWithUI(c.UI, c.opts...)
+	if k.AllEnvsSingleFile {
+		return nil, errors.Errorf("%s packages can't be rendered as a single file for all environments", Name)
+	}
+
+	// @step render each environment's manifests into its package directory
+	manifests := map[string][]byte{}
+	renderOutputPaths, err := k.Render(singleFile, outDir, workDir, projects, files, manifests, excluded)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, env := range sortedEnvs(projects) {
+		pkgDir := filepath.Join(outDir, env)
+		log.Debugf("Rendering environment [%s] as a kpt package", env)
+
+		// @step mark the package manifests with setters
+		setters := map[string]string{}
+		for _, file := range sortedFiles(manifests) {
+			data := manifests[file]
+			if filepath.Dir(file) != pkgDir || !isManifest(file) {
+				continue
+			}
+
+			marked, fileSetters, err := markSetters(data)
+			if err != nil {
+				return nil, errors.Wrapf(err, "Could not mark %s setters in %s", Name, file)
+			}
+			for name, value := range fileSetters {
+				setters[name] = value
+			}
+
+			if err := writeFile(file, marked, rendered); err != nil {
+				return nil, errors.Wrapf(err, "Could not render %s package to disk, details:\n", Name)
+			}
+		}
+
+		// @step write the package metadata
+		pkgFiles, err := packageFiles(env, projects[env].Name, setters)
+		if err != nil {
+			return nil, err
+		}
+		for name, data := range pkgFiles {
+			if err := writeFile(filepath.Join(pkgDir, name), data, rendered); err != nil {
+				return nil, errors.Wrapf(err, "Could not render %s package to disk, details:\n", Name)
+			}
+		}
+	}
+
+	// @step keep any other rendered files, e.g. manifests indexes
+	for file, data := range manifests {
+		if _, ok := rendered[file]; !ok {
+			rendered[file] = data
+		}
+	}
+
+	return renderOutputPaths, nil
+}
+
+// isManifest informs whether a file is a yaml manifest
+func isManifest(file string) bool {
+	ext := strings.ToLower(filepath.Ext(file))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// writeFile writes content to a file and tracks it as rendered
+func writeFile(file string, data []byte, rendered map[string][]byte) error {
+	if err := ioutil.WriteFile(file, data, 0644); err != nil {
+		log.ErrorWithFields(log.Fields{
+			"file": file,
+		}, "Failed to write content to a file")
+		return err
+	}
+	rendered[file] = data
+	return nil
+}
+
+func sortedEnvs(projects map[string]*composego.Project) []string {
+	var out []string
+	for env := range projects {
+		out = append(out, env)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func sortedFiles(files map[string][]byte) []string {
+	var out []string
+	for file := range files {
+		out = append(out, file)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// setterName returns the name of a workload attribute setter
+func setterName(workload, attribute string) string {
+	return fmt.Sprintf("%s-%s", workload, attribute)
+}
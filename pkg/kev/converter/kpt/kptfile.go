@@ -0,0 +1,242 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kpt
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	kptfileName       = "Kptfile"
+	kptfileAPIVersion = "kpt.dev/v1"
+	kptfileKind       = "Kptfile"
+
+	settersFileName = "setters.yaml"
+
+	// applySettersImage is the kpt function applying setters values to the package manifests
+	applySettersImage = "gcr.io/kpt-fn/apply-setters:v0.2"
+
+	// localConfigAnnotation marks package resources which aren't applied to the cluster
+	localConfigAnnotation = "config.kubernetes.io/local-config"
+)
+
+// workloadKinds lists the kinds whose replicas and main container image are exposed as setters
+var workloadKinds = map[string]bool{
+	"Deployment":  true,
+	"StatefulSet": true,
+	"DaemonSet":   true,
+	"Job":         true,
+	"CronJob":     true,
+	"Pod":         true,
+}
+
+// kptfile is the Kptfile content
+type kptfile struct {
+	APIVersion string    `yaml:"apiVersion"`
+	Kind       string    `yaml:"kind"`
+	Metadata   metadata  `yaml:"metadata"`
+	Info       info      `yaml:"info"`
+	Pipeline   *pipeline `yaml:"pipeline,omitempty"`
+}
+
+type metadata struct {
+	Name        string            `yaml:"name"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+type info struct {
+	Description string `yaml:"description"`
+}
+
+type pipeline struct {
+	Mutators []function `yaml:"mutators"`
+}
+
+type function struct {
+	Image      string `yaml:"image"`
+	ConfigPath string `yaml:"configPath"`
+}
+
+// settersConfig is the apply-setters function config
+type settersConfig struct {
+	APIVersion string            `yaml:"apiVersion"`
+	Kind       string            `yaml:"kind"`
+	Metadata   metadata          `yaml:"metadata"`
+	Data       map[string]string `yaml:"data"`
+}
+
+// packageFiles returns an environment's package Kptfile, and its setters config when there are any setters
+func packageFiles(env, project string, setters map[string]string) (map[string][]byte, error) {
+	localConfig := map[string]string{localConfigAnnotation: "true"}
+
+	kf := kptfile{
+		APIVersion: kptfileAPIVersion,
+		Kind:       kptfileKind,
+		Metadata:   metadata{Name: env, Annotations: localConfig},
+		Info:       info{Description: fmt.Sprintf("%s environment of %s rendered by kev", env, project)},
+	}
+
+	out := map[string][]byte{}
+	if len(setters) > 0 {
+		kf.Pipeline = &pipeline{
+			Mutators: []function{{Image: applySettersImage, ConfigPath: settersFileName}},
+		}
+
+		data, err := marshal(settersConfig{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+			Metadata:   metadata{Name: "setters", Annotations: localConfig},
+			Data:       setters,
+		})
+		if err != nil {
+			return nil, err
+		}
+		out[settersFileName] = data
+	}
+
+	data, err := marshal(kf)
+	if err != nil {
+		return nil, err
+	}
+	out[kptfileName] = data
+
+	return out, nil
+}
+
+// markSetters marks workloads replicas and main container image with kpt setter comments.
+// It returns the marked manifest and the setters values keyed by setter name.
+func markSetters(data []byte) ([]byte, map[string]string, error) {
+	setters := map[string]string{}
+
+	var docs []*yaml.Node
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc yaml.Node
+		if err := dec.Decode(&doc); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, nil, err
+		}
+		if len(doc.Content) > 0 {
+			markObject(doc.Content[0], setters)
+		}
+		docs = append(docs, &doc)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	for _, doc := range docs {
+		if err := enc.Encode(doc); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return nil, nil, err
+	}
+
+	return buf.Bytes(), setters, nil
+}
+
+// markObject marks a workload object, or the workloads in a list object, with kpt setter comments
+func markObject(obj *yaml.Node, setters map[string]string) {
+	kind := valueOf(obj, "kind")
+	if kind != nil && kind.Value == "List" {
+		if items := valueOf(obj, "items"); items != nil {
+			for _, item := range items.Content {
+				markObject(item, setters)
+			}
+		}
+		return
+	}
+
+	if kind == nil || !workloadKinds[kind.Value] {
+		return
+	}
+
+	name := valueOf(obj, "metadata", "name")
+	if name == nil {
+		return
+	}
+
+	if replicas := valueOf(obj, "spec", "replicas"); replicas != nil {
+		mark(replicas, setterName(name.Value, "replicas"), setters)
+	}
+
+	podSpecPath := []string{"spec", "template", "spec"}
+	switch kind.Value {
+	case "Pod":
+		podSpecPath = []string{"spec"}
+	case "CronJob":
+		podSpecPath = []string{"spec", "jobTemplate", "spec", "template", "spec"}
+	}
+
+	containers := valueOf(obj, append(podSpecPath, "containers")...)
+	if containers == nil || len(containers.Content) == 0 {
+		return
+	}
+	if image := valueOf(containers.Content[0], "image"); image != nil {
+		mark(image, setterName(name.Value, "image"), setters)
+	}
+}
+
+// mark adds a kpt setter comment to a scalar node and records its value
+func mark(node *yaml.Node, name string, setters map[string]string) {
+	node.LineComment = fmt.Sprintf("kpt-set: ${%s}", name)
+	setters[name] = node.Value
+}
+
+// valueOf returns the node found at the supplied mapping keys path, nil when not found
+func valueOf(node *yaml.Node, path ...string) *yaml.Node {
+	current := node
+	for _, key := range path {
+		if current.Kind != yaml.MappingNode {
+			return nil
+		}
+
+		var next *yaml.Node
+		for i := 0; i+1 < len(current.Content); i += 2 {
+			if current.Content[i].Value == key {
+				next = current.Content[i+1]
+				break
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		current = next
+	}
+	return current
+}
+
+func marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
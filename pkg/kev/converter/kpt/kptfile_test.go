@@ -0,0 +1,119 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kpt
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v3"
+)
+
+var _ = Describe("Kptfile", func() {
+
+	Describe("markSetters", func() {
+		manifest := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+        - name: web
+          image: web:1.0
+        - name: proxy
+          image: proxy:1.0
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  type: ClusterIP
+`
+
+		It("marks workload replicas and main container image with setters", func() {
+			marked, _, err := markSetters([]byte(manifest))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(marked)).To(ContainSubstring("replicas: 3 # kpt-set: ${web-replicas}"))
+			Expect(string(marked)).To(ContainSubstring("image: web:1.0 # kpt-set: ${web-image}"))
+			Expect(string(marked)).NotTo(ContainSubstring("${proxy-image}"))
+		})
+
+		It("returns setters values", func() {
+			_, setters, err := markSetters([]byte(manifest))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(setters).To(Equal(map[string]string{
+				"web-replicas": "3",
+				"web-image":    "web:1.0",
+			}))
+		})
+
+		It("marks workloads within a list", func() {
+			list := `apiVersion: v1
+kind: List
+items:
+  - apiVersion: batch/v1beta1
+    kind: CronJob
+    metadata:
+      name: backup
+    spec:
+      jobTemplate:
+        spec:
+          template:
+            spec:
+              containers:
+                - name: backup
+                  image: backup:1.0
+`
+			_, setters, err := markSetters([]byte(list))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(setters).To(HaveKeyWithValue("backup-image", "backup:1.0"))
+		})
+	})
+
+	Describe("packageFiles", func() {
+		It("writes the Kptfile with package metadata", func() {
+			files, err := packageFiles("dev", "shop", nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(HaveLen(1))
+
+			var kf kptfile
+			Expect(yaml.Unmarshal(files[kptfileName], &kf)).To(Succeed())
+			Expect(kf.APIVersion).To(Equal("kpt.dev/v1"))
+			Expect(kf.Kind).To(Equal("Kptfile"))
+			Expect(kf.Metadata.Name).To(Equal("dev"))
+			Expect(kf.Info.Description).To(ContainSubstring("shop"))
+			Expect(kf.Pipeline).To(BeNil())
+		})
+
+		It("writes the setters config and applies it in the Kptfile pipeline", func() {
+			files, err := packageFiles("dev", "shop", map[string]string{"web-replicas": "3"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(HaveKey(settersFileName))
+
+			var kf kptfile
+			Expect(yaml.Unmarshal(files[kptfileName], &kf)).To(Succeed())
+			Expect(kf.Pipeline.Mutators).To(Equal([]function{{Image: applySettersImage, ConfigPath: settersFileName}}))
+
+			var sc settersConfig
+			Expect(yaml.Unmarshal(files[settersFileName], &sc)).To(Succeed())
+			Expect(sc.Data).To(HaveKeyWithValue("web-replicas", "3"))
+		})
+	})
+})
@@ -0,0 +1,85 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultKubeVersion is the Kubernetes version generated objects apiVersions are selected for by default
+const DefaultKubeVersion = "1.19"
+
+// apiVersion is an object apiVersion served since a Kubernetes minor version
+type apiVersion struct {
+	since   int
+	version string
+}
+
+// apiVersions lists, for each kind of generated object with more than one supported apiVersion,
+// the apiVersion to use depending on the target Kubernetes minor version, most recent last.
+var apiVersions = map[string][]apiVersion{
+	"Ingress": {
+		{since: 0, version: "networking.k8s.io/v1beta1"},
+		{since: 19, version: "networking.k8s.io/v1"},
+	},
+	"PodDisruptionBudget": {
+		{since: 0, version: "policy/v1beta1"},
+		{since: 21, version: "policy/v1"},
+	},
+	"CronJob": {
+		{since: 0, version: "batch/v1beta1"},
+		{since: 21, version: "batch/v1"},
+	},
+	"HorizontalPodAutoscaler": {
+		{since: 0, version: "autoscaling/v2beta2"},
+		{since: 23, version: "autoscaling/v2"},
+	},
+}
+
+// ParseKubeVersion returns the minor version of a 1.x Kubernetes version, e.g. 1.24, v1.24 or 1.24.3
+func ParseKubeVersion(version string) (int, error) {
+	parts := strings.Split(strings.TrimPrefix(strings.TrimSpace(version), "v"), ".")
+	if len(parts) < 2 || parts[0] != "1" {
+		return 0, fmt.Errorf("kubernetes version must be in the 1.<minor> format, got %q", version)
+	}
+
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil || minor < 0 {
+		return 0, fmt.Errorf("kubernetes version must be in the 1.<minor> format, got %q", version)
+	}
+
+	return minor, nil
+}
+
+// apiVersionFor returns the apiVersion of an object kind for a Kubernetes version.
+// The default Kubernetes version is used when the version is empty or invalid.
+func apiVersionFor(kind, version string) string {
+	minor, err := ParseKubeVersion(version)
+	if err != nil {
+		minor, _ = ParseKubeVersion(DefaultKubeVersion)
+	}
+
+	selected := ""
+	for _, v := range apiVersions[kind] {
+		if minor >= v.since {
+			selected = v.version
+		}
+	}
+	return selected
+}
@@ -0,0 +1,59 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("API versions", func() {
+
+	Describe("ParseKubeVersion", func() {
+		It("parses the minor version of supported formats", func() {
+			for _, version := range []string{"1.24", "v1.24", "1.24.3"} {
+				minor, err := ParseKubeVersion(version)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(minor).To(Equal(24))
+			}
+		})
+
+		It("errors on invalid versions", func() {
+			for _, version := range []string{"", "1", "2.1", "1.x"} {
+				_, err := ParseKubeVersion(version)
+				Expect(err).To(HaveOccurred())
+			}
+		})
+	})
+
+	Describe("apiVersionFor", func() {
+		It("defaults to the apiVersions served by the default Kubernetes version", func() {
+			Expect(apiVersionFor("Ingress", "")).To(Equal("networking.k8s.io/v1"))
+			Expect(apiVersionFor("PodDisruptionBudget", "")).To(Equal("policy/v1beta1"))
+			Expect(apiVersionFor("CronJob", "")).To(Equal("batch/v1beta1"))
+			Expect(apiVersionFor("HorizontalPodAutoscaler", "")).To(Equal("autoscaling/v2beta2"))
+		})
+
+		It("selects the most recent apiVersion served by the target Kubernetes version", func() {
+			Expect(apiVersionFor("Ingress", "1.18")).To(Equal("networking.k8s.io/v1beta1"))
+			Expect(apiVersionFor("PodDisruptionBudget", "1.21")).To(Equal("policy/v1"))
+			Expect(apiVersionFor("CronJob", "1.24")).To(Equal("batch/v1"))
+			Expect(apiVersionFor("HorizontalPodAutoscaler", "1.22")).To(Equal("autoscaling/v2beta2"))
+			Expect(apiVersionFor("HorizontalPodAutoscaler", "1.23")).To(Equal("autoscaling/v2"))
+		})
+	})
+})
@@ -21,34 +21,253 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"time"
 
+	"github.com/appvia/kev/pkg/kev/config"
 	"github.com/appvia/kev/pkg/kev/log"
 	kmd "github.com/appvia/komando"
 	composego "github.com/compose-spec/compose-go/types"
 	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+	v1apps "k8s.io/api/apps/v1"
+	v1batch "k8s.io/api/batch/v1"
+	v1beta1batch "k8s.io/api/batch/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 const (
 	// Name of the converter
-	Name                  = "kubernetes"
-	singleFileDefaultName = "k8s.yaml"
+	Name                      = "kubernetes"
+	singleFileDefaultName     = "k8s.yaml"
+	singleJSONFileDefaultName = "k8s.json"
+
+	// OutputYAML encodes rendered manifests as YAML
+	OutputYAML = "yaml"
+
+	// OutputJSON encodes rendered manifests as JSON
+	OutputJSON = "json"
 
 	// MultiFileSubDir is default output directory name for kubernetes manifests
 	MultiFileSubDir = "k8s"
+
+	// EnvironmentLabel is the label identifying the environment an object, and the pods it templates, was rendered for
+	EnvironmentLabel = "kev.appvia.io/environment"
+
+	// SourceFileAnnotation is the annotation recording the compose source file an object's service originated from
+	SourceFileAnnotation = "kev.appvia.io/source-file"
+
+	// FieldManagerAnnotation is the annotation recording the field manager to use when server-side applying an object
+	FieldManagerAnnotation = "kev.appvia.io/field-manager"
+
+	// SkipRenderLabel is the compose service label excluding a service from the rendered manifests
+	SkipRenderLabel = "kev.service.skip-render"
+
+	// VolumeModeLabelPrefix is the compose service label prefix setting the file mode of a Secret or ConfigMap volume's files
+	// in octal, e.g. `kev.workload.volume-mode.ssh-key: "0400"` sets the `ssh-key` volume default mode to 0400
+	VolumeModeLabelPrefix = "kev.workload.volume-mode."
+
+	// ConfigMountModeLabel is the compose service label overriding how config files are mounted from ConfigMaps,
+	// one of: subpath, directory. By default single files are mounted with a sub path and directories as a whole.
+	ConfigMountModeLabel = "kev.workload.config-mount-mode"
+
+	// ConfigMountModeSubPath mounts config files in place with a sub path, keeping the mount directory contents.
+	// Sub path mounts don't receive ConfigMap updates live.
+	ConfigMountModeSubPath = "subpath"
+
+	// ConfigMountModeDirectory mounts config files' ConfigMap as a whole directory receiving ConfigMap updates live.
+	// It shadows the mount directory contents.
+	ConfigMountModeDirectory = "directory"
+
+	// CustomAnnotationLabelPrefix is the compose service label prefix adding custom annotations to generated objects,
+	// e.g. `kev.annotation.prometheus.io/scrape: "true"` annotates objects with `prometheus.io/scrape: "true"`
+	CustomAnnotationLabelPrefix = "kev.annotation."
+
+	// CustomLabelPrefix is the compose service label prefix adding custom labels to generated objects,
+	// e.g. `kev.label.team: payments` labels objects with `team: payments`
+	CustomLabelPrefix = "kev.label."
+
+	// RollbackConfigAnnotation is the annotation recording a workload's compose deploy rollback_config settings
+	RollbackConfigAnnotation = "kev.appvia.io/rollback-config"
+
+	// DependsOnStrategyLabel is the compose service label selecting how service dependencies are rendered
+	DependsOnStrategyLabel = "kev.service.depends-on-strategy"
+
+	// DependsOnAnnotationStrategy records service dependencies in an annotation on the workload
+	DependsOnAnnotationStrategy = "annotation"
+
+	// DependsOnInitContainerStrategy adds an init container waiting for each dependency's service to accept connections
+	DependsOnInitContainerStrategy = "init-container"
+
+	// DependsOnNoneStrategy ignores service dependencies
+	DependsOnNoneStrategy = "none"
+
+	// DependsOnAnnotation is the annotation recording the services a workload depends on
+	DependsOnAnnotation = "kev.appvia.io/depends-on"
+
+	// DependsOnWaitImage is the image of init containers waiting for service dependencies
+	DependsOnWaitImage = "busybox:1.33"
+
+	// WarningAnnotationPrefix is the prefix of annotations recording service conversion warnings,
+	// numbered in the order warnings were raised, e.g. `kev.appvia.io/warning-1`
+	WarningAnnotationPrefix = "kev.appvia.io/warning-"
 )
 
 // K8s is a native kubernetes manifests converter
 type K8s struct {
 	UI kmd.UI
+	// AllEnvsSingleFile renders all environments into a single multi-document manifest bundle
+	AllEnvsSingleFile bool
+	// ServiceSourceFiles maps compose service names to their originating compose source files.
+	// When set, rendered objects get annotated with their service source file.
+	ServiceSourceFiles map[string]string
+	// Concurrency is the maximum number of environments rendered concurrently. Defaults to 1, rendering
+	// environments sequentially so that each service conversion step gets reported.
+	Concurrency int
+	// FieldManager is the server-side apply field manager name rendered objects get annotated with, if set
+	FieldManager string
+	// QuotaHeadroom is the factor applied to environment resources when rendering a resource quota
+	// and limit range for the environment namespace. Disabled when zero.
+	QuotaHeadroom float64
+	// NamespaceConfigs maps environment names to their namespace configuration. When set for an environment,
+	// its resource quota & limit range take precedence over the quota derived from the environment's workloads.
+	NamespaceConfigs map[string]config.NamespaceConfig
+	// ExposeAPI is the API used to expose services, one of: ingress, traefik. Defaults to ingress.
+	ExposeAPI string
+	// PruneEmpty drops rendered objects carrying no meaningful configuration
+	PruneEmpty bool
+	// Index writes a markdown index summarising the rendered objects next to the manifests
+	Index bool
+	// Output is the encoding of rendered manifests, one of: yaml, json. Defaults to yaml.
+	Output string
+	// KubeVersion is the target Kubernetes version selecting generated objects apiVersions. Defaults to DefaultKubeVersion.
+	KubeVersion string
+	// WarningAnnotations records service conversion warnings as annotations on the service's objects
+	WarningAnnotations bool
+	// NodePortRange is the range node ports must be allocated from. Defaults to DefaultNodePortRange.
+	NodePortRange string
+	// EnvPatches maps environment names to the patches applied to their rendered objects
+	EnvPatches map[string][]config.Patch
+}
+
+// Option configures a native Kubernetes converter
+type Option func(c *K8s)
+
+// WithAllEnvsSingleFile configures the converter to render all environments into a single bundle file
+func WithAllEnvsSingleFile(allEnvsSingleFile bool) Option {
+	return func(c *K8s) {
+		c.AllEnvsSingleFile = allEnvsSingleFile
+	}
+}
+
+// WithServiceSourceFiles configures the converter to annotate rendered objects with their service source file
+func WithServiceSourceFiles(sourceFiles map[string]string) Option {
+	return func(c *K8s) {
+		c.ServiceSourceFiles = sourceFiles
+	}
+}
+
+// WithConcurrency configures the maximum number of environments rendered concurrently
+func WithConcurrency(concurrency int) Option {
+	return func(c *K8s) {
+		c.Concurrency = concurrency
+	}
+}
+
+// WithFieldManager configures the converter to annotate rendered objects with a server-side apply field manager name
+func WithFieldManager(fieldManager string) Option {
+	return func(c *K8s) {
+		c.FieldManager = fieldManager
+	}
+}
+
+// WithResourceQuota configures the converter to render a resource quota and limit range for each environment
+// namespace, derived from the environment's workload resources scaled by the headroom factor
+func WithResourceQuota(headroom float64) Option {
+	return func(c *K8s) {
+		c.QuotaHeadroom = headroom
+	}
+}
+
+// WithNamespaceConfigs configures the converter to place each environment's objects in its configured namespace
+// and render the namespace's resource quota and limit range, keyed by environment name
+func WithNamespaceConfigs(namespaceConfigs map[string]config.NamespaceConfig) Option {
+	return func(c *K8s) {
+		c.NamespaceConfigs = namespaceConfigs
+	}
+}
+
+// WithEnvPatches configures the converter to patch each environment's rendered objects, keyed by environment name
+func WithEnvPatches(patches map[string][]config.Patch) Option {
+	return func(c *K8s) {
+		c.EnvPatches = patches
+	}
+}
+
+// WithExposeAPI configures the API used to expose services, one of: ingress, traefik
+func WithExposeAPI(api string) Option {
+	return func(c *K8s) {
+		c.ExposeAPI = api
+	}
+}
+
+// WithPruneEmpty configures the converter to drop rendered objects carrying no meaningful configuration
+func WithPruneEmpty(pruneEmpty bool) Option {
+	return func(c *K8s) {
+		c.PruneEmpty = pruneEmpty
+	}
+}
+
+// WithIndex configures the converter to write a markdown index summarising the rendered objects
+func WithIndex(index bool) Option {
+	return func(c *K8s) {
+		c.Index = index
+	}
+}
+
+// WithOutput configures the encoding of rendered manifests, one of: yaml, json
+func WithOutput(output string) Option {
+	return func(c *K8s) {
+		c.Output = output
+	}
+}
+
+// WithKubeVersion configures the target Kubernetes version selecting generated objects apiVersions, e.g. 1.24
+func WithKubeVersion(version string) Option {
+	return func(c *K8s) {
+		c.KubeVersion = version
+	}
+}
+
+// WithNodePortRange configures the range node ports must be allocated from, e.g. 30000-32767
+func WithNodePortRange(portRange string) Option {
+	return func(c *K8s) {
+		c.NodePortRange = portRange
+	}
+}
+
+// WithWarningAnnotations configures the converter to record service conversion warnings as annotations on the service's objects
+func WithWarningAnnotations(warningAnnotations bool) Option {
+	return func(c *K8s) {
+		c.WarningAnnotations = warningAnnotations
+	}
 }
 
 // New return a native Kubernetes converter
-func New() *K8s {
-	return &K8s{}
+func New(opts ...Option) *K8s {
+	c := &K8s{Concurrency: 1}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
 }
 
-func NewWithUI(ui kmd.UI) *K8s {
-	return &K8s{UI: ui}
+func NewWithUI(ui kmd.UI, opts ...Option) *K8s {
+	c := New(opts...)
+	c.UI = ui
+	return c
 }
 
 // Render generates outcome
@@ -59,15 +278,31 @@ func (c *K8s) Render(singleFile bool,
 	rendered map[string][]byte,
 	excluded map[string][]string) (map[string]string, error) {
 
+	if c.AllEnvsSingleFile {
+		return c.renderBundle(dir, workDir, projects, files, rendered, excluded)
+	}
+
 	renderOutputPaths := map[string]string{}
 	envs := getSortedEnvs(projects)
-	for _, env := range envs {
-		project := projects[env]
 
-		log.Debugf("Rendering environment [%s]", env)
+	workers := c.Concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	concurrent := workers > 1 && len(envs) > 1
+
+	// @step each environment renders into its own accumulator, merged in environment order
+	// once all are done, so that output is deterministic and the accumulator is never written concurrently
+	envRendered := make([]map[string][]byte, len(envs))
+	envDurations := make([]time.Duration, len(envs))
 
-		envFile := files[env][len(files[env])-1]
-		c.UI.Output(fmt.Sprintf("%s: %s", env, envFile))
+	var g errgroup.Group
+	sem := make(chan struct{}, workers)
+	started := time.Now()
+
+	for i, env := range envs {
+		i, env := i, env
+		envRendered[i] = map[string][]byte{}
 
 		// @step override output directory if specified
 		outDirPath := ""
@@ -78,55 +313,473 @@ func (c *K8s) Render(singleFile bool,
 			outDirPath = filepath.Join(workDir, MultiFileSubDir, env)
 		}
 
-		// @step create output directory
-		// To generate outcome as a set of separate manifests first must create out directory
-		// as Kompose logic checks for this and only will do that for existing directories,
-		// otherwise will treat OutFile as regular file and output all manifests to that single file.
-		if err := os.MkdirAll(outDirPath, os.ModePerm); err != nil {
-			return nil, err
-		}
-
 		// @step generate multiple / single file
 		outFilePath := ""
 		if singleFile {
-			outFilePath = filepath.Join(outDirPath, singleFileDefaultName)
+			outFilePath = filepath.Join(outDirPath, c.singleFileName())
 		} else {
 			outFilePath = outDirPath
 		}
 
-		// @step kubernetes manifests output options
+		renderOutputPaths[env] = outFilePath
+
+		if !concurrent {
+			envFile := files[env][len(files[env])-1]
+			c.UI.Output(fmt.Sprintf("%s: %s (%s)", env, envFile, progress(i+1, len(envs), time.Since(started))))
+
+			if err := c.renderEnv(c.UI, env, outDirPath, outFilePath, projects[env], files[env], excluded[env], envRendered[i]); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		// @step service conversion steps aren't reported when rendering concurrently as their output would interleave
+		g.Go(func() error {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			envStarted := time.Now()
+			if err := c.renderEnv(kmd.NoOpUI(), env, outDirPath, outFilePath, projects[env], files[env], excluded[env], envRendered[i]); err != nil {
+				return err
+			}
+			envDurations[i] = time.Since(envStarted)
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	for i, env := range envs {
+		for file, data := range envRendered[i] {
+			rendered[file] = data
+		}
+
+		if concurrent {
+			envFile := files[env][len(files[env])-1]
+			c.UI.Output(fmt.Sprintf("%s: %s (%d/%d) in %s", env, envFile, i+1, len(envs), envDurations[i].Round(time.Millisecond)))
+		}
+	}
+
+	return renderOutputPaths, nil
+}
+
+// renderEnv transforms an environment's project and writes its manifests to disk
+func (c *K8s) renderEnv(ui kmd.UI, env, outDirPath, outFilePath string,
+	project *composego.Project,
+	files []string,
+	excluded []string,
+	rendered map[string][]byte) error {
+
+	log.Debugf("Rendering environment [%s]", env)
+
+	// @step create output directory
+	// To generate outcome as a set of separate manifests first must create out directory
+	// as Kompose logic checks for this and only will do that for existing directories,
+	// otherwise will treat OutFile as regular file and output all manifests to that single file.
+	if err := os.MkdirAll(outDirPath, os.ModePerm); err != nil {
+		return err
+	}
+
+	// @step kubernetes manifests output options
+	convertOpts := ConvertOptions{
+		InputFiles:   files,
+		OutFile:      outFilePath,
+		ExposeAPI:    c.ExposeAPI,
+		GenerateJSON: c.generateJSON(),
+	}
+
+	// @step Do the transformation
+	objects, err := c.transform(ui, env, convertOpts, project, excluded)
+	if err != nil {
+		return err
+	}
+
+	// @step Produce objects
+	if err := PrintList(objects, convertOpts, rendered); err != nil {
+		return errors.Wrapf(err, "Could not render %s manifests to disk, details:\n", Name)
+	}
+
+	// @step Produce manifests index
+	if c.Index {
+		section, err := manifestIndex(env, objects)
+		if err != nil {
+			return err
+		}
+		if err := writeIndex(outDirPath, [][]byte{section}, rendered); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// transform maps an environment's compose project to Kubernetes objects, post-processed as configured
+func (c *K8s) transform(ui kmd.UI, env string, convertOpts ConvertOptions, project *composego.Project, excluded []string) ([]runtime.Object, error) {
+	// @step set excluded docker compose services for current project
+	exc := []string{}
+	if excluded != nil {
+		exc = excluded
+	}
+
+	// @step Get Kubernetes transformer that maps compose project to Kubernetes primitives
+	k := &Kubernetes{Opt: convertOpts, Project: project, Excluded: exc, SourceFiles: c.ServiceSourceFiles, Environment: env, KubeVersion: c.KubeVersion, NodePortRange: c.NodePortRange, WarningAnnotations: c.WarningAnnotations, UI: ui}
+
+	objects, err := k.Transform()
+	if err != nil {
+		return nil, err
+	}
+
+	objects = c.prune(objects)
+	guardrails, err := c.envGuardrails(env)
+	if err != nil {
+		return nil, errors.Wrapf(err, "environment %s", env)
+	}
+	if len(guardrails) == 0 {
+		guardrails = c.guardrails(objects)
+	}
+	objects = append(objects, guardrails...)
+
+	objects, err = c.setNamespace(env, objects)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := labelEnvironment(objects, env); err != nil {
+		return nil, err
+	}
+
+	if err := c.annotateFieldManager(objects); err != nil {
+		return nil, err
+	}
+
+	// @step patches are applied last so they can override anything rendered above
+	if err := c.applyPatches(env, objects); err != nil {
+		return nil, err
+	}
+
+	return objects, nil
+}
+
+// TransformEnv transforms an environment's project into the Kubernetes objects Render would write for it,
+// without writing anything to disk. Other output formats build on these objects so that all converter
+// options apply to them. Service conversion steps are reported when the converter has a UI.
+func (c *K8s) TransformEnv(env string, project *composego.Project, files []string, excluded []string) ([]runtime.Object, error) {
+	convertOpts := ConvertOptions{
+		InputFiles:   files,
+		ExposeAPI:    c.ExposeAPI,
+		GenerateJSON: c.generateJSON(),
+	}
+
+	ui := c.UI
+	if ui == nil {
+		ui = kmd.NoOpUI()
+	}
+
+	return c.transform(ui, env, convertOpts, project, excluded)
+}
+
+// RenderEnvToMemory renders an environment's manifests without writing them to disk.
+// It returns the environment's output directory and the manifests, including the index when enabled,
+// keyed by the file path Render writes them to. Manifests are encoded the same way Render encodes them.
+func (c *K8s) RenderEnvToMemory(singleFile bool,
+	dir, workDir, env string,
+	project *composego.Project,
+	files []string,
+	excluded []string) (string, map[string][]byte, error) {
+
+	outDirPath := filepath.Join(workDir, MultiFileSubDir, env)
+	if dir != "" {
+		outDirPath = filepath.Join(dir, env)
+	}
+
+	objects, err := c.TransformEnv(env, project, files, excluded)
+	if err != nil {
+		return "", nil, err
+	}
+
+	out := map[string][]byte{}
+	if singleFile {
+		data, err := MarshalList(objects, c.generateJSON())
+		if err != nil {
+			return "", nil, err
+		}
+		// single file output is terminated with a new line
+		out[filepath.Join(outDirPath, c.singleFileName())] = append(data, '\n')
+	} else {
+		manifests, err := MarshalFiles(objects, c.generateJSON())
+		if err != nil {
+			return "", nil, err
+		}
+		for name, data := range manifests {
+			out[filepath.Join(outDirPath, name)] = data
+		}
+	}
+
+	if c.Index {
+		section, err := manifestIndex(env, objects)
+		if err != nil {
+			return "", nil, err
+		}
+		out[filepath.Join(outDirPath, IndexFileName)] = indexContent([][]byte{section})
+	}
+
+	return outDirPath, out, nil
+}
+
+// renderBundle renders all environments into a single multi-document manifest bundle.
+// Each object is placed in a namespace named after its environment and labelled with it
+// to avoid collisions between the same objects rendered for different environments.
+func (c *K8s) renderBundle(dir, workDir string,
+	projects map[string]*composego.Project,
+	files map[string][]string,
+	rendered map[string][]byte,
+	excluded map[string][]string) (map[string]string, error) {
+
+	// @step bundle is placed in the output directory root
+	outDirPath := dir
+	if outDirPath == "" {
+		outDirPath = filepath.Join(workDir, MultiFileSubDir)
+	}
+
+	if err := os.MkdirAll(outDirPath, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	outFilePath := filepath.Join(outDirPath, c.singleFileName())
+	renderOutputPaths := map[string]string{}
+
+	var (
+		objects    []runtime.Object
+		inputFiles []string
+		index      [][]byte
+	)
+
+	envs := getSortedEnvs(projects)
+	started := time.Now()
+	for i, env := range envs {
+		log.Debugf("Rendering environment [%s] into bundle", env)
+
+		envFile := files[env][len(files[env])-1]
+		c.UI.Output(fmt.Sprintf("%s: %s (%s)", env, envFile, progress(i+1, len(envs), time.Since(started))))
+
 		convertOpts := ConvertOptions{
 			InputFiles: files[env],
 			OutFile:    outFilePath,
+			ExposeAPI:  c.ExposeAPI,
 		}
 
-		renderOutputPaths[env] = outFilePath
+		envObjects, err := c.transform(c.UI, env, convertOpts, projects[env], excluded[env])
+		if err != nil {
+			return nil, err
+		}
 
-		// @step set excluded docker compose services for current project
-		exc := []string{}
-		if excluded != nil {
-			if e, ok := excluded[env]; ok {
-				exc = e
+		if err := setEnvironmentNamespace(envObjects, env); err != nil {
+			return nil, err
+		}
+
+		if c.Index {
+			section, err := manifestIndex(env, envObjects)
+			if err != nil {
+				return nil, err
 			}
+			index = append(index, section)
 		}
 
-		// @step Get Kubernetes transformer that maps compose project to Kubernetes primitives
-		k := &Kubernetes{Opt: convertOpts, Project: project, Excluded: exc, UI: c.UI}
+		objects = append(objects, envObjects...)
+		inputFiles = append(inputFiles, files[env]...)
+		renderOutputPaths[env] = outFilePath
+	}
 
-		// @step Do the transformation
-		objects, err := k.Transform()
-		if err != nil {
+	convertOpts := ConvertOptions{
+		InputFiles:   inputFiles,
+		OutFile:      outFilePath,
+		GenerateJSON: c.generateJSON(),
+	}
+
+	if err := PrintList(objects, convertOpts, rendered); err != nil {
+		return nil, errors.Wrapf(err, "Could not render %s manifests bundle to disk, details:\n", Name)
+	}
+
+	if c.Index {
+		if err := writeIndex(outDirPath, index, rendered); err != nil {
 			return nil, err
 		}
+	}
+
+	return renderOutputPaths, nil
+}
+
+// generateJSON returns whether rendered manifests are encoded as JSON
+func (c *K8s) generateJSON() bool {
+	return c.Output == OutputJSON
+}
+
+// singleFileName returns the name of the single file manifests are rendered to, matching their encoding
+func (c *K8s) singleFileName() string {
+	if c.generateJSON() {
+		return singleJSONFileDefaultName
+	}
+	return singleFileDefaultName
+}
+
+// setEnvironmentNamespace places objects in the environment namespace unless a
+// namespace has already been set. Environment labels are applied by labelEnvironment.
+func setEnvironmentNamespace(objects []runtime.Object, env string) error {
+	for _, o := range objects {
+		accessor, err := meta.Accessor(o)
+		if err != nil {
+			return err
+		}
+
+		// namespaces are cluster scoped
+		if _, ok := o.(*v1.Namespace); !ok && accessor.GetNamespace() == "" {
+			accessor.SetNamespace(env)
+		}
+	}
+	return nil
+}
 
-		// @step Produce objects
-		err = PrintList(objects, convertOpts, rendered)
+// labelEnvironment labels objects, and the pods they template, with the environment they were rendered for
+func labelEnvironment(objects []runtime.Object, env string) error {
+	for _, o := range objects {
+		accessor, err := meta.Accessor(o)
 		if err != nil {
-			return nil, errors.Wrapf(err, "Could not render %s manifests to disk, details:\n", Name)
+			return err
+		}
+		accessor.SetLabels(withLabel(accessor.GetLabels(), EnvironmentLabel, env))
+
+		if template := podTemplate(o); template != nil {
+			template.Labels = withLabel(template.Labels, EnvironmentLabel, env)
 		}
 	}
+	return nil
+}
 
-	return renderOutputPaths, nil
+// withLabel returns a copy of labels with the label set, as labels may be shared with selectors
+func withLabel(labels map[string]string, key, value string) map[string]string {
+	out := map[string]string{}
+	for k, v := range labels {
+		out[k] = v
+	}
+	out[key] = value
+	return out
+}
+
+// podTemplate returns the pod template of workload objects, nil for any other object
+func podTemplate(o runtime.Object) *v1.PodTemplateSpec {
+	switch t := o.(type) {
+	case *v1apps.Deployment:
+		return &t.Spec.Template
+	case *v1apps.StatefulSet:
+		return &t.Spec.Template
+	case *v1apps.DaemonSet:
+		return &t.Spec.Template
+	case *v1batch.Job:
+		return &t.Spec.Template
+	case *v1beta1batch.CronJob:
+		return &t.Spec.JobTemplate.Spec.Template
+	default:
+		return nil
+	}
+}
+
+// annotateSourceFile annotates objects with the compose source file their service originated from
+func annotateSourceFile(objects []runtime.Object, file string) error {
+	return annotate(objects, SourceFileAnnotation, file)
+}
+
+// annotateWarnings annotates objects with each of their service conversion warnings
+func annotateWarnings(objects []runtime.Object, warnings []string) error {
+	for i, w := range warnings {
+		if err := annotate(objects, fmt.Sprintf("%s%d", WarningAnnotationPrefix, i+1), w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// prune drops objects carrying no meaningful configuration, if configured
+func (c *K8s) prune(objects []runtime.Object) []runtime.Object {
+	if !c.PruneEmpty {
+		return objects
+	}
+	return pruneEmpty(objects)
+}
+
+// guardrails returns the environment namespace resource quota and limit range, if configured
+func (c *K8s) guardrails(objects []runtime.Object) []runtime.Object {
+	if c.QuotaHeadroom <= 0 {
+		return nil
+	}
+	return namespaceGuardrails(objects, c.QuotaHeadroom)
+}
+
+// envGuardrails returns the resource quota and limit range configured for the environment namespace, if any
+func (c *K8s) envGuardrails(env string) ([]runtime.Object, error) {
+	nc, ok := c.NamespaceConfigs[env]
+	if !ok {
+		return nil, nil
+	}
+	return configuredNamespaceGuardrails(nc)
+}
+
+// setNamespace places objects in the namespace configured for the environment, if any.
+// The Namespace object is rendered first, ahead of the objects it holds, when enabled.
+func (c *K8s) setNamespace(env string, objects []runtime.Object) ([]runtime.Object, error) {
+	nc, ok := c.NamespaceConfigs[env]
+	if !ok || nc.Name == "" {
+		return objects, nil
+	}
+
+	for _, o := range objects {
+		accessor, err := meta.Accessor(o)
+		if err != nil {
+			return nil, err
+		}
+		accessor.SetNamespace(nc.Name)
+	}
+
+	if !nc.Create {
+		return objects, nil
+	}
+
+	namespace := &v1.Namespace{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Namespace",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: nc.Name,
+		},
+	}
+
+	return append([]runtime.Object{namespace}, objects...), nil
+}
+
+// annotateFieldManager annotates objects with the server-side apply field manager name, if configured
+func (c *K8s) annotateFieldManager(objects []runtime.Object) error {
+	if c.FieldManager == "" {
+		return nil
+	}
+	return annotate(objects, FieldManagerAnnotation, c.FieldManager)
+}
+
+// annotate sets an annotation on all objects
+func annotate(objects []runtime.Object, key, value string) error {
+	for _, o := range objects {
+		accessor, err := meta.Accessor(o)
+		if err != nil {
+			return err
+		}
+
+		// copy annotations as they may be shared with the service configuration
+		annotations := configAnnotations(accessor.GetAnnotations())
+		annotations[key] = value
+		accessor.SetAnnotations(annotations)
+	}
+	return nil
 }
 
 func getSortedEnvs(projects map[string]*composego.Project) []string {
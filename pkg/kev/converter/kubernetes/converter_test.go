@@ -0,0 +1,536 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/appvia/kev/pkg/kev/config"
+	kmd "github.com/appvia/komando"
+	composego "github.com/compose-spec/compose-go/types"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var _ = Describe("Converter", func() {
+
+	Describe("New", func() {
+		It("renders environments separately by default", func() {
+			Expect(New().AllEnvsSingleFile).To(BeFalse())
+		})
+
+		It("applies supplied options", func() {
+			Expect(New(WithAllEnvsSingleFile(true)).AllEnvsSingleFile).To(BeTrue())
+			Expect(New(WithConcurrency(2)).Concurrency).To(Equal(2))
+			Expect(New(WithFieldManager("kev")).FieldManager).To(Equal("kev"))
+		})
+
+		It("renders environments sequentially by default", func() {
+			Expect(New().Concurrency).To(Equal(1))
+		})
+	})
+
+	Describe("Render", func() {
+		var (
+			dir      string
+			projects map[string]*composego.Project
+			files    map[string][]string
+		)
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "kev-render")
+			Expect(err).NotTo(HaveOccurred())
+
+			projects = map[string]*composego.Project{}
+			files = map[string][]string{}
+			for _, env := range []string{"dev", "stage", "prod"} {
+				projects[env] = &composego.Project{
+					Services: composego.Services{
+						{Name: "web", Image: "web:" + env},
+					},
+				}
+				files[env] = []string{"docker-compose.kev." + env + ".yaml"}
+			}
+		})
+
+		AfterEach(func() {
+			_ = os.RemoveAll(dir)
+		})
+
+		It("renders all environments regardless of concurrency", func() {
+			for _, concurrency := range []int{1, 3} {
+				rendered := map[string][]byte{}
+				c := NewWithUI(kmd.NoOpUI(), WithConcurrency(concurrency))
+
+				paths, err := c.Render(false, dir, dir, projects, files, rendered, nil)
+				Expect(err).NotTo(HaveOccurred())
+
+				for _, env := range []string{"dev", "stage", "prod"} {
+					Expect(paths).To(HaveKeyWithValue(env, filepath.Join(dir, env)))
+
+					manifest := filepath.Join(dir, env, "web-deployment.yaml")
+					Expect(rendered).To(HaveKey(manifest))
+					Expect(string(rendered[manifest])).To(ContainSubstring("image: web:" + env))
+				}
+			}
+		})
+
+		It("renders each object as JSON when configured", func() {
+			rendered := map[string][]byte{}
+			c := NewWithUI(kmd.NoOpUI(), WithOutput(OutputJSON))
+
+			_, err := c.Render(false, dir, dir, projects, files, rendered, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			manifest := filepath.Join(dir, "dev", "web-deployment.json")
+			Expect(rendered).To(HaveKey(manifest))
+
+			var obj map[string]interface{}
+			Expect(json.Unmarshal(rendered[manifest], &obj)).To(Succeed())
+			Expect(obj).To(HaveKeyWithValue("kind", "Deployment"))
+		})
+
+		It("renders a single JSON List file when configured", func() {
+			c := NewWithUI(kmd.NoOpUI(), WithOutput(OutputJSON))
+
+			paths, err := c.Render(true, dir, dir, projects, files, map[string][]byte{}, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(paths).To(HaveKeyWithValue("dev", filepath.Join(dir, "dev", "k8s.json")))
+
+			data, err := ioutil.ReadFile(paths["dev"])
+			Expect(err).NotTo(HaveOccurred())
+
+			var list map[string]interface{}
+			Expect(json.Unmarshal(data, &list)).To(Succeed())
+			Expect(list).To(HaveKeyWithValue("kind", "List"))
+		})
+	})
+
+	Describe("setEnvironmentNamespace", func() {
+		var objects []runtime.Object
+
+		BeforeEach(func() {
+			objects = []runtime.Object{
+				&v1apps.Deployment{
+					ObjectMeta: meta.ObjectMeta{
+						Name:   "web",
+						Labels: map[string]string{Selector: "web"},
+					},
+				},
+				&v1.Service{
+					ObjectMeta: meta.ObjectMeta{
+						Name:      "web",
+						Namespace: "custom",
+					},
+				},
+			}
+		})
+
+		It("leaves labels to the environment labelling", func() {
+			Expect(setEnvironmentNamespace(objects, "dev")).To(Succeed())
+
+			d := objects[0].(*v1apps.Deployment)
+			Expect(d.Labels).To(Equal(map[string]string{Selector: "web"}))
+			Expect(objects[1].(*v1.Service).Labels).To(BeEmpty())
+		})
+
+		It("places objects without a namespace in the environment namespace", func() {
+			Expect(setEnvironmentNamespace(objects, "dev")).To(Succeed())
+			Expect(objects[0].(*v1apps.Deployment).Namespace).To(Equal("dev"))
+		})
+
+		It("keeps already configured namespace", func() {
+			Expect(setEnvironmentNamespace(objects, "dev")).To(Succeed())
+			Expect(objects[1].(*v1.Service).Namespace).To(Equal("custom"))
+		})
+	})
+
+	Describe("setNamespace", func() {
+		var objects []runtime.Object
+
+		BeforeEach(func() {
+			objects = []runtime.Object{
+				&v1.Service{TypeMeta: meta.TypeMeta{Kind: "Service"}, ObjectMeta: meta.ObjectMeta{Name: "web"}},
+				&v1apps.Deployment{TypeMeta: meta.TypeMeta{Kind: "Deployment"}, ObjectMeta: meta.ObjectMeta{Name: "web"}},
+			}
+		})
+
+		It("leaves objects untouched when no namespace is configured", func() {
+			out, err := New().setNamespace("dev", objects)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(HaveLen(2))
+			Expect(out[0].(*v1.Service).Namespace).To(BeEmpty())
+		})
+
+		It("places all objects in the configured namespace", func() {
+			c := New(WithNamespaceConfigs(map[string]config.NamespaceConfig{"dev": {Name: "app-dev"}}))
+
+			out, err := c.setNamespace("dev", objects)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(HaveLen(2))
+			Expect(out[0].(*v1.Service).Namespace).To(Equal("app-dev"))
+			Expect(out[1].(*v1apps.Deployment).Namespace).To(Equal("app-dev"))
+		})
+
+		It("renders the namespace object first when enabled", func() {
+			c := New(WithNamespaceConfigs(map[string]config.NamespaceConfig{"dev": {Name: "app-dev", Create: true}}))
+
+			out, err := c.setNamespace("dev", objects)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(HaveLen(3))
+
+			ns, ok := out[0].(*v1.Namespace)
+			Expect(ok).To(BeTrue())
+			Expect(ns.Name).To(Equal("app-dev"))
+			Expect(ns.Namespace).To(BeEmpty())
+			Expect(out[1].GetObjectKind().GroupVersionKind().Kind).To(Equal("Service"))
+		})
+	})
+
+	Describe("applyPatches", func() {
+		var objects []runtime.Object
+
+		BeforeEach(func() {
+			objects = []runtime.Object{
+				&v1.Service{TypeMeta: meta.TypeMeta{Kind: "Service"}, ObjectMeta: meta.ObjectMeta{Name: "web"}},
+				&v1apps.Deployment{
+					TypeMeta:   meta.TypeMeta{Kind: "Deployment"},
+					ObjectMeta: meta.ObjectMeta{Name: "web"},
+					Spec: v1apps.DeploymentSpec{
+						Template: v1.PodTemplateSpec{
+							Spec: v1.PodSpec{
+								Containers: []v1.Container{
+									{Name: "web", Image: "nginx"},
+									{Name: "sidecar", Image: "envoy"},
+								},
+							},
+						},
+					},
+				},
+			}
+		})
+
+		It("leaves objects untouched when no patches are configured", func() {
+			Expect(New().applyPatches("dev", objects)).To(Succeed())
+			Expect(objects[1].(*v1apps.Deployment).Spec.Template.Spec.Containers).To(HaveLen(2))
+		})
+
+		It("merges strategic merge patches into the targeted object", func() {
+			c := New(WithEnvPatches(map[string][]config.Patch{"dev": {{
+				Target:         config.PatchTarget{Kind: "Deployment", Name: "web"},
+				StrategicMerge: "spec:\n  template:\n    spec:\n      containers:\n      - name: web\n        image: nginx:1.21\n",
+			}}}))
+
+			Expect(c.applyPatches("dev", objects)).To(Succeed())
+
+			containers := objects[1].(*v1apps.Deployment).Spec.Template.Spec.Containers
+			Expect(containers).To(HaveLen(2))
+			Expect(containers[0].Image).To(Equal("nginx:1.21"))
+			Expect(containers[1].Image).To(Equal("envoy"))
+		})
+
+		It("applies json6902 patches to the targeted object", func() {
+			c := New(WithEnvPatches(map[string][]config.Patch{"dev": {{
+				Target:   config.PatchTarget{Kind: "Deployment", Name: "web"},
+				JSON6902: "- op: remove\n  path: /spec/template/spec/containers/1\n",
+			}}}))
+
+			Expect(c.applyPatches("dev", objects)).To(Succeed())
+			Expect(objects[1].(*v1apps.Deployment).Spec.Template.Spec.Containers).To(HaveLen(1))
+		})
+
+		It("only patches the environment's objects", func() {
+			c := New(WithEnvPatches(map[string][]config.Patch{"prod": {{
+				Target:   config.PatchTarget{Kind: "Deployment", Name: "web"},
+				JSON6902: "- op: remove\n  path: /spec/template/spec/containers/1\n",
+			}}}))
+
+			Expect(c.applyPatches("dev", objects)).To(Succeed())
+			Expect(objects[1].(*v1apps.Deployment).Spec.Template.Spec.Containers).To(HaveLen(2))
+		})
+
+		It("errors when a patch targets an object that wasn't rendered", func() {
+			c := New(WithEnvPatches(map[string][]config.Patch{"dev": {{
+				Target:         config.PatchTarget{Kind: "StatefulSet", Name: "web"},
+				StrategicMerge: "spec: {}",
+			}}}))
+
+			err := c.applyPatches("dev", objects)
+			Expect(err).To(MatchError("environment dev patches[0] targets StatefulSet/web which isn't among the rendered objects"))
+		})
+
+		It("errors when a patch can't be applied", func() {
+			c := New(WithEnvPatches(map[string][]config.Patch{"dev": {{
+				Target:   config.PatchTarget{Kind: "Service", Name: "web"},
+				JSON6902: "- op: replace\n  path: /spec/missing/field\n  value: 1\n",
+			}}}))
+
+			err := c.applyPatches("dev", objects)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("environment dev patches[0] could not be applied to Service/web"))
+		})
+	})
+
+	Describe("labelEnvironment", func() {
+		var (
+			selector map[string]string
+			objects  []runtime.Object
+		)
+
+		BeforeEach(func() {
+			selector = map[string]string{Selector: "web"}
+			objects = []runtime.Object{
+				&v1apps.Deployment{
+					ObjectMeta: meta.ObjectMeta{
+						Name:   "web",
+						Labels: selector,
+					},
+					Spec: v1apps.DeploymentSpec{
+						Selector: &meta.LabelSelector{MatchLabels: selector},
+						Template: v1.PodTemplateSpec{
+							ObjectMeta: meta.ObjectMeta{Labels: selector},
+						},
+					},
+				},
+				&v1.Service{ObjectMeta: meta.ObjectMeta{Name: "web"}},
+			}
+		})
+
+		It("labels all objects and their pod templates with the environment name", func() {
+			Expect(labelEnvironment(objects, "dev")).To(Succeed())
+
+			d := objects[0].(*v1apps.Deployment)
+			Expect(d.Labels).To(HaveKeyWithValue(EnvironmentLabel, "dev"))
+			Expect(d.Labels).To(HaveKeyWithValue(Selector, "web"))
+			Expect(d.Spec.Template.Labels).To(HaveKeyWithValue(EnvironmentLabel, "dev"))
+			Expect(objects[1].(*v1.Service).Labels).To(HaveKeyWithValue(EnvironmentLabel, "dev"))
+		})
+
+		It("leaves shared selector labels untouched", func() {
+			Expect(labelEnvironment(objects, "dev")).To(Succeed())
+
+			d := objects[0].(*v1apps.Deployment)
+			Expect(d.Spec.Selector.MatchLabels).To(Equal(map[string]string{Selector: "web"}))
+		})
+
+		It("doesn't move objects to the environment namespace", func() {
+			Expect(labelEnvironment(objects, "dev")).To(Succeed())
+			Expect(objects[0].(*v1apps.Deployment).Namespace).To(BeEmpty())
+		})
+	})
+
+	Describe("annotateSourceFile", func() {
+		It("annotates all objects with the source file", func() {
+			objects := []runtime.Object{
+				&v1apps.Deployment{
+					ObjectMeta: meta.ObjectMeta{
+						Name:        "web",
+						Annotations: map[string]string{"foo": "bar"},
+					},
+				},
+				&v1.Service{ObjectMeta: meta.ObjectMeta{Name: "web"}},
+			}
+
+			Expect(annotateSourceFile(objects, "docker-compose.yaml")).To(Succeed())
+
+			d := objects[0].(*v1apps.Deployment)
+			Expect(d.Annotations).To(HaveKeyWithValue(SourceFileAnnotation, "docker-compose.yaml"))
+			Expect(d.Annotations).To(HaveKeyWithValue("foo", "bar"))
+			Expect(objects[1].(*v1.Service).Annotations).To(HaveKeyWithValue(SourceFileAnnotation, "docker-compose.yaml"))
+		})
+	})
+
+	Describe("annotateFieldManager", func() {
+		var objects []runtime.Object
+
+		BeforeEach(func() {
+			objects = []runtime.Object{&v1.Service{ObjectMeta: meta.ObjectMeta{Name: "web"}}}
+		})
+
+		It("annotates all objects with the field manager when configured", func() {
+			Expect(New(WithFieldManager("kev")).annotateFieldManager(objects)).To(Succeed())
+			Expect(objects[0].(*v1.Service).Annotations).To(HaveKeyWithValue(FieldManagerAnnotation, "kev"))
+		})
+
+		It("leaves objects untouched when not configured", func() {
+			Expect(New().annotateFieldManager(objects)).To(Succeed())
+			Expect(objects[0].(*v1.Service).Annotations).To(BeEmpty())
+		})
+	})
+
+	Describe("guardrails", func() {
+		var objects []runtime.Object
+
+		quantity := func(list v1.ResourceList, name v1.ResourceName) string {
+			q := list[name]
+			return q.String()
+		}
+
+		BeforeEach(func() {
+			replicas := int32(2)
+			objects = []runtime.Object{
+				&v1.Service{ObjectMeta: meta.ObjectMeta{Name: "web"}},
+				&v1apps.Deployment{
+					ObjectMeta: meta.ObjectMeta{Name: "web"},
+					Spec: v1apps.DeploymentSpec{
+						Replicas: &replicas,
+						Template: v1.PodTemplateSpec{
+							Spec: v1.PodSpec{
+								Containers: []v1.Container{{
+									Name: "web",
+									Resources: v1.ResourceRequirements{
+										Requests: v1.ResourceList{
+											v1.ResourceCPU:    resource.MustParse("100m"),
+											v1.ResourceMemory: resource.MustParse("64Mi"),
+										},
+										Limits: v1.ResourceList{
+											v1.ResourceCPU:    resource.MustParse("500m"),
+											v1.ResourceMemory: resource.MustParse("256Mi"),
+										},
+									},
+								}},
+							},
+						},
+					},
+				},
+				&v1apps.StatefulSet{
+					ObjectMeta: meta.ObjectMeta{Name: "db"},
+					Spec: v1apps.StatefulSetSpec{
+						Template: v1.PodTemplateSpec{
+							Spec: v1.PodSpec{
+								Containers: []v1.Container{{
+									Name: "db",
+									Resources: v1.ResourceRequirements{
+										Requests: v1.ResourceList{
+											v1.ResourceMemory: resource.MustParse("128Mi"),
+										},
+										Limits: v1.ResourceList{
+											v1.ResourceMemory: resource.MustParse("512Mi"),
+										},
+									},
+								}},
+							},
+						},
+					},
+				},
+			}
+		})
+
+		It("renders no objects when not configured", func() {
+			Expect(New().guardrails(objects)).To(BeEmpty())
+		})
+
+		It("renders a resource quota from workload resources scaled by headroom", func() {
+			guardrails := New(WithResourceQuota(1.5)).guardrails(objects)
+			Expect(guardrails).To(HaveLen(2))
+
+			quota := guardrails[0].(*v1.ResourceQuota)
+			Expect(quota.Name).To(Equal(ResourceQuotaName))
+			Expect(quota.Spec.Hard).NotTo(HaveKey(v1.ResourceCPU))
+			Expect(quantity(quota.Spec.Hard, v1.ResourceRequestsCPU)).To(Equal("300m"))
+			Expect(quantity(quota.Spec.Hard, v1.ResourceLimitsCPU)).To(Equal("1500m"))
+			Expect(quantity(quota.Spec.Hard, v1.ResourceRequestsMemory)).To(Equal("384Mi"))
+			Expect(quantity(quota.Spec.Hard, v1.ResourceLimitsMemory)).To(Equal("1536Mi"))
+		})
+
+		It("renders a limit range from the largest container resources", func() {
+			guardrails := New(WithResourceQuota(1.5)).guardrails(objects)
+
+			limits := guardrails[1].(*v1.LimitRange).Spec.Limits
+			Expect(limits).To(HaveLen(1))
+			Expect(limits[0].Type).To(Equal(v1.LimitTypeContainer))
+			Expect(quantity(limits[0].DefaultRequest, v1.ResourceMemory)).To(Equal("128Mi"))
+			Expect(quantity(limits[0].Default, v1.ResourceCPU)).To(Equal("500m"))
+			Expect(quantity(limits[0].Max, v1.ResourceMemory)).To(Equal("768Mi"))
+		})
+
+		It("renders no objects when workloads define no resources", func() {
+			Expect(New(WithResourceQuota(1.5)).guardrails(objects[:1])).To(BeEmpty())
+		})
+	})
+
+	Describe("envGuardrails", func() {
+		quantity := func(list v1.ResourceList, name v1.ResourceName) string {
+			q := list[name]
+			return q.String()
+		}
+
+		It("renders no objects for an environment without namespace config", func() {
+			c := New(WithNamespaceConfigs(map[string]config.NamespaceConfig{
+				"prod": {Quota: map[string]string{"pods": "20"}},
+			}))
+
+			guardrails, err := c.envGuardrails("dev")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(guardrails).To(BeEmpty())
+		})
+
+		It("renders the configured resource quota and limit range", func() {
+			c := New(WithNamespaceConfigs(map[string]config.NamespaceConfig{
+				"prod": {
+					Quota:           map[string]string{"requests.cpu": "4", "pods": "20"},
+					DefaultRequests: map[string]string{"cpu": "100m"},
+					DefaultLimits:   map[string]string{"memory": "512Mi"},
+				},
+			}))
+
+			guardrails, err := c.envGuardrails("prod")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(guardrails).To(HaveLen(2))
+
+			quota := guardrails[0].(*v1.ResourceQuota)
+			Expect(quota.Name).To(Equal(ResourceQuotaName))
+			Expect(quantity(quota.Spec.Hard, v1.ResourceRequestsCPU)).To(Equal("4"))
+			Expect(quantity(quota.Spec.Hard, v1.ResourcePods)).To(Equal("20"))
+
+			limits := guardrails[1].(*v1.LimitRange).Spec.Limits
+			Expect(limits).To(HaveLen(1))
+			Expect(quantity(limits[0].DefaultRequest, v1.ResourceCPU)).To(Equal("100m"))
+			Expect(quantity(limits[0].Default, v1.ResourceMemory)).To(Equal("512Mi"))
+			Expect(limits[0].Max).To(BeEmpty())
+		})
+
+		It("only renders a limit range when no quota is configured", func() {
+			c := New(WithNamespaceConfigs(map[string]config.NamespaceConfig{
+				"prod": {DefaultLimits: map[string]string{"cpu": "1"}},
+			}))
+
+			guardrails, err := c.envGuardrails("prod")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(guardrails).To(HaveLen(1))
+			Expect(guardrails[0]).To(BeAssignableToTypeOf(&v1.LimitRange{}))
+		})
+
+		It("returns an error for an invalid quantity", func() {
+			c := New(WithNamespaceConfigs(map[string]config.NamespaceConfig{
+				"prod": {Quota: map[string]string{"pods": "lots"}},
+			}))
+
+			_, err := c.envGuardrails("prod")
+			Expect(err).To(MatchError(ContainSubstring("invalid namespace quota")))
+		})
+	})
+})
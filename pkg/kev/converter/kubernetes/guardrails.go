@@ -0,0 +1,243 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"math"
+
+	"github.com/appvia/kev/pkg/kev/config"
+	"github.com/pkg/errors"
+	v1apps "k8s.io/api/apps/v1"
+	v1batch "k8s.io/api/batch/v1"
+	v1beta1batch "k8s.io/api/batch/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// ResourceQuotaName is the name of the resource quota rendered for an environment namespace
+	ResourceQuotaName = "kev-resource-quota"
+
+	// LimitRangeName is the name of the limit range rendered for an environment namespace
+	LimitRangeName = "kev-limit-range"
+
+	// DefaultQuotaHeadroom is the default factor applied to the environment's computed resources
+	DefaultQuotaHeadroom = 1.2
+)
+
+// workloadResources holds the aggregated resources of an environment's workloads
+type workloadResources struct {
+	// total holds the requests and limits of all workload pods, taking replicas into account
+	total v1.ResourceList
+	// maxRequests holds the largest container requests
+	maxRequests v1.ResourceList
+	// maxLimits holds the largest container limits
+	maxLimits v1.ResourceList
+}
+
+// namespaceGuardrails returns a resource quota and a limit range derived from the resource requests
+// and limits of the supplied workloads, scaled by the headroom factor.
+// Returns no objects when the workloads define no resources.
+func namespaceGuardrails(objects []runtime.Object, headroom float64) []runtime.Object {
+	res := collectWorkloadResources(objects)
+	if len(res.total) == 0 {
+		return nil
+	}
+
+	quota := newResourceQuota(scaleResources(res.total, headroom))
+	limitRange := newLimitRange(v1.LimitRangeItem{
+		Type:           v1.LimitTypeContainer,
+		DefaultRequest: res.maxRequests,
+		Default:        res.maxLimits,
+		Max:            scaleResources(res.maxLimits, headroom),
+	})
+
+	return []runtime.Object{quota, limitRange}
+}
+
+// configuredNamespaceGuardrails returns the resource quota and limit range set in the environment's namespace config.
+// Each object is only returned when the related resources are configured.
+func configuredNamespaceGuardrails(nc config.NamespaceConfig) ([]runtime.Object, error) {
+	var objects []runtime.Object
+
+	quota, err := parseResources(nc.Quota)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid namespace quota")
+	}
+	if len(quota) > 0 {
+		objects = append(objects, newResourceQuota(quota))
+	}
+
+	defaultRequests, err := parseResources(nc.DefaultRequests)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid namespace default requests")
+	}
+
+	defaultLimits, err := parseResources(nc.DefaultLimits)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid namespace default limits")
+	}
+
+	if len(defaultRequests) > 0 || len(defaultLimits) > 0 {
+		objects = append(objects, newLimitRange(v1.LimitRangeItem{
+			Type:           v1.LimitTypeContainer,
+			DefaultRequest: defaultRequests,
+			Default:        defaultLimits,
+		}))
+	}
+
+	return objects, nil
+}
+
+// newResourceQuota returns the environment namespace resource quota
+func newResourceQuota(hard v1.ResourceList) *v1.ResourceQuota {
+	return &v1.ResourceQuota{
+		TypeMeta: meta.TypeMeta{
+			Kind:       "ResourceQuota",
+			APIVersion: "v1",
+		},
+		ObjectMeta: meta.ObjectMeta{
+			Name: ResourceQuotaName,
+		},
+		Spec: v1.ResourceQuotaSpec{
+			Hard: hard,
+		},
+	}
+}
+
+// newLimitRange returns the environment namespace limit range
+func newLimitRange(limits ...v1.LimitRangeItem) *v1.LimitRange {
+	return &v1.LimitRange{
+		TypeMeta: meta.TypeMeta{
+			Kind:       "LimitRange",
+			APIVersion: "v1",
+		},
+		ObjectMeta: meta.ObjectMeta{
+			Name: LimitRangeName,
+		},
+		Spec: v1.LimitRangeSpec{
+			Limits: limits,
+		},
+	}
+}
+
+// parseResources parses resource quantities keyed by resource name
+func parseResources(resources map[string]string) (v1.ResourceList, error) {
+	list := v1.ResourceList{}
+	for name, value := range resources {
+		q, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s", name)
+		}
+		list[v1.ResourceName(name)] = q
+	}
+	return list, nil
+}
+
+// collectWorkloadResources aggregates cpu & memory requests and limits of the workloads' containers
+func collectWorkloadResources(objects []runtime.Object) workloadResources {
+	res := workloadResources{
+		total:       v1.ResourceList{},
+		maxRequests: v1.ResourceList{},
+		maxLimits:   v1.ResourceList{},
+	}
+
+	for _, o := range objects {
+		var replicas int64 = 1
+		var spec *v1.PodSpec
+
+		switch t := o.(type) {
+		case *v1apps.Deployment:
+			spec = &t.Spec.Template.Spec
+			if t.Spec.Replicas != nil {
+				replicas = int64(*t.Spec.Replicas)
+			}
+		case *v1apps.StatefulSet:
+			spec = &t.Spec.Template.Spec
+			if t.Spec.Replicas != nil {
+				replicas = int64(*t.Spec.Replicas)
+			}
+		case *v1apps.DaemonSet:
+			// number of daemon pods depends on cluster nodes, count a single one
+			spec = &t.Spec.Template.Spec
+		case *v1batch.Job:
+			spec = &t.Spec.Template.Spec
+			if t.Spec.Parallelism != nil {
+				replicas = int64(*t.Spec.Parallelism)
+			}
+		case *v1beta1batch.CronJob:
+			spec = &t.Spec.JobTemplate.Spec.Template.Spec
+		default:
+			continue
+		}
+
+		for _, c := range spec.Containers {
+			addResources(res.total, c.Resources.Requests, "requests.", replicas)
+			addResources(res.total, c.Resources.Limits, "limits.", replicas)
+			maxResources(res.maxRequests, c.Resources.Requests)
+			maxResources(res.maxLimits, c.Resources.Limits)
+		}
+	}
+
+	return res
+}
+
+// addResources adds cpu & memory quantities multiplied by the number of replicas to the prefixed totals
+func addResources(total, list v1.ResourceList, prefix string, replicas int64) {
+	for _, name := range []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory} {
+		q, ok := list[name]
+		if !ok || q.IsZero() {
+			continue
+		}
+
+		key := v1.ResourceName(prefix + string(name))
+		sum := total[key]
+		for i := int64(0); i < replicas; i++ {
+			sum.Add(q)
+		}
+		total[key] = sum
+	}
+}
+
+// maxResources records the largest cpu & memory quantities
+func maxResources(max, list v1.ResourceList) {
+	for _, name := range []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory} {
+		q, ok := list[name]
+		if !ok || q.IsZero() {
+			continue
+		}
+
+		if current, ok := max[name]; !ok || q.Cmp(current) > 0 {
+			max[name] = q.DeepCopy()
+		}
+	}
+}
+
+// scaleResources returns resource quantities multiplied by the supplied factor, rounded up
+func scaleResources(list v1.ResourceList, factor float64) v1.ResourceList {
+	scaled := v1.ResourceList{}
+	for name, q := range list {
+		if name == v1.ResourceCPU || name == v1.ResourceRequestsCPU || name == v1.ResourceLimitsCPU {
+			scaled[name] = *resource.NewMilliQuantity(int64(math.Ceil(float64(q.MilliValue())*factor)), resource.DecimalSI)
+			continue
+		}
+		scaled[name] = *resource.NewQuantity(int64(math.Ceil(float64(q.Value())*factor)), resource.BinarySI)
+	}
+	return scaled
+}
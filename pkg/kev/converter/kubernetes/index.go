@@ -0,0 +1,231 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/appvia/kev/pkg/kev/log"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	v1beta1batch "k8s.io/api/batch/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// IndexFileName is the name of the index summarising rendered manifests
+const IndexFileName = "MANIFEST.md"
+
+// objectPurposes describes the purpose of rendered objects by kind
+var objectPurposes = map[string]string{
+	"Deployment":              "Runs the service's long running pods",
+	"StatefulSet":             "Runs the service's pods with stable identities and storage",
+	"DaemonSet":               "Runs a service pod on each node",
+	"Job":                     "Runs the service's pods to completion",
+	"CronJob":                 "Runs the service's pods on a schedule",
+	"Service":                 "Routes in-cluster traffic to the service's pods",
+	"Ingress":                 "Exposes the service outside the cluster",
+	"IngressRoute":            "Exposes the service outside the cluster via Traefik",
+	"HorizontalPodAutoscaler": "Scales the workload replicas based on resource utilisation",
+	"PodDisruptionBudget":     "Limits voluntary disruptions of the workload's pods",
+	"PersistentVolumeClaim":   "Requests persistent storage for the service",
+	"ConfigMap":               "Holds configuration mounted or injected into pods",
+	"Secret":                  "Holds sensitive data mounted or injected into pods",
+	"NetworkPolicy":           "Restricts network traffic between pods",
+	"ServiceAccount":          "Identity the service's pods run as",
+	"Namespace":               "Holds the environment's objects",
+	"ResourceQuota":           "Caps the aggregate resource usage of the namespace",
+	"LimitRange":              "Defaults and caps container resources in the namespace",
+	"ServiceMonitor":          "Configures Prometheus scraping of the service's metrics",
+}
+
+// manifestIndex returns a markdown section summarising the objects rendered for an environment,
+// their purpose and key configuration
+func manifestIndex(env string, objects []runtime.Object) ([]byte, error) {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "## %s\n\n", env)
+	fmt.Fprintf(&buf, "| Kind | Name | Purpose | Configuration |\n")
+	fmt.Fprintf(&buf, "|------|------|---------|---------------|\n")
+
+	for _, o := range objects {
+		accessor, err := meta.Accessor(o)
+		if err != nil {
+			return nil, err
+		}
+
+		kind := o.GetObjectKind().GroupVersionKind().Kind
+		fmt.Fprintf(&buf, "| %s | %s | %s | %s |\n",
+			kind,
+			accessor.GetName(),
+			objectPurposes[kind],
+			escapeTableCell(strings.Join(objectDetails(o), "; ")),
+		)
+	}
+
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
+
+// objectDetails returns the key configuration of an object, e.g. workload replicas and resources
+func objectDetails(o runtime.Object) []string {
+	var details []string
+
+	if w, ok := lintWorkload(o); ok {
+		switch w.kind {
+		case "Deployment", "StatefulSet":
+			details = append(details, fmt.Sprintf("replicas: %d", w.replicas))
+		case "DaemonSet":
+			details = append(details, "replicas: one per node")
+		}
+		if cj, ok := o.(*v1beta1batch.CronJob); ok {
+			details = append(details, fmt.Sprintf("schedule: %s", cj.Spec.Schedule))
+		}
+		for _, c := range w.podSpec.Containers {
+			details = append(details, fmt.Sprintf("image: %s", c.Image))
+			if r := resourceDetails(c.Resources.Requests); r != "" {
+				details = append(details, fmt.Sprintf("requests: %s", r))
+			}
+			if r := resourceDetails(c.Resources.Limits); r != "" {
+				details = append(details, fmt.Sprintf("limits: %s", r))
+			}
+		}
+		return details
+	}
+
+	switch t := o.(type) {
+	case *v1.Service:
+		details = append(details, fmt.Sprintf("type: %s", t.Spec.Type))
+		if t.Spec.ClusterIP == v1.ClusterIPNone {
+			details = append(details, "headless")
+		}
+		var ports []string
+		for _, p := range t.Spec.Ports {
+			ports = append(ports, fmt.Sprintf("%d/%s", p.Port, p.Protocol))
+		}
+		if len(ports) > 0 {
+			details = append(details, fmt.Sprintf("ports: %s", strings.Join(ports, ", ")))
+		}
+	case *networking.Ingress:
+		var hosts []string
+		for _, r := range t.Spec.Rules {
+			hosts = append(hosts, r.Host)
+		}
+		if t.Spec.DefaultBackend != nil {
+			hosts = append(hosts, "default backend")
+		}
+		details = append(details, fmt.Sprintf("hosts: %s", strings.Join(hosts, ", ")))
+		if len(t.Spec.TLS) > 0 {
+			details = append(details, "tls")
+		}
+	case *networkingv1beta1.Ingress:
+		var hosts []string
+		for _, r := range t.Spec.Rules {
+			hosts = append(hosts, r.Host)
+		}
+		if t.Spec.Backend != nil {
+			hosts = append(hosts, "default backend")
+		}
+		details = append(details, fmt.Sprintf("hosts: %s", strings.Join(hosts, ", ")))
+		if len(t.Spec.TLS) > 0 {
+			details = append(details, "tls")
+		}
+	case *IngressRoute:
+		for _, r := range t.Spec.Routes {
+			details = append(details, fmt.Sprintf("match: %s", r.Match))
+		}
+		if t.Spec.TLS != nil {
+			details = append(details, "tls")
+		}
+	case *autoscalingv2beta2.HorizontalPodAutoscaler:
+		minReplicas := int32(1)
+		if t.Spec.MinReplicas != nil {
+			minReplicas = *t.Spec.MinReplicas
+		}
+		details = append(details, fmt.Sprintf("replicas: %d-%d", minReplicas, t.Spec.MaxReplicas))
+	case *policyv1beta1.PodDisruptionBudget:
+		if t.Spec.MinAvailable != nil {
+			details = append(details, fmt.Sprintf("min available: %s", t.Spec.MinAvailable.String()))
+		}
+		if t.Spec.MaxUnavailable != nil {
+			details = append(details, fmt.Sprintf("max unavailable: %s", t.Spec.MaxUnavailable.String()))
+		}
+	case *v1.PersistentVolumeClaim:
+		if size, ok := t.Spec.Resources.Requests[v1.ResourceStorage]; ok {
+			details = append(details, fmt.Sprintf("size: %s", size.String()))
+		}
+		if t.Spec.StorageClassName != nil {
+			details = append(details, fmt.Sprintf("storage class: %s", *t.Spec.StorageClassName))
+		}
+	case *v1.ConfigMap:
+		details = append(details, fmt.Sprintf("keys: %d", len(t.Data)+len(t.BinaryData)))
+	case *v1.Secret:
+		details = append(details, fmt.Sprintf("keys: %d", len(t.Data)+len(t.StringData)))
+	}
+
+	return details
+}
+
+// resourceDetails returns the cpu, memory & ephemeral storage quantities of a resource list
+func resourceDetails(list v1.ResourceList) string {
+	var out []string
+	for _, name := range []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory, v1.ResourceEphemeralStorage} {
+		if q, ok := list[name]; ok {
+			out = append(out, fmt.Sprintf("%s=%s", name, q.String()))
+		}
+	}
+	return strings.Join(out, ", ")
+}
+
+// escapeTableCell escapes characters breaking markdown table cells
+func escapeTableCell(s string) string {
+	return strings.ReplaceAll(s, "|", "\\|")
+}
+
+// writeIndex writes the manifests index composed of the supplied sections to the directory
+func writeIndex(dir string, sections [][]byte, rendered map[string][]byte) error {
+	data := indexContent(sections)
+
+	file := filepath.Join(dir, IndexFileName)
+	if err := ioutil.WriteFile(file, data, 0644); err != nil {
+		log.ErrorWithFields(log.Fields{
+			"file": file,
+		}, "Failed to write content to a file")
+		return err
+	}
+	rendered[file] = data
+
+	return nil
+}
+
+// indexContent returns the index document made of the supplied environment sections
+func indexContent(sections [][]byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("# Rendered manifests\n\n")
+	buf.WriteString("This index is generated by kev from the rendered manifests. Do not edit.\n\n")
+	for _, s := range sections {
+		buf.Write(s)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,82 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var _ = Describe("manifestIndex", func() {
+
+	var objects []runtime.Object
+
+	BeforeEach(func() {
+		replicas := int32(3)
+		objects = []runtime.Object{
+			&v1apps.Deployment{
+				TypeMeta:   meta.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+				ObjectMeta: meta.ObjectMeta{Name: "web"},
+				Spec: v1apps.DeploymentSpec{
+					Replicas: &replicas,
+					Template: v1.PodTemplateSpec{
+						Spec: v1.PodSpec{
+							Containers: []v1.Container{{
+								Name:  "web",
+								Image: "nginx:1.21",
+								Resources: v1.ResourceRequirements{
+									Limits: v1.ResourceList{
+										v1.ResourceCPU:    resource.MustParse("500m"),
+										v1.ResourceMemory: resource.MustParse("128Mi"),
+									},
+								},
+							}},
+						},
+					},
+				},
+			},
+			&v1.Service{
+				TypeMeta:   meta.TypeMeta{Kind: "Service", APIVersion: "v1"},
+				ObjectMeta: meta.ObjectMeta{Name: "web"},
+				Spec: v1.ServiceSpec{
+					Type:  v1.ServiceTypeClusterIP,
+					Ports: []v1.ServicePort{{Port: 80, Protocol: v1.ProtocolTCP}},
+				},
+			},
+		}
+	})
+
+	It("lists each object with its purpose", func() {
+		index, err := manifestIndex("dev", objects)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(index)).To(HavePrefix("## dev\n"))
+		Expect(string(index)).To(ContainSubstring("| Deployment | web | " + objectPurposes["Deployment"] + " |"))
+		Expect(string(index)).To(ContainSubstring("| Service | web | " + objectPurposes["Service"] + " |"))
+	})
+
+	It("summarises the key configuration derived from the objects", func() {
+		index, err := manifestIndex("dev", objects)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(index)).To(ContainSubstring("replicas: 3; image: nginx:1.21; limits: cpu=500m, memory=128Mi"))
+		Expect(string(index)).To(ContainSubstring("type: ClusterIP; ports: 80/TCP"))
+	})
+})
@@ -0,0 +1,201 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// ExposeAPIIngress exposes services using K8s Ingress objects
+	ExposeAPIIngress = "ingress"
+
+	// ExposeAPITraefik exposes services using Traefik v2 IngressRoute custom resources
+	ExposeAPITraefik = "traefik"
+
+	// traefikRouterAnnotationPrefix is the prefix of traefik ingress router annotations
+	traefikRouterAnnotationPrefix = "traefik.ingress.kubernetes.io/router."
+)
+
+// TraefikGroupVersion is the group version of Traefik v2 custom resources
+var TraefikGroupVersion = schema.GroupVersion{Group: "traefik.io", Version: "v1alpha1"}
+
+// AddTraefikToScheme registers Traefik custom resources with the supplied scheme
+func AddTraefikToScheme(s *runtime.Scheme) error {
+	s.AddKnownTypes(TraefikGroupVersion, &IngressRoute{})
+	return nil
+}
+
+// IngressRoute is a Traefik v2 custom resource routing HTTP traffic to K8s services
+type IngressRoute struct {
+	meta.TypeMeta   `json:",inline"`
+	meta.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec IngressRouteSpec `json:"spec"`
+}
+
+// IngressRouteSpec defines the routes of an IngressRoute
+type IngressRouteSpec struct {
+	EntryPoints []string           `json:"entryPoints,omitempty"`
+	Routes      []IngressRouteRule `json:"routes"`
+	TLS         *IngressRouteTLS   `json:"tls,omitempty"`
+}
+
+// IngressRouteRule defines a rule matching requests routed to services
+type IngressRouteRule struct {
+	Match    string                `json:"match"`
+	Kind     string                `json:"kind"`
+	Priority int                   `json:"priority,omitempty"`
+	Services []IngressRouteService `json:"services,omitempty"`
+}
+
+// IngressRouteService defines a K8s service requests are routed to
+type IngressRouteService struct {
+	Name string `json:"name"`
+	Port int32  `json:"port"`
+}
+
+// IngressRouteTLS defines the TLS configuration of an IngressRoute
+type IngressRouteTLS struct {
+	SecretName   string `json:"secretName,omitempty"`
+	CertResolver string `json:"certResolver,omitempty"`
+}
+
+// DeepCopyInto copies the receiver into out
+func (in *IngressRoute) DeepCopyInto(out *IngressRoute) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+
+	if in.Spec.EntryPoints != nil {
+		out.Spec.EntryPoints = make([]string, len(in.Spec.EntryPoints))
+		copy(out.Spec.EntryPoints, in.Spec.EntryPoints)
+	}
+
+	if in.Spec.Routes != nil {
+		out.Spec.Routes = make([]IngressRouteRule, len(in.Spec.Routes))
+		for i, r := range in.Spec.Routes {
+			out.Spec.Routes[i] = r
+			if r.Services != nil {
+				out.Spec.Routes[i].Services = make([]IngressRouteService, len(r.Services))
+				copy(out.Spec.Routes[i].Services, r.Services)
+			}
+		}
+	}
+
+	if in.Spec.TLS != nil {
+		tls := *in.Spec.TLS
+		out.Spec.TLS = &tls
+	}
+}
+
+// DeepCopy returns a deep copy of the IngressRoute
+func (in *IngressRoute) DeepCopy() *IngressRoute {
+	if in == nil {
+		return nil
+	}
+	out := new(IngressRoute)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject implements runtime.Object
+func (in *IngressRoute) DeepCopyObject() runtime.Object {
+	return in.DeepCopy()
+}
+
+// initIngressRoute initialises Traefik IngressRoute object routing the exposed service domains
+// to the service port. Traefik router annotations configure the route entry points, priority and TLS.
+func (k *Kubernetes) initIngressRoute(projectService ProjectService, port int32) *IngressRoute {
+	expose, _ := projectService.exposeService()
+	if expose == "" {
+		return nil
+	}
+	hosts := regexp.MustCompile("[ ,]*,[ ,]*").Split(expose, -1)
+
+	annotations := map[string]string{}
+	for key, value := range projectService.ingressAnnotations() {
+		if !strings.HasPrefix(key, traefikRouterAnnotationPrefix) {
+			annotations[key] = value
+		}
+	}
+	routerOption := func(option string) string {
+		return projectService.ingressAnnotations()[traefikRouterAnnotationPrefix+option]
+	}
+
+	ingressRoute := &IngressRoute{
+		TypeMeta: meta.TypeMeta{
+			Kind:       "IngressRoute",
+			APIVersion: TraefikGroupVersion.String(),
+		},
+		ObjectMeta: meta.ObjectMeta{
+			Name:        projectService.Name,
+			Labels:      configLabels(projectService.Name),
+			Annotations: annotations,
+		},
+	}
+
+	if entryPoints := routerOption("entrypoints"); entryPoints != "" {
+		for _, ep := range strings.Split(entryPoints, ",") {
+			ingressRoute.Spec.EntryPoints = append(ingressRoute.Spec.EntryPoints, strings.TrimSpace(ep))
+		}
+	}
+
+	priority, _ := strconv.Atoi(routerOption("priority"))
+	services := []IngressRouteService{{Name: projectService.Name, Port: port}}
+
+	if hasDefaultIngressBackendKeyword(hosts) {
+		ingressRoute.Spec.Routes = []IngressRouteRule{
+			{Match: "PathPrefix(`/`)", Kind: "Rule", Priority: priority, Services: services},
+		}
+		return ingressRoute
+	}
+
+	for _, host := range hosts {
+		host, p := parseIngressPath(host)
+
+		match := fmt.Sprintf("Host(`%s`)", host)
+		if p != "" {
+			match += fmt.Sprintf(" && PathPrefix(`%s`)", p)
+		}
+
+		ingressRoute.Spec.Routes = append(ingressRoute.Spec.Routes, IngressRouteRule{
+			Match:    match,
+			Kind:     "Rule",
+			Priority: priority,
+			Services: services,
+		})
+	}
+
+	tlsSecretName := projectService.tlsSecretName()
+	certResolver := routerOption("tls.certresolver")
+	if tlsSecretName != "" || certResolver != "" || routerOption("tls") == "true" {
+		ingressRoute.Spec.TLS = &IngressRouteTLS{
+			SecretName:   tlsSecretName,
+			CertResolver: certResolver,
+		}
+	}
+
+	return ingressRoute
+}
@@ -0,0 +1,207 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"fmt"
+	"strings"
+
+	v1apps "k8s.io/api/apps/v1"
+	v1batch "k8s.io/api/batch/v1"
+	v1beta1batch "k8s.io/api/batch/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// LintSeverity is the severity of a lint finding
+type LintSeverity string
+
+const (
+	// LintWarning severity doesn't fail linting
+	LintWarning LintSeverity = "warning"
+	// LintError severity fails linting
+	LintError LintSeverity = "error"
+
+	// LintAll selects all lint checks
+	LintAll = "all"
+	// LintMissingResourceLimits flags containers without cpu or memory limits
+	LintMissingResourceLimits = "missing-resource-limits"
+	// LintMissingProbes flags long running workloads without liveness & readiness probes
+	LintMissingProbes = "missing-probes"
+	// LintRunAsRoot flags containers that may run as the root user
+	LintRunAsRoot = "run-as-root"
+	// LintLatestImageTag flags containers using an image with the latest or no tag
+	LintLatestImageTag = "latest-image-tag"
+	// LintMissingPDB flags multi-replica workloads without a pod disruption budget
+	LintMissingPDB = "missing-pdb"
+)
+
+// LintChecks lists all supported lint checks
+var LintChecks = []string{
+	LintMissingResourceLimits,
+	LintMissingProbes,
+	LintRunAsRoot,
+	LintLatestImageTag,
+	LintMissingPDB,
+}
+
+// LintFinding is a best-practice issue found in a rendered object
+type LintFinding struct {
+	Check    string
+	Object   string
+	Message  string
+	Severity LintSeverity
+}
+
+// workload holds the lint relevant details of a workload object
+type workload struct {
+	kind        string
+	name        string
+	replicas    int32
+	podSpec     v1.PodSpec
+	longRunning bool
+}
+
+// Lint checks rendered objects for common Kubernetes best-practice issues.
+// Findings of checks listed in errorOn are reported as errors, all others as warnings.
+// Use LintAll to report findings of all checks as errors.
+func Lint(objects []runtime.Object, errorOn []string) []LintFinding {
+	severity := func(check string) LintSeverity {
+		for _, c := range errorOn {
+			if c == check || c == LintAll {
+				return LintError
+			}
+		}
+		return LintWarning
+	}
+
+	var findings []LintFinding
+	report := func(check string, w workload, format string, args ...interface{}) {
+		findings = append(findings, LintFinding{
+			Check:    check,
+			Object:   fmt.Sprintf("%s/%s", w.kind, w.name),
+			Message:  fmt.Sprintf(format, args...),
+			Severity: severity(check),
+		})
+	}
+
+	// @step collect pod disruption budgets by the workload they select
+	budgets := map[string]bool{}
+	for _, o := range objects {
+		if pdb, ok := o.(*policyv1beta1.PodDisruptionBudget); ok && pdb.Spec.Selector != nil {
+			budgets[pdb.Spec.Selector.MatchLabels[Selector]] = true
+		}
+	}
+
+	for _, o := range objects {
+		w, ok := lintWorkload(o)
+		if !ok {
+			continue
+		}
+
+		if w.replicas > 1 && !budgets[w.name] && (w.kind == "Deployment" || w.kind == "StatefulSet") {
+			report(LintMissingPDB, w, "runs %d replicas without a pod disruption budget", w.replicas)
+		}
+
+		for _, c := range w.podSpec.Containers {
+			if _, ok := c.Resources.Limits[v1.ResourceCPU]; !ok {
+				report(LintMissingResourceLimits, w, "container %s has no cpu limit", c.Name)
+			}
+			if _, ok := c.Resources.Limits[v1.ResourceMemory]; !ok {
+				report(LintMissingResourceLimits, w, "container %s has no memory limit", c.Name)
+			}
+
+			if w.longRunning && c.LivenessProbe == nil && c.ReadinessProbe == nil {
+				report(LintMissingProbes, w, "container %s has neither liveness nor readiness probe", c.Name)
+			}
+
+			if mayRunAsRoot(w.podSpec.SecurityContext, c.SecurityContext) {
+				report(LintRunAsRoot, w, "container %s may run as root", c.Name)
+			}
+
+			if latestImageTag(c.Image) {
+				report(LintLatestImageTag, w, "container %s uses image %s without a pinned tag", c.Name, c.Image)
+			}
+		}
+	}
+
+	return findings
+}
+
+// lintWorkload extracts the lint relevant details of a workload object
+func lintWorkload(o runtime.Object) (workload, bool) {
+	replicas := func(r *int32) int32 {
+		if r == nil {
+			return 1
+		}
+		return *r
+	}
+
+	switch t := o.(type) {
+	case *v1apps.Deployment:
+		return workload{"Deployment", t.Name, replicas(t.Spec.Replicas), t.Spec.Template.Spec, true}, true
+	case *v1apps.StatefulSet:
+		return workload{"StatefulSet", t.Name, replicas(t.Spec.Replicas), t.Spec.Template.Spec, true}, true
+	case *v1apps.DaemonSet:
+		return workload{"DaemonSet", t.Name, 1, t.Spec.Template.Spec, true}, true
+	case *v1batch.Job:
+		return workload{"Job", t.Name, 1, t.Spec.Template.Spec, false}, true
+	case *v1beta1batch.CronJob:
+		return workload{"CronJob", t.Name, 1, t.Spec.JobTemplate.Spec.Template.Spec, false}, true
+	default:
+		return workload{}, false
+	}
+}
+
+// mayRunAsRoot checks whether a container isn't prevented from running as the root user.
+// Container security context settings take precedence over the pod's.
+func mayRunAsRoot(pod *v1.PodSecurityContext, container *v1.SecurityContext) bool {
+	var (
+		runAsUser    *int64
+		runAsNonRoot *bool
+	)
+
+	if pod != nil {
+		runAsUser, runAsNonRoot = pod.RunAsUser, pod.RunAsNonRoot
+	}
+	if container != nil {
+		if container.RunAsUser != nil {
+			runAsUser = container.RunAsUser
+		}
+		if container.RunAsNonRoot != nil {
+			runAsNonRoot = container.RunAsNonRoot
+		}
+	}
+
+	if runAsNonRoot != nil && *runAsNonRoot {
+		return false
+	}
+	return runAsUser == nil || *runAsUser == 0
+}
+
+// latestImageTag checks whether an image reference uses the latest tag, either explicitly or implicitly.
+// Images referenced by digest are considered pinned.
+func latestImageTag(image string) bool {
+	if strings.Contains(image, "@") {
+		return false
+	}
+
+	name := image[strings.LastIndex(image, "/")+1:]
+	i := strings.LastIndex(name, ":")
+	return i < 0 || name[i+1:] == "latest"
+}
@@ -0,0 +1,167 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var _ = Describe("Lint", func() {
+
+	var (
+		deployment *v1apps.Deployment
+		objects    []runtime.Object
+		errorOn    []string
+	)
+
+	checks := func(findings []LintFinding) []string {
+		var out []string
+		for _, f := range findings {
+			out = append(out, f.Check)
+		}
+		return out
+	}
+
+	BeforeEach(func() {
+		replicas := int32(1)
+		user := int64(1000)
+		deployment = &v1apps.Deployment{
+			ObjectMeta: meta.ObjectMeta{Name: "web"},
+			Spec: v1apps.DeploymentSpec{
+				Replicas: &replicas,
+				Template: v1.PodTemplateSpec{
+					Spec: v1.PodSpec{
+						SecurityContext: &v1.PodSecurityContext{RunAsUser: &user},
+						Containers: []v1.Container{
+							{
+								Name:  "web",
+								Image: "nginx:1.21",
+								Resources: v1.ResourceRequirements{
+									Limits: v1.ResourceList{
+										v1.ResourceCPU:    resource.MustParse("0.5"),
+										v1.ResourceMemory: resource.MustParse("500Mi"),
+									},
+								},
+								LivenessProbe: &v1.Probe{},
+							},
+						},
+					},
+				},
+			},
+		}
+		errorOn = nil
+	})
+
+	JustBeforeEach(func() {
+		objects = []runtime.Object{deployment}
+	})
+
+	When("workload follows best practices", func() {
+		It("reports no findings", func() {
+			Expect(Lint(objects, errorOn)).To(BeEmpty())
+		})
+	})
+
+	When("workload has issues", func() {
+		BeforeEach(func() {
+			replicas := int32(3)
+			deployment.Spec.Replicas = &replicas
+			deployment.Spec.Template.Spec.SecurityContext = nil
+			deployment.Spec.Template.Spec.Containers[0].Image = "nginx"
+			deployment.Spec.Template.Spec.Containers[0].Resources = v1.ResourceRequirements{}
+			deployment.Spec.Template.Spec.Containers[0].LivenessProbe = nil
+		})
+
+		It("reports findings for all checks as warnings by default", func() {
+			findings := Lint(objects, errorOn)
+			Expect(checks(findings)).To(ConsistOf(
+				LintMissingPDB,
+				LintMissingResourceLimits,
+				LintMissingResourceLimits,
+				LintMissingProbes,
+				LintRunAsRoot,
+				LintLatestImageTag,
+			))
+			for _, f := range findings {
+				Expect(f.Severity).To(Equal(LintWarning))
+				Expect(f.Object).To(Equal("Deployment/web"))
+			}
+		})
+
+		Context("and checks are configured as errors", func() {
+			BeforeEach(func() {
+				errorOn = []string{LintRunAsRoot}
+			})
+
+			It("reports their findings as errors", func() {
+				for _, f := range Lint(objects, errorOn) {
+					if f.Check == LintRunAsRoot {
+						Expect(f.Severity).To(Equal(LintError))
+					} else {
+						Expect(f.Severity).To(Equal(LintWarning))
+					}
+				}
+			})
+		})
+
+		Context("and all checks are configured as errors", func() {
+			BeforeEach(func() {
+				errorOn = []string{LintAll}
+			})
+
+			It("reports all findings as errors", func() {
+				for _, f := range Lint(objects, errorOn) {
+					Expect(f.Severity).To(Equal(LintError))
+				}
+			})
+		})
+	})
+
+	When("multi-replica workload has a pod disruption budget", func() {
+		BeforeEach(func() {
+			replicas := int32(3)
+			deployment.Spec.Replicas = &replicas
+		})
+
+		It("doesn't report a missing pod disruption budget", func() {
+			pdb := &policyv1beta1.PodDisruptionBudget{
+				ObjectMeta: meta.ObjectMeta{Name: "web"},
+				Spec: policyv1beta1.PodDisruptionBudgetSpec{
+					Selector: &meta.LabelSelector{MatchLabels: configLabels("web")},
+				},
+			}
+			Expect(Lint(append(objects, pdb), errorOn)).To(BeEmpty())
+		})
+	})
+
+	Describe("latestImageTag", func() {
+		It("detects implicit & explicit latest tags", func() {
+			Expect(latestImageTag("nginx")).To(BeTrue())
+			Expect(latestImageTag("nginx:latest")).To(BeTrue())
+			Expect(latestImageTag("localhost:5000/nginx")).To(BeTrue())
+			Expect(latestImageTag("localhost:5000/nginx:1.21")).To(BeFalse())
+			Expect(latestImageTag("nginx@sha256:abc")).To(BeFalse())
+		})
+	})
+})
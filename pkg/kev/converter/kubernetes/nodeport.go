@@ -0,0 +1,81 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/appvia/kev/pkg/kev/log"
+)
+
+// DefaultNodePortRange is the default range of ports the K8s API server allocates node ports from
+const DefaultNodePortRange = "30000-32767"
+
+// ParseNodePortRange parses a node port range in the K8s API server `--service-node-port-range` format, e.g. 30000-32767
+func ParseNodePortRange(portRange string) (int32, int32, error) {
+	bounds := strings.SplitN(strings.TrimSpace(portRange), "-", 2)
+	if len(bounds) != 2 {
+		return 0, 0, fmt.Errorf("invalid node port range %q, expected <min>-<max>, e.g. %s", portRange, DefaultNodePortRange)
+	}
+
+	min, minErr := strconv.ParseInt(strings.TrimSpace(bounds[0]), 10, 32)
+	max, maxErr := strconv.ParseInt(strings.TrimSpace(bounds[1]), 10, 32)
+	if minErr != nil || maxErr != nil || min < 1 || max > 65535 || min > max {
+		return 0, 0, fmt.Errorf("invalid node port range %q, expected <min>-<max>, e.g. %s", portRange, DefaultNodePortRange)
+	}
+
+	return int32(min), int32(max), nil
+}
+
+// validateNodePorts checks the project services node ports are within the node port range,
+// and warns about node ports requested by more than one service as only one of them can be allocated.
+func (k *Kubernetes) validateNodePorts(projectServices []ProjectService) error {
+	portRange := k.NodePortRange
+	if portRange == "" {
+		portRange = DefaultNodePortRange
+	}
+
+	min, max, err := ParseNodePortRange(portRange)
+	if err != nil {
+		return err
+	}
+
+	requested := map[int32]string{}
+	for _, projectService := range projectServices {
+		np := projectService.nodePort()
+		if np == 0 {
+			continue
+		}
+
+		if np < min || np > max {
+			return fmt.Errorf("`%s` node port %d is outside of the node port range %s", projectService.Name, np, portRange)
+		}
+
+		if other, ok := requested[np]; ok {
+			log.WarnfWithFields(log.Fields{
+				"project-service": projectService.Name,
+				"node-port":       np,
+			}, "Node port %d is also requested by `%s`, only one of the services can be allocated it", np, other)
+			continue
+		}
+		requested[np] = projectService.Name
+	}
+
+	return nil
+}
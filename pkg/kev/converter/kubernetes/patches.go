@@ -0,0 +1,114 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/appvia/kev/pkg/kev/config"
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// applyPatches applies the patches configured for the environment to its rendered objects, in order.
+// It fails when a patch targets an object that wasn't rendered.
+func (c *K8s) applyPatches(env string, objects []runtime.Object) error {
+	for i, patch := range c.EnvPatches[env] {
+		idx := -1
+		for j, o := range objects {
+			typeMeta, objectMeta := objectMetas(o)
+			if typeMeta.Kind == patch.Target.Kind && objectMeta.Name == patch.Target.Name {
+				idx = j
+				break
+			}
+		}
+
+		if idx < 0 {
+			return fmt.Errorf("environment %s patches[%d] targets %s which isn't among the rendered objects", env, i, patch.Target)
+		}
+
+		patched, err := applyPatch(objects[idx], patch)
+		if err != nil {
+			return errors.Wrapf(err, "environment %s patches[%d] could not be applied to %s", env, i, patch.Target)
+		}
+		objects[idx] = patched
+	}
+
+	return nil
+}
+
+// applyPatch returns a copy of the object with the strategic merge or JSON6902 patch applied.
+// Unstructured objects have no patch strategy metadata, so strategic merge patches are applied to them as JSON merge patches.
+func applyPatch(obj runtime.Object, patch config.Patch) (runtime.Object, error) {
+	original, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	_, isUnstructured := obj.(*unstructured.Unstructured)
+
+	var patched []byte
+	if patch.StrategicMerge != "" {
+		p, err := yaml.ToJSON([]byte(patch.StrategicMerge))
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid strategic merge patch")
+		}
+
+		if isUnstructured {
+			patched, err = jsonpatch.MergePatch(original, p)
+		} else {
+			patched, err = strategicpatch.StrategicMergePatch(original, p, obj)
+		}
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		p, err := yaml.ToJSON([]byte(patch.JSON6902))
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid json6902 patch")
+		}
+
+		ops, err := jsonpatch.DecodePatch(p)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid json6902 patch")
+		}
+
+		if patched, err = ops.Apply(original); err != nil {
+			return nil, err
+		}
+	}
+
+	if isUnstructured {
+		out := &unstructured.Unstructured{}
+		if err := out.UnmarshalJSON(patched); err != nil {
+			return nil, err
+		}
+		return out, nil
+	}
+
+	out := reflect.New(reflect.TypeOf(obj).Elem()).Interface().(runtime.Object)
+	if err := json.Unmarshal(patched, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
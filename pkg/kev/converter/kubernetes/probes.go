@@ -1,7 +1,7 @@
 package kubernetes
 
 import (
-	"errors"
+	"fmt"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -18,10 +18,19 @@ func ReadinessProbeToV1Probe(rp config.ReadinessProbe) (*v1.Probe, error) {
 	return v1probe(rp.Type, rp.ProbeConfig)
 }
 
+// StartupProbeToV1Probe converts a startup probe to a v1 probe.
+// It returns nil when the startup probe hasn't been defined.
+func StartupProbeToV1Probe(sp config.StartupProbe) (*v1.Probe, error) {
+	if sp.Type == "" {
+		return nil, nil
+	}
+	return v1probe(sp.Type, sp.ProbeConfig)
+}
+
 func v1probe(probeType string, pc config.ProbeConfig) (*v1.Probe, error) {
 	pt, ok := config.ProbeTypeFromString(probeType)
 	if !ok {
-		return nil, errors.New("invalid probe type")
+		return nil, fmt.Errorf("invalid probe type %q", probeType)
 	}
 
 	if pt == config.ProbeTypeNone {
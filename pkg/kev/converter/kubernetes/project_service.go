@@ -19,7 +19,9 @@ package kubernetes
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/appvia/kev/pkg/kev/config"
 	"github.com/appvia/kev/pkg/kev/log"
@@ -29,6 +31,7 @@ import (
 	v1apps "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
@@ -44,15 +47,108 @@ func NewProjectService(svc composego.ServiceConfig) (ProjectService, error) {
 	}, nil
 }
 
+// warnWithFields reports a service conversion warning
+func (p *ProjectService) warnWithFields(fields log.Fields, msg string) {
+	if p.warn == nil {
+		log.WarnWithFields(fields, msg)
+		return
+	}
+	p.warn(fields, msg)
+}
+
+// warnfWithFields reports a formatted service conversion warning, see warnWithFields
+func (p *ProjectService) warnfWithFields(fields log.Fields, format string, args ...interface{}) {
+	p.warnWithFields(fields, fmt.Sprintf(format, args...))
+}
+
 // enabled returns Bool telling Kev whether app component is enabled/disabled
+// A component is disabled via config extension or the `kev.service.skip-render` compose service label.
 func (p *ProjectService) enabled() bool {
+	if cast.ToBool(p.Labels[SkipRenderLabel]) {
+		return false
+	}
 	return !p.SvcK8sConfig.Disabled
 }
 
+// dependsOnStrategy returns the strategy rendering service dependencies, defaults to an annotation
+func (p *ProjectService) dependsOnStrategy() (string, error) {
+	strategy := strings.TrimSpace(p.Labels[DependsOnStrategyLabel])
+
+	switch strategy {
+	case "":
+		return DependsOnAnnotationStrategy, nil
+	case DependsOnAnnotationStrategy, DependsOnInitContainerStrategy, DependsOnNoneStrategy:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("unsupported depends_on strategy %q, supported strategies: %s, %s, %s",
+			strategy, DependsOnAnnotationStrategy, DependsOnInitContainerStrategy, DependsOnNoneStrategy)
+	}
+}
+
+// vpaMode returns the update mode of the service workload vertical pod autoscaler, empty when disabled
+func (p *ProjectService) vpaMode() (string, error) {
+	mode := strings.TrimSpace(p.Labels[VPAModeLabel])
+	if mode == "" {
+		return "", nil
+	}
+
+	for _, m := range VPAUpdateModes {
+		if strings.EqualFold(m, mode) {
+			return m, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported vpa mode %q, supported modes: %s", mode, strings.Join(VPAUpdateModes, ", "))
+}
+
+// volumeModes returns the Secret & ConfigMap volumes file modes set via the project service volume mode labels,
+// keyed by volume name
+func (p *ProjectService) volumeModes() (map[string]int32, error) {
+	modes := map[string]int32{}
+	for label, value := range p.Labels {
+		if !strings.HasPrefix(label, VolumeModeLabelPrefix) {
+			continue
+		}
+
+		name := strings.TrimPrefix(label, VolumeModeLabelPrefix)
+		mode, err := strconv.ParseInt(strings.TrimSpace(value), 8, 32)
+		if err != nil || mode < 0 || mode > 0777 {
+			return nil, fmt.Errorf("invalid %s label value %q, expected an octal file mode between 0000 and 0777, e.g. 0400", label, value)
+		}
+		modes[name] = int32(mode)
+	}
+	return modes, nil
+}
+
+// configMountMode returns the config files mount mode set via the project service config mount mode label, if any
+func (p *ProjectService) configMountMode() (string, error) {
+	mode := strings.ToLower(strings.TrimSpace(p.Labels[ConfigMountModeLabel]))
+	switch mode {
+	case "", ConfigMountModeSubPath, ConfigMountModeDirectory:
+		return mode, nil
+	}
+
+	return "", fmt.Errorf("unsupported %s label value %q, supported modes: "+
+		"%s (mounts config files in place, but they don't receive ConfigMap updates live), "+
+		"%s (config files receive ConfigMap updates live, but the mount directory contents get shadowed)",
+		ConfigMountModeLabel, mode, ConfigMountModeSubPath, ConfigMountModeDirectory)
+}
+
+// backoffLimit returns the number of retries before a Job is marked as failed, if configured
+func (p *ProjectService) backoffLimit() *int32 {
+	return p.SvcK8sConfig.Workload.BackoffLimit
+}
+
+// ttlSecondsAfterFinished returns the seconds a finished Job is kept for before being cleaned up, if configured
+func (p *ProjectService) ttlSecondsAfterFinished() *int32 {
+	return p.SvcK8sConfig.Workload.TTLSecondsAfterFinished
+}
+
 // command returns the workload command
 // When defined via config extension takes precedence over Entrypoint defined by the compose service spec.
 // Compose project service spec Entrypoint is equivalent to a k8s command,
 // see: https://github.com/kubernetes/kompose/blob/0036f0c32b37d0a521421b76e58b580b7574c127/docs/conversion.md
+// An explicitly empty Entrypoint clears the image entrypoint, in which case the compose Command is run directly.
+// It's an empty, non nil, command when the compose Command isn't defined either.
 func (p *ProjectService) command() []string {
 	var out []string
 
@@ -60,6 +156,10 @@ func (p *ProjectService) command() []string {
 		out = []string(p.Entrypoint)
 	}
 
+	if p.clearsEntrypoint() {
+		out = append([]string{}, p.Command...)
+	}
+
 	if len(p.SvcK8sConfig.Workload.Command) > 0 {
 		out = p.SvcK8sConfig.Workload.Command
 	}
@@ -74,7 +174,8 @@ func (p *ProjectService) command() []string {
 func (p *ProjectService) commandArgs() []string {
 	var out []string
 
-	if len(p.Command) > 0 {
+	// compose Command is the k8s command when the image entrypoint is cleared
+	if len(p.Command) > 0 && !p.clearsEntrypoint() {
 		out = []string(p.Command)
 	}
 
@@ -85,6 +186,30 @@ func (p *ProjectService) commandArgs() []string {
 	return out
 }
 
+// clearsEntrypoint returns true when the compose service spec explicitly sets an empty Entrypoint, i.e. `entrypoint: []`,
+// as opposed to leaving it undefined
+func (p *ProjectService) clearsEntrypoint() bool {
+	return p.Entrypoint != nil && len(p.Entrypoint) == 0
+}
+
+// stopSignalLifecycle returns a container lifecycle sending the compose `stop_signal` to the container's main process
+// before it's stopped, as K8s always stops containers with SIGTERM. It's nil when the stop signal isn't set or is SIGTERM.
+// Note: The pre stop hook runs a shell, so it requires the container image to provide one.
+func (p *ProjectService) stopSignalLifecycle() *v1.Lifecycle {
+	signal := strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(p.StopSignal)), "SIG")
+	if signal == "" || signal == "TERM" {
+		return nil
+	}
+
+	return &v1.Lifecycle{
+		PreStop: &v1.Handler{
+			Exec: &v1.ExecAction{
+				Command: []string{"/bin/sh", "-c", fmt.Sprintf("kill -%s 1", signal)},
+			},
+		},
+	}
+}
+
 // podAnnotations returns the workload pod annotations
 func (p *ProjectService) podAnnotations() map[string]string {
 	out := p.SvcK8sConfig.Workload.Annotations
@@ -119,7 +244,7 @@ func (p *ProjectService) workloadType() config.WorkloadType {
 	workloadType := p.SvcK8sConfig.Workload.Type
 
 	if p.Deploy != nil && p.Deploy.Mode == "global" && !config.WorkloadTypesEqual(workloadType, config.DaemonSetWorkload) {
-		log.WarnfWithFields(log.Fields{
+		p.warnfWithFields(log.Fields{
 			"project-service": p.Name,
 			"workload-type":   workloadType.String(),
 		}, "Compose service defined as 'global' should map to K8s DaemonSet. Current configuration forces conversion to %s",
@@ -129,6 +254,16 @@ func (p *ProjectService) workloadType() config.WorkloadType {
 	return workloadType
 }
 
+// podDisruptionBudget returns the pod disruption budget configuration for the project service workload
+func (p *ProjectService) podDisruptionBudget() config.PodDisruptionBudget {
+	return p.SvcK8sConfig.Workload.PodDisruptionBudget
+}
+
+// schedule returns the cron schedule for the project service workload
+func (p *ProjectService) schedule() string {
+	return p.SvcK8sConfig.Workload.Schedule
+}
+
 // serviceType returns service type for project service workload
 func (p *ProjectService) serviceType() (config.ServiceType, error) {
 	serviceType := p.SvcK8sConfig.Service.Type
@@ -159,6 +294,75 @@ func (p *ProjectService) nodePort() int32 {
 	return int32(p.SvcK8sConfig.Service.NodePort)
 }
 
+// loadBalancerIP returns the requested IP address for LoadBalancer service type
+func (p *ProjectService) loadBalancerIP() string {
+	return strings.TrimSpace(p.SvcK8sConfig.Service.LoadBalancerIP)
+}
+
+// sessionAffinity returns the k8s service session affinity, blank when not configured
+func (p *ProjectService) sessionAffinity() string {
+	return strings.TrimSpace(p.SvcK8sConfig.Service.SessionAffinity)
+}
+
+// initContainers returns the workload's init containers configuration keyed by init container name
+func (p *ProjectService) initContainers() map[string]config.InitContainer {
+	return p.SvcK8sConfig.Workload.InitContainers
+}
+
+// sidecars returns the workload's sidecar containers configuration keyed by sidecar name
+func (p *ProjectService) sidecars() map[string]config.Sidecar {
+	return p.SvcK8sConfig.Workload.Sidecars
+}
+
+// portGroups returns the service port groups split out of the service into dedicated services, keyed by group name
+func (p *ProjectService) portGroups() map[string][]int {
+	return p.SvcK8sConfig.Service.PortGroups
+}
+
+// sessionAffinityTimeout returns the ClientIP session affinity timeout in seconds, zero when not configured
+func (p *ProjectService) sessionAffinityTimeout() int32 {
+	return int32(p.SvcK8sConfig.Service.SessionAffinityTimeout)
+}
+
+// monitorEnabled tells whether a Prometheus operator ServiceMonitor should scrape the service
+func (p *ProjectService) monitorEnabled() bool {
+	return p.SvcK8sConfig.Service.Monitor.Enabled
+}
+
+// monitorPath returns the path metrics are scraped from
+func (p *ProjectService) monitorPath() string {
+	if path := strings.TrimSpace(p.SvcK8sConfig.Service.Monitor.Path); path != "" {
+		return path
+	}
+	return config.DefaultMonitorPath
+}
+
+// monitorPort returns the name of the k8s service port metrics are scraped from.
+// Defaults to the first service port.
+func (p *ProjectService) monitorPort() string {
+	if port := strings.TrimSpace(p.SvcK8sConfig.Service.Monitor.Port); port != "" {
+		return port
+	}
+
+	ports := p.ports()
+	if len(ports) == 0 {
+		return ""
+	}
+	if ports[0].Published != 0 {
+		return cast.ToString(ports[0].Published)
+	}
+	return cast.ToString(ports[0].Target)
+}
+
+// serviceAnnotations returns the k8s service annotations, e.g. cloud provider load balancer settings
+func (p *ProjectService) serviceAnnotations() map[string]string {
+	annotations := p.SvcK8sConfig.Service.Annotations
+	if len(annotations) == 0 {
+		annotations = map[string]string{}
+	}
+	return annotations
+}
+
 // exposeService tells whether service for project component should be exposed
 func (p *ProjectService) exposeService() (string, error) {
 	val := strings.TrimSpace(p.SvcK8sConfig.Service.Expose.Domain)
@@ -175,6 +379,16 @@ func (p *ProjectService) tlsSecretName() string {
 	return p.SvcK8sConfig.Service.Expose.TlsSecret
 }
 
+// exposeGroups returns the project service expose groups, each exposing the service on its own ingress controller
+func (p *ProjectService) exposeGroups() []config.ExposeGroup {
+	return p.SvcK8sConfig.Service.Expose.Groups
+}
+
+// legacyIngress tells whether the exposed service should use the legacy networking.k8s.io/v1beta1 Ingress API
+func (p *ProjectService) legacyIngress() bool {
+	return p.SvcK8sConfig.Service.Expose.IngressAPIVersion == config.LegacyIngressAPIVersion
+}
+
 // ingressAnnotations returns the ingress annotations for exposed service (to be used in the ingress configuration)
 func (p *ProjectService) ingressAnnotations() map[string]string {
 	annotations := p.SvcK8sConfig.Service.Expose.IngressAnnotations
@@ -184,6 +398,37 @@ func (p *ProjectService) ingressAnnotations() map[string]string {
 	return annotations
 }
 
+// rollbackConfig returns the compose deploy rollback_config settings as comma separated key=value pairs,
+// empty when not configured. Kubernetes doesn't roll back failed rollouts automatically, so they're informational only.
+func (p *ProjectService) rollbackConfig() string {
+	if p.Deploy == nil || p.Deploy.RollbackConfig == nil {
+		return ""
+	}
+
+	cfg := p.Deploy.RollbackConfig
+	var settings []string
+	if cfg.Parallelism != nil {
+		settings = append(settings, fmt.Sprintf("parallelism=%d", *cfg.Parallelism))
+	}
+	if cfg.Delay != 0 {
+		settings = append(settings, fmt.Sprintf("delay=%s", time.Duration(cfg.Delay)))
+	}
+	if cfg.FailureAction != "" {
+		settings = append(settings, fmt.Sprintf("failure_action=%s", cfg.FailureAction))
+	}
+	if cfg.Monitor != 0 {
+		settings = append(settings, fmt.Sprintf("monitor=%s", time.Duration(cfg.Monitor)))
+	}
+	if cfg.MaxFailureRatio != 0 {
+		settings = append(settings, fmt.Sprintf("max_failure_ratio=%s", strconv.FormatFloat(float64(cfg.MaxFailureRatio), 'f', -1, 32)))
+	}
+	if cfg.Order != "" {
+		settings = append(settings, fmt.Sprintf("order=%s", cfg.Order))
+	}
+
+	return strings.Join(settings, ",")
+}
+
 // getKubernetesUpdateStrategy gets update strategy for compose project service
 // Note: it only supports `parallelism` and `order`
 func (p *ProjectService) getKubernetesUpdateStrategy() *v1apps.RollingUpdateDeployment {
@@ -276,14 +521,147 @@ func (p *ProjectService) volumes(project *composego.Project) ([]Volumes, error)
 	return vols, nil
 }
 
-// placement returns information regarding pod affinity
-// @todo Add placement support via an extension!
+// placement returns the pod node selector derived from the deploy block placement constraints
+// and the node selector configured via extension, the latter taking precedence
 func (p *ProjectService) placement() map[string]string {
+	var placement map[string]string
 	if p.Deploy != nil && p.Deploy.Placement.Constraints != nil {
-		return loadPlacement(p.Deploy.Placement.Constraints)
+		placement = p.loadPlacement(p.Deploy.Placement.Constraints)
 	}
 
-	return nil
+	nodeSelector := p.SvcK8sConfig.Workload.NodeSelector
+	if len(nodeSelector) == 0 {
+		return placement
+	}
+
+	if placement == nil {
+		placement = make(map[string]string, len(nodeSelector))
+	}
+	for k, v := range nodeSelector {
+		placement[k] = v
+	}
+
+	return placement
+}
+
+// nodeAffinity returns the required node affinity expanded from the node affinity expression configured via extension
+func (p *ProjectService) nodeAffinity() *v1.NodeAffinity {
+	expr := strings.TrimSpace(p.SvcK8sConfig.Workload.NodeAffinity)
+	if expr == "" {
+		return nil
+	}
+
+	requirements, err := nodeSelectorRequirements(expr)
+	if err != nil {
+		p.warnfWithFields(log.Fields{
+			"project-service": p.Name,
+		}, "Invalid node affinity expression %q. Skipping ...", expr)
+		return nil
+	}
+
+	return &v1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+			NodeSelectorTerms: []v1.NodeSelectorTerm{
+				{MatchExpressions: requirements},
+			},
+		},
+	}
+}
+
+// affinity returns pod affinity derived from the deploy block placement preferences
+// and the node affinity and anti-affinity configured via extension
+func (p *ProjectService) affinity() *v1.Affinity {
+	var affinity *v1.Affinity
+	if p.Deploy != nil && len(p.Deploy.Placement.Preferences) > 0 {
+		affinity = p.loadPlacementPreferences(p.Deploy.Placement.Preferences)
+	}
+
+	if nodeAffinity := p.nodeAffinity(); nodeAffinity != nil {
+		if affinity == nil {
+			affinity = &v1.Affinity{}
+		}
+		affinity.NodeAffinity = nodeAffinity
+	}
+
+	antiAffinity := p.SvcK8sConfig.Workload.AntiAffinity
+	if antiAffinity.Topology == "" {
+		return affinity
+	}
+
+	if affinity == nil {
+		affinity = &v1.Affinity{}
+	}
+	if affinity.PodAntiAffinity == nil {
+		affinity.PodAntiAffinity = &v1.PodAntiAffinity{}
+	}
+
+	term := antiAffinityTerm(p.Name, antiAffinity.Topology)
+
+	if antiAffinity.Required {
+		p.warnfWithFields(log.Fields{
+			"project-service": p.Name,
+		}, "Required pod anti-affinity allows at most one replica per %s. Pods will stay pending when there aren't enough of them to schedule all replicas", antiAffinity.Topology)
+
+		affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution = append(
+			affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution, term)
+		return affinity
+	}
+
+	// anti-affinity configured via extension takes precedence over placement preferences
+	affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(
+		[]v1.WeightedPodAffinityTerm{{Weight: 100, PodAffinityTerm: term}},
+		affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution...)
+
+	return affinity
+}
+
+// tolerations returns pod tolerations configured via extension
+func (p *ProjectService) tolerations() ([]v1.Toleration, error) {
+	parsed, err := config.ParseTolerations(p.SvcK8sConfig.Workload.Tolerations)
+	if err != nil {
+		return nil, err
+	}
+
+	var tolerations []v1.Toleration
+	for _, t := range parsed {
+		tolerations = append(tolerations, v1.Toleration{
+			Key:      t.Key,
+			Operator: v1.TolerationOperator(t.Operator),
+			Value:    t.Value,
+			Effect:   v1.TaintEffect(t.Effect),
+		})
+	}
+
+	return tolerations, nil
+}
+
+// topologySpreadConstraints returns pod topology spread constraints configured via extension
+func (p *ProjectService) topologySpreadConstraints() []v1.TopologySpreadConstraint {
+	spread := p.SvcK8sConfig.Workload.TopologySpread
+	if spread.TopologyKey == "" {
+		return nil
+	}
+
+	maxSkew := spread.MaxSkew
+	if maxSkew == 0 {
+		maxSkew = config.DefaultTopologySpreadMaxSkew
+	}
+
+	whenUnsatisfiable := spread.WhenUnsatisfiable
+	if whenUnsatisfiable == "" {
+		whenUnsatisfiable = config.DefaultTopologySpreadWhenUnsatisfiable
+	}
+
+	return []v1.TopologySpreadConstraint{
+		{
+			MaxSkew:           int32(maxSkew),
+			TopologyKey:       spread.TopologyKey,
+			WhenUnsatisfiable: v1.UnsatisfiableConstraintAction(whenUnsatisfiable),
+			LabelSelector: &meta.LabelSelector{
+				MatchLabels: configLabels(p.Name),
+			},
+		},
+	}
 }
 
 // resourceRequests returns workload resource requests (memory & cpu)
@@ -341,6 +719,7 @@ func (p *ProjectService) resourceLimits() (*int64, *int64, *int64) {
 
 	// @step extract limits from deploy block if present
 	if p.Deploy != nil && p.Deploy.Resources.Limits != nil {
+		memLimit = int64(p.Deploy.Resources.Limits.MemoryBytes)
 		cpu, _ := resource.ParseQuantity(p.Deploy.Resources.Limits.NanoCPUs)
 		cpuLimit = cpu.ToDec().MilliValue()
 	}
@@ -378,9 +757,92 @@ func (p *ProjectService) fsGroup() *int64 {
 	return p.SvcK8sConfig.Workload.PodSecurity.FsGroup
 }
 
-// imagePullPolicy returns image PullPolicy for project service
+// fsGroupChangePolicy returns pod security context fsGroup change policy, nil when not configured
+func (p *ProjectService) fsGroupChangePolicy() *v1.PodFSGroupChangePolicy {
+	policy := p.SvcK8sConfig.Workload.PodSecurity.FsGroupChangePolicy
+	if policy == "" {
+		return nil
+	}
+	v := v1.PodFSGroupChangePolicy(policy)
+	return &v
+}
+
+// runAsNonRoot returns pod security context runAsNonRoot value
+func (p *ProjectService) runAsNonRoot() *bool {
+	return p.SvcK8sConfig.Workload.PodSecurity.RunAsNonRoot
+}
+
+// seccompProfile returns pod security context seccomp profile, nil when not configured
+func (p *ProjectService) seccompProfile() *v1.SeccompProfile {
+	profile := p.SvcK8sConfig.Workload.PodSecurity.SeccompProfile
+
+	switch {
+	case profile == config.SeccompProfileRuntimeDefault:
+		return &v1.SeccompProfile{Type: v1.SeccompProfileTypeRuntimeDefault}
+	case strings.HasPrefix(profile, config.SeccompProfileLocalhostPrefix):
+		path := strings.TrimPrefix(profile, config.SeccompProfileLocalhostPrefix)
+		return &v1.SeccompProfile{Type: v1.SeccompProfileTypeLocalhost, LocalhostProfile: &path}
+	default:
+		return nil
+	}
+}
+
+// readOnlyRootFilesystem returns container security context read only root filesystem flag, nil when not configured
+func (p *ProjectService) readOnlyRootFilesystem() *bool {
+	return p.securityFlag("readOnlyRootFilesystem", p.SvcK8sConfig.Workload.ContainerSecurity.ReadOnlyRootFilesystem)
+}
+
+// allowPrivilegeEscalation returns container security context allow privilege escalation flag, nil when not configured
+func (p *ProjectService) allowPrivilegeEscalation() *bool {
+	return p.securityFlag("allowPrivilegeEscalation", p.SvcK8sConfig.Workload.ContainerSecurity.AllowPrivilegeEscalation)
+}
+
+// securityFlag parses a container security context boolean flag, skipping non boolean values
+func (p *ProjectService) securityFlag(name, value string) *bool {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+
+	flag, err := strconv.ParseBool(value)
+	if err != nil {
+		p.warnfWithFields(log.Fields{
+			"project-service": p.Name,
+			name:              value,
+		}, "Ignoring container security context %s value. It must be specified as a boolean.", name)
+		return nil
+	}
+
+	return &flag
+}
+
+// imagePullPolicy returns image PullPolicy for project service, defaults to IfNotPresent
 func (p *ProjectService) imagePullPolicy() v1.PullPolicy {
-	return v1.PullPolicy(p.SvcK8sConfig.Workload.ImagePull.Policy)
+	policy := strings.TrimSpace(p.SvcK8sConfig.Workload.ImagePull.Policy)
+	if policy == "" {
+		policy = config.DefaultImagePullPolicy
+	}
+	return v1.PullPolicy(policy)
+}
+
+// terminationMessagePolicy returns the container termination message policy, blank when not configured
+func (p *ProjectService) terminationMessagePolicy() v1.TerminationMessagePolicy {
+	return v1.TerminationMessagePolicy(p.SvcK8sConfig.Workload.TerminationMessage.Policy)
+}
+
+// terminationMessagePath returns the container termination message path, blank when not configured
+func (p *ProjectService) terminationMessagePath() string {
+	return p.SvcK8sConfig.Workload.TerminationMessage.Path
+}
+
+// terminationGracePeriod returns the pod termination grace period in whole seconds as set by compose `stop_grace_period`,
+// nil when not set so that the Kubernetes default applies
+func (p *ProjectService) terminationGracePeriod() *int64 {
+	if p.StopGracePeriod == nil {
+		return nil
+	}
+	seconds := int64(time.Duration(*p.StopGracePeriod).Seconds())
+	return &seconds
 }
 
 // imagePullSecret returns image pull secret (for private registries)
@@ -388,6 +850,21 @@ func (p *ProjectService) imagePullSecret() string {
 	return p.SvcK8sConfig.Workload.ImagePull.Secret
 }
 
+// imagePullSecrets returns deduplicated image pull secret names from the comma separated image pull secret
+func (p *ProjectService) imagePullSecrets() []string {
+	var secrets []string
+	seen := map[string]bool{}
+	for _, s := range strings.Split(p.imagePullSecret(), ",") {
+		s = strings.TrimSpace(s)
+		if s == "" || seen[s] {
+			continue
+		}
+		seen[s] = true
+		secrets = append(secrets, s)
+	}
+	return secrets
+}
+
 // serviceAccountName returns service account name to be used by the pod
 func (p *ProjectService) serviceAccountName() string {
 	return p.SvcK8sConfig.Workload.ServiceAccountName
@@ -421,7 +898,7 @@ func (p *ProjectService) environment() composego.MappingWithEquals {
 			if result != "" {
 				envs[name] = &result
 			} else {
-				log.WarnWithFields(log.Fields{
+				p.warnWithFields(log.Fields{
 					"project-service": p.Name,
 					"env-var":         name,
 				}, "Env Var has no value and will be ignored")
@@ -494,3 +971,13 @@ func (p *ProjectService) ReadinessProbe() (*v1.Probe, error) {
 
 	return ReadinessProbeToV1Probe(k8sconf.Workload.ReadinessProbe)
 }
+
+func (p *ProjectService) StartupProbe() (*v1.Probe, error) {
+	p1 := p.ServiceConfig
+	k8sconf, err := config.SvcK8sConfigFromCompose(&p1)
+	if err != nil {
+		return nil, err
+	}
+
+	return StartupProbeToV1Probe(k8sconf.Workload.StartupProbe)
+}
@@ -125,6 +125,16 @@ var _ = Describe("ProjectService", func() {
 				Expect(projectService.enabled()).To(BeTrue())
 			})
 		})
+
+		When("component is labelled to skip render", func() {
+			JustBeforeEach(func() {
+				projectService.Labels = composego.Labels{SkipRenderLabel: "true"}
+			})
+
+			It("returns false", func() {
+				Expect(projectService.enabled()).To(BeFalse())
+			})
+		})
 	})
 
 	Describe("command", func() {
@@ -600,6 +610,141 @@ var _ = Describe("ProjectService", func() {
 		})
 	})
 
+	Describe("monitorPath", func() {
+
+		Context("when specified via an extension", func() {
+			BeforeEach(func() {
+				svcK8sConfig.Service.Monitor.Path = "/stats"
+			})
+
+			It("will use the extension value", func() {
+				Expect(projectService.monitorPath()).To(Equal("/stats"))
+			})
+		})
+
+		Context("when not specified via an extension", func() {
+			It("will return the default metrics path", func() {
+				Expect(projectService.monitorPath()).To(Equal(config.DefaultMonitorPath))
+			})
+		})
+	})
+
+	Describe("monitorPort", func() {
+
+		Context("when specified via an extension", func() {
+			BeforeEach(func() {
+				svcK8sConfig.Service.Monitor.Port = "metrics"
+			})
+
+			It("will use the extension value", func() {
+				Expect(projectService.monitorPort()).To(Equal("metrics"))
+			})
+		})
+
+		Context("when not specified via an extension", func() {
+			BeforeEach(func() {
+				ports = []composego.ServicePortConfig{
+					{Target: 9090},
+					{Target: 8080, Published: 80},
+				}
+			})
+
+			It("will return the first service port name", func() {
+				Expect(projectService.monitorPort()).To(Equal("9090"))
+			})
+		})
+
+		Context("when the service has no ports", func() {
+			It("will return an empty string", func() {
+				Expect(projectService.monitorPort()).To(Equal(""))
+			})
+		})
+	})
+
+	Describe("legacyIngress", func() {
+
+		Context("when legacy ingress API version is specified via an extension", func() {
+			BeforeEach(func() {
+				svcK8sConfig.Service.Expose.IngressAPIVersion = config.LegacyIngressAPIVersion
+			})
+
+			It("returns true", func() {
+				Expect(projectService.legacyIngress()).To(BeTrue())
+			})
+		})
+
+		Context("when ingress API version is not specified via an extension", func() {
+			It("returns false", func() {
+				Expect(projectService.legacyIngress()).To(BeFalse())
+			})
+		})
+	})
+
+	Describe("volumeModes", func() {
+
+		When("volume mode labels are set", func() {
+			JustBeforeEach(func() {
+				projectService.Labels = composego.Labels{
+					VolumeModeLabelPrefix + "ssh-key": "0400",
+					VolumeModeLabelPrefix + "scripts": "755",
+					"unrelated":                       "label",
+				}
+			})
+
+			It("returns the octal file modes keyed by volume name", func() {
+				modes, err := projectService.volumeModes()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(modes).To(Equal(map[string]int32{"ssh-key": 0400, "scripts": 0755}))
+			})
+		})
+
+		When("a volume mode label isn't a legal octal file mode", func() {
+			JustBeforeEach(func() {
+				projectService.Labels = composego.Labels{VolumeModeLabelPrefix + "ssh-key": "0900"}
+			})
+
+			It("returns an error", func() {
+				_, err := projectService.volumeModes()
+				Expect(err).To(MatchError(`invalid kev.workload.volume-mode.ssh-key label value "0900", expected an octal file mode between 0000 and 0777, e.g. 0400`))
+			})
+		})
+
+		When("no volume mode labels are set", func() {
+			It("returns no modes", func() {
+				modes, err := projectService.volumeModes()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(modes).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("rollbackConfig", func() {
+
+		Context("when deploy block defines a rollback config", func() {
+			BeforeEach(func() {
+				parallelism := uint64(1)
+				deploy = &composego.DeployConfig{
+					RollbackConfig: &composego.UpdateConfig{
+						Parallelism:   &parallelism,
+						Delay:         composego.Duration(10 * time.Second),
+						FailureAction: "pause",
+						Order:         "stop-first",
+					},
+				}
+			})
+
+			It("returns the configured settings", func() {
+				Expect(projectService.rollbackConfig()).To(Equal("parallelism=1,delay=10s,failure_action=pause,order=stop-first"))
+			})
+		})
+
+		Context("when deploy block doesn't define a rollback config", func() {
+			It("returns empty string", func() {
+				Expect(projectService.rollbackConfig()).To(BeEmpty())
+			})
+		})
+	})
+
 	Describe("getKubernetesUpdateStrategy", func() {
 
 		Context("when deploy block defined and contains UpdateConfig details", func() {
@@ -767,6 +912,233 @@ var _ = Describe("ProjectService", func() {
 				Expect(projectService.placement()).To(BeNil())
 			})
 		})
+
+		Context("when node selector is configured via extension", func() {
+
+			BeforeEach(func() {
+				deploy = &composego.DeployConfig{
+					Placement: composego.Placement{
+						Constraints: []string{
+							"node.labels.disktype==hdd",
+							"node.role==worker",
+						},
+					},
+				}
+				svcK8sConfig.Workload.NodeSelector = map[string]string{
+					"disktype":                  "ssd",
+					"cloud.google.com/gke-pool": "highmem",
+				}
+			})
+
+			It("merges it with placement constraints taking precedence over them", func() {
+				Expect(projectService.placement()).To(Equal(map[string]string{
+					"disktype":                       "ssd",
+					"cloud.google.com/gke-pool":      "highmem",
+					"node-role.kubernetes.io/worker": "true",
+				}))
+			})
+		})
+	})
+
+	Describe("nodeAffinity", func() {
+
+		Context("when node affinity is not configured", func() {
+			It("returns nil", func() {
+				Expect(projectService.nodeAffinity()).To(BeNil())
+			})
+		})
+
+		Context("when node affinity expression is configured via extension", func() {
+
+			BeforeEach(func() {
+				svcK8sConfig.Workload.NodeAffinity = "topology.kubernetes.io/zone in (eu-west-2a,eu-west-2b),node.kubernetes.io/instance-type!=t3.micro,gpu"
+			})
+
+			It("returns required node affinity matching all expression requirements", func() {
+				terms := projectService.nodeAffinity().RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+				Expect(terms).To(HaveLen(1))
+				Expect(terms[0].MatchExpressions).To(ConsistOf(
+					v1.NodeSelectorRequirement{
+						Key:      "topology.kubernetes.io/zone",
+						Operator: v1.NodeSelectorOpIn,
+						Values:   []string{"eu-west-2a", "eu-west-2b"},
+					},
+					v1.NodeSelectorRequirement{
+						Key:      "node.kubernetes.io/instance-type",
+						Operator: v1.NodeSelectorOpNotIn,
+						Values:   []string{"t3.micro"},
+					},
+					v1.NodeSelectorRequirement{
+						Key:      "gpu",
+						Operator: v1.NodeSelectorOpExists,
+					},
+				))
+			})
+
+			It("is included in pod affinity", func() {
+				Expect(projectService.affinity().NodeAffinity).To(Equal(projectService.nodeAffinity()))
+			})
+		})
+	})
+
+	Describe("tolerations", func() {
+
+		Context("when tolerations are not configured", func() {
+			It("returns no tolerations", func() {
+				tolerations, err := projectService.tolerations()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(tolerations).To(BeEmpty())
+			})
+		})
+
+		Context("when tolerations are configured via extension", func() {
+
+			BeforeEach(func() {
+				svcK8sConfig.Workload.Tolerations = []string{"dedicated=gpu:NoSchedule", "spot:NoExecute"}
+			})
+
+			It("returns pod tolerations", func() {
+				tolerations, err := projectService.tolerations()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(tolerations).To(Equal([]v1.Toleration{
+					{Key: "dedicated", Operator: v1.TolerationOpEqual, Value: "gpu", Effect: v1.TaintEffectNoSchedule},
+					{Key: "spot", Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoExecute},
+				}))
+			})
+		})
+	})
+
+	Describe("topologySpreadConstraints", func() {
+
+		Context("when topology spread is not configured", func() {
+			It("returns nil", func() {
+				Expect(projectService.topologySpreadConstraints()).To(BeNil())
+			})
+		})
+
+		Context("when only topology key is configured via extension", func() {
+
+			BeforeEach(func() {
+				svcK8sConfig.Workload.TopologySpread.TopologyKey = "topology.kubernetes.io/zone"
+			})
+
+			It("returns a constraint with default max skew and unsatisfiable handling", func() {
+				constraints := projectService.topologySpreadConstraints()
+				Expect(constraints).To(HaveLen(1))
+				Expect(constraints[0].TopologyKey).To(Equal("topology.kubernetes.io/zone"))
+				Expect(constraints[0].MaxSkew).To(BeEquivalentTo(config.DefaultTopologySpreadMaxSkew))
+				Expect(constraints[0].WhenUnsatisfiable).To(Equal(v1.DoNotSchedule))
+				Expect(constraints[0].LabelSelector.MatchLabels).To(Equal(configLabels(projectServiceName)))
+			})
+		})
+
+		Context("when all topology spread settings are configured via extension", func() {
+
+			BeforeEach(func() {
+				svcK8sConfig.Workload.TopologySpread = config.TopologySpread{
+					MaxSkew:           2,
+					TopologyKey:       "kubernetes.io/hostname",
+					WhenUnsatisfiable: "ScheduleAnyway",
+				}
+			})
+
+			It("returns a constraint as configured", func() {
+				constraints := projectService.topologySpreadConstraints()
+				Expect(constraints).To(HaveLen(1))
+				Expect(constraints[0].TopologyKey).To(Equal("kubernetes.io/hostname"))
+				Expect(constraints[0].MaxSkew).To(BeEquivalentTo(2))
+				Expect(constraints[0].WhenUnsatisfiable).To(Equal(v1.ScheduleAnyway))
+			})
+		})
+	})
+
+	Describe("affinity", func() {
+
+		Context("when placement preferences have been provided in deploy block", func() {
+
+			BeforeEach(func() {
+				deploy = &composego.DeployConfig{
+					Placement: composego.Placement{
+						Preferences: []composego.PlacementPreferences{
+							{Spread: "node.labels.zone"},
+							{Spread: "engine.labels.operatingsystem"},
+							{Spread: "node.hostname"},
+						},
+					},
+				}
+			})
+
+			It("returns preferred pod anti-affinity terms for supported preferences", func() {
+				terms := projectService.affinity().PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+				Expect(terms).To(HaveLen(2))
+				Expect(terms[0].Weight).To(BeEquivalentTo(100))
+				Expect(terms[0].PodAffinityTerm.TopologyKey).To(Equal("zone"))
+				Expect(terms[0].PodAffinityTerm.LabelSelector.MatchLabels).To(HaveKeyWithValue(Selector, projectServiceName))
+				Expect(terms[1].Weight).To(BeEquivalentTo(98))
+				Expect(terms[1].PodAffinityTerm.TopologyKey).To(Equal("kubernetes.io/hostname"))
+			})
+		})
+
+		Context("when only unsupported placement preferences are provided", func() {
+
+			BeforeEach(func() {
+				deploy = &composego.DeployConfig{
+					Placement: composego.Placement{
+						Preferences: []composego.PlacementPreferences{
+							{Spread: "engine.labels.operatingsystem"},
+						},
+					},
+				}
+			})
+
+			It("returns nil", func() {
+				Expect(projectService.affinity()).To(BeNil())
+			})
+		})
+
+		Context("when placement preferences are not provided in deploy block", func() {
+			It("returns nil", func() {
+				Expect(projectService.affinity()).To(BeNil())
+			})
+		})
+
+		Context("when preferred anti-affinity is configured via extension", func() {
+
+			BeforeEach(func() {
+				svcK8sConfig.Workload.AntiAffinity.Topology = config.ZoneAntiAffinityTopology
+				deploy = &composego.DeployConfig{
+					Placement: composego.Placement{
+						Preferences: []composego.PlacementPreferences{
+							{Spread: "node.labels.rack"},
+						},
+					},
+				}
+			})
+
+			It("returns preferred pod anti-affinity term ahead of placement preferences", func() {
+				terms := projectService.affinity().PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+				Expect(terms).To(HaveLen(2))
+				Expect(terms[0].Weight).To(BeEquivalentTo(100))
+				Expect(terms[0].PodAffinityTerm.TopologyKey).To(Equal("topology.kubernetes.io/zone"))
+				Expect(terms[0].PodAffinityTerm.LabelSelector.MatchLabels).To(HaveKeyWithValue(Selector, projectServiceName))
+				Expect(terms[1].PodAffinityTerm.TopologyKey).To(Equal("rack"))
+			})
+		})
+
+		Context("when required anti-affinity is configured via extension", func() {
+
+			BeforeEach(func() {
+				svcK8sConfig.Workload.AntiAffinity.Topology = config.NodeAntiAffinityTopology
+				svcK8sConfig.Workload.AntiAffinity.Required = true
+			})
+
+			It("returns required pod anti-affinity term", func() {
+				antiAffinity := projectService.affinity().PodAntiAffinity
+				Expect(antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution).To(BeEmpty())
+				Expect(antiAffinity.RequiredDuringSchedulingIgnoredDuringExecution).To(HaveLen(1))
+				Expect(antiAffinity.RequiredDuringSchedulingIgnoredDuringExecution[0].TopologyKey).To(Equal("kubernetes.io/hostname"))
+			})
+		})
 	})
 
 	Describe("resourceRequests", func() {
@@ -971,6 +1343,26 @@ var _ = Describe("ProjectService", func() {
 		})
 	})
 
+	Describe("fsGroupChangePolicy", func() {
+
+		Context("when defined via an extension", func() {
+			BeforeEach(func() {
+				svcK8sConfig.Workload.PodSecurity.FsGroupChangePolicy = "OnRootMismatch"
+			})
+
+			It("returns the extension value", func() {
+				expected := v1.FSGroupChangeOnRootMismatch
+				Expect(projectService.fsGroupChangePolicy()).To(Equal(&expected))
+			})
+		})
+
+		Context("when not defined via an extension", func() {
+			It("returns nil", func() {
+				Expect(projectService.fsGroupChangePolicy()).To(BeNil())
+			})
+		})
+	})
+
 	Describe("imagePullPolicy", func() {
 
 		Context("when defined via extension", func() {
@@ -991,6 +1383,13 @@ var _ = Describe("ProjectService", func() {
 			})
 		})
 
+		Context("when blank in the service extension", func() {
+			It("returns default value", func() {
+				ps := ProjectService{SvcK8sConfig: config.SvcK8sConfig{}}
+				Expect(ps.imagePullPolicy()).To(Equal(v1.PullIfNotPresent))
+			})
+		})
+
 		Context("for invalid image pull policy", func() {
 			policy := "invalid-policy-name"
 			extensions := make(map[string]interface{})
@@ -1013,6 +1412,48 @@ var _ = Describe("ProjectService", func() {
 		})
 	})
 
+	Describe("terminationMessagePolicy", func() {
+
+		Context("when defined via extension", func() {
+			BeforeEach(func() {
+				svcK8sConfig.Workload.TerminationMessage.Policy = "FallbackToLogsOnError"
+				svcK8sConfig.Workload.TerminationMessage.Path = "/tmp/termination-log"
+			})
+
+			It("returns the extension values", func() {
+				Expect(projectService.terminationMessagePolicy()).To(Equal(v1.TerminationMessageFallbackToLogsOnError))
+				Expect(projectService.terminationMessagePath()).To(Equal("/tmp/termination-log"))
+			})
+		})
+
+		Context("when not defined via extension", func() {
+			It("leaves termination message settings unset", func() {
+				Expect(projectService.terminationMessagePolicy()).To(BeEmpty())
+				Expect(projectService.terminationMessagePath()).To(BeEmpty())
+			})
+		})
+
+		Context("for invalid termination message policy", func() {
+			extensions := make(map[string]interface{})
+
+			JustBeforeEach(func() {
+				svcK8sConfig.Workload.TerminationMessage.Policy = "Logs"
+				m, err := svcK8sConfig.Map()
+				Expect(err).NotTo(HaveOccurred())
+
+				extensions[config.K8SExtensionKey] = m
+			})
+
+			It("returns an error", func() {
+				_, err := NewProjectService(composego.ServiceConfig{
+					Extensions: extensions,
+				})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("SvcK8sConfig.Workload.TerminationMessage.Policy"))
+			})
+		})
+	})
+
 	Describe("imagePullSecret", func() {
 
 		Context("when defined via extension", func() {
@@ -1034,6 +1475,25 @@ var _ = Describe("ProjectService", func() {
 		})
 	})
 
+	Describe("imagePullSecrets", func() {
+
+		Context("when a comma separated list is defined via extension", func() {
+			BeforeEach(func() {
+				svcK8sConfig.Workload.ImagePull.Secret = "first, second,,first ,third"
+			})
+
+			It("returns trimmed and deduplicated secret names", func() {
+				Expect(projectService.imagePullSecrets()).To(Equal([]string{"first", "second", "third"}))
+			})
+		})
+
+		Context("when not defined via extension", func() {
+			It("returns no secret names", func() {
+				Expect(projectService.imagePullSecrets()).To(BeEmpty())
+			})
+		})
+	})
+
 	Describe("serviceAccountName", func() {
 
 		Context("when defined an extension", func() {
@@ -1381,4 +1841,43 @@ var _ = Describe("ProjectService", func() {
 		})
 
 	})
+
+	Describe("startupProbe", func() {
+		When("not defined via extension", func() {
+			It("returns no probe", func() {
+				p, err := projectService.StartupProbe()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(p).To(BeNil())
+			})
+		})
+
+		When("defined via extension", func() {
+			BeforeEach(func() {
+				svcK8sConfig.Workload.StartupProbe.Type = config.ProbeTypeHTTP.String()
+				svcK8sConfig.Workload.StartupProbe.HTTP.Path = "/status"
+				svcK8sConfig.Workload.StartupProbe.HTTP.Port = 8080
+				svcK8sConfig.Workload.StartupProbe.FailureThreshold = 30
+			})
+
+			It("returns a probe with the configured handler", func() {
+				p, err := projectService.StartupProbe()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(p.HTTPGet.Port.IntValue()).To(Equal(8080))
+				Expect(p.HTTPGet.Path).To(Equal("/status"))
+				Expect(p.FailureThreshold).To(BeEquivalentTo(30))
+			})
+		})
+
+		When("disabled via extension", func() {
+			BeforeEach(func() {
+				svcK8sConfig.Workload.StartupProbe.Type = config.ProbeTypeNone.String()
+			})
+
+			It("returns no probe", func() {
+				p, err := projectService.StartupProbe()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(p).To(BeNil())
+			})
+		})
+	})
 })
@@ -0,0 +1,185 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"github.com/appvia/kev/pkg/kev/log"
+	v1apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// objectRef identifies an object by its kind and name
+type objectRef struct {
+	kind string
+	name string
+}
+
+// pruneEmpty drops objects carrying no meaningful configuration. An object is considered empty when:
+//   - Service: has no ports and isn't headless
+//   - ConfigMap: has no data
+//   - Secret: has no data
+//   - NetworkPolicy: allows all ingress traffic and doesn't restrict egress traffic, i.e. has no effect
+//   - PodDisruptionBudget: defines neither min available nor max unavailable pods
+//
+// Empty objects referenced by other objects (e.g. a StatefulSet's governing service) are always kept.
+func pruneEmpty(objects []runtime.Object) []runtime.Object {
+	referenced := referencedObjects(objects)
+
+	var kept []runtime.Object
+	for _, o := range objects {
+		ref, empty := emptyObject(o)
+		if !empty || referenced[ref] {
+			kept = append(kept, o)
+			continue
+		}
+
+		log.DebugfWithFields(log.Fields{
+			"kind": ref.kind,
+			"name": ref.name,
+		}, "Pruning empty object")
+	}
+
+	return kept
+}
+
+// emptyObject checks whether the object carries no meaningful configuration
+func emptyObject(o runtime.Object) (objectRef, bool) {
+	switch t := o.(type) {
+	case *v1.Service:
+		return objectRef{"Service", t.Name}, len(t.Spec.Ports) == 0 && t.Spec.ClusterIP != v1.ClusterIPNone
+	case *v1.ConfigMap:
+		return objectRef{"ConfigMap", t.Name}, len(t.Data) == 0 && len(t.BinaryData) == 0
+	case *v1.Secret:
+		return objectRef{"Secret", t.Name}, len(t.Data) == 0 && len(t.StringData) == 0
+	case *networking.NetworkPolicy:
+		return objectRef{"NetworkPolicy", t.Name}, noopNetworkPolicy(t.Spec)
+	case *policyv1beta1.PodDisruptionBudget:
+		return objectRef{"PodDisruptionBudget", t.Name}, t.Spec.MinAvailable == nil && t.Spec.MaxUnavailable == nil
+	default:
+		return objectRef{}, false
+	}
+}
+
+// noopNetworkPolicy checks whether the network policy allows all ingress and doesn't restrict egress traffic
+func noopNetworkPolicy(spec networking.NetworkPolicySpec) bool {
+	for _, t := range spec.PolicyTypes {
+		if t == networking.PolicyTypeEgress {
+			return false
+		}
+	}
+
+	for _, rule := range spec.Ingress {
+		if len(rule.From) == 0 && len(rule.Ports) == 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// referencedObjects collects the services, config maps and secrets other objects depend on
+func referencedObjects(objects []runtime.Object) map[objectRef]bool {
+	refs := map[objectRef]bool{}
+
+	for _, o := range objects {
+		switch t := o.(type) {
+		case *v1apps.StatefulSet:
+			refs[objectRef{"Service", t.Spec.ServiceName}] = true
+		case *networking.Ingress:
+			if t.Spec.DefaultBackend != nil && t.Spec.DefaultBackend.Service != nil {
+				refs[objectRef{"Service", t.Spec.DefaultBackend.Service.Name}] = true
+			}
+			for _, r := range t.Spec.Rules {
+				if r.HTTP == nil {
+					continue
+				}
+				for _, p := range r.HTTP.Paths {
+					if p.Backend.Service != nil {
+						refs[objectRef{"Service", p.Backend.Service.Name}] = true
+					}
+				}
+			}
+		case *networkingv1beta1.Ingress:
+			if t.Spec.Backend != nil {
+				refs[objectRef{"Service", t.Spec.Backend.ServiceName}] = true
+			}
+			for _, r := range t.Spec.Rules {
+				if r.HTTP == nil {
+					continue
+				}
+				for _, p := range r.HTTP.Paths {
+					refs[objectRef{"Service", p.Backend.ServiceName}] = true
+				}
+			}
+		case *IngressRoute:
+			for _, r := range t.Spec.Routes {
+				for _, s := range r.Services {
+					refs[objectRef{"Service", s.Name}] = true
+				}
+			}
+		}
+
+		if w, ok := lintWorkload(o); ok {
+			podSpecReferences(w.podSpec, refs)
+		}
+	}
+
+	return refs
+}
+
+// podSpecReferences collects the config maps and secrets referenced by the pod spec
+func podSpecReferences(spec v1.PodSpec, refs map[objectRef]bool) {
+	for _, s := range spec.ImagePullSecrets {
+		refs[objectRef{"Secret", s.Name}] = true
+	}
+
+	for _, vol := range spec.Volumes {
+		if vol.ConfigMap != nil {
+			refs[objectRef{"ConfigMap", vol.ConfigMap.Name}] = true
+		}
+		if vol.Secret != nil {
+			refs[objectRef{"Secret", vol.Secret.SecretName}] = true
+		}
+	}
+
+	containers := append(append([]v1.Container{}, spec.InitContainers...), spec.Containers...)
+	for _, c := range containers {
+		for _, from := range c.EnvFrom {
+			if from.ConfigMapRef != nil {
+				refs[objectRef{"ConfigMap", from.ConfigMapRef.Name}] = true
+			}
+			if from.SecretRef != nil {
+				refs[objectRef{"Secret", from.SecretRef.Name}] = true
+			}
+		}
+		for _, env := range c.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			if env.ValueFrom.ConfigMapKeyRef != nil {
+				refs[objectRef{"ConfigMap", env.ValueFrom.ConfigMapKeyRef.Name}] = true
+			}
+			if env.ValueFrom.SecretKeyRef != nil {
+				refs[objectRef{"Secret", env.ValueFrom.SecretKeyRef.Name}] = true
+			}
+		}
+	}
+}
@@ -0,0 +1,116 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+var _ = Describe("pruneEmpty", func() {
+
+	service := func(name string, ports ...int32) *v1.Service {
+		svc := &v1.Service{ObjectMeta: meta.ObjectMeta{Name: name}}
+		for _, p := range ports {
+			svc.Spec.Ports = append(svc.Spec.Ports, v1.ServicePort{Port: p})
+		}
+		return svc
+	}
+
+	It("drops empty objects", func() {
+		objects := []runtime.Object{
+			service("no-ports"),
+			&v1.ConfigMap{ObjectMeta: meta.ObjectMeta{Name: "no-data"}},
+			&v1.Secret{ObjectMeta: meta.ObjectMeta{Name: "no-data"}},
+			&policyv1beta1.PodDisruptionBudget{ObjectMeta: meta.ObjectMeta{Name: "no-budget"}},
+			&networking.NetworkPolicy{
+				ObjectMeta: meta.ObjectMeta{Name: "allow-all"},
+				Spec: networking.NetworkPolicySpec{
+					Ingress: []networking.NetworkPolicyIngressRule{{}},
+				},
+			},
+		}
+
+		Expect(pruneEmpty(objects)).To(BeEmpty())
+	})
+
+	It("keeps objects carrying configuration", func() {
+		minAvailable := intstr.FromInt(1)
+		objects := []runtime.Object{
+			service("web", 80),
+			&v1.Service{
+				ObjectMeta: meta.ObjectMeta{Name: "headless"},
+				Spec:       v1.ServiceSpec{ClusterIP: v1.ClusterIPNone},
+			},
+			&v1.ConfigMap{ObjectMeta: meta.ObjectMeta{Name: "cfg"}, Data: map[string]string{"k": "v"}},
+			&policyv1beta1.PodDisruptionBudget{
+				ObjectMeta: meta.ObjectMeta{Name: "web"},
+				Spec:       policyv1beta1.PodDisruptionBudgetSpec{MinAvailable: &minAvailable},
+			},
+			&networking.NetworkPolicy{
+				ObjectMeta: meta.ObjectMeta{Name: "deny-all"},
+				Spec:       networking.NetworkPolicySpec{},
+			},
+		}
+
+		Expect(pruneEmpty(objects)).To(Equal(objects))
+	})
+
+	It("keeps empty objects other objects depend on", func() {
+		statefulSet := &v1apps.StatefulSet{
+			ObjectMeta: meta.ObjectMeta{Name: "db"},
+			Spec: v1apps.StatefulSetSpec{
+				ServiceName: "db",
+				Template: v1.PodTemplateSpec{
+					Spec: v1.PodSpec{
+						Volumes: []v1.Volume{{
+							Name: "cfg",
+							VolumeSource: v1.VolumeSource{
+								ConfigMap: &v1.ConfigMapVolumeSource{
+									LocalObjectReference: v1.LocalObjectReference{Name: "db-config"},
+								},
+							},
+						}},
+						Containers: []v1.Container{{
+							Name: "db",
+							EnvFrom: []v1.EnvFromSource{{
+								SecretRef: &v1.SecretEnvSource{
+									LocalObjectReference: v1.LocalObjectReference{Name: "db-env"},
+								},
+							}},
+						}},
+					},
+				},
+			},
+		}
+		objects := []runtime.Object{
+			service("db"),
+			&v1.ConfigMap{ObjectMeta: meta.ObjectMeta{Name: "db-config"}},
+			&v1.Secret{ObjectMeta: meta.ObjectMeta{Name: "db-env"}},
+			statefulSet,
+		}
+
+		Expect(pruneEmpty(objects)).To(Equal(objects))
+	})
+})
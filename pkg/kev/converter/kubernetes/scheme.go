@@ -0,0 +1,85 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"sync"
+
+	v1apps "k8s.io/api/apps/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	v1batch "k8s.io/api/batch/v1"
+	v1beta1batch "k8s.io/api/batch/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+)
+
+var (
+	// scheme is the runtime scheme shared by all conversions performed by the converter.
+	// It knows about every built-in type the converter produces and can be extended
+	// with custom resources via AddToScheme.
+	scheme   = runtime.NewScheme()
+	schemeMu sync.RWMutex
+)
+
+func init() {
+	utilruntime.Must(AddToScheme(
+		v1.AddToScheme,
+		v1apps.AddToScheme,
+		v1batch.AddToScheme,
+		v1beta1batch.AddToScheme,
+		autoscalingv2beta2.AddToScheme,
+		networking.AddToScheme,
+		networkingv1beta1.AddToScheme,
+		policyv1beta1.AddToScheme,
+		AddTraefikToScheme,
+		AddVPAToScheme,
+	))
+}
+
+// AddToScheme registers additional types with the converter's runtime scheme.
+// Library consumers should use it to register custom resources (e.g. CRDs) so that
+// objects of those types get serialised with the correct apiVersion and kind.
+// It accepts the AddToScheme functions usually exposed by API packages.
+func AddToScheme(funcs ...func(*runtime.Scheme) error) error {
+	schemeMu.Lock()
+	defer schemeMu.Unlock()
+
+	return runtime.NewSchemeBuilder(funcs...).AddToScheme(scheme)
+}
+
+// objectKinds returns all group version kinds registered for the object's type
+// with the converter's runtime scheme.
+func objectKinds(obj runtime.Object) ([]schema.GroupVersionKind, error) {
+	schemeMu.RLock()
+	defer schemeMu.RUnlock()
+
+	gvks, _, err := scheme.ObjectKinds(obj)
+	return gvks, err
+}
+
+// convertWithScheme converts object to a given group version using the converter's runtime scheme.
+func convertWithScheme(obj runtime.Object, version schema.GroupVersion) (runtime.Object, error) {
+	schemeMu.RLock()
+	defer schemeMu.RUnlock()
+
+	return scheme.ConvertToVersion(obj, version)
+}
@@ -21,6 +21,7 @@
 package kubernetes
 
 import (
+	"bytes"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -31,6 +32,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/appvia/kev/pkg/kev/config"
 	"github.com/appvia/kev/pkg/kev/log"
@@ -42,11 +44,14 @@ import (
 	v1apps "k8s.io/api/apps/v1"
 	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
 	v1batch "k8s.io/api/batch/v1"
+	v1beta1batch "k8s.io/api/batch/v1beta1"
 	v1 "k8s.io/api/core/v1"
 	networking "k8s.io/api/networking/v1"
 	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
@@ -55,10 +60,39 @@ const DefaultIngressBackendKeyword = "default"
 
 // Kubernetes transformer
 type Kubernetes struct {
-	Opt      ConvertOptions     // user provided options from the command line
-	Project  *composego.Project // docker compose project
-	Excluded []string           // docker compose service names that should be excluded
-	UI       kmd.UI
+	Opt         ConvertOptions     // user provided options from the command line
+	Project     *composego.Project // docker compose project
+	Excluded    []string           // docker compose service names that should be excluded
+	SourceFiles map[string]string  // docker compose service names mapped to their source files (optional)
+	Environment string             // name of the environment being rendered, used to expand metadata placeholders
+	KubeVersion string             // target Kubernetes version selecting generated objects apiVersions, DefaultKubeVersion if empty
+	// NodePortRange is the range node ports must be allocated from, DefaultNodePortRange if empty
+	NodePortRange string
+	// WarningAnnotations records service conversion warnings as annotations on the service's objects
+	WarningAnnotations bool
+	UI                 kmd.UI
+
+	warnings []string // conversion warnings of the service being converted
+}
+
+// apiVersion returns the apiVersion of a generated object kind for the target Kubernetes version
+func (k *Kubernetes) apiVersion(kind string) string {
+	return apiVersionFor(kind, k.KubeVersion)
+}
+
+// warnWithFields logs a service conversion warning once and records it for the service's objects annotations
+func (k *Kubernetes) warnWithFields(fields log.Fields, msg string) {
+	if contains(k.warnings, msg) {
+		return
+	}
+
+	log.WarnWithFields(fields, msg)
+	k.warnings = append(k.warnings, msg)
+}
+
+// warnfWithFields logs a formatted service conversion warning, see warnWithFields
+func (k *Kubernetes) warnfWithFields(fields log.Fields, format string, args ...interface{}) {
+	k.warnWithFields(fields, fmt.Sprintf(format, args...))
 }
 
 // Transform converts compose project to set of k8s objects
@@ -91,26 +125,50 @@ func (k *Kubernetes) Transform() ([]runtime.Object, error) {
 	// @step sort project services by name for consistency
 	sortServices(k.Project)
 
-	// @step iterate over sorted service definitions
+	// @step collect services to convert, skipping excluded & disabled ones
+	var projectServices []ProjectService
 	for _, pSvc := range k.Project.Services {
-		// @step skip service if excluded
 		if contains(k.Excluded, pSvc.Name) {
 			continue
 		}
 
-		stepSvc := sg.Add(fmt.Sprintf("Converting service: %s", pSvc.Name))
-		var objects []runtime.Object
-
 		projectService, err := NewProjectService(pSvc)
 		if err != nil {
 			return nil, err
 		}
+		projectService.warn = k.warnWithFields
 
-		// @step skip disabled services
 		if !projectService.enabled() {
+			log.InfoWithFields(log.Fields{
+				"project-service": projectService.Name,
+			}, "Service is disabled and will be skipped")
 			continue
 		}
 
+		if err := k.expandMetadataPlaceholders(&projectService); err != nil {
+			return nil, err
+		}
+
+		projectServices = append(projectServices, projectService)
+	}
+
+	// @step validate services node ports
+	if err := k.validateNodePorts(projectServices); err != nil {
+		return nil, err
+	}
+
+	// @step iterate over sorted service definitions
+	total := len(projectServices)
+	started := time.Now()
+	for i, projectService := range projectServices {
+		pSvc := projectService.ServiceConfig
+		current := i + 1
+		svcStarted := time.Now()
+		stepSvc := sg.Add(fmt.Sprintf("Converting service: %s (%s)", pSvc.Name, progress(current, total, time.Since(started))))
+		var objects []runtime.Object
+		var err error
+		k.warnings = nil
+
 		// @step normalise project service name
 		if rfc1123dns(projectService.Name) != projectService.Name {
 			log.DebugfWithFields(log.Fields{
@@ -134,7 +192,11 @@ func (k *Kubernetes) Transform() ([]runtime.Object, error) {
 
 		// @step create kubernetes object (never create a pod in isolation!)
 		// https://kubernetes.io/docs/concepts/workloads/pods/pod-lifecycle/#pod-lifetime
-		objects = k.createKubernetesObjects(projectService)
+		objects, err = k.createKubernetesObjects(projectService)
+		if err != nil {
+			stepSvc.Error()
+			return nil, err
+		}
 
 		// @step create service / ingress
 		serviceType, err := projectService.serviceType()
@@ -153,7 +215,15 @@ func (k *Kubernetes) Transform() ([]runtime.Object, error) {
 				stepSvc.Error()
 				return nil, errors.Wrapf(err, "%s", msg)
 			}
+
+			// Split grouped ports out of the service into a dedicated service per group
+			groupSvcs, err := k.createPortGroupServices(projectService, svc)
+			if err != nil {
+				stepSvc.Error()
+				return nil, err
+			}
 			objects = append(objects, svc)
+			objects = append(objects, groupSvcs...)
 
 			// For exposed service also create an ingress (Note: only the first port is used for ingress!)
 			expose, err := projectService.exposeService()
@@ -163,7 +233,21 @@ func (k *Kubernetes) Transform() ([]runtime.Object, error) {
 				return nil, errors.Wrapf(err, "%s", msg)
 			}
 			if expose != "" {
-				objects = append(objects, k.initIngress(projectService, svc.Spec.Ports[0].Port))
+				if k.Opt.ExposeAPI == ExposeAPITraefik {
+					objects = append(objects, k.initIngressRoute(projectService, svc.Spec.Ports[0].Port))
+				} else if projectService.legacyIngress() || k.apiVersion("Ingress") != networking.SchemeGroupVersion.String() {
+					objects = append(objects, k.initLegacyIngress(projectService, svc.Spec.Ports[0].Port))
+				} else {
+					objects = append(objects, k.initIngress(projectService, svc.Spec.Ports[0].Port))
+				}
+			}
+
+			// Create an ingress for each expose group, exposing the service on multiple ingress controllers
+			objects = append(objects, k.initExposeGroupIngresses(projectService, svc.Spec.Ports[0].Port)...)
+
+			// Create a Prometheus operator service monitor scraping the service, if enabled
+			if sm := k.initServiceMonitor(projectService); sm != nil {
+				objects = append(objects, sm)
 			}
 		} else if config.ServiceTypesEqual(serviceType, config.HeadlessService) {
 			// No ports defined - creating headless service instead
@@ -178,7 +262,24 @@ func (k *Kubernetes) Transform() ([]runtime.Object, error) {
 			return nil, errors.Wrapf(err, "%s", msg)
 		}
 
-		stepSvc.Success(fmt.Sprintf("Converted service: %s", pSvc.Name))
+		// @step annotate objects with the service conversion warnings
+		if k.WarningAnnotations {
+			if err := annotateWarnings(objects, k.warnings); err != nil {
+				stepSvc.Error()
+				return nil, err
+			}
+		}
+
+		// @step annotate objects with the compose source file the service originated from
+		if file, ok := k.SourceFiles[pSvc.Name]; ok {
+			if err := annotateSourceFile(objects, file); err != nil {
+				stepSvc.Error()
+				return nil, err
+			}
+		}
+
+		stepSvc.Success(fmt.Sprintf("Converted service: %s (%d/%d) in %s",
+			pSvc.Name, current, total, time.Since(svcStarted).Round(time.Millisecond)))
 		for _, object := range objects {
 			k.UI.Output(
 				fmt.Sprintf("rendered %s", object.GetObjectKind().GroupVersionKind().Kind),
@@ -223,7 +324,9 @@ func (k *Kubernetes) Transform() ([]runtime.Object, error) {
 
 	// @step sort all object so Services are first and remove duplicates
 	k.sortServicesFirst(&allobjects)
-	k.removeDupObjects(&allobjects)
+	if err := k.removeDupObjects(&allobjects); err != nil {
+		return nil, err
+	}
 
 	return allobjects, nil
 }
@@ -238,8 +341,8 @@ func (k *Kubernetes) initPodSpec(projectService ProjectService) v1.PodSpec {
 		image = projectService.Name
 	}
 
-	// @step get image pull secret for the pod
-	pullSecret := projectService.imagePullSecret()
+	// @step get image pull secrets for the pod
+	pullSecrets := projectService.imagePullSecrets()
 
 	// @step get service account for the pod
 	serviceAccount := projectService.serviceAccountName()
@@ -258,22 +361,44 @@ func (k *Kubernetes) initPodSpec(projectService ProjectService) v1.PodSpec {
 			},
 		},
 	}
-	if len(command) > 0 {
+	if command != nil {
 		pod.Containers[0].Command = command
 	}
+	if command != nil && len(command) == 0 {
+		k.warnWithFields(log.Fields{
+			"project-service": projectService.Name,
+		}, "Empty entrypoint without a command can't clear the image entrypoint in K8s, the image entrypoint will be used")
+	}
 	if len(commandArgs) > 0 {
 		pod.Containers[0].Args = commandArgs
 	}
-	if pullSecret != "" {
-		pod.ImagePullSecrets = []v1.LocalObjectReference{
-			{
-				Name: pullSecret,
-			},
-		}
+	if workingDir := projectService.WorkingDir; workingDir != "" {
+		pod.Containers[0].WorkingDir = workingDir
+	}
+	pod.Containers[0].Lifecycle = projectService.stopSignalLifecycle()
+
+	// @step keep stdin open and allocate a TTY for interactive services.
+	// Compose stdin_open keeps stdin open across attach sessions, so StdinOnce is left unset.
+	pod.Containers[0].Stdin = projectService.StdinOpen
+	pod.Containers[0].TTY = projectService.Tty
+
+	for _, pullSecret := range pullSecrets {
+		pod.ImagePullSecrets = append(pod.ImagePullSecrets, v1.LocalObjectReference{
+			Name: pullSecret,
+		})
 	}
 	if serviceAccount != "" {
 		pod.ServiceAccountName = serviceAccount
 	}
+	if restartPolicy, err := projectService.restartPolicy(); err == nil {
+		pod.RestartPolicy = restartPolicy
+	}
+
+	// @step configure pod termination grace period
+	pod.TerminationGracePeriodSeconds = projectService.terminationGracePeriod()
+
+	// @step append sidecars, keeping the workload container first
+	pod.Containers = append(pod.Containers, k.configSidecars(projectService)...)
 
 	return pod
 }
@@ -318,7 +443,7 @@ func (k *Kubernetes) initPodSpecWithConfigMap(projectService ProjectService) v1.
 		key, err := k.getConfigMapKeyFromMeta(value.Source)
 		if err != nil {
 			// config is most likely defined as external
-			log.WarnfWithFields(log.Fields{
+			k.warnfWithFields(log.Fields{
 				"project-service": projectService.Name,
 				"config":          value.Source,
 			}, "Cannot parse config: %s", err.Error())
@@ -341,24 +466,26 @@ func (k *Kubernetes) initPodSpecWithConfigMap(projectService ProjectService) v1.
 			VolumeSource: v1.VolumeSource{ConfigMap: &volSource},
 		}
 
-		volumeMounts = append(volumeMounts,
-			v1.VolumeMount{
-				Name:      cmVolName,
-				MountPath: target,
-				SubPath:   subPath,
-			})
+		volumeMount := v1.VolumeMount{
+			Name:      cmVolName,
+			MountPath: target,
+			SubPath:   subPath,
+		}
+
+		// mount the config file directory instead so the config receives ConfigMap updates live
+		if mode, _ := projectService.configMountMode(); mode == ConfigMountModeDirectory {
+			volumeMount.MountPath = filepath.Dir(target)
+			volumeMount.SubPath = ""
+		}
+
+		volumeMounts = append(volumeMounts, volumeMount)
 
 		volumes = append(volumes, cmVol)
 	}
 
 	pod := k.initPodSpec(projectService)
-	pod.Containers = []v1.Container{
-		{
-			Name:         projectService.Name,
-			Image:        projectService.Image,
-			VolumeMounts: volumeMounts,
-		},
-	}
+	pod.Containers[0].Image = projectService.Image
+	pod.Containers[0].VolumeMounts = volumeMounts
 	pod.Volumes = volumes
 
 	return pod
@@ -374,7 +501,7 @@ func (k *Kubernetes) initSvc(projectService ProjectService) *v1.Service {
 		},
 		ObjectMeta: meta.ObjectMeta{
 			Name:   rfc1123label(projectService.Name),
-			Labels: configLabels(projectService.Name),
+			Labels: configObjectLabels(projectService),
 		},
 		Spec: v1.ServiceSpec{
 			Selector: configLabels(projectService.Name),
@@ -392,7 +519,7 @@ func (k *Kubernetes) initConfigMap(projectService ProjectService, configMapName
 		},
 		ObjectMeta: meta.ObjectMeta{
 			Name:   rfc1123dns(configMapName),
-			Labels: configLabels(projectService.Name),
+			Labels: configObjectLabels(projectService),
 		},
 		Data: data,
 	}
@@ -453,7 +580,7 @@ func (k *Kubernetes) initConfigMapFromDir(projectService ProjectService, configM
 		}
 	}
 
-	return k.initConfigMap(projectService, configMapName, dataMap), nil
+	return binaryDataToConfigMapBinaryData(k.initConfigMap(projectService, configMapName, dataMap)), nil
 }
 
 // initConfigMapFromFile initializes a ConfigMap object from a single file
@@ -483,7 +610,7 @@ func (k *Kubernetes) initConfigMapFromFile(projectService ProjectService, fileNa
 		return nil, fmt.Errorf("No config found matching the file name")
 	}
 
-	return k.initConfigMap(projectService, configMapName, dataMap), nil
+	return binaryDataToConfigMapBinaryData(k.initConfigMap(projectService, configMapName, dataMap)), nil
 }
 
 // initDeployment initializes Kubernetes Deployment object
@@ -515,7 +642,7 @@ func (k *Kubernetes) initDeployment(projectService ProjectService) *v1apps.Deplo
 			Template: v1.PodTemplateSpec{
 				ObjectMeta: meta.ObjectMeta{
 					Annotations: configAnnotations(projectService.Labels, projectService.podAnnotations()),
-					Labels:      configLabels(projectService.Name),
+					Labels:      configObjectLabels(projectService),
 				},
 				Spec: podSpec,
 			},
@@ -537,6 +664,8 @@ func (k *Kubernetes) initDeployment(projectService ProjectService) *v1apps.Deplo
 		}, "Set deployment rolling update")
 	}
 
+	k.annotateRollbackConfig(projectService, &dc.ObjectMeta)
+
 	return dc
 }
 
@@ -589,7 +718,7 @@ func (k *Kubernetes) initStatefulSet(projectService ProjectService) *v1apps.Stat
 			Template: v1.PodTemplateSpec{
 				ObjectMeta: meta.ObjectMeta{
 					Annotations: configAnnotations(projectService.Labels, projectService.podAnnotations()),
-					Labels:      configLabels(projectService.Name),
+					Labels:      configObjectLabels(projectService),
 				},
 				Spec: podSpec,
 			},
@@ -601,9 +730,31 @@ func (k *Kubernetes) initStatefulSet(projectService ProjectService) *v1apps.Stat
 		},
 	}
 
+	k.annotateRollbackConfig(projectService, &sts.ObjectMeta)
+
 	return sts
 }
 
+// annotateRollbackConfig records the compose deploy rollback_config of a project service in a workload annotation.
+// Kubernetes has no automated rollbacks so a warning points users at how failed rollouts get rolled back instead.
+func (k *Kubernetes) annotateRollbackConfig(projectService ProjectService, objectMeta *meta.ObjectMeta) {
+	rollback := projectService.rollbackConfig()
+	if rollback == "" {
+		return
+	}
+
+	if objectMeta.Annotations == nil {
+		objectMeta.Annotations = map[string]string{}
+	}
+	objectMeta.Annotations[RollbackConfigAnnotation] = rollback
+
+	k.warnWithFields(log.Fields{
+		"project-service": projectService.Name,
+		"rollback-config": rollback,
+	}, "Kubernetes doesn't roll back failed rollouts automatically, deploy.rollback_config is only recorded in the "+
+		RollbackConfigAnnotation+" annotation. Roll back with `kubectl rollout undo` or a progressive delivery tool instead.")
+}
+
 // initJob initialises a new Kubernetes Job
 func (k *Kubernetes) initJob(projectService ProjectService, replicas int) *v1batch.Job {
 	repl := int32(replicas)
@@ -633,7 +784,7 @@ func (k *Kubernetes) initJob(projectService ProjectService, replicas int) *v1bat
 			Template: v1.PodTemplateSpec{
 				ObjectMeta: meta.ObjectMeta{
 					Annotations: configAnnotations(projectService.Labels, projectService.podAnnotations()),
-					Labels:      configLabels(projectService.Name),
+					Labels:      configObjectLabels(projectService),
 				},
 				Spec: podSpec,
 			},
@@ -643,14 +794,109 @@ func (k *Kubernetes) initJob(projectService ProjectService, replicas int) *v1bat
 	return j
 }
 
+// initCronJob initialises a new Kubernetes CronJob
+// Note: CronJob is served as batch/v1beta1 or batch/v1 depending on the target K8s version.
+func (k *Kubernetes) initCronJob(projectService ProjectService) *v1beta1batch.CronJob {
+	job := k.initJob(projectService, int(projectService.replicas()))
+
+	// Jobs spawned by the CronJob get their selector generated by the controller
+	jobSpec := job.Spec
+	jobSpec.Selector = nil
+
+	return &v1beta1batch.CronJob{
+		TypeMeta: meta.TypeMeta{
+			Kind:       "CronJob",
+			APIVersion: k.apiVersion("CronJob"),
+		},
+		ObjectMeta: meta.ObjectMeta{
+			Name:   projectService.Name,
+			Labels: configAllLabels(projectService),
+		},
+		Spec: v1beta1batch.CronJobSpec{
+			Schedule: projectService.schedule(),
+			JobTemplate: v1beta1batch.JobTemplateSpec{
+				ObjectMeta: meta.ObjectMeta{
+					Labels: configObjectLabels(projectService),
+				},
+				Spec: jobSpec,
+			},
+		},
+	}
+}
+
 // initIngress initialises ingress object
 // @orig: https://github.com/kubernetes/kompose/blob/master/pkg/transformer/kubernetes/kubernetes.go#L446
-// @todo change to networkingv1 after migration to k8s 0.19
-func (k *Kubernetes) initIngress(projectService ProjectService, port int32) *networkingv1beta1.Ingress {
+func (k *Kubernetes) initIngress(projectService ProjectService, port int32) *networking.Ingress {
+	expose, _ := projectService.exposeService()
+	if expose == "" {
+		return nil
+	}
+
+	return k.newIngress(projectService, projectService.Name, expose, projectService.tlsSecretName(), projectService.ingressAnnotations(), port)
+}
+
+// newIngress initialises an ingress object named as given, routing the expose hosts to the project service port
+func (k *Kubernetes) newIngress(projectService ProjectService, name, expose, tlsSecretName string, annotations map[string]string, port int32) *networking.Ingress {
+	hosts := regexp.MustCompile("[ ,]*,[ ,]*").Split(expose, -1)
+
+	ingress := &networking.Ingress{
+		TypeMeta: meta.TypeMeta{
+			Kind:       "Ingress",
+			APIVersion: "networking.k8s.io/v1",
+		},
+		ObjectMeta: meta.ObjectMeta{
+			Name:        name,
+			Labels:      configObjectLabels(projectService),
+			Annotations: annotations,
+		},
+		Spec: networking.IngressSpec{},
+	}
+
+	if hasDefaultIngressBackendKeyword(hosts) {
+		ingress.Spec.DefaultBackend = &networking.IngressBackend{
+			Service: &networking.IngressServiceBackend{
+				Name: projectService.Name,
+				Port: networking.ServiceBackendPort{
+					Number: port,
+				},
+			},
+		}
+		return ingress
+	}
+
+	var ingressRules []networking.IngressRule
+	for _, host := range hosts {
+		host, p := parseIngressPath(host)
+		ingressRules = append(ingressRules, createIngressRule(host, p, projectService.Name, port))
+	}
+	ingress.Spec.Rules = ingressRules
+
+	if tlsSecretName != "" {
+		ingress.Spec.TLS = []networking.IngressTLS{
+			{
+				Hosts:      hosts,
+				SecretName: tlsSecretName,
+			},
+		}
+	}
+
+	return ingress
+}
+
+// initLegacyIngress initialises networking.k8s.io/v1beta1 ingress object for clusters older than K8s 1.19
+// @orig: https://github.com/kubernetes/kompose/blob/master/pkg/transformer/kubernetes/kubernetes.go#L446
+func (k *Kubernetes) initLegacyIngress(projectService ProjectService, port int32) *networkingv1beta1.Ingress {
 	expose, _ := projectService.exposeService()
 	if expose == "" {
 		return nil
 	}
+
+	return k.newLegacyIngress(projectService, projectService.Name, expose, projectService.tlsSecretName(), projectService.ingressAnnotations(), port)
+}
+
+// newLegacyIngress initialises a networking.k8s.io/v1beta1 ingress object named as given,
+// routing the expose hosts to the project service port
+func (k *Kubernetes) newLegacyIngress(projectService ProjectService, name, expose, tlsSecretName string, annotations map[string]string, port int32) *networkingv1beta1.Ingress {
 	hosts := regexp.MustCompile("[ ,]*,[ ,]*").Split(expose, -1)
 
 	ingress := &networkingv1beta1.Ingress{
@@ -659,9 +905,9 @@ func (k *Kubernetes) initIngress(projectService ProjectService, port int32) *net
 			APIVersion: "networking.k8s.io/v1beta1",
 		},
 		ObjectMeta: meta.ObjectMeta{
-			Name:        projectService.Name,
-			Labels:      configLabels(projectService.Name),
-			Annotations: projectService.ingressAnnotations(),
+			Name:        name,
+			Labels:      configObjectLabels(projectService),
+			Annotations: annotations,
 		},
 		Spec: networkingv1beta1.IngressSpec{},
 	}
@@ -679,11 +925,10 @@ func (k *Kubernetes) initIngress(projectService ProjectService, port int32) *net
 	var ingressRules []networkingv1beta1.IngressRule
 	for _, host := range hosts {
 		host, p := parseIngressPath(host)
-		ingressRules = append(ingressRules, createIngressRule(host, p, projectService.Name, port))
+		ingressRules = append(ingressRules, createLegacyIngressRule(host, p, projectService.Name, port))
 	}
 	ingress.Spec.Rules = ingressRules
 
-	tlsSecretName := projectService.tlsSecretName()
 	if tlsSecretName != "" {
 		ingress.Spec.TLS = []networkingv1beta1.IngressTLS{
 			{
@@ -696,12 +941,99 @@ func (k *Kubernetes) initIngress(projectService ProjectService, port int32) *net
 	return ingress
 }
 
+// initExposeGroupIngresses initialises an ingress object for each of the project service expose groups.
+// Each ingress is named after the service and its group, and targets the group ingress class.
+func (k *Kubernetes) initExposeGroupIngresses(projectService ProjectService, port int32) []runtime.Object {
+	groups := projectService.exposeGroups()
+	if len(groups) == 0 {
+		return nil
+	}
+
+	if k.Opt.ExposeAPI == ExposeAPITraefik {
+		k.warnWithFields(log.Fields{
+			"project-service": projectService.Name,
+		}, "Expose groups aren't supported by the Traefik expose API. Their ingresses haven't been created")
+		return nil
+	}
+
+	var objects []runtime.Object
+	for _, group := range groups {
+		name := fmt.Sprintf("%s-%s", projectService.Name, group.Name)
+		annotations := map[string]string{}
+		for key, value := range group.IngressAnnotations {
+			annotations[key] = value
+		}
+
+		var ingressClass *string
+		if group.IngressClass != "" {
+			class := group.IngressClass
+			ingressClass = &class
+		}
+
+		if projectService.legacyIngress() || k.apiVersion("Ingress") != networking.SchemeGroupVersion.String() {
+			ingress := k.newLegacyIngress(projectService, name, group.Domain, group.TlsSecret, annotations, port)
+			ingress.Spec.IngressClassName = ingressClass
+			objects = append(objects, ingress)
+		} else {
+			ingress := k.newIngress(projectService, name, group.Domain, group.TlsSecret, annotations, port)
+			ingress.Spec.IngressClassName = ingressClass
+			objects = append(objects, ingress)
+		}
+	}
+
+	return objects
+}
+
+// initServiceMonitor initialises Prometheus operator ServiceMonitor custom resource scraping
+// the project service metrics. It's rendered as an unstructured object as the Prometheus operator
+// API types aren't part of the K8s API.
+func (k *Kubernetes) initServiceMonitor(projectService ProjectService) *unstructured.Unstructured {
+	if !projectService.monitorEnabled() {
+		return nil
+	}
+
+	port := projectService.monitorPort()
+	if port == "" {
+		k.warnWithFields(log.Fields{
+			"project-service": projectService.Name,
+		}, "Service exposes no ports to scrape metrics from. ServiceMonitor hasn't been created")
+		return nil
+	}
+
+	matchLabels := map[string]interface{}{}
+	for key, value := range configLabels(projectService.Name) {
+		matchLabels[key] = value
+	}
+
+	sm := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"selector": map[string]interface{}{
+					"matchLabels": matchLabels,
+				},
+				"endpoints": []interface{}{
+					map[string]interface{}{
+						"port": port,
+						"path": projectService.monitorPath(),
+					},
+				},
+			},
+		},
+	}
+	sm.SetAPIVersion("monitoring.coreos.com/v1")
+	sm.SetKind("ServiceMonitor")
+	sm.SetName(projectService.Name)
+	sm.SetLabels(configObjectLabels(projectService))
+
+	return sm
+}
+
 // initHpa initialises horizontal pod autoscaler for a project service
 func (k *Kubernetes) initHpa(projectService ProjectService, target runtime.Object) *autoscalingv2beta2.HorizontalPodAutoscaler {
 	t := reflect.ValueOf(target).Elem()
 	typeMeta := t.FieldByName("TypeMeta").Interface().(meta.TypeMeta)
 	if !contains([]string{"Deployment", "StatefulSet"}, typeMeta.Kind) {
-		log.WarnWithFields(log.Fields{
+		k.warnWithFields(log.Fields{
 			"project-service": projectService.Name,
 			"kind":            typeMeta.Kind,
 		}, "Unsupported target kind for Horizontal Pod Autoscaler. Skipping ...")
@@ -726,7 +1058,7 @@ func (k *Kubernetes) initHpa(projectService ProjectService, target runtime.Objec
 
 	// max replicas should be greater than min replicas!
 	if maxRepl > 0 && maxRepl <= replicas {
-		log.WarnWithFields(log.Fields{
+		k.warnWithFields(log.Fields{
 			"project-service":        projectService.Name,
 			"replicas":               replicas,
 			"autoscale-max-replicas": maxRepl,
@@ -766,11 +1098,11 @@ func (k *Kubernetes) initHpa(projectService ProjectService, target runtime.Objec
 	return &autoscalingv2beta2.HorizontalPodAutoscaler{
 		TypeMeta: meta.TypeMeta{
 			Kind:       "HorizontalPodAutoscaler",
-			APIVersion: "autoscaling/v2beta2",
+			APIVersion: k.apiVersion("HorizontalPodAutoscaler"),
 		},
 		ObjectMeta: meta.ObjectMeta{
 			Name:        projectService.Name,
-			Labels:      configLabels(projectService.Name),
+			Labels:      configObjectLabels(projectService),
 			Annotations: configAnnotations(projectService.Labels),
 		},
 		Spec: autoscalingv2beta2.HorizontalPodAutoscalerSpec{
@@ -789,6 +1121,49 @@ func (k *Kubernetes) initHpa(projectService ProjectService, target runtime.Objec
 	}
 }
 
+// initPodDisruptionBudget initialises a pod disruption budget for a project service workload.
+// It returns nil when neither min available nor max unavailable pods have been configured.
+// Note: PodDisruptionBudget is served as policy/v1beta1 by the K8s API version currently supported.
+func (k *Kubernetes) initPodDisruptionBudget(projectService ProjectService) (*policyv1beta1.PodDisruptionBudget, error) {
+	pdb := projectService.podDisruptionBudget()
+
+	if err := pdb.Validate(); err != nil {
+		return nil, errors.Wrapf(err, "project service %s", projectService.Name)
+	}
+
+	if pdb.MinAvailable == "" && pdb.MaxUnavailable == "" {
+		return nil, nil
+	}
+
+	spec := policyv1beta1.PodDisruptionBudgetSpec{
+		Selector: &meta.LabelSelector{
+			MatchLabels: configLabels(projectService.Name),
+		},
+	}
+
+	if pdb.MinAvailable != "" {
+		minAvailable := intstr.Parse(pdb.MinAvailable)
+		spec.MinAvailable = &minAvailable
+	}
+
+	if pdb.MaxUnavailable != "" {
+		maxUnavailable := intstr.Parse(pdb.MaxUnavailable)
+		spec.MaxUnavailable = &maxUnavailable
+	}
+
+	return &policyv1beta1.PodDisruptionBudget{
+		TypeMeta: meta.TypeMeta{
+			Kind:       "PodDisruptionBudget",
+			APIVersion: k.apiVersion("PodDisruptionBudget"),
+		},
+		ObjectMeta: meta.ObjectMeta{
+			Name:   projectService.Name,
+			Labels: configObjectLabels(projectService),
+		},
+		Spec: spec,
+	}, nil
+}
+
 // initServiceAccount initialises Service Account for a project service
 // It only creates the ServiceAccount spec for accounts with name other than `default`
 func (k *Kubernetes) initServiceAccount(projectService ProjectService) *v1.ServiceAccount {
@@ -803,7 +1178,7 @@ func (k *Kubernetes) initServiceAccount(projectService ProjectService) *v1.Servi
 			},
 			ObjectMeta: meta.ObjectMeta{
 				Name:        saname,
-				Labels:      configLabels(projectService.Name),
+				Labels:      configObjectLabels(projectService),
 				Annotations: configAnnotations(projectService.Labels),
 			},
 			AutomountServiceAccountToken: &automountSAToken,
@@ -863,14 +1238,20 @@ func (k *Kubernetes) createPVC(volume Volumes) (*v1.PersistentVolumeClaim, error
 		return nil, err
 	}
 
+	// @step unnamed volumes, e.g. bind mounts, are claimed by the volume mount's claim name
+	name := volume.VolumeName
+	if name == "" {
+		name = volume.PVCName
+	}
+
 	pvc := &v1.PersistentVolumeClaim{
 		TypeMeta: meta.TypeMeta{
 			Kind:       "PersistentVolumeClaim",
 			APIVersion: "v1",
 		},
 		ObjectMeta: meta.ObjectMeta{
-			Name:   volume.VolumeName,
-			Labels: configLabels(volume.VolumeName),
+			Name:   name,
+			Labels: configLabels(name),
 		},
 		Spec: v1.PersistentVolumeClaimSpec{
 			Resources: v1.ResourceRequirements{
@@ -948,7 +1329,7 @@ func (k *Kubernetes) configServicePorts(serviceType config.ServiceType, projectS
 		if _, ok := seenPorts[int(port.Published)]; ok {
 			// https://github.com/kubernetes/kubernetes/issues/2995
 			if config.ServiceTypesEqual(serviceType, config.LoadBalancerService) {
-				log.WarnWithFields(log.Fields{
+				k.warnWithFields(log.Fields{
 					"project-service": projectService.Name,
 					"port":            port.Published,
 				}, "LoadBalancer service type cannot use TCP and UDP for the same port")
@@ -997,24 +1378,33 @@ func (k *Kubernetes) configCapabilities(projectService ProjectService) *v1.Capab
 }
 
 // configTmpfs configure the tmpfs.
+// Each tmpfs entry, e.g. `/run` or `/run:rw,size=64m`, becomes a memory backed EmptyDir volume
+// mounted at the requested path. A size option sets the EmptyDir size limit.
 // @orig: https://github.com/kubernetes/kompose/blob/master/pkg/transformer/kubernetes/kubernetes.go#L664
-func (k *Kubernetes) configTmpfs(projectService ProjectService) ([]v1.VolumeMount, []v1.Volume) {
+func (k *Kubernetes) configTmpfs(projectService ProjectService) ([]v1.VolumeMount, []v1.Volume, error) {
 	volumeMounts := []v1.VolumeMount{}
 	volumes := []v1.Volume{}
 
-	for index, volume := range projectService.Tmpfs {
+	for index, entry := range projectService.Tmpfs {
 		// @step naming volumes if multiple tmpfs are provided
 		volumeName := fmt.Sprintf("%s-tmpfs%d", projectService.Name, index)
-		volume = strings.Split(volume, ":")[0]
+
+		mountPath, sizeLimit, readOnly, err := parseTmpfs(entry)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "service %s tmpfs", projectService.Name)
+		}
+
 		// @step create a new volume mount object and append to list
 		volMount := v1.VolumeMount{
 			Name:      volumeName,
-			MountPath: volume,
+			MountPath: mountPath,
+			ReadOnly:  readOnly,
 		}
 		volumeMounts = append(volumeMounts, volMount)
 
 		// @step create tmpfs specific empty volumes
 		volSource := k.configEmptyVolumeSource("tmpfs")
+		volSource.EmptyDir.SizeLimit = sizeLimit
 
 		// @step create a new volume object using the volsource and add to list
 		vol := v1.Volume{
@@ -1025,7 +1415,7 @@ func (k *Kubernetes) configTmpfs(projectService ProjectService) ([]v1.VolumeMoun
 		volumes = append(volumes, vol)
 	}
 
-	return volumeMounts, volumes
+	return volumeMounts, volumes, nil
 }
 
 // configSecretVolumes config volumes from secret.
@@ -1034,6 +1424,7 @@ func (k *Kubernetes) configTmpfs(projectService ProjectService) ([]v1.VolumeMoun
 // when mount to a container. This is the part that missing in compose. So we will create a single key secret from compose
 // config and the key's name will be the secret's name, it's value is the file content.
 // compose'secret can only be mounted at `/run/secrets`, so we will hardcoded this.
+// Secrets not declared at the project level are skipped, external ones are referenced by their external name.
 // @orig: https://github.com/kubernetes/kompose/blob/master/pkg/transformer/kubernetes/kubernetes.go#L699
 func (k *Kubernetes) configSecretVolumes(projectService ProjectService) ([]v1.VolumeMount, []v1.Volume) {
 	var volumeMounts []v1.VolumeMount
@@ -1041,13 +1432,29 @@ func (k *Kubernetes) configSecretVolumes(projectService ProjectService) ([]v1.Vo
 
 	if len(projectService.Secrets) > 0 {
 		for _, secretConfig := range projectService.Secrets {
+			// @step only mount secrets declared at the project level
+			secret, ok := k.Project.Secrets[secretConfig.Source]
+			if !ok {
+				k.warnWithFields(log.Fields{
+					"project-service": projectService.Name,
+					"secret":          secretConfig.Source,
+				}, "Secret is not declared in the compose project and will not be mounted")
+				continue
+			}
+
+			// @step external secrets are referenced by name, they're expected to exist in the target namespace
+			secretName := secretConfig.Source
+			if secret.External.External && secret.External.Name != "" {
+				secretName = secret.External.Name
+			}
+
 			if secretConfig.UID != "" {
-				log.WarnWithFields(log.Fields{
+				k.warnWithFields(log.Fields{
 					"project-service": projectService.Name,
 				}, "Ignoring `uid` field on compose project service secret")
 			}
 			if secretConfig.GID != "" {
-				log.WarnWithFields(log.Fields{
+				k.warnWithFields(log.Fields{
 					"project-service": projectService.Name,
 				}, "Ignoring `gid` field on compose project service secret")
 			}
@@ -1086,7 +1493,7 @@ func (k *Kubernetes) configSecretVolumes(projectService ProjectService) ([]v1.Vo
 
 			volSource := v1.VolumeSource{
 				Secret: &v1.SecretVolumeSource{
-					SecretName: secretConfig.Source,
+					SecretName: secretName,
 					Items: []v1.KeyToPath{{
 						Key:  secretConfig.Source,
 						Path: itemPath,
@@ -1116,6 +1523,47 @@ func (k *Kubernetes) configSecretVolumes(projectService ProjectService) ([]v1.Vo
 	return volumeMounts, volumes
 }
 
+// setVolumeModes sets the default file mode of the named Secret & ConfigMap volumes,
+// taking precedence over the compose file modes. Volumes without a mode use the K8s default (0644).
+func (k *Kubernetes) setVolumeModes(projectService ProjectService, volumes []v1.Volume, modes map[string]int32) {
+	if len(modes) == 0 {
+		return
+	}
+
+	set := map[string]bool{}
+	for i := range volumes {
+		mode, ok := modes[volumes[i].Name]
+		if !ok {
+			continue
+		}
+
+		m := mode
+		switch {
+		case volumes[i].Secret != nil:
+			volumes[i].Secret.DefaultMode = &m
+			set[volumes[i].Name] = true
+		case volumes[i].ConfigMap != nil:
+			volumes[i].ConfigMap.DefaultMode = &m
+			set[volumes[i].Name] = true
+		}
+	}
+
+	names := make([]string, 0, len(modes))
+	for name := range modes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !set[name] {
+			k.warnfWithFields(log.Fields{
+				"project-service": projectService.Name,
+				"volume":          name,
+			}, "Volume mode is only applicable to Secret & ConfigMap volumes, no such volume named %s", name)
+		}
+	}
+}
+
 // configVolumes configure the container volumes.
 // @orig: https://github.com/kubernetes/kompose/blob/master/pkg/transformer/kubernetes/kubernetes.go#L774
 func (k *Kubernetes) configVolumes(projectService ProjectService) ([]v1.VolumeMount, []v1.Volume, []*v1.PersistentVolumeClaim, []*v1.ConfigMap, error) {
@@ -1150,6 +1598,12 @@ func (k *Kubernetes) configVolumes(projectService ProjectService) ([]v1.VolumeMo
 		// check if ro/rw mode is defined, default rw
 		readonly := len(volume.Mode) > 0 && volume.Mode == "ro"
 
+		// long syntax options are looked up on the compose volume mounted at the same path
+		svcVolume := serviceVolumeByTarget(projectService.Volumes, volume.Container)
+		if svcVolume != nil && svcVolume.ReadOnly {
+			readonly = true
+		}
+
 		if volume.VolumeName == "" {
 			if useEmptyVolumes {
 				volumeName = strings.Replace(volume.PVCName, "claim", "empty", 1)
@@ -1170,6 +1624,14 @@ func (k *Kubernetes) configVolumes(projectService ProjectService) ([]v1.VolumeMo
 			ReadOnly:  readonly,
 			MountPath: volume.Container,
 		}
+		var keyMounts []v1.VolumeMount
+
+		if svcVolume != nil && svcVolume.Volume != nil && svcVolume.Volume.NoCopy {
+			log.DebugWithFields(log.Fields{
+				"project-service": projectService.Name,
+				"volume":          volume.Container,
+			}, "Volume nocopy option is the K8s default, volumes are never populated from the container image")
+		}
 
 		// @ step get a volume source based on the type of volume we are using
 		// For PVC we will also create a PVC object and add to list
@@ -1182,9 +1644,10 @@ func (k *Kubernetes) configVolumes(projectService ProjectService) ([]v1.VolumeMo
 
 			volsource = k.configEmptyVolumeSource("volume")
 		} else if useHostPath {
-			log.DebugWithFields(log.Fields{
+			k.warnWithFields(log.Fields{
 				"project-service": projectService.Name,
-			}, "Use HostPath volume")
+				"host":            volume.Host,
+			}, "HostPath volume exposes the node filesystem to the pod and ties it to the node's data")
 
 			source, err := k.configHostPathVolumeSource(volume.Host)
 			if err != nil {
@@ -1206,7 +1669,26 @@ func (k *Kubernetes) configVolumes(projectService ProjectService) ([]v1.VolumeMo
 			cms = append(cms, cm)
 			volsource = k.configConfigMapVolumeSource(volumeName, volume.Container, cm)
 
-			if useSubPathMount(cm) {
+			mode, _ := projectService.configMountMode()
+			switch {
+			case mode == ConfigMountModeDirectory && useSubPathMount(cm):
+				// mount the file's directory so the file receives ConfigMap updates live
+				k.warnfWithFields(log.Fields{
+					"project-service": projectService.Name,
+					"volume":          volume.Container,
+				}, "Config file mounted in %s mode shadows the contents of its directory %s",
+					ConfigMountModeDirectory, filepath.Dir(volume.Container))
+
+				volMount.MountPath = filepath.Dir(volume.Container)
+			case mode == ConfigMountModeSubPath && !useSubPathMount(cm):
+				// mount each of the directory files in place, keeping the directory contents
+				for _, key := range configMapKeys(cm) {
+					keyMount := volMount
+					keyMount.MountPath = path.Join(volume.Container, key)
+					keyMount.SubPath = key
+					keyMounts = append(keyMounts, keyMount)
+				}
+			case useSubPathMount(cm):
 				volMount.SubPath = volsource.ConfigMap.Items[0].Path
 			}
 
@@ -1218,6 +1700,10 @@ func (k *Kubernetes) configVolumes(projectService ProjectService) ([]v1.VolumeMo
 			volsource = k.configPVCVolumeSource(volumeName, readonly)
 
 			if volume.VFrom == "" {
+				// the claim access mode follows the mount, including long syntax read only mounts
+				if readonly {
+					volume.Mode = "ro"
+				}
 				createdPVC, err := k.createPVC(volume)
 
 				if err != nil {
@@ -1229,7 +1715,11 @@ func (k *Kubernetes) configVolumes(projectService ProjectService) ([]v1.VolumeMo
 			}
 
 		}
-		volumeMounts = append(volumeMounts, volMount)
+		if len(keyMounts) > 0 {
+			volumeMounts = append(volumeMounts, keyMounts...)
+		} else {
+			volumeMounts = append(volumeMounts, volMount)
+		}
 
 		// @step create a new volume object using the volsource and add to list
 		vol := v1.Volume{
@@ -1239,7 +1729,7 @@ func (k *Kubernetes) configVolumes(projectService ProjectService) ([]v1.VolumeMo
 		volumes = append(volumes, vol)
 
 		if len(volume.Host) > 0 && (!useHostPath && !useConfigMap) {
-			log.WarnWithFields(log.Fields{
+			k.warnWithFields(log.Fields{
 				"project-service": projectService.Name,
 				"host":            volume.Host,
 			}, "Volume mount on the host isn't supported. Ignoring path on the host")
@@ -1249,6 +1739,237 @@ func (k *Kubernetes) configVolumes(projectService ProjectService) ([]v1.VolumeMo
 	return volumeMounts, volumes, PVCs, cms, nil
 }
 
+// configInitContainers configures the project service's init containers, sorted by name for determinism.
+// Init containers inherit the workload container's environment variables, overridden by their own,
+// and volume mounts unless configured otherwise.
+func (k *Kubernetes) configInitContainers(projectService ProjectService, envs []v1.EnvVar, volumeMounts []v1.VolumeMount) []v1.Container {
+	configured := projectService.initContainers()
+	if len(configured) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(configured))
+	for name := range configured {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var containers []v1.Container
+	for _, name := range names {
+		cfg := configured[name]
+
+		// @step inherit the workload container env vars, overriding those configured for the init container
+		overrides := EnvSort{}
+		for key, value := range cfg.Env {
+			overrides = append(overrides, v1.EnvVar{Name: key, Value: value})
+		}
+		sort.Sort(overrides)
+
+		var env []v1.EnvVar
+		for _, e := range envs {
+			if _, ok := cfg.Env[e.Name]; !ok {
+				env = append(env, e)
+			}
+		}
+		env = append(env, overrides...)
+
+		container := v1.Container{
+			Name:    rfc1123label(name),
+			Image:   cfg.Image,
+			Command: cfg.Command,
+			Args:    cfg.Args,
+			Env:     env,
+		}
+
+		if cfg.InheritVolumeMounts == nil || *cfg.InheritVolumeMounts {
+			container.VolumeMounts = append([]v1.VolumeMount{}, volumeMounts...)
+		}
+
+		containers = append(containers, container)
+	}
+
+	return containers
+}
+
+// configSidecars configures the project service's sidecar containers, sorted by name for determinism.
+func (k *Kubernetes) configSidecars(projectService ProjectService) []v1.Container {
+	configured := projectService.sidecars()
+	if len(configured) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(configured))
+	for name := range configured {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var containers []v1.Container
+	for _, name := range names {
+		cfg := configured[name]
+
+		container := v1.Container{
+			Name:      rfc1123label(name),
+			Image:     cfg.Image,
+			Command:   cfg.Command,
+			Args:      cfg.Args,
+			Resources: containerResources(cfg.Resource),
+		}
+
+		for _, port := range cfg.Ports {
+			container.Ports = append(container.Ports, v1.ContainerPort{
+				ContainerPort: int32(port),
+				Protocol:      v1.ProtocolTCP,
+			})
+		}
+
+		containers = append(containers, container)
+	}
+
+	return containers
+}
+
+// checkContainerNames errors when containers of a pod share a name, across its init containers,
+// e.g. those waiting for service dependencies, the workload container and sidecars.
+func checkContainerNames(spec v1.PodSpec) error {
+	seen := map[string]bool{}
+	for _, c := range append(append([]v1.Container{}, spec.InitContainers...), spec.Containers...) {
+		if seen[c.Name] {
+			return fmt.Errorf("container %s has the same name as another container in the pod", c.Name)
+		}
+		seen[c.Name] = true
+	}
+	return nil
+}
+
+// containerResources returns container resource requests and limits for the supplied resource configuration
+func containerResources(r config.Resource) v1.ResourceRequirements {
+	requests := v1.ResourceList{}
+	limits := v1.ResourceList{}
+
+	quantities := []struct {
+		list  v1.ResourceList
+		name  v1.ResourceName
+		value string
+	}{
+		{requests, v1.ResourceMemory, r.Memory},
+		{requests, v1.ResourceCPU, r.CPU},
+		{requests, v1.ResourceEphemeralStorage, r.Storage},
+		{limits, v1.ResourceMemory, r.MaxMemory},
+		{limits, v1.ResourceCPU, r.MaxCPU},
+		{limits, v1.ResourceEphemeralStorage, r.MaxStorage},
+	}
+
+	for _, q := range quantities {
+		if q.value == "" {
+			continue
+		}
+		if v, err := resource.ParseQuantity(q.value); err == nil {
+			q.list[q.name] = v
+		}
+	}
+
+	var out v1.ResourceRequirements
+	if len(requests) > 0 {
+		out.Requests = requests
+	}
+	if len(limits) > 0 {
+		out.Limits = limits
+	}
+	return out
+}
+
+// configDependsOn configures the project service dependencies according to its depends_on strategy.
+// It returns annotations recording the dependencies, or init containers waiting for each dependency's
+// service to accept connections. Dependencies without a k8s service to connect to are skipped with a warning.
+func (k *Kubernetes) configDependsOn(projectService ProjectService) (map[string]string, []v1.Container, error) {
+	if len(projectService.DependsOn) == 0 {
+		return nil, nil, nil
+	}
+
+	strategy, err := projectService.dependsOnStrategy()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	deps := append([]string{}, projectService.DependsOn...)
+	sort.Strings(deps)
+
+	switch strategy {
+	case DependsOnNoneStrategy:
+		return nil, nil, nil
+	case DependsOnAnnotationStrategy:
+		var names []string
+		for _, dep := range deps {
+			names = append(names, rfc1123dns(dep))
+		}
+		return map[string]string{DependsOnAnnotation: strings.Join(names, ",")}, nil, nil
+	}
+
+	var containers []v1.Container
+	for _, dep := range deps {
+		depProjectService, err := k.convertedService(dep)
+		if err != nil {
+			return nil, nil, err
+		}
+		if depProjectService == nil {
+			k.warnWithFields(log.Fields{
+				"project-service": projectService.Name,
+				"dependency":      dep,
+			}, "Dependency isn't a rendered project service and won't be waited for. Skipping ...")
+			continue
+		}
+
+		serviceType, err := depProjectService.serviceType()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if !k.portsExist(depProjectService) || config.ServiceTypesEqual(serviceType, config.NoService) ||
+			config.ServiceTypesEqual(serviceType, config.HeadlessService) {
+			k.warnWithFields(log.Fields{
+				"project-service": projectService.Name,
+				"dependency":      dep,
+			}, "Dependency has no k8s service port to wait for. Skipping ...")
+			continue
+		}
+
+		host := rfc1123dns(dep)
+		port := k.configServicePorts(serviceType, depProjectService)[0].Port
+
+		containers = append(containers, v1.Container{
+			Name:  rfc1123dns("wait-for-" + dep),
+			Image: DependsOnWaitImage,
+			Command: []string{
+				"sh",
+				"-c",
+				fmt.Sprintf("until nc -z %s %d; do echo waiting for %s; sleep 2; done", host, port, host),
+			},
+		})
+	}
+
+	return nil, containers, nil
+}
+
+// convertedService returns the project service of the supplied name when it's converted along with the other services,
+// nil when the project doesn't define it or it's excluded or disabled, as no objects get rendered for it
+func (k *Kubernetes) convertedService(name string) (*ProjectService, error) {
+	svc := findByName(k.Project.Services, name)
+	if svc == nil || contains(k.Excluded, svc.Name) {
+		return nil, nil
+	}
+
+	projectService, err := NewProjectService(*svc)
+	if err != nil {
+		return nil, err
+	}
+	if !projectService.enabled() {
+		return nil, nil
+	}
+
+	return &projectService, nil
+}
+
 // configEmptyVolumeSource is a helper function to create an EmptyDir v1.VolumeSource
 // either for Tmpfs or for emptyvolumes
 // @orig: https://github.com/kubernetes/kompose/blob/master/pkg/transformer/kubernetes/kubernetes.go#L894
@@ -1271,14 +1992,9 @@ func (k *Kubernetes) configConfigMapVolumeSource(cmName string, targetPath strin
 	s.Name = cmName
 
 	if useSubPathMount(cm) {
-		var keys []string
 		var key string
 
-		for k := range cm.Data {
-			keys = append(keys, k)
-		}
-
-		if len(keys) > 0 {
+		if keys := configMapKeys(cm); len(keys) > 0 {
 			key = keys[0]
 		}
 
@@ -1394,7 +2110,7 @@ func (k *Kubernetes) configEnvs(projectService ProjectService) ([]v1.EnvVar, err
 					},
 				})
 			} else {
-				log.WarnfWithFields(log.Fields{
+				k.warnfWithFields(log.Fields{
 					"project-service": projectService.Name,
 					"env-var":         k,
 					"path":            thePath,
@@ -1421,7 +2137,7 @@ func (k *Kubernetes) configEnvs(projectService ProjectService) ([]v1.EnvVar, err
 					},
 				})
 			} else {
-				log.WarnfWithFields(log.Fields{
+				k.warnfWithFields(log.Fields{
 					"project-service": projectService.Name,
 					"env-var":         k,
 					"container":       parts[1],
@@ -1461,29 +2177,75 @@ func (k *Kubernetes) configEnvs(projectService ProjectService) ([]v1.EnvVar, err
 
 // createKubernetesObjects generates a Kubernetes object for each input compose project service
 // @orig: https://github.com/kubernetes/kompose/blob/master/pkg/transformer/kubernetes/kubernetes.go#L1020
-func (k *Kubernetes) createKubernetesObjects(projectService ProjectService) []runtime.Object {
+func (k *Kubernetes) createKubernetesObjects(projectService ProjectService) ([]runtime.Object, error) {
 	var objects []runtime.Object
 
 	// @step get workload type
 	workloadType := projectService.workloadType()
 
+	// @step validate the config files mount mode
+	if _, err := projectService.configMountMode(); err != nil {
+		return nil, err
+	}
+
 	// @step create ConfigMap objects for compose project service (external are not supported!)
 	if len(projectService.Configs) > 0 {
 		objects = k.createConfigMapFromComposeConfig(projectService, objects)
 	}
 
 	// @step create object based on inferred / manually configured workload controller type
-	var o runtime.Object
+	// o is the workload eligible for a pod disruption budget & horizontal pod autoscaler,
+	// workload is the object created for the project service whatever its type
+	var o, workload runtime.Object
+	var jobSpec *v1batch.JobSpec
 
 	switch {
 	case config.WorkloadTypesEqual(workloadType, config.DeploymentWorkload):
 		o = k.initDeployment(projectService)
+		workload = o
 		objects = append(objects, o)
 	case config.WorkloadTypesEqual(workloadType, config.StatefulSetWorkload):
 		o = k.initStatefulSet(projectService)
+		workload = o
 		objects = append(objects, o)
 	case config.WorkloadTypesEqual(workloadType, config.DaemonSetWorkload):
-		objects = append(objects, k.initDaemonSet(projectService))
+		workload = k.initDaemonSet(projectService)
+		objects = append(objects, workload)
+	case config.WorkloadTypesEqual(workloadType, config.JobWorkload):
+		// a scheduled Job gets wrapped in a CronJob
+		if projectService.schedule() != "" {
+			cronJob := k.initCronJob(projectService)
+			jobSpec = &cronJob.Spec.JobTemplate.Spec
+			workload = cronJob
+			objects = append(objects, cronJob)
+		} else {
+			job := k.initJob(projectService, int(projectService.replicas()))
+			jobSpec = &job.Spec
+			workload = job
+			objects = append(objects, job)
+		}
+	case config.WorkloadTypesEqual(workloadType, config.CronJobWorkload):
+		cronJob := k.initCronJob(projectService)
+		jobSpec = &cronJob.Spec.JobTemplate.Spec
+		workload = cronJob
+		objects = append(objects, cronJob)
+	}
+
+	// @step set the backoff limit and time to live of Jobs, if configured
+	if jobSpec != nil {
+		jobSpec.BackoffLimit = projectService.backoffLimit()
+		jobSpec.TTLSecondsAfterFinished = projectService.ttlSecondsAfterFinished()
+	}
+
+	// @step create a pod disruption budget for eligible objects
+	if o != nil {
+		pdb, err := k.initPodDisruptionBudget(projectService)
+		if err != nil {
+			return nil, err
+		}
+		if pdb != nil {
+			objects = append(objects, pdb)
+		}
 	}
 
 	// @step create a horizontal pod autoscaler for eligible objects
@@ -1494,12 +2256,23 @@ func (k *Kubernetes) createKubernetesObjects(projectService ProjectService) []ru
 		}
 	}
 
+	// @step create a vertical pod autoscaler for the workload, if enabled
+	if workload != nil {
+		vpa, err := k.initVpa(projectService, workload)
+		if err != nil {
+			return nil, err
+		}
+		if vpa != nil {
+			objects = append(objects, vpa)
+		}
+	}
+
 	// @step create a Service Account if speficied
 	if sa := k.initServiceAccount(projectService); sa != nil {
 		objects = append(objects, sa)
 	}
 
-	return objects
+	return objects, nil
 }
 
 // createConfigMapFromComposeConfig will create ConfigMap objects for each non-external config
@@ -1510,7 +2283,7 @@ func (k *Kubernetes) createConfigMapFromComposeConfig(projectService ProjectServ
 		currentConfigObj := k.Project.Configs[currentConfigName]
 
 		if currentConfigObj.External.External {
-			log.WarnWithFields(log.Fields{
+			k.warnWithFields(log.Fields{
 				"project-service": projectService.Name,
 				"config-name":     currentConfigName,
 			}, "Your deployment expects configmap to exist in the target K8s cluster namespace.")
@@ -1545,7 +2318,7 @@ func (k *Kubernetes) initPod(projectService ProjectService) *v1.Pod {
 		},
 		ObjectMeta: meta.ObjectMeta{
 			Name:        projectService.Name,
-			Labels:      configLabels(projectService.Name),
+			Labels:      configObjectLabels(projectService),
 			Annotations: configAnnotations(projectService.Labels, projectService.podAnnotations()),
 		},
 		Spec: k.initPodSpec(projectService),
@@ -1594,24 +2367,37 @@ func (k *Kubernetes) updateController(obj runtime.Object, updateTemplate func(*v
 			log.Error("Unable to update Deployment template")
 			return err
 		}
+		k.enforceRestartPolicy("Deployment", t.Name, &t.Spec.Template.Spec, v1.RestartPolicyAlways)
 		updateMeta(&t.ObjectMeta)
 	case *v1apps.StatefulSet:
 		if err = updateTemplate(&t.Spec.Template); err != nil {
 			log.Error("Unable to update StatefulSet template")
 			return err
 		}
+		k.enforceRestartPolicy("StatefulSet", t.Name, &t.Spec.Template.Spec, v1.RestartPolicyAlways)
 		updateMeta(&t.ObjectMeta)
 	case *v1apps.DaemonSet:
 		if err = updateTemplate(&t.Spec.Template); err != nil {
 			log.Error("Unable to update DaemonSet template")
 			return err
 		}
+		k.enforceRestartPolicy("DaemonSet", t.Name, &t.Spec.Template.Spec, v1.RestartPolicyAlways)
 		updateMeta(&t.ObjectMeta)
 	case *v1batch.Job:
 		if err = updateTemplate(&t.Spec.Template); err != nil {
 			log.Error("Unable to update Job template")
 			return err
 		}
+		// Jobs only support OnFailure & Never restart policies
+		k.enforceRestartPolicy("Job", t.Name, &t.Spec.Template.Spec, v1.RestartPolicyOnFailure, v1.RestartPolicyNever)
+		updateMeta(&t.ObjectMeta)
+	case *v1beta1batch.CronJob:
+		template := &t.Spec.JobTemplate.Spec.Template
+		if err = updateTemplate(template); err != nil {
+			log.Error("Unable to update CronJob template")
+			return err
+		}
+		k.enforceRestartPolicy("CronJob", t.Name, &template.Spec, v1.RestartPolicyOnFailure, v1.RestartPolicyNever)
 		updateMeta(&t.ObjectMeta)
 	case *v1.Pod:
 		p := v1.PodTemplateSpec{
@@ -1655,11 +2441,97 @@ func (k *Kubernetes) createService(serviceType config.ServiceType, projectServic
 		svc.Spec.Type = v1SvcType
 	}
 
-	svc.ObjectMeta.Annotations = configAnnotations(projectService.Labels)
+	// @step configure the load balancer IP if requested, otherwise it's left for the cloud provider to assign
+	if lbIP := projectService.loadBalancerIP(); lbIP != "" {
+		if config.ServiceTypesEqual(serviceType, config.LoadBalancerService) {
+			svc.Spec.LoadBalancerIP = lbIP
+		} else {
+			k.warnWithFields(log.Fields{
+				"project-service": projectService.Name,
+				"service-type":    serviceType.String(),
+			}, "Load balancer IP is only applicable to LoadBalancer service type. Skipping ...")
+		}
+	}
+
+	// @step configure client session stickiness if requested, otherwise k8s defaults to no session affinity
+	switch affinity := projectService.sessionAffinity(); affinity {
+	case "":
+	case config.SessionAffinityNone:
+		svc.Spec.SessionAffinity = v1.ServiceAffinityNone
+	case config.SessionAffinityClientIP:
+		svc.Spec.SessionAffinity = v1.ServiceAffinityClientIP
+		if timeout := projectService.sessionAffinityTimeout(); timeout > 0 {
+			svc.Spec.SessionAffinityConfig = &v1.SessionAffinityConfig{
+				ClientIP: &v1.ClientIPConfig{
+					TimeoutSeconds: &timeout,
+				},
+			}
+		}
+	default:
+		return nil, fmt.Errorf("`%s` service session affinity `%s` not supported", projectService.Name, affinity)
+	}
+
+	svc.ObjectMeta.Annotations = configAnnotations(projectService.Labels, projectService.serviceAnnotations())
 
 	return svc, nil
 }
 
+// createPortGroupServices moves the ports of each port group out of the service into a dedicated ClusterIP service
+// named after the service and the group. All services select the same pods. Ports are matched on their service port.
+func (k *Kubernetes) createPortGroupServices(projectService ProjectService, svc *v1.Service) ([]runtime.Object, error) {
+	groups := projectService.portGroups()
+	if len(groups) == 0 {
+		return nil, nil
+	}
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var objects []runtime.Object
+	for _, name := range names {
+		inGroup := map[int32]bool{}
+		for _, p := range groups[name] {
+			inGroup[int32(p)] = true
+		}
+
+		var grouped, remaining []v1.ServicePort
+		for _, p := range svc.Spec.Ports {
+			if inGroup[p.Port] {
+				p.NodePort = 0
+				grouped = append(grouped, p)
+			} else {
+				remaining = append(remaining, p)
+			}
+		}
+
+		if len(grouped) == 0 {
+			k.warnWithFields(log.Fields{
+				"project-service": projectService.Name,
+				"port-group":      name,
+			}, "Port group doesn't match any service port. Skipping ...")
+			continue
+		}
+
+		groupSvc := k.initSvc(projectService)
+		groupSvc.Name = rfc1123label(fmt.Sprintf("%s-%s", projectService.Name, name))
+		groupSvc.Spec.Type = v1.ServiceTypeClusterIP
+		groupSvc.Spec.Ports = grouped
+		groupSvc.ObjectMeta.Annotations = configAnnotations(projectService.Labels, projectService.serviceAnnotations())
+
+		svc.Spec.Ports = remaining
+		objects = append(objects, groupSvc)
+	}
+
+	if len(svc.Spec.Ports) == 0 {
+		return nil, fmt.Errorf("`%s` service port groups must leave at least one port in the service", projectService.Name)
+	}
+
+	return objects, nil
+}
+
 // createHeadlessService creates a k8s headless service.
 // This is used for docker-compose services without ports. For such services we can't create regular Kubernetes Service.
 // and without Service Pods can't find each other using DNS names.
@@ -1703,7 +2575,11 @@ func (k *Kubernetes) updateKubernetesObjects(projectService ProjectService, obje
 
 	// @step configure Tmpfs
 	if len(projectService.Tmpfs) > 0 {
-		TmpVolumesMount, TmpVolumes := k.configTmpfs(projectService)
+		TmpVolumesMount, TmpVolumes, err := k.configTmpfs(projectService)
+		if err != nil {
+			log.Error("Unable to configure tmpfs volumes")
+			return err
+		}
 		volumes = append(volumes, TmpVolumes...)
 		volumesMounts = append(volumesMounts, TmpVolumesMount...)
 	}
@@ -1727,8 +2603,26 @@ func (k *Kubernetes) updateKubernetesObjects(projectService ProjectService, obje
 	// @step configure capabilities
 	capabilities := k.configCapabilities(projectService)
 
+	// @step configure service dependencies
+	dependsOnAnnotations, initContainers, err := k.configDependsOn(projectService)
+	if err != nil {
+		log.ErrorWithFields(log.Fields{
+			"project-service": projectService.Name,
+		}, "Unable to configure service dependencies")
+		return err
+	}
+
+	// @step configure init containers, run after those waiting for service dependencies
+	initContainers = append(initContainers, k.configInitContainers(projectService, envs, volumesMounts)...)
+
 	// @step configure annotations
-	annotations := configAnnotations(projectService.Labels)
+	annotations := configAnnotations(projectService.Labels, dependsOnAnnotations)
+
+	// @step configure Secret & ConfigMap volumes file modes
+	volumeModes, err := projectService.volumeModes()
+	if err != nil {
+		return err
+	}
 
 	// @step fillTemplate function will fill the pod template with the values calculated from config
 	fillTemplate := func(template *v1.PodTemplateSpec) error {
@@ -1738,12 +2632,37 @@ func (k *Kubernetes) updateKubernetesObjects(projectService ProjectService, obje
 		template.Spec.Containers[0].Env = envs
 		template.Spec.Containers[0].Command = projectService.command()
 		template.Spec.Containers[0].Args = projectService.commandArgs()
-		template.Spec.Containers[0].WorkingDir = projectService.WorkingDir
 		template.Spec.Containers[0].VolumeMounts = append(template.Spec.Containers[0].VolumeMounts, volumesMounts...)
-		template.Spec.Containers[0].Stdin = projectService.StdinOpen
-		template.Spec.Containers[0].TTY = projectService.Tty
+		template.Spec.Containers[0].TerminationMessagePolicy = projectService.terminationMessagePolicy()
+		template.Spec.Containers[0].TerminationMessagePath = projectService.terminationMessagePath()
+		for i := range template.Spec.Containers[1:] {
+			// @step share the pod's volumes with sidecars
+			template.Spec.Containers[i+1].VolumeMounts = append(template.Spec.Containers[i+1].VolumeMounts, volumesMounts...)
+		}
 		template.Spec.Volumes = append(template.Spec.Volumes, volumes...)
+		k.setVolumeModes(projectService, template.Spec.Volumes, volumeModes)
+		template.Spec.InitContainers = append(template.Spec.InitContainers, initContainers...)
+		if err := checkContainerNames(template.Spec); err != nil {
+			log.ErrorWithFields(log.Fields{
+				"project-service": projectService.Name,
+			}, "Container names must be unique within the pod")
+
+			return err
+		}
 		template.Spec.NodeSelector = projectService.placement()
+		template.Spec.Affinity = projectService.affinity()
+		template.Spec.TopologySpreadConstraints = projectService.topologySpreadConstraints()
+
+		// @step configure tolerations allowing pods to be scheduled onto tainted nodes
+		tolerations, err := projectService.tolerations()
+		if err != nil {
+			log.ErrorWithFields(log.Fields{
+				"project-service": projectService.Name,
+			}, "Tolerations definition has errors")
+
+			return err
+		}
+		template.Spec.Tolerations = tolerations
 
 		// @step configure the HealthCheck
 		healthCheck, err := projectService.LivenessProbe()
@@ -1772,19 +2691,18 @@ func (k *Kubernetes) updateKubernetesObjects(projectService ProjectService, obje
 			template.Spec.Containers[0].ReadinessProbe = readinessProbe
 		}
 
-		// @step configure pod termination grace priod
-		if projectService.StopGracePeriod != nil && len(projectService.StopGracePeriod.String()) > 0 {
-			sgp, err := durationStrToSecondsInt(projectService.StopGracePeriod.String())
-			if err != nil {
-				log.ErrorWithFields(log.Fields{
-					"project-service": projectService.Name,
-					"duration":        projectService.StopGracePeriod.String(),
-				}, "Failed to parse pod termination grace period duration")
+		// @step configure startup probe
+		// Note: This is not covered by the docker compose spec
+		startupProbe, err := projectService.StartupProbe()
+		if err != nil {
+			log.ErrorWithFields(log.Fields{
+				"project-service": projectService.Name,
+			}, "Startup probe definition has errors")
 
-				return err
-			}
-			gracePeriod := int64(*sgp)
-			template.Spec.TerminationGracePeriodSeconds = &gracePeriod
+			return err
+		}
+		if startupProbe != nil {
+			template.Spec.Containers[0].StartupProbe = startupProbe
 		}
 
 		// @step configure pod resource requests and limits
@@ -1866,17 +2784,22 @@ func (k *Kubernetes) updateKubernetesObjects(projectService ProjectService, obje
 // sortServicesFirst - sorts the objects so that services are first
 // according to best practice kubernetes services should be created first
 // http://kubernetes.io/docs/user-guide/config-best-practices/
+// Namespaces are kept ahead of services as they must exist before the objects they hold.
 // @orig: https://github.com/kubernetes/kompose/blob/master/pkg/transformer/kubernetes/k8sutils.go#L661
 func (k *Kubernetes) sortServicesFirst(objs *[]runtime.Object) {
-	var svc, others, ret []runtime.Object
+	var ns, svc, others, ret []runtime.Object
 
 	for _, obj := range *objs {
-		if obj.GetObjectKind().GroupVersionKind().Kind == "Service" {
+		switch obj.GetObjectKind().GroupVersionKind().Kind {
+		case "Namespace":
+			ns = append(ns, obj)
+		case "Service":
 			svc = append(svc, obj)
-		} else {
+		default:
 			others = append(others, obj)
 		}
 	}
+	ret = append(ret, ns...)
 	ret = append(ret, svc...)
 	ret = append(ret, others...)
 
@@ -1884,15 +2807,21 @@ func (k *Kubernetes) sortServicesFirst(objs *[]runtime.Object) {
 }
 
 // removeDupObjects removes duplicate objects...
+// ConfigMaps sharing a name get their data merged into the first one, it errors when the same key has conflicting values
+// or is held as data in one and binary data in the other.
 // @orig: https://github.com/kubernetes/kompose/blob/master/pkg/transformer/kubernetes/k8sutils.go#L679
-func (k *Kubernetes) removeDupObjects(objs *[]runtime.Object) {
+func (k *Kubernetes) removeDupObjects(objs *[]runtime.Object) error {
 	var result []runtime.Object
-	exist := map[string]bool{}
+	exist := map[string]runtime.Object{}
 
 	for _, obj := range *objs {
 		if us, ok := obj.(meta.Object); ok {
 			k := obj.GetObjectKind().GroupVersionKind().String() + us.GetNamespace() + us.GetName()
-			if exist[k] {
+			if existing, ok := exist[k]; ok {
+				if err := mergeDupConfigMaps(existing, obj); err != nil {
+					return err
+				}
+
 				log.DebugfWithFields(log.Fields{
 					"configmap": us.GetName(),
 				}, "Remove duplicate resource: %s/%s", obj.GetObjectKind().GroupVersionKind().Kind, us.GetName())
@@ -1900,7 +2829,7 @@ func (k *Kubernetes) removeDupObjects(objs *[]runtime.Object) {
 				continue
 			} else {
 				result = append(result, obj)
-				exist[k] = true
+				exist[k] = obj
 			}
 		} else {
 			result = append(result, obj)
@@ -1908,6 +2837,58 @@ func (k *Kubernetes) removeDupObjects(objs *[]runtime.Object) {
 	}
 
 	*objs = result
+	return nil
+}
+
+// mergeDupConfigMaps merges the data of a duplicate ConfigMap into the ConfigMap of the same name kept in the output.
+// It's a no-op for any other objects.
+func mergeDupConfigMaps(kept, dup runtime.Object) error {
+	keptCm, ok := kept.(*v1.ConfigMap)
+	if !ok {
+		return nil
+	}
+	dupCm, ok := dup.(*v1.ConfigMap)
+	if !ok {
+		return nil
+	}
+
+	for key, value := range dupCm.Data {
+		if existing, ok := keptCm.Data[key]; ok && existing != value {
+			return fmt.Errorf("ConfigMap %s key %s has conflicting values", keptCm.Name, key)
+		}
+	}
+	for key, value := range dupCm.BinaryData {
+		if existing, ok := keptCm.BinaryData[key]; ok && !bytes.Equal(existing, value) {
+			return fmt.Errorf("ConfigMap %s key %s has conflicting values", keptCm.Name, key)
+		}
+	}
+
+	// a key can't be held in both data and binary data of a ConfigMap
+	for key := range dupCm.Data {
+		if _, ok := keptCm.BinaryData[key]; ok {
+			return fmt.Errorf("ConfigMap %s key %s is set in both data and binary data", keptCm.Name, key)
+		}
+	}
+	for key := range dupCm.BinaryData {
+		if _, ok := keptCm.Data[key]; ok {
+			return fmt.Errorf("ConfigMap %s key %s is set in both data and binary data", keptCm.Name, key)
+		}
+	}
+
+	for key, value := range dupCm.Data {
+		if keptCm.Data == nil {
+			keptCm.Data = map[string]string{}
+		}
+		keptCm.Data[key] = value
+	}
+	for key, value := range dupCm.BinaryData {
+		if keptCm.BinaryData == nil {
+			keptCm.BinaryData = map[string][]byte{}
+		}
+		keptCm.BinaryData[key] = value
+	}
+
+	return nil
 }
 
 // setPodResources configures pod resources
@@ -1967,6 +2948,15 @@ func (k *Kubernetes) setPodSecurityContext(projectService ProjectService, podSec
 	// @step set FsGroup
 	podSecurityContext.FSGroup = projectService.fsGroup()
 
+	// @step set FSGroupChangePolicy
+	podSecurityContext.FSGroupChangePolicy = projectService.fsGroupChangePolicy()
+
+	// @step set RunAsNonRoot
+	podSecurityContext.RunAsNonRoot = projectService.runAsNonRoot()
+
+	// @step set SeccompProfile
+	podSecurityContext.SeccompProfile = projectService.seccompProfile()
+
 	// @step set supplementalGroups
 	if projectService.GroupAdd != nil {
 		var groups []int64
@@ -1974,7 +2964,7 @@ func (k *Kubernetes) setPodSecurityContext(projectService ProjectService, podSec
 		for _, g := range projectService.GroupAdd {
 			gid, err := strconv.ParseInt(g, 10, 64)
 			if err != nil {
-				log.WarnWithFields(log.Fields{
+				k.warnWithFields(log.Fields{
 					"project-service":    projectService.Name,
 					"supplemental-group": g,
 				}, "Ignoring supplemental group as it's not numeric. Supplemental groups must be specified as a GID (numeric).")
@@ -1991,13 +2981,17 @@ func (k *Kubernetes) setSecurityContext(projectService ProjectService, capabilit
 	// @step set Privileged
 	if projectService.Privileged {
 		securityContext.Privileged = &projectService.Privileged
+
+		k.warnWithFields(log.Fields{
+			"project-service": projectService.Name,
+		}, "Privileged container has full access to the node")
 	}
 
 	// @step set RunAsUser
 	if projectService.User != "" {
 		uid, err := strconv.ParseInt(projectService.User, 10, 64)
 		if err != nil {
-			log.WarnWithFields(log.Fields{
+			k.warnWithFields(log.Fields{
 				"project-service": projectService.Name,
 				"user":            projectService.User,
 			}, "Ignoring `user` directive value. User must be specified as a UID (numeric).")
@@ -2006,8 +3000,52 @@ func (k *Kubernetes) setSecurityContext(projectService ProjectService, capabilit
 		}
 	}
 
+	// @step set ReadOnlyRootFilesystem
+	securityContext.ReadOnlyRootFilesystem = projectService.readOnlyRootFilesystem()
+
+	// @step set AllowPrivilegeEscalation
+	securityContext.AllowPrivilegeEscalation = projectService.allowPrivilegeEscalation()
+
 	// @step set capabilities if specified
 	if len(capabilities.Add) > 0 || len(capabilities.Drop) > 0 {
 		securityContext.Capabilities = capabilities
 	}
 }
+
+// expandMetadataPlaceholders expands the ${service}, ${env} & ${project} placeholders
+// in a project service's extra labels and annotations.
+func (k *Kubernetes) expandMetadataPlaceholders(projectService *ProjectService) error {
+	vars := map[string]string{
+		"service": projectService.Name,
+		"env":     k.Environment,
+		"project": k.Project.Name,
+	}
+
+	var err error
+	workload := &projectService.SvcK8sConfig.Workload
+	if workload.Annotations, err = expandPlaceholdersInMap(workload.Annotations, vars); err != nil {
+		return errors.Wrapf(err, "service %s workload annotations", projectService.Name)
+	}
+
+	service := &projectService.SvcK8sConfig.Service
+	if service.Annotations, err = expandPlaceholdersInMap(service.Annotations, vars); err != nil {
+		return errors.Wrapf(err, "service %s service annotations", projectService.Name)
+	}
+	if service.Expose.IngressAnnotations, err = expandPlaceholdersInMap(service.Expose.IngressAnnotations, vars); err != nil {
+		return errors.Wrapf(err, "service %s ingress annotations", projectService.Name)
+	}
+
+	if projectService.Deploy != nil && projectService.Deploy.Labels != nil {
+		labels, err := expandPlaceholdersInMap(projectService.Deploy.Labels, vars)
+		if err != nil {
+			return errors.Wrapf(err, "service %s deploy labels", projectService.Name)
+		}
+
+		// copy deploy config to leave the compose project untouched
+		deploy := *projectService.Deploy
+		deploy.Labels = labels
+		projectService.Deploy = &deploy
+	}
+
+	return nil
+}
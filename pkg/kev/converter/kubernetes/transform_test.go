@@ -17,11 +17,13 @@
 package kubernetes
 
 import (
+	"encoding/json"
 	"fmt"
 	"path"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/appvia/kev/pkg/kev/config"
 	kmd "github.com/appvia/komando"
@@ -30,13 +32,17 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/sirupsen/logrus"
 	v1apps "k8s.io/api/apps/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
 	v1batch "k8s.io/api/batch/v1"
+	v1beta1batch "k8s.io/api/batch/v1beta1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/api/extensions/v1beta1"
 	networking "k8s.io/api/networking/v1"
 	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
@@ -103,6 +109,87 @@ var _ = Describe("Transform", func() {
 			})
 
 		})
+
+		When("service is disabled via extension", func() {
+
+			BeforeEach(func() {
+				excluded = []string{}
+				projectService.Extensions = map[string]interface{}{
+					config.K8SExtensionKey: map[string]interface{}{"disabled": true},
+				}
+			})
+
+			It("doesn't include kubernetes objects for the project service", func() {
+				objs, err := k.Transform()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(objs).To(BeEmpty())
+			})
+		})
+
+		When("service is labelled to skip render", func() {
+
+			BeforeEach(func() {
+				excluded = []string{}
+				projectService.Labels = composego.Labels{SkipRenderLabel: "true"}
+			})
+
+			It("doesn't include kubernetes objects for the project service", func() {
+				objs, err := k.Transform()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(objs).To(BeEmpty())
+			})
+		})
+
+		When("service conversion raises warnings", func() {
+
+			BeforeEach(func() {
+				excluded = []string{}
+				projectService.Privileged = true
+			})
+
+			It("annotates the service objects with the warnings when enabled", func() {
+				k.WarningAnnotations = true
+
+				objs, err := k.Transform()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(objs).To(HaveLen(1))
+
+				u, err := ToUnstructured(objs[0])
+				Expect(err).NotTo(HaveOccurred())
+
+				annotations := u["metadata"].(map[string]interface{})["annotations"]
+				Expect(annotations).To(HaveKeyWithValue(WarningAnnotationPrefix+"1", "Privileged container has full access to the node"))
+			})
+
+			It("annotates the service objects with the warnings raised by the project service", func() {
+				k.WarningAnnotations = true
+				projectService.Deploy = &composego.DeployConfig{
+					Placement: composego.Placement{Constraints: []string{"invalid==value"}},
+				}
+				k.Project.Services = composego.Services{projectService.ServiceConfig}
+
+				objs, err := k.Transform()
+				Expect(err).NotTo(HaveOccurred())
+
+				u, err := ToUnstructured(objs[0])
+				Expect(err).NotTo(HaveOccurred())
+
+				annotations := u["metadata"].(map[string]interface{})["annotations"]
+				Expect(annotations).To(ContainElement(HavePrefix("Constraint in placement is not supported")))
+			})
+
+			It("doesn't annotate the service objects with the warnings by default", func() {
+				objs, err := k.Transform()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(objs).To(HaveLen(1))
+
+				u, err := ToUnstructured(objs[0])
+				Expect(err).NotTo(HaveOccurred())
+
+				annotations, _ := u["metadata"].(map[string]interface{})["annotations"].(map[string]interface{})
+				Expect(annotations).NotTo(HaveKey(WarningAnnotationPrefix + "1"))
+			})
+		})
 	})
 
 	Describe("initPodSpec", func() {
@@ -126,6 +213,7 @@ var _ = Describe("Transform", func() {
 						},
 					},
 					ServiceAccountName: "default",
+					RestartPolicy:      v1.RestartPolicyAlways,
 				}))
 			})
 		})
@@ -152,6 +240,31 @@ var _ = Describe("Transform", func() {
 			})
 		})
 
+		Context("with multiple image pull secrets specified via an extension", func() {
+			BeforeEach(func() {
+				svcK8sConfig := config.DefaultSvcK8sConfig()
+				svcK8sConfig.Workload.ImagePull.Secret = "my-pp-secret, other-secret"
+
+				m, err := svcK8sConfig.Map()
+				Expect(err).NotTo(HaveOccurred())
+
+				projectService.Extensions = map[string]interface{}{
+					config.K8SExtensionKey: m,
+				}
+
+				projectService, err = NewProjectService(projectService.ServiceConfig)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("references each image pull secret in the spec", func() {
+				spec := k.initPodSpec(projectService)
+				Expect(spec.ImagePullSecrets).To(Equal([]v1.LocalObjectReference{
+					{Name: "my-pp-secret"},
+					{Name: "other-secret"},
+				}))
+			})
+		})
+
 		Context("with service account name supplied via an extension", func() {
 			BeforeEach(func() {
 				svcK8sConfig := config.DefaultSvcK8sConfig()
@@ -217,6 +330,50 @@ var _ = Describe("Transform", func() {
 				})
 			})
 
+			When("both entrypoint and command specified via project service spec", func() {
+				BeforeEach(func() {
+					projectService.Entrypoint = []string{"/bin/sh", "-c"}
+					projectService.Command = []string{"echo hi"}
+				})
+
+				It("uses entrypoint as container command and command as container args", func() {
+					spec := k.initPodSpec(projectService)
+					Expect(spec.Containers[0].Command).To(Equal([]string{"/bin/sh", "-c"}))
+					Expect(spec.Containers[0].Args).To(Equal([]string{"echo hi"}))
+				})
+			})
+
+			When("entrypoint is explicitly empty in project service spec", func() {
+				BeforeEach(func() {
+					projectService.Entrypoint = []string{}
+				})
+
+				It("sets an empty container command", func() {
+					spec := k.initPodSpec(projectService)
+					Expect(spec.Containers[0].Command).NotTo(BeNil())
+					Expect(spec.Containers[0].Command).To(BeEmpty())
+				})
+
+				It("runs the project service spec command directly as container command", func() {
+					projectService.Command = []string{"/app/server", "--port", "8080"}
+
+					spec := k.initPodSpec(projectService)
+					Expect(spec.Containers[0].Command).To(Equal([]string{"/app/server", "--port", "8080"}))
+					Expect(spec.Containers[0].Args).To(BeNil())
+				})
+			})
+
+			When("entrypoint is absent from project service spec", func() {
+				It("leaves the container command nil", func() {
+					projectService.Entrypoint = nil
+					projectService.Command = []string{"echo hi"}
+
+					spec := k.initPodSpec(projectService)
+					Expect(spec.Containers[0].Command).To(BeNil())
+					Expect(spec.Containers[0].Args).To(Equal([]string{"echo hi"}))
+				})
+			})
+
 			When("command not specified in config extension nor in project service spec", func() {
 				It("doesn't set up container command", func() {
 					spec := k.initPodSpec(projectService)
@@ -265,6 +422,48 @@ var _ = Describe("Transform", func() {
 			})
 		})
 
+		Context("with working dir specified in project service spec", func() {
+			It("uses the working dir for the container", func() {
+				projectService.WorkingDir = "/app"
+				Expect(k.initPodSpec(projectService).Containers[0].WorkingDir).To(Equal("/app"))
+			})
+
+			It("leaves the container working dir blank when empty", func() {
+				projectService.WorkingDir = ""
+				Expect(k.initPodSpec(projectService).Containers[0].WorkingDir).To(BeEmpty())
+			})
+		})
+
+		Context("with stop signal specified in project service spec", func() {
+			It("sends the stop signal to the container's main process before it's stopped", func() {
+				projectService.StopSignal = "SIGQUIT"
+				Expect(k.initPodSpec(projectService).Containers[0].Lifecycle).To(Equal(&v1.Lifecycle{
+					PreStop: &v1.Handler{
+						Exec: &v1.ExecAction{
+							Command: []string{"/bin/sh", "-c", "kill -QUIT 1"},
+						},
+					},
+				}))
+			})
+
+			It("doesn't configure a container lifecycle for SIGTERM", func() {
+				projectService.StopSignal = "SIGTERM"
+				Expect(k.initPodSpec(projectService).Containers[0].Lifecycle).To(BeNil())
+			})
+		})
+
+		Context("with tty and stdin_open specified in project service spec", func() {
+			It("allocates a TTY and keeps stdin open for the container", func() {
+				projectService.Tty = true
+				projectService.StdinOpen = true
+
+				container := k.initPodSpec(projectService).Containers[0]
+				Expect(container.TTY).To(BeTrue())
+				Expect(container.Stdin).To(BeTrue())
+				Expect(container.StdinOnce).To(BeFalse())
+			})
+		})
+
 		It("generates pod spec as expected", func() {
 			Expect(k.initPodSpec(projectService)).To(Equal(v1.PodSpec{
 				Containers: []v1.Container{
@@ -274,11 +473,93 @@ var _ = Describe("Transform", func() {
 					},
 				},
 				ServiceAccountName: "default",
+				RestartPolicy:      v1.RestartPolicyAlways,
 			}))
 		})
 
 	})
 
+	Describe("termination grace period", func() {
+		When("compose stop_grace_period is set", func() {
+			BeforeEach(func() {
+				stopGracePeriod := composego.Duration(30 * time.Second)
+				ps, err := NewProjectService(composego.ServiceConfig{
+					Name:            "web",
+					Image:           "some-image",
+					StopGracePeriod: &stopGracePeriod,
+				})
+				Expect(err).NotTo(HaveOccurred())
+				projectService = ps
+			})
+
+			It("sets the pod termination grace period in seconds", func() {
+				expected := int64(30)
+				Expect(k.initPodSpec(projectService).TerminationGracePeriodSeconds).To(Equal(&expected))
+			})
+		})
+
+		When("compose stop_grace_period is not set", func() {
+			It("leaves the pod termination grace period to the Kubernetes default", func() {
+				Expect(k.initPodSpec(projectService).TerminationGracePeriodSeconds).To(BeNil())
+			})
+		})
+	})
+
+	Describe("restart policy", func() {
+		When("compose restart policy is on-failure", func() {
+			BeforeEach(func() {
+				ps, err := NewProjectService(composego.ServiceConfig{
+					Name:    "web",
+					Image:   "some-image",
+					Restart: "on-failure",
+				})
+				Expect(err).NotTo(HaveOccurred())
+				projectService = ps
+			})
+
+			It("yields OnFailure pod restart policy", func() {
+				Expect(k.initPodSpec(projectService).RestartPolicy).To(Equal(v1.RestartPolicyOnFailure))
+			})
+
+			It("defaults Deployment pod restart policy to Always", func() {
+				d := k.initDeployment(projectService)
+				Expect(k.updateController(d, func(*v1.PodTemplateSpec) error { return nil }, func(*meta.ObjectMeta) {})).To(Succeed())
+				Expect(d.Spec.Template.Spec.RestartPolicy).To(Equal(v1.RestartPolicyAlways))
+			})
+		})
+
+		When("compose restart policy is no", func() {
+			BeforeEach(func() {
+				ps, err := NewProjectService(composego.ServiceConfig{
+					Name:    "web",
+					Image:   "some-image",
+					Restart: "no",
+				})
+				Expect(err).NotTo(HaveOccurred())
+				projectService = ps
+			})
+
+			It("yields Never pod restart policy", func() {
+				Expect(k.initPodSpec(projectService).RestartPolicy).To(Equal(v1.RestartPolicyNever))
+			})
+
+			It("keeps Never restart policy for a Job", func() {
+				j := k.initJob(projectService, 1)
+				Expect(k.updateController(j, func(*v1.PodTemplateSpec) error { return nil }, func(*meta.ObjectMeta) {})).To(Succeed())
+				Expect(j.Spec.Template.Spec.RestartPolicy).To(Equal(v1.RestartPolicyNever))
+			})
+		})
+
+		When("restart policy is Always for a Job", func() {
+			It("uses OnFailure instead", func() {
+				j := k.initJob(projectService, 1)
+				Expect(j.Spec.Template.Spec.RestartPolicy).To(Equal(v1.RestartPolicyAlways))
+				Expect(k.updateController(j, func(*v1.PodTemplateSpec) error { return nil }, func(*meta.ObjectMeta) {})).To(Succeed())
+				Expect(j.Spec.Template.Spec.RestartPolicy).To(Equal(v1.RestartPolicyOnFailure))
+			})
+		})
+	})
+
 	Describe("initPodSpecWithConfigMap", func() {
 
 		When("project service references config(s)", func() {
@@ -313,6 +594,21 @@ var _ = Describe("Transform", func() {
 				Expect(volumeMount.SubPath).To(Equal(subPath))
 			})
 
+			Context("and the directory config mount mode label", func() {
+				BeforeEach(func() {
+					projectService.Labels = composego.Labels{ConfigMountModeLabel: ConfigMountModeDirectory}
+				})
+
+				It("mounts the config directory without a sub path", func() {
+					spec := k.initPodSpecWithConfigMap(projectService)
+
+					volumeMount := spec.Containers[0].VolumeMounts[0]
+					Expect(volumeMount.MountPath).To(Equal("/mount"))
+					Expect(volumeMount.SubPath).To(BeEmpty())
+					Expect(spec.Volumes[0].ConfigMap.Items[0].Path).To(Equal(subPath))
+				})
+			})
+
 			Context("and config metadata is not specified in the project", func() {
 				BeforeEach(func() {
 					project.Configs = composego.Configs{}
@@ -361,6 +657,7 @@ var _ = Describe("Transform", func() {
 						},
 					},
 					ServiceAccountName: "default",
+					RestartPolicy:      v1.RestartPolicyAlways,
 				}))
 			})
 		})
@@ -392,6 +689,18 @@ var _ = Describe("Transform", func() {
 				Expect(k.initSvc(projectService).Name).To(HaveLen(63))
 			})
 		})
+
+		When("project service has custom labels", func() {
+			BeforeEach(func() {
+				projectService.Labels = composego.Labels{"kev.label.team": "payments"}
+			})
+
+			It("adds them to the service labels but not to its selector", func() {
+				svc := k.initSvc(projectService)
+				Expect(svc.Labels).To(HaveKeyWithValue("team", "payments"))
+				Expect(svc.Spec.Selector).To(Equal(configLabels(projectService.Name)))
+			})
+		})
 	})
 
 	Describe("initConfigMapFromFileOrDir", func() {
@@ -445,6 +754,51 @@ var _ = Describe("Transform", func() {
 				Expect(cm.Data).To(HaveKey("config-b"))
 			})
 		})
+
+		Context("with directory of text and binary files", func() {
+			configMapName := "assets"
+			dir := "../../testdata/converter/kubernetes/binary-configs/"
+
+			It("keeps text files in data and binary files in binary data", func() {
+				cm, err := k.initConfigMapFromFileOrDir(projectService, configMapName, dir)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(cm.Data).To(Equal(map[string]string{"app.conf": "title: kev\n"}))
+				Expect(cm.BinaryData).To(HaveLen(1))
+				Expect(cm.BinaryData).To(HaveKey("logo.png"))
+			})
+
+			It("serialises binary data base64 encoded", func() {
+				cm, err := k.initConfigMapFromFileOrDir(projectService, configMapName, dir)
+				Expect(err).ToNot(HaveOccurred())
+
+				out, err := json.Marshal(cm)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(string(out)).To(ContainSubstring(`"binaryData":{"logo.png":"iVBORw0KGgoA//4="}`))
+			})
+		})
+
+		Context("with single binary file", func() {
+			configMapName := "logo"
+			filePath := "../../testdata/converter/kubernetes/binary-configs/logo.png"
+
+			BeforeEach(func() {
+				project.Configs = composego.Configs{
+					"logo": composego.ConfigObjConfig(
+						composego.FileObjectConfig{
+							Name: "logo",
+							File: filePath,
+						},
+					),
+				}
+			})
+
+			It("returns config map with the file in binary data", func() {
+				cm, err := k.initConfigMapFromFileOrDir(projectService, configMapName, filePath)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(cm.Data).To(BeEmpty())
+				Expect(cm.BinaryData).To(HaveKeyWithValue("logo.png", []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n', 0x00, 0xff, 0xfe}))
+			})
+		})
 	})
 
 	Describe("initConfigMap", func() {
@@ -647,6 +1001,43 @@ var _ = Describe("Transform", func() {
 				Expect(d.ObjectMeta.Annotations).To(HaveLen(0))
 			})
 		})
+
+		Context("for project service with a rollback config", func() {
+			BeforeEach(func() {
+				projectService.Deploy = &composego.DeployConfig{
+					RollbackConfig: &composego.UpdateConfig{
+						FailureAction: "pause",
+					},
+				}
+			})
+
+			It("records it in a deployment annotation", func() {
+				d := k.initDeployment(projectService)
+				Expect(d.Annotations).To(HaveKeyWithValue(RollbackConfigAnnotation, "failure_action=pause"))
+			})
+		})
+
+		Context("for project service with custom labels", func() {
+			BeforeEach(func() {
+				projectService.Labels = composego.Labels{
+					"kev.label.team":        "payments",
+					"kev.label." + Selector: "other",
+				}
+			})
+
+			It("adds them to the deployment and its pod template", func() {
+				d := k.initDeployment(projectService)
+				Expect(d.Labels).To(HaveKeyWithValue("team", "payments"))
+				Expect(d.Spec.Template.Labels).To(HaveKeyWithValue("team", "payments"))
+			})
+
+			It("doesn't override the selector label", func() {
+				d := k.initDeployment(projectService)
+				Expect(d.Labels).To(HaveKeyWithValue(Selector, projectService.Name))
+				Expect(d.Spec.Template.Labels).To(HaveKeyWithValue(Selector, projectService.Name))
+				Expect(d.Spec.Selector.MatchLabels).To(Equal(configLabels(projectService.Name)))
+			})
+		})
 	})
 
 	Describe("initDaemonSet", func() {
@@ -894,6 +1285,99 @@ var _ = Describe("Transform", func() {
 		})
 	})
 
+	Describe("initCronJob", func() {
+		schedule := "*/5 * * * *"
+
+		BeforeEach(func() {
+			svcK8sConfig := config.DefaultSvcK8sConfig()
+			svcK8sConfig.Workload.Type = config.CronJobWorkload
+			svcK8sConfig.Workload.Schedule = schedule
+			svcK8sConfig.Workload.Replicas = 2
+			ext, err := svcK8sConfig.Map()
+			Expect(err).NotTo(HaveOccurred())
+
+			projectService.Extensions = map[string]interface{}{config.K8SExtensionKey: ext}
+			projectService, err = NewProjectService(projectService.ServiceConfig)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("generates kubernetes CronJob with the configured schedule", func() {
+			c := k.initCronJob(projectService)
+			Expect(c.Kind).To(Equal("CronJob"))
+			Expect(c.APIVersion).To(Equal("batch/v1beta1"))
+			Expect(c.Name).To(Equal(projectService.Name))
+			Expect(c.Spec.Schedule).To(Equal(schedule))
+		})
+
+		It("reuses job parallelism and completions based on replicas", func() {
+			c := k.initCronJob(projectService)
+			Expect(*c.Spec.JobTemplate.Spec.Parallelism).To(BeEquivalentTo(2))
+			Expect(*c.Spec.JobTemplate.Spec.Completions).To(BeEquivalentTo(2))
+		})
+
+		It("leaves job selector generation to the controller", func() {
+			c := k.initCronJob(projectService)
+			Expect(c.Spec.JobTemplate.Spec.Selector).To(BeNil())
+		})
+
+		It("reuses the pod spec builder", func() {
+			c := k.initCronJob(projectService)
+			Expect(c.Spec.JobTemplate.Spec.Template.Spec).To(Equal(k.initPodSpec(projectService)))
+		})
+	})
+
+	Describe("initExposeGroupIngresses", func() {
+		port := int32(1234)
+
+		When("project service has no expose groups", func() {
+			It("doesn't initiate any ingresses", func() {
+				Expect(k.initExposeGroupIngresses(projectService, port)).To(BeEmpty())
+			})
+		})
+
+		When("project service has expose groups", func() {
+			BeforeEach(func() {
+				projectService.SvcK8sConfig.Service.Expose.Groups = []config.ExposeGroup{
+					{Name: "internal", IngressClass: "nginx-internal", Domain: "app.internal"},
+					{Name: "external", IngressClass: "nginx-external", Domain: "app.example.com", TlsSecret: "app-tls"},
+				}
+			})
+
+			It("initialises an Ingress per group with the group ingress class", func() {
+				objects := k.initExposeGroupIngresses(projectService, port)
+				Expect(objects).To(HaveLen(2))
+
+				internal := objects[0].(*networking.Ingress)
+				Expect(internal.Name).To(Equal(projectService.Name + "-internal"))
+				Expect(*internal.Spec.IngressClassName).To(Equal("nginx-internal"))
+				Expect(internal.Spec.Rules[0].Host).To(Equal("app.internal"))
+				Expect(internal.Spec.TLS).To(BeEmpty())
+
+				external := objects[1].(*networking.Ingress)
+				Expect(external.Name).To(Equal(projectService.Name + "-external"))
+				Expect(*external.Spec.IngressClassName).To(Equal("nginx-external"))
+				Expect(external.Spec.TLS).To(Equal([]networking.IngressTLS{
+					{Hosts: []string{"app.example.com"}, SecretName: "app-tls"},
+				}))
+			})
+
+			It("initialises legacy Ingresses when the legacy ingress API is configured", func() {
+				projectService.SvcK8sConfig.Service.Expose.IngressAPIVersion = config.LegacyIngressAPIVersion
+
+				objects := k.initExposeGroupIngresses(projectService, port)
+				Expect(objects).To(HaveLen(2))
+
+				internal := objects[0].(*networkingv1beta1.Ingress)
+				Expect(*internal.Spec.IngressClassName).To(Equal("nginx-internal"))
+			})
+
+			It("doesn't initiate any ingresses for the Traefik expose API", func() {
+				k.Opt.ExposeAPI = ExposeAPITraefik
+				Expect(k.initExposeGroupIngresses(projectService, port)).To(BeEmpty())
+			})
+		})
+	})
+
 	Describe("initIngress", func() {
 		port := int32(1234)
 
@@ -922,29 +1406,34 @@ var _ = Describe("Transform", func() {
 			It("initialises Ingress with a port routing to the project service name", func() {
 				ing := k.initIngress(projectService, port)
 
-				Expect(ing).To(Equal(&networkingv1beta1.Ingress{
+				pathType := networking.PathTypePrefix
+
+				Expect(ing).To(Equal(&networking.Ingress{
 					TypeMeta: meta.TypeMeta{
 						Kind:       "Ingress",
-						APIVersion: "networking.k8s.io/v1beta1",
+						APIVersion: "networking.k8s.io/v1",
 					},
 					ObjectMeta: meta.ObjectMeta{
 						Name:        projectService.Name,
 						Labels:      configLabels(projectService.Name),
 						Annotations: ingressAnnotations,
 					},
-					Spec: networkingv1beta1.IngressSpec{
-						Rules: []networkingv1beta1.IngressRule{
+					Spec: networking.IngressSpec{
+						Rules: []networking.IngressRule{
 							{
 								Host: domain,
-								IngressRuleValue: networkingv1beta1.IngressRuleValue{
-									HTTP: &networkingv1beta1.HTTPIngressRuleValue{
-										Paths: []networkingv1beta1.HTTPIngressPath{
+								IngressRuleValue: networking.IngressRuleValue{
+									HTTP: &networking.HTTPIngressRuleValue{
+										Paths: []networking.HTTPIngressPath{
 											{
-												Path: "",
-												Backend: networkingv1beta1.IngressBackend{
-													ServiceName: projectService.Name,
-													ServicePort: intstr.IntOrString{
-														IntVal: port,
+												Path:     "/",
+												PathType: &pathType,
+												Backend: networking.IngressBackend{
+													Service: &networking.IngressServiceBackend{
+														Name: projectService.Name,
+														Port: networking.ServiceBackendPort{
+															Number: port,
+														},
 													},
 												},
 											},
@@ -965,13 +1454,13 @@ var _ = Describe("Transform", func() {
 
 			It("initialises Ingress with the correct service", func() {
 				ingress := k.initIngress(projectService, port)
-				configuredService := ingress.Spec.Rules[0].IngressRuleValue.HTTP.Paths[0].Backend.ServiceName
+				configuredService := ingress.Spec.Rules[0].IngressRuleValue.HTTP.Paths[0].Backend.Service.Name
 				Expect(configuredService).To(Equal(projectService.Name))
 			})
 
 			It("initialises Ingress with the correct port", func() {
 				ingress := k.initIngress(projectService, port)
-				configuredPort := ingress.Spec.Rules[0].IngressRuleValue.HTTP.Paths[0].Backend.ServicePort.IntVal
+				configuredPort := ingress.Spec.Rules[0].IngressRuleValue.HTTP.Paths[0].Backend.Service.Port.Number
 				Expect(configuredPort).To(Equal(port))
 			})
 		})
@@ -994,6 +1483,12 @@ var _ = Describe("Transform", func() {
 				ingressPath := ingress.Spec.Rules[0].IngressRuleValue.HTTP.Paths[0].Path
 				Expect(ingressPath).To(Equal("/" + path))
 			})
+
+			It("specifies Prefix path type in the initialised Ingress", func() {
+				ingress := k.initIngress(projectService, port)
+				pathType := ingress.Spec.Rules[0].IngressRuleValue.HTTP.Paths[0].PathType
+				Expect(*pathType).To(Equal(networking.PathTypePrefix))
+			})
 		})
 
 		When("project service extension exposing the k8s service using a comma separated list of domain names", func() {
@@ -1020,8 +1515,8 @@ var _ = Describe("Transform", func() {
 
 			It("creates a default backend in the initialised Ingress with no rules`", func() {
 				ingress := k.initIngress(projectService, port)
-				Expect(ingress.Spec.Backend.ServiceName).To(Equal(projectService.Name))
-				Expect(ingress.Spec.Backend.ServicePort.IntVal).To(Equal(port))
+				Expect(ingress.Spec.DefaultBackend.Service.Name).To(Equal(projectService.Name))
+				Expect(ingress.Spec.DefaultBackend.Service.Port.Number).To(Equal(port))
 				Expect(ingress.Spec.Rules).To(HaveLen(0))
 			})
 		})
@@ -1052,7 +1547,7 @@ var _ = Describe("Transform", func() {
 			It("will include it in the ingress spec", func() {
 				ing := k.initIngress(projectService, port)
 
-				Expect(ing.Spec.TLS).To(Equal([]networkingv1beta1.IngressTLS{
+				Expect(ing.Spec.TLS).To(Equal([]networking.IngressTLS{
 					{
 						Hosts:      []string{"domain.name"},
 						SecretName: "my-tls-secret",
@@ -1074,213 +1569,585 @@ var _ = Describe("Transform", func() {
 		})
 	})
 
-	Describe("initHpa", func() {
-		var obj runtime.Object
+	Describe("initServiceMonitor", func() {
 
-		Context("with supported object kind", func() {
+		When("project service extension doesn't enable monitoring", func() {
+			It("doesn't initiate a service monitor", func() {
+				Expect(k.initServiceMonitor(projectService)).To(BeNil())
+			})
+		})
+
+		When("project service extension enables monitoring", func() {
 			BeforeEach(func() {
-				obj = &v1apps.Deployment{
-					TypeMeta: meta.TypeMeta{
-						Kind:       "Deployment",
-						APIVersion: "apps/v1",
-					},
+				projectService.Ports = []composego.ServicePortConfig{{Target: 8080}}
+				projectService.SvcK8sConfig.Service.Monitor = config.Monitor{
+					Enabled: true,
+					Path:    "/stats",
 				}
 			})
 
-			Context("with autoscaling options specified", func() {
-
-				When("the maximum number of replicas is defined", func() {
-					BeforeEach(func() {
-						projectService.SvcK8sConfig.Workload.Autoscale.MaxReplicas = 10
-					})
-
-					It("initialises HPA with expected API version referencing passed object", func() {
-						hpa := k.initHpa(projectService, obj)
-						Expect(hpa.APIVersion).To(Equal("autoscaling/v2beta2"))
-						Expect(hpa.Spec.ScaleTargetRef.Kind).To(Equal("Deployment"))
-						Expect(hpa.Spec.ScaleTargetRef.APIVersion).To(Equal("apps/v1"))
-						Expect(hpa.Spec.ScaleTargetRef.Name).To(Equal(projectService.Name))
-					})
-
-					When("workload CPU threshold parameters is also specified", func() {
-						BeforeEach(func() {
-							projectService.SvcK8sConfig.Workload.Autoscale.MaxReplicas = 10
-							projectService.SvcK8sConfig.Workload.Autoscale.CPUThreshold = 65
-						})
-
-						It("initialises Horizontal Pod Autoscaler for a project service", func() {
-							hpa := k.initHpa(projectService, obj)
-							Expect(hpa.Spec.MaxReplicas).To(BeEquivalentTo(10))
-							// first metrics is CPU
-							Expect(hpa.Spec.Metrics[0].Resource.Name).To(BeEquivalentTo("cpu"))
-							Expect(hpa.Spec.Metrics[0].Resource.Target.Type).To(BeEquivalentTo("Utilization"))
-							Expect(*hpa.Spec.Metrics[0].Resource.Target.AverageUtilization).To(BeEquivalentTo(65))
-						})
-					})
-
-					When("workload CPU threshold is not specified", func() {
-
-						BeforeEach(func() {
-							projectService.SvcK8sConfig.Workload.Autoscale.MaxReplicas = 10
-						})
-
-						It("initialises Horizontal Pod Autoscaler for a project service with default target CPU utilization of 70%", func() {
-							hpa := k.initHpa(projectService, obj)
-							Expect(hpa.Spec.MaxReplicas).To(BeEquivalentTo(10))
-							// first metrics is CPU
-							Expect(hpa.Spec.Metrics[0].Resource.Name).To(BeEquivalentTo("cpu"))
-							Expect(hpa.Spec.Metrics[0].Resource.Target.Type).To(BeEquivalentTo("Utilization"))
-							Expect(*hpa.Spec.Metrics[0].Resource.Target.AverageUtilization).To(BeEquivalentTo(70))
-						})
-					})
-
-					When("autoscaling max replicas number is lower or equal to initial number of replicas", func() {
-						BeforeEach(func() {
-							projectService.SvcK8sConfig.Workload.Replicas = 10
-							projectService.SvcK8sConfig.Workload.Autoscale.MaxReplicas = 5
-						})
-
-						It("doesn't initialise the Horizontal Pod Autoscaler", func() {
-							hpa := k.initHpa(projectService, obj)
-							Expect(hpa).To(BeNil())
-						})
-					})
-
-					When("the maximum number of replicas is specified as 0", func() {
-						BeforeEach(func() {
-							projectService.SvcK8sConfig.Workload.Autoscale.MaxReplicas = 0
-						})
-
-						It("doesn't initialize Horizontal Pod Autoscaler for that project service", func() {
-							hpa := k.initHpa(projectService, obj)
-							Expect(hpa).To(BeNil())
-						})
-					})
+			It("initialises ServiceMonitor selecting the project service", func() {
+				sm := k.initServiceMonitor(projectService)
+				Expect(sm.GetAPIVersion()).To(Equal("monitoring.coreos.com/v1"))
+				Expect(sm.GetKind()).To(Equal("ServiceMonitor"))
+				Expect(sm.GetName()).To(Equal(projectService.Name))
 
-					When("workload Memory threshold parameter is also specified", func() {
-						BeforeEach(func() {
-							projectService.SvcK8sConfig.Workload.Autoscale.MaxReplicas = 10
-							projectService.SvcK8sConfig.Workload.Autoscale.MemoryThreshold = 40
-						})
+				matchLabels, _, err := unstructured.NestedStringMap(sm.Object, "spec", "selector", "matchLabels")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(matchLabels).To(Equal(configLabels(projectService.Name)))
+			})
 
-						It("initialises Horizontal Pod Autoscaler for a project service", func() {
-							hpa := k.initHpa(projectService, obj)
-							Expect(hpa.Spec.MaxReplicas).To(BeEquivalentTo(10))
-							// second metric is Memory
-							Expect(hpa.Spec.Metrics[1].Resource.Name).To(BeEquivalentTo("memory"))
-							Expect(hpa.Spec.Metrics[1].Resource.Target.Type).To(BeEquivalentTo("Utilization"))
-							Expect(*hpa.Spec.Metrics[1].Resource.Target.AverageUtilization).To(BeEquivalentTo(40))
-						})
-					})
+			It("scrapes metrics from the configured path of the first service port", func() {
+				endpoints, _, err := unstructured.NestedSlice(k.initServiceMonitor(projectService).Object, "spec", "endpoints")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(endpoints).To(Equal([]interface{}{
+					map[string]interface{}{"port": "8080", "path": "/stats"},
+				}))
+			})
+		})
 
-					When("workload Memory threshold is not specified", func() {
+		When("project service exposes no ports", func() {
+			BeforeEach(func() {
+				projectService.Ports = nil
+				projectService.Expose = nil
+				projectService.SvcK8sConfig.Service.Monitor.Enabled = true
+			})
 
-						BeforeEach(func() {
-							projectService.SvcK8sConfig.Workload.Autoscale.MaxReplicas = 10
-						})
+			It("doesn't initiate a service monitor", func() {
+				Expect(k.initServiceMonitor(projectService)).To(BeNil())
+			})
+		})
+	})
 
-						It("initialises Horizontal Pod Autoscaler for a project service with default target Memory utilization of 70%", func() {
-							hpa := k.initHpa(projectService, obj)
-							Expect(hpa.Spec.MaxReplicas).To(BeEquivalentTo(10))
-							// second metric is Memory
-							Expect(hpa.Spec.Metrics[1].Resource.Name).To(BeEquivalentTo("memory"))
-							Expect(hpa.Spec.Metrics[1].Resource.Target.Type).To(BeEquivalentTo("Utilization"))
-							Expect(*hpa.Spec.Metrics[1].Resource.Target.AverageUtilization).To(BeEquivalentTo(70))
-						})
-					})
-				})
+	Describe("initIngressRoute", func() {
+		port := int32(1234)
 
-				When("the maximum number of replicas is not defined", func() {
-					It("doesn't initialize Horizontal Pod Autoscaler for that project service", func() {
-						hpa := k.initHpa(projectService, obj)
-						Expect(hpa).To(BeNil())
-					})
-				})
+		When("project service extension exposing the k8s service using an empty string", func() {
+			BeforeEach(func() {
+				projectService.SvcK8sConfig.Service.Expose.Domain = ""
+			})
 
+			It("doesn't initiate an ingress route", func() {
+				Expect(k.initIngressRoute(projectService, port)).To(BeNil())
 			})
 		})
 
-		Context("with not supported object kind", func() {
+		When("project service extension exposing the k8s service using domains with a path", func() {
 			BeforeEach(func() {
-				obj = &v1apps.StatefulSet{
+				projectService.SvcK8sConfig.Service.Expose.Domain = "domain.name/api,another.domain.name"
+				projectService.SvcK8sConfig.Service.Expose.TlsSecret = "tls-secret"
+				projectService.SvcK8sConfig.Service.Expose.IngressAnnotations = map[string]string{
+					"traefik.ingress.kubernetes.io/router.entrypoints": "web,websecure",
+					"cert-manager.io/cluster-issuer":                   "prod-le-dns01",
+				}
+			})
+
+			It("initialises Traefik IngressRoute routing hosts & paths to the project service port", func() {
+				services := []IngressRouteService{{Name: projectService.Name, Port: port}}
+
+				Expect(k.initIngressRoute(projectService, port)).To(Equal(&IngressRoute{
 					TypeMeta: meta.TypeMeta{
-						Kind:       "StatefulSet",
-						APIVersion: "apps/v1",
+						Kind:       "IngressRoute",
+						APIVersion: "traefik.io/v1alpha1",
 					},
-				}
+					ObjectMeta: meta.ObjectMeta{
+						Name:   projectService.Name,
+						Labels: configLabels(projectService.Name),
+						Annotations: map[string]string{
+							"cert-manager.io/cluster-issuer": "prod-le-dns01",
+						},
+					},
+					Spec: IngressRouteSpec{
+						EntryPoints: []string{"web", "websecure"},
+						Routes: []IngressRouteRule{
+							{Match: "Host(`domain.name`) && PathPrefix(`/api`)", Kind: "Rule", Services: services},
+							{Match: "Host(`another.domain.name`)", Kind: "Rule", Services: services},
+						},
+						TLS: &IngressRouteTLS{SecretName: "tls-secret"},
+					},
+				}))
 			})
 
-			It("doesn't initialize Horizontal Pod Autoscaler for that project service", func() {
-				hpa := k.initHpa(projectService, obj)
-				Expect(hpa).To(BeNil())
+			It("is registered with the converter's scheme", func() {
+				gvks, err := objectKinds(k.initIngressRoute(projectService, port))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(gvks[0]).To(Equal(TraefikGroupVersion.WithKind("IngressRoute")))
 			})
 		})
 
-	})
-
-	Describe("initSa", func() {
-		When("service account name is specified as empty string in the workload configuration", func() {
+		When("project service extension exposing the k8s service using a default ingress backend", func() {
 			BeforeEach(func() {
-				projectService.SvcK8sConfig.Workload.ServiceAccountName = ""
+				projectService.SvcK8sConfig.Service.Expose.Domain = DefaultIngressBackendKeyword
 			})
 
-			It("doesn't initialize ServiceAccount for that project service", func() {
-				sa := k.initServiceAccount(projectService)
-				Expect(sa).To(BeNil())
+			It("routes all requests to the project service", func() {
+				ingressRoute := k.initIngressRoute(projectService, port)
+				Expect(ingressRoute.Spec.Routes).To(HaveLen(1))
+				Expect(ingressRoute.Spec.Routes[0].Match).To(Equal("PathPrefix(`/`)"))
 			})
 		})
+	})
 
-		When("service account name is defined as `default`", func() {
+	Describe("initLegacyIngress", func() {
+		port := int32(1234)
+
+		When("project service extension exposing the k8s service using an empty string", func() {
 			BeforeEach(func() {
-				projectService.SvcK8sConfig.Workload.ServiceAccountName = "default"
+				projectService.SvcK8sConfig.Service.Expose.Domain = ""
 			})
 
-			It("doesn't initialize ServiceAccount for that project service", func() {
-				sa := k.initServiceAccount(projectService)
-				Expect(sa).To(BeNil())
+			It("doesn't initiate an ingress", func() {
+				Expect(k.initLegacyIngress(projectService, port)).To(BeNil())
 			})
 		})
 
-		When("service account name is specified with name different than `default`", func() {
+		When("project service extension exposing the k8s service", func() {
+			domain := "domain.name"
+			ingressAnnotations := map[string]string{
+				"kubernetes.io/ingress.class":    "external",
+				"cert-manager.io/cluster-issuer": "prod-le-dns01",
+			}
+
 			BeforeEach(func() {
-				projectService.SvcK8sConfig.Workload.ServiceAccountName = "mysvcacc"
+				projectService.SvcK8sConfig.Service.Expose.Domain = domain
+				projectService.SvcK8sConfig.Service.Expose.IngressAnnotations = ingressAnnotations
 			})
 
-			It("initializes ServiceAccount for the project service", func() {
-				sa := k.initServiceAccount(projectService)
-				Expect(sa).ToNot(BeNil())
-
-				automountSAToken := false
+			It("initialises Ingress with a port routing to the project service name", func() {
+				ing := k.initLegacyIngress(projectService, port)
 
-				expected := &v1.ServiceAccount{
+				Expect(ing).To(Equal(&networkingv1beta1.Ingress{
 					TypeMeta: meta.TypeMeta{
-						Kind:       "ServiceAccount",
-						APIVersion: "v1",
+						Kind:       "Ingress",
+						APIVersion: "networking.k8s.io/v1beta1",
 					},
 					ObjectMeta: meta.ObjectMeta{
-						Name:        "mysvcacc",
+						Name:        projectService.Name,
 						Labels:      configLabels(projectService.Name),
-						Annotations: configAnnotations(projectService.Labels),
+						Annotations: ingressAnnotations,
 					},
-					AutomountServiceAccountToken: &automountSAToken,
-				}
-
-				Expect(sa).To(Equal(expected))
+					Spec: networkingv1beta1.IngressSpec{
+						Rules: []networkingv1beta1.IngressRule{
+							{
+								Host: domain,
+								IngressRuleValue: networkingv1beta1.IngressRuleValue{
+									HTTP: &networkingv1beta1.HTTPIngressRuleValue{
+										Paths: []networkingv1beta1.HTTPIngressPath{
+											{
+												Path: "",
+												Backend: networkingv1beta1.IngressBackend{
+													ServiceName: projectService.Name,
+													ServicePort: intstr.IntOrString{
+														IntVal: port,
+													},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				}))
 			})
 		})
-	})
-
-	Describe("createSecrets", func() {
-		secretName := "my-secret"
-		var secretConfig composego.SecretConfig
-
-		JustBeforeEach(func() {
-			project.Secrets = composego.Secrets{
-				secretName: secretConfig,
-			}
-		})
 
-		Context("for external secrets", func() {
+		When("project service extension exposing the k8s service using a domain name", func() {
+			BeforeEach(func() {
+				projectService.SvcK8sConfig.Service.Expose.Domain = "domain.name"
+			})
+
+			It("initialises Ingress with the correct service", func() {
+				ingress := k.initLegacyIngress(projectService, port)
+				configuredService := ingress.Spec.Rules[0].IngressRuleValue.HTTP.Paths[0].Backend.ServiceName
+				Expect(configuredService).To(Equal(projectService.Name))
+			})
+
+			It("initialises Ingress with the correct port", func() {
+				ingress := k.initLegacyIngress(projectService, port)
+				configuredPort := ingress.Spec.Rules[0].IngressRuleValue.HTTP.Paths[0].Backend.ServicePort.IntVal
+				Expect(configuredPort).To(Equal(port))
+			})
+		})
+
+		When("project service extension exposing the k8s service using a domain with a path", func() {
+			domain := "domain.name"
+			path := "path"
+
+			BeforeEach(func() {
+				projectService.SvcK8sConfig.Service.Expose.Domain = filepath.Join(domain, path)
+			})
+
+			It("specifies host in the initialised Ingress", func() {
+				ingress := k.initLegacyIngress(projectService, port)
+				Expect(ingress.Spec.Rules[0].Host).To(Equal(domain))
+			})
+
+			It("specifies path in the initialised Ingress", func() {
+				ingress := k.initLegacyIngress(projectService, port)
+				ingressPath := ingress.Spec.Rules[0].IngressRuleValue.HTTP.Paths[0].Path
+				Expect(ingressPath).To(Equal("/" + path))
+			})
+		})
+
+		When("project service extension exposing the k8s service using a comma separated list of domain names", func() {
+			domains := []string{
+				"domain.name",
+				"another.domain.name",
+			}
+
+			BeforeEach(func() {
+				projectService.SvcK8sConfig.Service.Expose.Domain = strings.Join(domains, ",")
+			})
+
+			It("specifies all comma separated hosts in the initialised Ingress", func() {
+				ingress := k.initLegacyIngress(projectService, port)
+				Expect(ingress.Spec.Rules[0].Host).To(Equal(domains[0]))
+				Expect(ingress.Spec.Rules[1].Host).To(Equal(domains[1]))
+			})
+		})
+
+		When("project service extension exposing the k8s service using a default ingress backend", func() {
+			BeforeEach(func() {
+				projectService.SvcK8sConfig.Service.Expose.Domain = DefaultIngressBackendKeyword
+			})
+
+			It("creates a default backend in the initialised Ingress with no rules`", func() {
+				ingress := k.initLegacyIngress(projectService, port)
+				Expect(ingress.Spec.Backend.ServiceName).To(Equal(projectService.Name))
+				Expect(ingress.Spec.Backend.ServicePort.IntVal).To(Equal(port))
+				Expect(ingress.Spec.Rules).To(HaveLen(0))
+			})
+		})
+
+		When("project service extension instructing to expose the k8s service with domain and ingress annotations", func() {
+			ingressAnnotations := map[string]string{
+				"kubernetes.io/ingress.class":    "external",
+				"cert-manager.io/cluster-issuer": "prod-le-dns01",
+			}
+
+			BeforeEach(func() {
+				projectService.SvcK8sConfig.Service.Expose.IngressAnnotations = ingressAnnotations
+				projectService.SvcK8sConfig.Service.Expose.Domain = "domain.name"
+			})
+
+			It("initialises Ingress with configured ingress annotations", func() {
+				ingress := k.initLegacyIngress(projectService, port)
+				Expect(ingress.ObjectMeta.Annotations).To(Equal(ingressAnnotations))
+			})
+		})
+
+		When("TLS secret name was specified via extension", func() {
+			BeforeEach(func() {
+				projectService.SvcK8sConfig.Service.Expose.Domain = "domain.name"
+				projectService.SvcK8sConfig.Service.Expose.TlsSecret = "my-tls-secret"
+			})
+
+			It("will include it in the ingress spec", func() {
+				ing := k.initLegacyIngress(projectService, port)
+
+				Expect(ing.Spec.TLS).To(Equal([]networkingv1beta1.IngressTLS{
+					{
+						Hosts:      []string{"domain.name"},
+						SecretName: "my-tls-secret",
+					},
+				}))
+			})
+		})
+
+		When("TLS secret name was specified via extension for service exposed with default ingress backend", func() {
+			BeforeEach(func() {
+				projectService.SvcK8sConfig.Service.Expose.Domain = DefaultIngressBackendKeyword
+				projectService.SvcK8sConfig.Service.Expose.TlsSecret = "my-tls-secret"
+			})
+
+			It("does not create a TLS object in the ingress spec", func() {
+				ing := k.initLegacyIngress(projectService, port)
+				Expect(ing.Spec.TLS).To(HaveLen(0))
+			})
+		})
+	})
+
+	Describe("initPodDisruptionBudget", func() {
+
+		Context("when neither min available nor max unavailable is configured", func() {
+			It("doesn't initialise a pod disruption budget", func() {
+				pdb, err := k.initPodDisruptionBudget(projectService)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(pdb).To(BeNil())
+			})
+		})
+
+		Context("when min available is configured", func() {
+			BeforeEach(func() {
+				projectService.SvcK8sConfig.Workload.PodDisruptionBudget.MinAvailable = "2"
+			})
+
+			It("initialises a pod disruption budget selecting the project service pods", func() {
+				pdb, err := k.initPodDisruptionBudget(projectService)
+				Expect(err).NotTo(HaveOccurred())
+
+				minAvailable := intstr.FromInt(2)
+				Expect(pdb).To(Equal(&policyv1beta1.PodDisruptionBudget{
+					TypeMeta: meta.TypeMeta{
+						Kind:       "PodDisruptionBudget",
+						APIVersion: "policy/v1beta1",
+					},
+					ObjectMeta: meta.ObjectMeta{
+						Name:   projectService.Name,
+						Labels: configLabels(projectService.Name),
+					},
+					Spec: policyv1beta1.PodDisruptionBudgetSpec{
+						MinAvailable: &minAvailable,
+						Selector: &meta.LabelSelector{
+							MatchLabels: configLabels(projectService.Name),
+						},
+					},
+				}))
+			})
+
+			It("uses the apiVersion served by the target Kubernetes version", func() {
+				k.KubeVersion = "1.24"
+
+				pdb, err := k.initPodDisruptionBudget(projectService)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(pdb.APIVersion).To(Equal("policy/v1"))
+			})
+		})
+
+		Context("when max unavailable is configured as a percentage", func() {
+			BeforeEach(func() {
+				projectService.SvcK8sConfig.Workload.PodDisruptionBudget.MaxUnavailable = "50%"
+			})
+
+			It("initialises a pod disruption budget with max unavailable pods", func() {
+				pdb, err := k.initPodDisruptionBudget(projectService)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(pdb.Spec.MinAvailable).To(BeNil())
+				Expect(*pdb.Spec.MaxUnavailable).To(Equal(intstr.FromString("50%")))
+			})
+		})
+
+		Context("when both min available and max unavailable are configured", func() {
+			BeforeEach(func() {
+				projectService.SvcK8sConfig.Workload.PodDisruptionBudget.MinAvailable = "1"
+				projectService.SvcK8sConfig.Workload.PodDisruptionBudget.MaxUnavailable = "1"
+			})
+
+			It("returns an error", func() {
+				_, err := k.initPodDisruptionBudget(projectService)
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("initHpa", func() {
+		var obj runtime.Object
+
+		Context("with supported object kind", func() {
+			BeforeEach(func() {
+				obj = &v1apps.Deployment{
+					TypeMeta: meta.TypeMeta{
+						Kind:       "Deployment",
+						APIVersion: "apps/v1",
+					},
+				}
+			})
+
+			Context("with autoscaling options specified", func() {
+
+				When("the maximum number of replicas is defined", func() {
+					BeforeEach(func() {
+						projectService.SvcK8sConfig.Workload.Autoscale.MaxReplicas = 10
+					})
+
+					It("initialises HPA with expected API version referencing passed object", func() {
+						hpa := k.initHpa(projectService, obj)
+						Expect(hpa.APIVersion).To(Equal("autoscaling/v2beta2"))
+						Expect(hpa.Spec.ScaleTargetRef.Kind).To(Equal("Deployment"))
+						Expect(hpa.Spec.ScaleTargetRef.APIVersion).To(Equal("apps/v1"))
+						Expect(hpa.Spec.ScaleTargetRef.Name).To(Equal(projectService.Name))
+					})
+
+					When("workload CPU threshold parameters is also specified", func() {
+						BeforeEach(func() {
+							projectService.SvcK8sConfig.Workload.Autoscale.MaxReplicas = 10
+							projectService.SvcK8sConfig.Workload.Autoscale.CPUThreshold = 65
+						})
+
+						It("initialises Horizontal Pod Autoscaler for a project service", func() {
+							hpa := k.initHpa(projectService, obj)
+							Expect(hpa.Spec.MaxReplicas).To(BeEquivalentTo(10))
+							// first metrics is CPU
+							Expect(hpa.Spec.Metrics[0].Resource.Name).To(BeEquivalentTo("cpu"))
+							Expect(hpa.Spec.Metrics[0].Resource.Target.Type).To(BeEquivalentTo("Utilization"))
+							Expect(*hpa.Spec.Metrics[0].Resource.Target.AverageUtilization).To(BeEquivalentTo(65))
+						})
+					})
+
+					When("workload CPU threshold is not specified", func() {
+
+						BeforeEach(func() {
+							projectService.SvcK8sConfig.Workload.Autoscale.MaxReplicas = 10
+						})
+
+						It("initialises Horizontal Pod Autoscaler for a project service with default target CPU utilization of 70%", func() {
+							hpa := k.initHpa(projectService, obj)
+							Expect(hpa.Spec.MaxReplicas).To(BeEquivalentTo(10))
+							// first metrics is CPU
+							Expect(hpa.Spec.Metrics[0].Resource.Name).To(BeEquivalentTo("cpu"))
+							Expect(hpa.Spec.Metrics[0].Resource.Target.Type).To(BeEquivalentTo("Utilization"))
+							Expect(*hpa.Spec.Metrics[0].Resource.Target.AverageUtilization).To(BeEquivalentTo(70))
+						})
+					})
+
+					When("autoscaling max replicas number is lower or equal to initial number of replicas", func() {
+						BeforeEach(func() {
+							projectService.SvcK8sConfig.Workload.Replicas = 10
+							projectService.SvcK8sConfig.Workload.Autoscale.MaxReplicas = 5
+						})
+
+						It("doesn't initialise the Horizontal Pod Autoscaler", func() {
+							hpa := k.initHpa(projectService, obj)
+							Expect(hpa).To(BeNil())
+						})
+					})
+
+					When("the maximum number of replicas is specified as 0", func() {
+						BeforeEach(func() {
+							projectService.SvcK8sConfig.Workload.Autoscale.MaxReplicas = 0
+						})
+
+						It("doesn't initialize Horizontal Pod Autoscaler for that project service", func() {
+							hpa := k.initHpa(projectService, obj)
+							Expect(hpa).To(BeNil())
+						})
+					})
+
+					When("workload Memory threshold parameter is also specified", func() {
+						BeforeEach(func() {
+							projectService.SvcK8sConfig.Workload.Autoscale.MaxReplicas = 10
+							projectService.SvcK8sConfig.Workload.Autoscale.MemoryThreshold = 40
+						})
+
+						It("initialises Horizontal Pod Autoscaler for a project service", func() {
+							hpa := k.initHpa(projectService, obj)
+							Expect(hpa.Spec.MaxReplicas).To(BeEquivalentTo(10))
+							// second metric is Memory
+							Expect(hpa.Spec.Metrics[1].Resource.Name).To(BeEquivalentTo("memory"))
+							Expect(hpa.Spec.Metrics[1].Resource.Target.Type).To(BeEquivalentTo("Utilization"))
+							Expect(*hpa.Spec.Metrics[1].Resource.Target.AverageUtilization).To(BeEquivalentTo(40))
+						})
+					})
+
+					When("workload Memory threshold is not specified", func() {
+
+						BeforeEach(func() {
+							projectService.SvcK8sConfig.Workload.Autoscale.MaxReplicas = 10
+						})
+
+						It("initialises Horizontal Pod Autoscaler for a project service with default target Memory utilization of 70%", func() {
+							hpa := k.initHpa(projectService, obj)
+							Expect(hpa.Spec.MaxReplicas).To(BeEquivalentTo(10))
+							// second metric is Memory
+							Expect(hpa.Spec.Metrics[1].Resource.Name).To(BeEquivalentTo("memory"))
+							Expect(hpa.Spec.Metrics[1].Resource.Target.Type).To(BeEquivalentTo("Utilization"))
+							Expect(*hpa.Spec.Metrics[1].Resource.Target.AverageUtilization).To(BeEquivalentTo(70))
+						})
+					})
+				})
+
+				When("the maximum number of replicas is not defined", func() {
+					It("doesn't initialize Horizontal Pod Autoscaler for that project service", func() {
+						hpa := k.initHpa(projectService, obj)
+						Expect(hpa).To(BeNil())
+					})
+				})
+
+			})
+		})
+
+		Context("with not supported object kind", func() {
+			BeforeEach(func() {
+				obj = &v1apps.StatefulSet{
+					TypeMeta: meta.TypeMeta{
+						Kind:       "StatefulSet",
+						APIVersion: "apps/v1",
+					},
+				}
+			})
+
+			It("doesn't initialize Horizontal Pod Autoscaler for that project service", func() {
+				hpa := k.initHpa(projectService, obj)
+				Expect(hpa).To(BeNil())
+			})
+		})
+
+	})
+
+	Describe("initSa", func() {
+		When("service account name is specified as empty string in the workload configuration", func() {
+			BeforeEach(func() {
+				projectService.SvcK8sConfig.Workload.ServiceAccountName = ""
+			})
+
+			It("doesn't initialize ServiceAccount for that project service", func() {
+				sa := k.initServiceAccount(projectService)
+				Expect(sa).To(BeNil())
+			})
+		})
+
+		When("service account name is defined as `default`", func() {
+			BeforeEach(func() {
+				projectService.SvcK8sConfig.Workload.ServiceAccountName = "default"
+			})
+
+			It("doesn't initialize ServiceAccount for that project service", func() {
+				sa := k.initServiceAccount(projectService)
+				Expect(sa).To(BeNil())
+			})
+		})
+
+		When("service account name is specified with name different than `default`", func() {
+			BeforeEach(func() {
+				projectService.SvcK8sConfig.Workload.ServiceAccountName = "mysvcacc"
+			})
+
+			It("initializes ServiceAccount for the project service", func() {
+				sa := k.initServiceAccount(projectService)
+				Expect(sa).ToNot(BeNil())
+
+				automountSAToken := false
+
+				expected := &v1.ServiceAccount{
+					TypeMeta: meta.TypeMeta{
+						Kind:       "ServiceAccount",
+						APIVersion: "v1",
+					},
+					ObjectMeta: meta.ObjectMeta{
+						Name:        "mysvcacc",
+						Labels:      configLabels(projectService.Name),
+						Annotations: configAnnotations(projectService.Labels),
+					},
+					AutomountServiceAccountToken: &automountSAToken,
+				}
+
+				Expect(sa).To(Equal(expected))
+			})
+		})
+	})
+
+	Describe("createSecrets", func() {
+		secretName := "my-secret"
+		var secretConfig composego.SecretConfig
+
+		JustBeforeEach(func() {
+			project.Secrets = composego.Secrets{
+				secretName: secretConfig,
+			}
+		})
+
+		Context("for external secrets", func() {
 			BeforeEach(func() {
 				secretConfig = composego.SecretConfig(
 					composego.FileObjectConfig{
@@ -1288,318 +2155,864 @@ var _ = Describe("Transform", func() {
 							External: true,
 						},
 					},
-				)
+				)
+			})
+
+			It("logs a warning and doesn't create a secret", func() {
+				s, err := k.createSecrets()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(s).To(HaveLen(0))
+
+				assertLog(logrus.WarnLevel,
+					"https://kubernetes.io/docs/tasks/inject-data-application/distribute-credentials-secure/",
+					map[string]string{})
+			})
+		})
+
+		Context("for secrets referencing local file", func() {
+
+			When("file exists", func() {
+				BeforeEach(func() {
+					secretConfig = composego.SecretConfig(
+						composego.FileObjectConfig{
+							File: "../../testdata/converter/kubernetes/secrets/secret_file",
+						},
+					)
+				})
+
+				It("returns a slice of secret objects", func() {
+					expected := []*v1.Secret{
+						{
+							TypeMeta: meta.TypeMeta{
+								Kind:       "Secret",
+								APIVersion: "v1",
+							},
+							ObjectMeta: meta.ObjectMeta{
+								Name:   secretName,
+								Labels: configLabels(secretName),
+							},
+							Type: v1.SecretTypeOpaque,
+							Data: map[string][]byte{
+								secretName: {109, 121, 32, 115, 101, 99, 114, 101, 116, 32, 100, 97, 116, 97, 10},
+							},
+						},
+					}
+
+					Expect(k.createSecrets()).To(Equal(expected))
+				})
+			})
+
+			When("file doesn't exist", func() {
+				filePath := "wrong/path"
+
+				BeforeEach(func() {
+					secretConfig = composego.SecretConfig(
+						composego.FileObjectConfig{
+							File: filePath,
+						},
+					)
+				})
+
+				It("returns an error", func() {
+					s, err := k.createSecrets()
+					Expect(err).To(HaveOccurred())
+					Expect(s).To(BeNil())
+					Expect(err).To(MatchError(fmt.Sprintf("open %s: no such file or directory", filePath)))
+				})
+			})
+		})
+	})
+
+	Describe("createPVC", func() {
+
+		Context("with unspecified or wrong volume size", func() {
+			volume := Volumes{
+				VolumeName: "some-name",
+				PVCSize:    "invalid-amount",
+			}
+
+			It("returns an error", func() {
+				_, err := k.createPVC(volume)
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		When("size is provided", func() {
+			pvcSize := "100Mi"
+
+			volume := Volumes{
+				VolumeName: "some-name",
+				PVCSize:    pvcSize,
+			}
+
+			expectedQuantity, _ := resource.ParseQuantity(pvcSize)
+
+			It("creates a PVC object", func() {
+				Expect(k.createPVC(volume)).To(Equal(&v1.PersistentVolumeClaim{
+					TypeMeta: meta.TypeMeta{
+						Kind:       "PersistentVolumeClaim",
+						APIVersion: "v1",
+					},
+					ObjectMeta: meta.ObjectMeta{
+						Name:   volume.VolumeName,
+						Labels: configLabels(volume.VolumeName),
+					},
+					Spec: v1.PersistentVolumeClaimSpec{
+						Resources: v1.ResourceRequirements{
+							Requests: v1.ResourceList{
+								v1.ResourceStorage: expectedQuantity,
+							},
+						},
+						AccessModes: []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+					},
+				}))
+			})
+		})
+
+		When("volume mode is set to read only", func() {
+			volume := Volumes{
+				VolumeName: "some-name",
+				PVCSize:    "10Gi",
+				Mode:       "ro",
+			}
+
+			It("sets correct access mode", func() {
+				var spec v1.PersistentVolumeClaimSpec
+
+				pvc, err := k.createPVC(volume)
+				if pvc != nil {
+					spec = pvc.Spec
+				}
+
+				Expect(spec.AccessModes).To(Equal([]v1.PersistentVolumeAccessMode{v1.ReadOnlyMany}))
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		When("selector value is specified", func() {
+			volume := Volumes{
+				VolumeName:    "some-name",
+				PVCSize:       "10Gi",
+				SelectorValue: "some-selector",
+			}
+
+			It("sets MatchLabels selector in the spec", func() {
+				pvc, _ := k.createPVC(volume)
+				Expect(pvc.Spec.Selector).To(Equal(&meta.LabelSelector{
+					MatchLabels: configLabels(volume.SelectorValue),
+				}))
+			})
+		})
+
+		When("storage class is specified", func() {
+			storageClassName := "ssd"
+
+			volume := Volumes{
+				VolumeName:   "some-name",
+				PVCSize:      "10Gi",
+				StorageClass: storageClassName,
+			}
+
+			It("sets StorageClassName in the spec", func() {
+				pvc, _ := k.createPVC(volume)
+				Expect(pvc.Spec.StorageClassName).To(Equal(&storageClassName))
+			})
+		})
+	})
+
+	Describe("configPorts", func() {
+
+		When("project service has ports defined via ports or expose attributes", func() {
+			BeforeEach(func() {
+				projectService.Ports = []composego.ServicePortConfig{
+					{
+						Target:    8080,
+						Published: 80,
+						HostIP:    "10.10.10.10",
+						Protocol:  "tcp",
+					},
+					{
+						Target:    8080,
+						Published: 9999,
+						HostIP:    "10.10.10.10",
+						Protocol:  "tcp",
+					},
+				}
+			})
+
+			It("returns a slice of unique ContainerPort objects", func() {
+				p := k.configPorts(projectService)
+				Expect(p).To(HaveLen(1))
+				Expect(p).To(Equal([]v1.ContainerPort{
+					{
+						ContainerPort: int32(8080),
+						Protocol:      "TCP",
+						HostIP:        "10.10.10.10",
+					},
+				}))
+			})
+		})
+	})
+
+	Describe("configServicePorts", func() {
+
+		When("project service has ports defined via ports or expose attributes", func() {
+			BeforeEach(func() {
+				projectService.Ports = []composego.ServicePortConfig{
+					{
+						Target:   8080,
+						Protocol: "tcp",
+					},
+					{
+						Target:    8080,
+						Published: 9999,
+						Protocol:  "tcp",
+					},
+				}
+			})
+
+			It("returns a slice of ServicePort objects", func() {
+				p := k.configServicePorts(config.ClusterIPService, projectService)
+				Expect(p).To(HaveLen(2))
+				Expect(p).To(Equal([]v1.ServicePort{
+					{
+						Name:     "8080",
+						Protocol: "TCP",
+						Port:     8080,
+						TargetPort: intstr.IntOrString{
+							Type:   0,
+							IntVal: 8080,
+							StrVal: "8080",
+						},
+						NodePort: 0,
+					},
+					{
+						Name:     "9999",
+						Protocol: "TCP",
+						Port:     9999,
+						TargetPort: intstr.IntOrString{
+							Type:   0,
+							IntVal: 8080,
+							StrVal: "8080",
+						},
+						NodePort: 0,
+					},
+				}))
+			})
+
+			Context("and nodeport service is in use", func() {
+				nodePort := int32(4444)
+
+				BeforeEach(func() {
+					projectService.SvcK8sConfig.Service.NodePort = int(nodePort)
+				})
+
+				It("specifies that port in the service port spec", func() {
+					p := k.configServicePorts(config.NodePortService, projectService)
+					Expect(p[0].NodePort).To(Equal(nodePort))
+				})
+			})
+		})
+	})
+
+	Describe("configCapabilities", func() {
+		When("cap_add capabilities are specified", func() {
+			capAdd := "ALL"
+
+			BeforeEach(func() {
+				projectService.CapAdd = []string{
+					capAdd,
+				}
+			})
+
+			It("returns capabilities as expected", func() {
+				caps := k.configCapabilities(projectService)
+				Expect(caps).To(Equal(&v1.Capabilities{
+					Add: []v1.Capability{
+						v1.Capability(capAdd),
+					},
+					Drop: make([]v1.Capability, 0),
+				}))
+			})
+		})
+
+		When("cap_drops capabilities are specified", func() {
+			capDrop := "NET_ADMIN"
+
+			BeforeEach(func() {
+				projectService.CapDrop = []string{
+					capDrop,
+				}
+			})
+
+			It("returns capabilities as expected", func() {
+				caps := k.configCapabilities(projectService)
+				Expect(caps).To(Equal(&v1.Capabilities{
+					Add: make([]v1.Capability, 0),
+					Drop: []v1.Capability{
+						v1.Capability(capDrop),
+					},
+				}))
 			})
+		})
+	})
 
-			It("logs a warning and doesn't create a secret", func() {
-				s, err := k.createSecrets()
-				Expect(err).ToNot(HaveOccurred())
-				Expect(s).To(HaveLen(0))
+	Describe("configInitContainers", func() {
+		envs := []v1.EnvVar{
+			{Name: "DB_HOST", Value: "db"},
+			{Name: "LOG_LEVEL", Value: "info"},
+		}
+		volumeMounts := []v1.VolumeMount{
+			{Name: "data", MountPath: "/data"},
+		}
 
-				assertLog(logrus.WarnLevel,
-					"https://kubernetes.io/docs/tasks/inject-data-application/distribute-credentials-secure/",
-					map[string]string{})
+		Context("without init containers", func() {
+			It("returns no init containers", func() {
+				Expect(k.configInitContainers(projectService, envs, volumeMounts)).To(BeEmpty())
 			})
 		})
 
-		Context("for secrets referencing local file", func() {
+		Context("with init containers", func() {
+			BeforeEach(func() {
+				noMounts := false
+				projectService.SvcK8sConfig.Workload.InitContainers = map[string]config.InitContainer{
+					"migrate": {
+						Image:   "app:latest",
+						Command: []string{"./migrate"},
+						Args:    []string{"up"},
+						Env:     map[string]string{"LOG_LEVEL": "debug"},
+					},
+					"assets": {
+						Image:               "app:latest",
+						Command:             []string{"./compile-assets"},
+						InheritVolumeMounts: &noMounts,
+					},
+				}
+			})
 
-			When("file exists", func() {
-				BeforeEach(func() {
-					secretConfig = composego.SecretConfig(
-						composego.FileObjectConfig{
-							File: "../../testdata/converter/kubernetes/secrets/secret_file",
-						},
-					)
-				})
+			It("sorts them by name", func() {
+				containers := k.configInitContainers(projectService, envs, volumeMounts)
+				Expect(containers).To(HaveLen(2))
+				Expect(containers[0].Name).To(Equal("assets"))
+				Expect(containers[1].Name).To(Equal("migrate"))
+			})
 
-				It("returns a slice of secret objects", func() {
-					expected := []*v1.Secret{
-						{
-							TypeMeta: meta.TypeMeta{
-								Kind:       "Secret",
-								APIVersion: "v1",
-							},
-							ObjectMeta: meta.ObjectMeta{
-								Name:   secretName,
-								Labels: configLabels(secretName),
-							},
-							Type: v1.SecretTypeOpaque,
-							Data: map[string][]byte{
-								secretName: {109, 121, 32, 115, 101, 99, 114, 101, 116, 32, 100, 97, 116, 97, 10},
-							},
-						},
-					}
+			It("configures their image, command and args", func() {
+				migrate := k.configInitContainers(projectService, envs, volumeMounts)[1]
+				Expect(migrate.Image).To(Equal("app:latest"))
+				Expect(migrate.Command).To(Equal([]string{"./migrate"}))
+				Expect(migrate.Args).To(Equal([]string{"up"}))
+			})
 
-					Expect(k.createSecrets()).To(Equal(expected))
-				})
+			It("inherits the workload container env vars, overridden by their own", func() {
+				migrate := k.configInitContainers(projectService, envs, volumeMounts)[1]
+				Expect(migrate.Env).To(Equal([]v1.EnvVar{
+					{Name: "DB_HOST", Value: "db"},
+					{Name: "LOG_LEVEL", Value: "debug"},
+				}))
 			})
 
-			When("file doesn't exist", func() {
-				filePath := "wrong/path"
+			It("inherits the workload container volume mounts unless disabled", func() {
+				containers := k.configInitContainers(projectService, envs, volumeMounts)
+				Expect(containers[0].VolumeMounts).To(BeEmpty())
+				Expect(containers[1].VolumeMounts).To(Equal(volumeMounts))
+			})
+		})
+	})
 
-				BeforeEach(func() {
-					secretConfig = composego.SecretConfig(
-						composego.FileObjectConfig{
-							File: filePath,
+	Describe("configSidecars", func() {
+		Context("without sidecars", func() {
+			It("returns no sidecars", func() {
+				Expect(k.configSidecars(projectService)).To(BeEmpty())
+			})
+		})
+
+		Context("with sidecars", func() {
+			BeforeEach(func() {
+				projectService.SvcK8sConfig.Workload.Sidecars = map[string]config.Sidecar{
+					"proxy": {
+						Image: "envoyproxy/envoy",
+						Args:  []string{"-c", "/etc/envoy.yaml"},
+						Ports: []int{9901},
+						Resource: config.Resource{
+							CPU:       "100m",
+							MaxMemory: "128Mi",
 						},
-					)
-				})
+					},
+					"logger": {
+						Image:   "fluent/fluent-bit",
+						Command: []string{"/fluent-bit/bin/fluent-bit"},
+					},
+				}
+			})
 
-				It("returns an error", func() {
-					s, err := k.createSecrets()
-					Expect(err).To(HaveOccurred())
-					Expect(s).To(BeNil())
-					Expect(err).To(MatchError(fmt.Sprintf("open %s: no such file or directory", filePath)))
-				})
+			It("sorts them by name", func() {
+				containers := k.configSidecars(projectService)
+				Expect(containers).To(HaveLen(2))
+				Expect(containers[0].Name).To(Equal("logger"))
+				Expect(containers[1].Name).To(Equal("proxy"))
+			})
+
+			It("configures their image, command, args and ports", func() {
+				proxy := k.configSidecars(projectService)[1]
+				Expect(proxy.Image).To(Equal("envoyproxy/envoy"))
+				Expect(proxy.Args).To(Equal([]string{"-c", "/etc/envoy.yaml"}))
+				Expect(proxy.Ports).To(Equal([]v1.ContainerPort{{ContainerPort: 9901, Protocol: v1.ProtocolTCP}}))
+			})
+
+			It("configures their own resource requests and limits", func() {
+				proxy := k.configSidecars(projectService)[1]
+				Expect(proxy.Resources.Requests).To(Equal(v1.ResourceList{v1.ResourceCPU: resource.MustParse("100m")}))
+				Expect(proxy.Resources.Limits).To(Equal(v1.ResourceList{v1.ResourceMemory: resource.MustParse("128Mi")}))
+			})
+
+			It("appends them to the pod spec after the workload container", func() {
+				spec := k.initPodSpec(projectService)
+				Expect(spec.Containers).To(HaveLen(3))
+				Expect(spec.Containers[0].Name).To(Equal(projectService.Name))
+				Expect(spec.Containers[1].Name).To(Equal("logger"))
+				Expect(spec.Containers[2].Name).To(Equal("proxy"))
 			})
 		})
 	})
 
-	Describe("createPVC", func() {
-
-		Context("with unspecified or wrong volume size", func() {
-			volume := Volumes{
-				VolumeName: "some-name",
-				PVCSize:    "invalid-amount",
+	Describe("checkContainerNames", func() {
+		It("accepts uniquely named containers", func() {
+			spec := v1.PodSpec{
+				InitContainers: []v1.Container{{Name: "wait-for-db"}, {Name: "migrate"}},
+				Containers:     []v1.Container{{Name: "web"}, {Name: "logger"}, {Name: "proxy"}},
 			}
+			Expect(checkContainerNames(spec)).To(Succeed())
+		})
 
-			It("returns an error", func() {
-				_, err := k.createPVC(volume)
-				Expect(err).To(HaveOccurred())
-			})
+		It("rejects a sidecar named like the workload container", func() {
+			err := checkContainerNames(v1.PodSpec{Containers: []v1.Container{{Name: "web"}, {Name: "logger"}, {Name: "web"}}})
+			Expect(err).To(MatchError("container web has the same name as another container in the pod"))
 		})
 
-		When("size is provided", func() {
-			pvcSize := "100Mi"
+		It("rejects sidecars sharing a name", func() {
+			err := checkContainerNames(v1.PodSpec{Containers: []v1.Container{{Name: "web"}, {Name: "proxy"}, {Name: "proxy"}}})
+			Expect(err).To(MatchError("container proxy has the same name as another container in the pod"))
+		})
 
-			volume := Volumes{
-				VolumeName: "some-name",
-				PVCSize:    pvcSize,
+		It("rejects an init container named like an app container", func() {
+			spec := v1.PodSpec{
+				InitContainers: []v1.Container{{Name: "migrate"}},
+				Containers:     []v1.Container{{Name: "web"}, {Name: "migrate"}},
 			}
+			Expect(checkContainerNames(spec)).To(MatchError("container migrate has the same name as another container in the pod"))
+		})
 
-			expectedQuantity, _ := resource.ParseQuantity(pvcSize)
+		It("rejects a configured init container named like a dependency wait container", func() {
+			spec := v1.PodSpec{
+				InitContainers: []v1.Container{{Name: "wait-for-db"}, {Name: "wait-for-db"}},
+				Containers:     []v1.Container{{Name: "web"}},
+			}
+			Expect(checkContainerNames(spec)).To(MatchError("container wait-for-db has the same name as another container in the pod"))
+		})
+	})
 
-			It("creates a PVC object", func() {
-				Expect(k.createPVC(volume)).To(Equal(&v1.PersistentVolumeClaim{
-					TypeMeta: meta.TypeMeta{
-						Kind:       "PersistentVolumeClaim",
-						APIVersion: "v1",
+	Describe("configDependsOn", func() {
+		BeforeEach(func() {
+			projectService.DependsOn = []string{"db", "worker"}
+
+			project.Services = composego.Services{
+				{
+					Name:  "db",
+					Image: "mysql",
+					Ports: []composego.ServicePortConfig{{Target: 3306, Protocol: "tcp"}},
+					Extensions: map[string]interface{}{
+						config.K8SExtensionKey: map[string]interface{}{
+							"service": map[string]interface{}{"type": "ClusterIP"},
+						},
 					},
-					ObjectMeta: meta.ObjectMeta{
-						Name:   volume.VolumeName,
-						Labels: configLabels(volume.VolumeName),
+				},
+				{Name: "worker", Image: "worker"},
+			}
+		})
+
+		When("no depends_on strategy is specified", func() {
+			It("records dependencies in an annotation", func() {
+				annotations, containers, err := k.configDependsOn(projectService)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(annotations).To(Equal(map[string]string{DependsOnAnnotation: "db,worker"}))
+				Expect(containers).To(BeEmpty())
+			})
+		})
+
+		When("init container depends_on strategy is specified", func() {
+			BeforeEach(func() {
+				projectService.Labels = composego.Labels{DependsOnStrategyLabel: DependsOnInitContainerStrategy}
+			})
+
+			It("adds init containers waiting for dependencies exposing a k8s service port only", func() {
+				annotations, containers, err := k.configDependsOn(projectService)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(annotations).To(BeEmpty())
+				Expect(containers).To(Equal([]v1.Container{
+					{
+						Name:    "wait-for-db",
+						Image:   DependsOnWaitImage,
+						Command: []string{"sh", "-c", "until nc -z db 3306; do echo waiting for db; sleep 2; done"},
 					},
-					Spec: v1.PersistentVolumeClaimSpec{
-						Resources: v1.ResourceRequirements{
-							Requests: v1.ResourceList{
-								v1.ResourceStorage: expectedQuantity,
-							},
+				}))
+			})
+
+			It("doesn't wait for excluded dependencies", func() {
+				k.Excluded = []string{"db"}
+
+				_, containers, err := k.configDependsOn(projectService)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(containers).To(BeEmpty())
+			})
+
+			It("doesn't wait for dependencies skipped from rendering", func() {
+				project.Services[0].Labels = composego.Labels{SkipRenderLabel: "true"}
+
+				_, containers, err := k.configDependsOn(projectService)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(containers).To(BeEmpty())
+			})
+
+			It("resolves dependencies named with characters normalised in rendered names", func() {
+				project.Services[0].Name = "my_db"
+				projectService.DependsOn = []string{"my_db"}
+
+				_, containers, err := k.configDependsOn(projectService)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(containers).To(HaveLen(1))
+				Expect(containers[0].Name).To(Equal("wait-for-my-db"))
+				Expect(containers[0].Command[2]).To(ContainSubstring("nc -z my-db 3306"))
+			})
+		})
+
+		When("none depends_on strategy is specified", func() {
+			BeforeEach(func() {
+				projectService.Labels = composego.Labels{DependsOnStrategyLabel: DependsOnNoneStrategy}
+			})
+
+			It("ignores dependencies", func() {
+				annotations, containers, err := k.configDependsOn(projectService)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(annotations).To(BeEmpty())
+				Expect(containers).To(BeEmpty())
+			})
+		})
+
+		When("unsupported depends_on strategy is specified", func() {
+			BeforeEach(func() {
+				projectService.Labels = composego.Labels{DependsOnStrategyLabel: "sidecar"}
+			})
+
+			It("returns an error", func() {
+				_, _, err := k.configDependsOn(projectService)
+				Expect(err).To(MatchError(ContainSubstring(`unsupported depends_on strategy "sidecar"`)))
+			})
+		})
+	})
+
+	Describe("configTmpfs", func() {
+		When("tmpfs is specified as a single path", func() {
+			BeforeEach(func() {
+				projectService.Tmpfs = composego.StringList{"/run"}
+			})
+
+			It("mounts a memory backed EmptyDir volume at the path", func() {
+				mounts, volumes, err := k.configTmpfs(projectService)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mounts).To(Equal([]v1.VolumeMount{
+					{Name: "web-tmpfs0", MountPath: "/run"},
+				}))
+				Expect(volumes).To(Equal([]v1.Volume{
+					{
+						Name: "web-tmpfs0",
+						VolumeSource: v1.VolumeSource{
+							EmptyDir: &v1.EmptyDirVolumeSource{Medium: v1.StorageMediumMemory},
 						},
-						AccessModes: []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
 					},
 				}))
 			})
 		})
 
-		When("volume mode is set to read only", func() {
-			volume := Volumes{
-				VolumeName: "some-name",
-				PVCSize:    "10Gi",
-				Mode:       "ro",
-			}
+		When("tmpfs is specified as a list with options", func() {
+			BeforeEach(func() {
+				projectService.Tmpfs = composego.StringList{"/run", "/tmp:rw,size=64m", "/cache:ro,size=1g,mode=1777"}
+			})
 
-			It("sets correct access mode", func() {
-				var spec v1.PersistentVolumeClaimSpec
+			It("mounts a volume per entry", func() {
+				mounts, volumes, err := k.configTmpfs(projectService)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mounts).To(HaveLen(3))
+				Expect(volumes).To(HaveLen(3))
+				Expect(mounts[1]).To(Equal(v1.VolumeMount{Name: "web-tmpfs1", MountPath: "/tmp"}))
+				Expect(mounts[2]).To(Equal(v1.VolumeMount{Name: "web-tmpfs2", MountPath: "/cache", ReadOnly: true}))
+			})
 
-				pvc, err := k.createPVC(volume)
-				if pvc != nil {
-					spec = pvc.Spec
-				}
+			It("maps sizes to EmptyDir size limits", func() {
+				_, volumes, err := k.configTmpfs(projectService)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(volumes[0].EmptyDir.SizeLimit).To(BeNil())
+				Expect(volumes[1].EmptyDir.SizeLimit.String()).To(Equal("64Mi"))
+				Expect(volumes[2].EmptyDir.SizeLimit.String()).To(Equal("1Gi"))
+			})
+		})
 
-				Expect(spec.AccessModes).To(Equal([]v1.PersistentVolumeAccessMode{v1.ReadOnlyMany}))
-				Expect(err).ToNot(HaveOccurred())
+		When("tmpfs size is invalid", func() {
+			BeforeEach(func() {
+				projectService.Tmpfs = composego.StringList{"/tmp:size=lots"}
+			})
+
+			It("returns an error", func() {
+				_, _, err := k.configTmpfs(projectService)
+				Expect(err).To(MatchError(ContainSubstring(`unsupported size "lots"`)))
 			})
 		})
+	})
 
-		When("selector value is specified", func() {
-			volume := Volumes{
-				VolumeName:    "some-name",
-				PVCSize:       "10Gi",
-				SelectorValue: "some-selector",
+	Describe("configSecretVolumes", func() {
+		BeforeEach(func() {
+			project.Secrets = composego.Secrets{
+				"db-password": composego.SecretConfig{
+					File: "./secrets/db-password.txt",
+				},
+				"api-token": composego.SecretConfig{
+					External: composego.External{
+						External: true,
+						Name:     "shared-api-token",
+					},
+				},
 			}
+		})
 
-			It("sets MatchLabels selector in the spec", func() {
-				pvc, _ := k.createPVC(volume)
-				Expect(pvc.Spec.Selector).To(Equal(&meta.LabelSelector{
-					MatchLabels: configLabels(volume.SelectorValue),
+		AfterEach(func() {
+			project.Secrets = nil
+		})
+
+		When("service references a file backed secret", func() {
+			BeforeEach(func() {
+				projectService.Secrets = []composego.ServiceSecretConfig{
+					{Source: "db-password", Target: "/etc/app/db-password"},
+				}
+			})
+
+			It("mounts the secret volume at the target path", func() {
+				mounts, volumes := k.configSecretVolumes(projectService)
+				Expect(volumes).To(Equal([]v1.Volume{
+					{
+						Name: "db-password",
+						VolumeSource: v1.VolumeSource{
+							Secret: &v1.SecretVolumeSource{
+								SecretName: "db-password",
+								Items:      []v1.KeyToPath{{Key: "db-password", Path: "db-password"}},
+							},
+						},
+					},
+				}))
+				Expect(mounts).To(Equal([]v1.VolumeMount{
+					{Name: "db-password", MountPath: "/etc/app"},
 				}))
 			})
 		})
 
-		When("storage class is specified", func() {
-			storageClassName := "ssd"
+		When("service references an external secret", func() {
+			BeforeEach(func() {
+				projectService.Secrets = []composego.ServiceSecretConfig{
+					{Source: "api-token"},
+				}
+			})
 
-			volume := Volumes{
-				VolumeName:   "some-name",
-				PVCSize:      "10Gi",
-				StorageClass: storageClassName,
-			}
+			It("references the secret by its external name", func() {
+				mounts, volumes := k.configSecretVolumes(projectService)
+				Expect(volumes).To(HaveLen(1))
+				Expect(volumes[0].Secret.SecretName).To(Equal("shared-api-token"))
+				Expect(volumes[0].Secret.Items).To(Equal([]v1.KeyToPath{{Key: "api-token", Path: "api-token"}}))
+				Expect(mounts).To(Equal([]v1.VolumeMount{
+					{Name: "api-token", MountPath: "/run/secrets/api-token"},
+				}))
+			})
+		})
 
-			It("sets StorageClassName in the spec", func() {
-				pvc, _ := k.createPVC(volume)
-				Expect(pvc.Spec.StorageClassName).To(Equal(&storageClassName))
+		When("service references a secret not declared in the project", func() {
+			BeforeEach(func() {
+				projectService.Secrets = []composego.ServiceSecretConfig{
+					{Source: "unknown"},
+				}
+			})
+
+			It("doesn't mount it", func() {
+				mounts, volumes := k.configSecretVolumes(projectService)
+				Expect(volumes).To(BeEmpty())
+				Expect(mounts).To(BeEmpty())
 			})
 		})
 	})
 
-	Describe("configPorts", func() {
-
-		When("project service has ports defined via ports or expose attributes", func() {
+	Describe("configVolumes", func() {
+		When("service mounts a read only named volume", func() {
 			BeforeEach(func() {
-				projectService.Ports = []composego.ServicePortConfig{
-					{
-						Target:    8080,
-						Published: 80,
-						HostIP:    "10.10.10.10",
-						Protocol:  "tcp",
-					},
+				project.Volumes = composego.Volumes{
+					"data": composego.VolumeConfig{Name: "data"},
+				}
+				projectService.Volumes = []composego.ServiceVolumeConfig{
 					{
-						Target:    8080,
-						Published: 9999,
-						HostIP:    "10.10.10.10",
-						Protocol:  "tcp",
+						Type:     "volume",
+						Source:   "data",
+						Target:   "/var/lib/data",
+						ReadOnly: true,
+						Volume:   &composego.ServiceVolumeVolume{NoCopy: true},
 					},
 				}
 			})
 
-			It("returns a slice of unique ContainerPort objects", func() {
-				p := k.configPorts(projectService)
-				Expect(p).To(HaveLen(1))
-				Expect(p).To(Equal([]v1.ContainerPort{
-					{
-						ContainerPort: int32(8080),
-						Protocol:      "TCP",
-						HostIP:        "10.10.10.10",
-					},
+			It("marks the volume mount read only", func() {
+				mounts, _, _, _, err := k.configVolumes(projectService)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mounts).To(Equal([]v1.VolumeMount{
+					{Name: "data", MountPath: "/var/lib/data", ReadOnly: true},
 				}))
 			})
-		})
-	})
 
-	Describe("configServicePorts", func() {
+			It("claims the named volume read only", func() {
+				_, volumes, pvcs, _, err := k.configVolumes(projectService)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(volumes).To(HaveLen(1))
+				Expect(volumes[0].PersistentVolumeClaim).To(Equal(&v1.PersistentVolumeClaimVolumeSource{
+					ClaimName: "data",
+					ReadOnly:  true,
+				}))
+				Expect(pvcs).To(HaveLen(1))
+				Expect(pvcs[0].Name).To(Equal("data"))
+				Expect(pvcs[0].Spec.AccessModes).To(Equal([]v1.PersistentVolumeAccessMode{v1.ReadOnlyMany}))
+			})
+		})
 
-		When("project service has ports defined via ports or expose attributes", func() {
+		When("service mounts a bind volume", func() {
 			BeforeEach(func() {
-				projectService.Ports = []composego.ServicePortConfig{
-					{
-						Target:   8080,
-						Protocol: "tcp",
-					},
+				projectService.Volumes = []composego.ServiceVolumeConfig{
 					{
-						Target:    8080,
-						Published: 9999,
-						Protocol:  "tcp",
+						Type:   "bind",
+						Source: "./config",
+						Target: "/etc/app",
 					},
 				}
 			})
 
-			It("returns a slice of ServicePort objects", func() {
-				p := k.configServicePorts(config.ClusterIPService, projectService)
-				Expect(p).To(HaveLen(2))
-				Expect(p).To(Equal([]v1.ServicePort{
-					{
-						Name:     "8080",
-						Protocol: "TCP",
-						Port:     8080,
-						TargetPort: intstr.IntOrString{
-							Type:   0,
-							IntVal: 8080,
-							StrVal: "8080",
-						},
-						NodePort: 0,
-					},
-					{
-						Name:     "9999",
-						Protocol: "TCP",
-						Port:     9999,
-						TargetPort: intstr.IntOrString{
-							Type:   0,
-							IntVal: 8080,
-							StrVal: "8080",
-						},
-						NodePort: 0,
-					},
-				}))
+			It("mounts a volume claimed by the service claim name", func() {
+				mounts, volumes, pvcs, _, err := k.configVolumes(projectService)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mounts).To(HaveLen(1))
+				Expect(mounts[0].Name).To(Equal("web-claim0"))
+				Expect(mounts[0].MountPath).To(Equal("/etc/app"))
+				Expect(mounts[0].ReadOnly).To(BeFalse())
+				Expect(volumes[0].PersistentVolumeClaim.ClaimName).To(Equal("web-claim0"))
+				Expect(pvcs).To(HaveLen(1))
+				Expect(pvcs[0].Name).To(Equal("web-claim0"))
+				Expect(pvcs[0].Spec.AccessModes).To(Equal([]v1.PersistentVolumeAccessMode{v1.ReadWriteOnce}))
 			})
+		})
+	})
 
-			Context("and nodeport service is in use", func() {
-				nodePort := int32(4444)
+	Describe("setVolumeModes", func() {
+		var volumes []v1.Volume
 
-				BeforeEach(func() {
-					projectService.SvcK8sConfig.Service.NodePort = int(nodePort)
-				})
+		BeforeEach(func() {
+			volumes = []v1.Volume{
+				{Name: "ssh-key", VolumeSource: v1.VolumeSource{Secret: &v1.SecretVolumeSource{SecretName: "ssh-key"}}},
+				{Name: "scripts", VolumeSource: v1.VolumeSource{ConfigMap: &v1.ConfigMapVolumeSource{}}},
+				{Name: "data", VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}}},
+			}
+		})
 
-				It("specifies that port in the service port spec", func() {
-					p := k.configServicePorts(config.NodePortService, projectService)
-					Expect(p[0].NodePort).To(Equal(nodePort))
-				})
-			})
+		It("sets the default mode of the named Secret & ConfigMap volumes", func() {
+			k.setVolumeModes(projectService, volumes, map[string]int32{"ssh-key": 0400, "scripts": 0755})
+			Expect(*volumes[0].Secret.DefaultMode).To(BeEquivalentTo(0400))
+			Expect(*volumes[1].ConfigMap.DefaultMode).To(BeEquivalentTo(0755))
+		})
+
+		It("leaves volumes without a mode to the K8s default", func() {
+			k.setVolumeModes(projectService, volumes, map[string]int32{"ssh-key": 0400})
+			Expect(volumes[1].ConfigMap.DefaultMode).To(BeNil())
+		})
+
+		It("ignores modes of other volume types", func() {
+			k.setVolumeModes(projectService, volumes, map[string]int32{"data": 0400})
+			Expect(volumes[2].EmptyDir).To(Equal(&v1.EmptyDirVolumeSource{}))
 		})
 	})
 
-	Describe("configCapabilities", func() {
-		When("cap_add capabilities are specified", func() {
-			capAdd := "ALL"
+	Describe("configVolumes with ConfigMap volumes", func() {
+		JustBeforeEach(func() {
+			k.Opt.Volumes = "configMap"
+		})
 
+		When("service mounts a directory", func() {
 			BeforeEach(func() {
-				projectService.CapAdd = []string{
-					capAdd,
+				projectService.Volumes = []composego.ServiceVolumeConfig{
+					{Type: "bind", Source: "../../testdata/converter/kubernetes/configmaps", Target: "/etc/app"},
 				}
 			})
 
-			It("returns capabilities as expected", func() {
-				caps := k.configCapabilities(projectService)
-				Expect(caps).To(Equal(&v1.Capabilities{
-					Add: []v1.Capability{
-						v1.Capability(capAdd),
-					},
-					Drop: make([]v1.Capability, 0),
+			It("mounts the whole ConfigMap directory by default", func() {
+				mounts, _, _, _, err := k.configVolumes(projectService)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mounts).To(Equal([]v1.VolumeMount{
+					{Name: "web-cm0", MountPath: "/etc/app"},
 				}))
 			})
+
+			Context("with the subpath config mount mode label", func() {
+				BeforeEach(func() {
+					projectService.Labels = composego.Labels{ConfigMountModeLabel: ConfigMountModeSubPath}
+				})
+
+				It("mounts each of the directory files in place with a sub path", func() {
+					mounts, _, _, _, err := k.configVolumes(projectService)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(mounts).To(Equal([]v1.VolumeMount{
+						{Name: "web-cm0", MountPath: "/etc/app/config-a", SubPath: "config-a"},
+						{Name: "web-cm0", MountPath: "/etc/app/config-b", SubPath: "config-b"},
+					}))
+				})
+			})
 		})
 
-		When("cap_drops capabilities are specified", func() {
-			capDrop := "NET_ADMIN"
+		When("service mounts a single file", func() {
+			source := "../../testdata/converter/kubernetes/configmaps/config-a"
 
 			BeforeEach(func() {
-				projectService.CapDrop = []string{
-					capDrop,
+				project.Configs = composego.Configs{
+					"config-a": composego.ConfigObjConfig(composego.FileObjectConfig{Name: "config-a", File: source}),
+				}
+				projectService.Volumes = []composego.ServiceVolumeConfig{
+					{Type: "bind", Source: source, Target: "/etc/app/app.conf"},
 				}
 			})
 
-			It("returns capabilities as expected", func() {
-				caps := k.configCapabilities(projectService)
-				Expect(caps).To(Equal(&v1.Capabilities{
-					Add: make([]v1.Capability, 0),
-					Drop: []v1.Capability{
-						v1.Capability(capDrop),
-					},
+			It("mounts the file with a sub path by default", func() {
+				mounts, _, _, _, err := k.configVolumes(projectService)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mounts).To(Equal([]v1.VolumeMount{
+					{Name: "web-cm0", MountPath: "/etc/app/app.conf", SubPath: "app.conf"},
 				}))
 			})
-		})
-	})
-
-	// @todo
-	Describe("configTmpfs", func() {
-	})
 
-	// @todo
-	Describe("configSecretVolumes", func() {
-	})
+			Context("with the directory config mount mode label", func() {
+				BeforeEach(func() {
+					projectService.Labels = composego.Labels{ConfigMountModeLabel: ConfigMountModeDirectory}
+				})
 
-	// @todo
-	Describe("configVolumes", func() {
+				It("mounts the file's directory without a sub path", func() {
+					mounts, volumes, _, _, err := k.configVolumes(projectService)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(mounts).To(Equal([]v1.VolumeMount{
+						{Name: "web-cm0", MountPath: "/etc/app"},
+					}))
+					Expect(volumes[0].ConfigMap.Items).To(Equal([]v1.KeyToPath{
+						{Key: "config-a", Path: "app.conf"},
+					}))
+				})
+			})
+		})
 	})
 
 	Describe("configEmptyVolumeSource", func() {
@@ -1854,61 +3267,269 @@ var _ = Describe("Transform", func() {
 
 			Context("as container resource resource field", func() {
 
-				Context("with valid container resource eg. container.{my-container}.limits.cpu", func() {
-					configRef := "container.my-container.limits.cpu"
+				Context("with valid container resource eg. container.{my-container}.limits.cpu", func() {
+					configRef := "container.my-container.limits.cpu"
+
+					BeforeEach(func() {
+						projectService.Environment = composego.MappingWithEquals{
+							"MY_CONFIG": &configRef,
+						}
+					})
+
+					It("expands that env variable to reference container resource field", func() {
+						vars, err := k.configEnvs(projectService)
+
+						Expect(vars[0].ValueFrom).To(Equal(&v1.EnvVarSource{
+							ResourceFieldRef: &v1.ResourceFieldSelector{
+								ContainerName: "my-container",
+								Resource:      "limits.cpu",
+							},
+						}))
+						Expect(err).ToNot(HaveOccurred())
+					})
+				})
+
+				Context("with not supported resource", func() {
+					configRef := "container.my-container.unsupported.resource"
+
+					BeforeEach(func() {
+						projectService.Environment = composego.MappingWithEquals{
+							"MY_CONFIG": &configRef,
+						}
+					})
+
+					It("doesn't add environment variable with misconfigured reference", func() {
+						vars, err := k.configEnvs(projectService)
+
+						Expect(vars).To(HaveLen(0))
+
+						assertLog(logrus.WarnLevel,
+							"Unsupported Container resource reference: unsupported.resource",
+							map[string]string{
+								"project-service": projectService.Name,
+								"env-var":         "MY_CONFIG",
+								"container":       "my-container",
+								"resource":        "unsupported.resource",
+							})
+
+						Expect(err).ToNot(HaveOccurred())
+					})
+				})
+			})
+		})
+	})
+
+	// @todo
+	// covered by partial methods specs
+	Describe("createKubernetesObjects", func() {
+		Context("with a Job workload type", func() {
+			BeforeEach(func() {
+				projectService.SvcK8sConfig.Workload.Type = config.JobWorkload
+				projectService.SvcK8sConfig.Workload.Replicas = 3
+			})
+
+			It("creates a Job with completions from replicas", func() {
+				objects, err := k.createKubernetesObjects(projectService)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(objects).To(HaveLen(1))
+
+				job, ok := objects[0].(*v1batch.Job)
+				Expect(ok).To(BeTrue())
+				Expect(*job.Spec.Completions).To(BeEquivalentTo(3))
+			})
+
+			It("sets the Job backoff limit and time to live", func() {
+				backoffLimit, ttl := int32(4), int32(3600)
+				projectService.SvcK8sConfig.Workload.BackoffLimit = &backoffLimit
+				projectService.SvcK8sConfig.Workload.TTLSecondsAfterFinished = &ttl
+
+				objects, err := k.createKubernetesObjects(projectService)
+				Expect(err).NotTo(HaveOccurred())
+
+				job := objects[0].(*v1batch.Job)
+				Expect(*job.Spec.BackoffLimit).To(BeEquivalentTo(4))
+				Expect(*job.Spec.TTLSecondsAfterFinished).To(BeEquivalentTo(3600))
+			})
+		})
+
+		Context("with a Job workload type and a schedule", func() {
+			BeforeEach(func() {
+				backoffLimit, ttl := int32(1), int32(60)
+				projectService.SvcK8sConfig.Workload.Type = config.JobWorkload
+				projectService.SvcK8sConfig.Workload.Replicas = 2
+				projectService.SvcK8sConfig.Workload.Schedule = "0 2 * * *"
+				projectService.SvcK8sConfig.Workload.BackoffLimit = &backoffLimit
+				projectService.SvcK8sConfig.Workload.TTLSecondsAfterFinished = &ttl
+			})
+
+			It("wraps the Job template in a CronJob on the schedule", func() {
+				objects, err := k.createKubernetesObjects(projectService)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(objects).To(HaveLen(1))
+
+				cronJob, ok := objects[0].(*v1beta1batch.CronJob)
+				Expect(ok).To(BeTrue())
+				Expect(cronJob.Spec.Schedule).To(Equal("0 2 * * *"))
+				Expect(*cronJob.Spec.JobTemplate.Spec.Completions).To(BeEquivalentTo(2))
+				Expect(cronJob.Spec.JobTemplate.Spec.Selector).To(BeNil())
+			})
+
+			It("honours the Job backoff limit and time to live", func() {
+				objects, err := k.createKubernetesObjects(projectService)
+				Expect(err).NotTo(HaveOccurred())
+
+				cronJob := objects[0].(*v1beta1batch.CronJob)
+				Expect(*cronJob.Spec.JobTemplate.Spec.BackoffLimit).To(BeEquivalentTo(1))
+				Expect(*cronJob.Spec.JobTemplate.Spec.TTLSecondsAfterFinished).To(BeEquivalentTo(60))
+			})
+		})
+
+		Context("with a Deployment workload type and a schedule", func() {
+			BeforeEach(func() {
+				projectService.SvcK8sConfig.Workload.Type = config.DeploymentWorkload
+				projectService.SvcK8sConfig.Workload.Schedule = "0 2 * * *"
+			})
+
+			It("ignores the schedule", func() {
+				objects, err := k.createKubernetesObjects(projectService)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(objects[0]).To(BeAssignableToTypeOf(&v1apps.Deployment{}))
+			})
+		})
+
+		Context("with an unsupported config mount mode label", func() {
+			BeforeEach(func() {
+				projectService.Labels = composego.Labels{ConfigMountModeLabel: "copy"}
+			})
+
+			It("returns an error explaining the live update tradeoff of each mode", func() {
+				_, err := k.createKubernetesObjects(projectService)
+				Expect(err).To(MatchError(ContainSubstring(`unsupported kev.workload.config-mount-mode label value "copy"`)))
+				Expect(err).To(MatchError(ContainSubstring("don't receive ConfigMap updates live")))
+			})
+		})
+
+		Context("with a vertical pod autoscaler mode label", func() {
+			BeforeEach(func() {
+				projectService.SvcK8sConfig.Workload.Type = config.DeploymentWorkload
+				projectService.Labels = composego.Labels{VPAModeLabel: "off"}
+			})
+
+			It("creates a vertical pod autoscaler targeting the workload in the given update mode", func() {
+				objects, err := k.createKubernetesObjects(projectService)
+				Expect(err).NotTo(HaveOccurred())
+
+				var vpa *VerticalPodAutoscaler
+				for _, o := range objects {
+					if v, ok := o.(*VerticalPodAutoscaler); ok {
+						vpa = v
+					}
+				}
+				Expect(vpa).NotTo(BeNil())
+				Expect(vpa.APIVersion).To(Equal("autoscaling.k8s.io/v1"))
+				Expect(vpa.Spec.TargetRef.Kind).To(Equal("Deployment"))
+				Expect(vpa.Spec.TargetRef.Name).To(Equal(projectService.Name))
+				Expect(vpa.Spec.UpdatePolicy.UpdateMode).To(Equal(VPAUpdateModeOff))
+			})
+
+			It("targets the workload when a pod disruption budget and horizontal pod autoscaler are also created", func() {
+				projectService.SvcK8sConfig.Workload.PodDisruptionBudget.MinAvailable = "1"
+				projectService.SvcK8sConfig.Workload.Autoscale.MaxReplicas = 5
 
-					BeforeEach(func() {
-						projectService.Environment = composego.MappingWithEquals{
-							"MY_CONFIG": &configRef,
-						}
-					})
+				objects, err := k.createKubernetesObjects(projectService)
+				Expect(err).NotTo(HaveOccurred())
 
-					It("expands that env variable to reference container resource field", func() {
-						vars, err := k.configEnvs(projectService)
+				var vpa *VerticalPodAutoscaler
+				var pdb, hpa bool
+				for _, o := range objects {
+					switch v := o.(type) {
+					case *VerticalPodAutoscaler:
+						vpa = v
+					case *policyv1beta1.PodDisruptionBudget:
+						pdb = true
+					case *autoscalingv2beta2.HorizontalPodAutoscaler:
+						hpa = true
+					}
+				}
+				Expect(pdb).To(BeTrue())
+				Expect(hpa).To(BeTrue())
+				Expect(vpa).NotTo(BeNil())
+				Expect(vpa.Spec.TargetRef.Kind).To(Equal("Deployment"))
+			})
 
-						Expect(vars[0].ValueFrom).To(Equal(&v1.EnvVarSource{
-							ResourceFieldRef: &v1.ResourceFieldSelector{
-								ContainerName: "my-container",
-								Resource:      "limits.cpu",
-							},
-						}))
-						Expect(err).ToNot(HaveOccurred())
-					})
-				})
+			It("targets DaemonSet workloads", func() {
+				projectService.SvcK8sConfig.Workload.Type = config.DaemonSetWorkload
 
-				Context("with not supported resource", func() {
-					configRef := "container.my-container.unsupported.resource"
+				objects, err := k.createKubernetesObjects(projectService)
+				Expect(err).NotTo(HaveOccurred())
 
-					BeforeEach(func() {
-						projectService.Environment = composego.MappingWithEquals{
-							"MY_CONFIG": &configRef,
-						}
-					})
+				var vpa *VerticalPodAutoscaler
+				for _, o := range objects {
+					if v, ok := o.(*VerticalPodAutoscaler); ok {
+						vpa = v
+					}
+				}
+				Expect(vpa).NotTo(BeNil())
+				Expect(vpa.Spec.TargetRef.Kind).To(Equal("DaemonSet"))
+			})
 
-					It("doesn't add environment variable with misconfigured reference", func() {
-						vars, err := k.configEnvs(projectService)
+			It("returns an error for an unsupported update mode", func() {
+				projectService.Labels = composego.Labels{VPAModeLabel: "sometimes"}
 
-						Expect(vars).To(HaveLen(0))
+				_, err := k.createKubernetesObjects(projectService)
+				Expect(err).To(MatchError(ContainSubstring(`unsupported vpa mode "sometimes"`)))
+			})
+		})
 
-						assertLog(logrus.WarnLevel,
-							"Unsupported Container resource reference: unsupported.resource",
-							map[string]string{
-								"project-service": projectService.Name,
-								"env-var":         "MY_CONFIG",
-								"container":       "my-container",
-								"resource":        "unsupported.resource",
-							})
+		Context("without a vertical pod autoscaler mode label", func() {
+			It("doesn't create a vertical pod autoscaler", func() {
+				objects, err := k.createKubernetesObjects(projectService)
+				Expect(err).NotTo(HaveOccurred())
 
-						Expect(err).ToNot(HaveOccurred())
-					})
-				})
+				for _, o := range objects {
+					Expect(o).NotTo(BeAssignableToTypeOf(&VerticalPodAutoscaler{}))
+				}
 			})
 		})
 	})
 
-	// @todo
-	// covered by partial methods specs
-	Describe("createKubernetesObjects", func() {
+	Describe("validateNodePorts", func() {
+		nodePortService := func(name string, nodePort int) ProjectService {
+			ps, err := NewProjectService(composego.ServiceConfig{Name: name, Image: "some-image"})
+			Expect(err).NotTo(HaveOccurred())
+			ps.SvcK8sConfig.Service.Type = config.NodePortService
+			ps.SvcK8sConfig.Service.NodePort = nodePort
+			return ps
+		}
+
+		It("accepts node ports within the default node port range", func() {
+			services := []ProjectService{nodePortService("web", 30000), nodePortService("api", 32767)}
+			Expect(k.validateNodePorts(services)).To(Succeed())
+		})
+
+		It("returns an error for a node port outside of the default node port range", func() {
+			err := k.validateNodePorts([]ProjectService{nodePortService("web", 8080)})
+			Expect(err).To(MatchError("`web` node port 8080 is outside of the node port range 30000-32767"))
+		})
+
+		It("validates node ports against a configured node port range", func() {
+			k.NodePortRange = "20000-22767"
+
+			Expect(k.validateNodePorts([]ProjectService{nodePortService("web", 20000)})).To(Succeed())
+			err := k.validateNodePorts([]ProjectService{nodePortService("web", 30000)})
+			Expect(err).To(MatchError("`web` node port 30000 is outside of the node port range 20000-22767"))
+		})
+
+		It("doesn't fail when services request the same node port", func() {
+			services := []ProjectService{nodePortService("web", 30080), nodePortService("api", 30080)}
+			Expect(k.validateNodePorts(services)).To(Succeed())
+		})
+
+		It("returns an error for an invalid node port range", func() {
+			k.NodePortRange = "32767-30000"
+			err := k.validateNodePorts([]ProjectService{nodePortService("web", 30080)})
+			Expect(err).To(MatchError(ContainSubstring(`invalid node port range "32767-30000"`)))
+		})
 	})
 
 	Describe("createConfigMapFromComposeConfig", func() {
@@ -1992,6 +3613,76 @@ var _ = Describe("Transform", func() {
 	Describe("updateController", func() {
 	})
 
+	Describe("createPortGroupServices", func() {
+		var svc *v1.Service
+
+		BeforeEach(func() {
+			projectService.Ports = []composego.ServicePortConfig{
+				{Target: 8080, Protocol: "tcp"},
+				{Target: 9090, Protocol: "tcp"},
+			}
+		})
+
+		JustBeforeEach(func() {
+			var err error
+			svc, err = k.createService(config.NodePortService, projectService)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		Context("without port groups", func() {
+			It("keeps all ports in the service", func() {
+				objects, err := k.createPortGroupServices(projectService, svc)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(objects).To(BeEmpty())
+				Expect(svc.Spec.Ports).To(HaveLen(2))
+			})
+		})
+
+		Context("with a port group", func() {
+			BeforeEach(func() {
+				projectService.SvcK8sConfig.Service.PortGroups = map[string][]int{"metrics": {9090}}
+			})
+
+			It("moves the grouped ports into a dedicated ClusterIP service selecting the same pods", func() {
+				objects, err := k.createPortGroupServices(projectService, svc)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(objects).To(HaveLen(1))
+
+				groupSvc := objects[0].(*v1.Service)
+				Expect(groupSvc.Name).To(Equal(projectService.Name + "-metrics"))
+				Expect(groupSvc.Spec.Type).To(Equal(v1.ServiceTypeClusterIP))
+				Expect(groupSvc.Spec.Selector).To(Equal(svc.Spec.Selector))
+				Expect(groupSvc.Spec.Ports).To(HaveLen(1))
+				Expect(groupSvc.Spec.Ports[0].Port).To(BeEquivalentTo(9090))
+
+				Expect(svc.Spec.Type).To(Equal(v1.ServiceTypeNodePort))
+				Expect(svc.Spec.Ports).To(HaveLen(1))
+				Expect(svc.Spec.Ports[0].Port).To(BeEquivalentTo(8080))
+			})
+
+			It("annotates the port group service with the service annotations", func() {
+				projectService.SvcK8sConfig.Service.Annotations = map[string]string{"prometheus.io/scrape": "true"}
+
+				objects, err := k.createPortGroupServices(projectService, svc)
+				Expect(err).NotTo(HaveOccurred())
+
+				groupSvc := objects[0].(*v1.Service)
+				Expect(groupSvc.Annotations).To(HaveKeyWithValue("prometheus.io/scrape", "true"))
+			})
+		})
+
+		Context("with port groups covering all ports", func() {
+			BeforeEach(func() {
+				projectService.SvcK8sConfig.Service.PortGroups = map[string][]int{"all": {8080, 9090}}
+			})
+
+			It("returns an error", func() {
+				_, err := k.createPortGroupServices(projectService, svc)
+				Expect(err).To(MatchError("`web` service port groups must leave at least one port in the service"))
+			})
+		})
+	})
+
 	Describe("createService", func() {
 		BeforeEach(func() {
 			projectService.Ports = []composego.ServicePortConfig{
@@ -2036,6 +3727,78 @@ var _ = Describe("Transform", func() {
 				Expect(svc.Spec.Ports).To(Equal(expectedPorts))
 			})
 		})
+
+		Context("for load balancer service type", func() {
+			It("creates a load balancer service without load balancer IP by default", func() {
+				svc, err := k.createService(config.LoadBalancerService, projectService)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(svc.Spec.Type).To(Equal(v1.ServiceTypeLoadBalancer))
+				Expect(svc.Spec.LoadBalancerIP).To(BeEmpty())
+			})
+
+			It("sets the configured load balancer IP", func() {
+				projectService.SvcK8sConfig.Service.LoadBalancerIP = "10.0.0.1"
+
+				svc, err := k.createService(config.LoadBalancerService, projectService)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(svc.Spec.LoadBalancerIP).To(Equal("10.0.0.1"))
+			})
+
+			It("includes the configured service annotations", func() {
+				projectService.SvcK8sConfig.Service.Annotations = map[string]string{
+					"service.beta.kubernetes.io/aws-load-balancer-internal": "true",
+				}
+
+				svc, err := k.createService(config.LoadBalancerService, projectService)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(svc.ObjectMeta.Annotations).To(HaveKeyWithValue("service.beta.kubernetes.io/aws-load-balancer-internal", "true"))
+			})
+		})
+
+		Context("for non load balancer service type with load balancer IP", func() {
+			It("ignores the load balancer IP", func() {
+				projectService.SvcK8sConfig.Service.LoadBalancerIP = "10.0.0.1"
+
+				svc, err := k.createService(config.ClusterIPService, projectService)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(svc.Spec.LoadBalancerIP).To(BeEmpty())
+			})
+		})
+
+		Context("with session affinity", func() {
+			It("doesn't configure session affinity by default", func() {
+				svc, err := k.createService(config.ClusterIPService, projectService)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(svc.Spec.SessionAffinity).To(BeEmpty())
+				Expect(svc.Spec.SessionAffinityConfig).To(BeNil())
+			})
+
+			It("sets ClientIP session affinity with the configured timeout", func() {
+				projectService.SvcK8sConfig.Service.SessionAffinity = config.SessionAffinityClientIP
+				projectService.SvcK8sConfig.Service.SessionAffinityTimeout = 600
+
+				svc, err := k.createService(config.ClusterIPService, projectService)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(svc.Spec.SessionAffinity).To(Equal(v1.ServiceAffinityClientIP))
+				Expect(*svc.Spec.SessionAffinityConfig.ClientIP.TimeoutSeconds).To(BeEquivalentTo(600))
+			})
+
+			It("sets ClientIP session affinity without timeout leaving k8s default", func() {
+				projectService.SvcK8sConfig.Service.SessionAffinity = config.SessionAffinityClientIP
+
+				svc, err := k.createService(config.ClusterIPService, projectService)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(svc.Spec.SessionAffinity).To(Equal(v1.ServiceAffinityClientIP))
+				Expect(svc.Spec.SessionAffinityConfig).To(BeNil())
+			})
+
+			It("returns an error for unsupported session affinity", func() {
+				projectService.SvcK8sConfig.Service.SessionAffinity = "Cookie"
+
+				_, err := k.createService(config.ClusterIPService, projectService)
+				Expect(err).To(MatchError(ContainSubstring("service session affinity `Cookie` not supported")))
+			})
+		})
 	})
 
 	Describe("createHeadlessService", func() {
@@ -2088,6 +3851,47 @@ var _ = Describe("Transform", func() {
 			objs = append(objs, o)
 		})
 
+		Context("sidecars", func() {
+			BeforeEach(func() {
+				o.Spec.Template.Spec.Containers = append(o.Spec.Template.Spec.Containers, v1.Container{Name: "proxy"})
+			})
+
+			It("returns an error when the container name is taken by a sidecar", func() {
+				projectService.ContainerName = "proxy"
+
+				err := k.updateKubernetesObjects(projectService, &objs)
+				Expect(err).To(MatchError("container proxy has the same name as another container in the pod"))
+			})
+		})
+
+		Context("init containers", func() {
+			It("returns an error when an init container is named like the workload container", func() {
+				projectService.SvcK8sConfig.Workload.InitContainers = map[string]config.InitContainer{
+					"foo": {Image: "busybox"},
+				}
+
+				err := k.updateKubernetesObjects(projectService, &objs)
+				Expect(err).To(MatchError("container foo has the same name as another container in the pod"))
+			})
+		})
+
+		Context("image pull policy", func() {
+
+			It("sets the default image pull policy on the container", func() {
+				err := k.updateKubernetesObjects(projectService, &objs)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(o.Spec.Template.Spec.Containers[0].ImagePullPolicy).To(Equal(v1.PullIfNotPresent))
+			})
+
+			It("sets the configured image pull policy on the container", func() {
+				projectService.SvcK8sConfig.Workload.ImagePull.Policy = "Always"
+
+				err := k.updateKubernetesObjects(projectService, &objs)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(o.Spec.Template.Spec.Containers[0].ImagePullPolicy).To(Equal(v1.PullAlways))
+			})
+		})
+
 		Context("readiness probe", func() {
 
 			When("readiness probe is defined for project service", func() {
@@ -2154,6 +3958,16 @@ var _ = Describe("Transform", func() {
 			Expect(objs[0].GetObjectKind().GroupVersionKind().Kind).To(Equal("Service"))
 			Expect(objs[1].GetObjectKind().GroupVersionKind().Kind).To(Equal("Deployment"))
 		})
+
+		It("keeps namespaces ahead of services", func() {
+			withNamespace := []runtime.Object{
+				&v1.Service{TypeMeta: meta.TypeMeta{Kind: "Service"}},
+				&v1.Namespace{TypeMeta: meta.TypeMeta{Kind: "Namespace"}},
+			}
+			k.sortServicesFirst(&withNamespace)
+			Expect(withNamespace[0].GetObjectKind().GroupVersionKind().Kind).To(Equal("Namespace"))
+			Expect(withNamespace[1].GetObjectKind().GroupVersionKind().Kind).To(Equal("Service"))
+		})
 	})
 
 	Describe("removeDupObjects", func() {
@@ -2197,6 +4011,60 @@ var _ = Describe("Transform", func() {
 				Expect(objs[1].GetObjectKind().GroupVersionKind().Kind).To(Equal("Deployment"))
 			})
 		})
+
+		Context("with same named ConfigMaps carrying different keys", func() {
+			configMap := func(data map[string]string) *v1.ConfigMap {
+				return &v1.ConfigMap{
+					TypeMeta:   meta.TypeMeta{Kind: "ConfigMap"},
+					ObjectMeta: meta.ObjectMeta{Name: "config2"},
+					Data:       data,
+				}
+			}
+
+			It("merges their data into a single ConfigMap", func() {
+				merged := []runtime.Object{
+					configMap(map[string]string{"app.conf": "a", "shared.conf": "s"}),
+					configMap(map[string]string{"worker.conf": "w", "shared.conf": "s"}),
+				}
+
+				Expect(k.removeDupObjects(&merged)).To(Succeed())
+				Expect(merged).To(HaveLen(1))
+				Expect(merged[0].(*v1.ConfigMap).Data).To(Equal(map[string]string{
+					"app.conf":    "a",
+					"shared.conf": "s",
+					"worker.conf": "w",
+				}))
+			})
+
+			It("returns an error when the same key has conflicting values", func() {
+				conflicting := []runtime.Object{
+					configMap(map[string]string{"app.conf": "a"}),
+					configMap(map[string]string{"app.conf": "b"}),
+				}
+
+				err := k.removeDupObjects(&conflicting)
+				Expect(err).To(MatchError("ConfigMap config2 key app.conf has conflicting values"))
+			})
+
+			It("returns an error when the same key is held as data and binary data", func() {
+				binary := configMap(nil)
+				binary.BinaryData = map[string][]byte{"app.conf": []byte("a")}
+
+				conflicting := []runtime.Object{
+					configMap(map[string]string{"app.conf": "a"}),
+					binary,
+				}
+				err := k.removeDupObjects(&conflicting)
+				Expect(err).To(MatchError("ConfigMap config2 key app.conf is set in both data and binary data"))
+
+				conflicting = []runtime.Object{
+					binary,
+					configMap(map[string]string{"app.conf": "a"}),
+				}
+				err = k.removeDupObjects(&conflicting)
+				Expect(err).To(MatchError("ConfigMap config2 key app.conf is set in both data and binary data"))
+			})
+		})
 	})
 
 	Describe("setPodResources", func() {
@@ -2289,6 +4157,28 @@ var _ = Describe("Transform", func() {
 				Expect(podSpec.Spec.Containers[0].Resources.Limits.Cpu().String()).To(Equal("500m"))
 			})
 		})
+
+		Context("with ephemeral storage request and limit provided in configuration", func() {
+			BeforeEach(func() {
+				svcK8sConfig := config.DefaultSvcK8sConfig()
+				svcK8sConfig.Workload.Resource.Storage = "100Mi"
+				svcK8sConfig.Workload.Resource.MaxStorage = "1Gi"
+
+				ext, err := svcK8sConfig.Map()
+				Expect(err).NotTo(HaveOccurred())
+				projectService.Extensions = map[string]interface{}{
+					config.K8SExtensionKey: ext,
+				}
+
+				projectService, err = NewProjectService(projectService.ServiceConfig)
+			})
+
+			It("sets container ephemeral storage request and limit as expected", func() {
+				k.setPodResources(projectService, podSpec)
+				Expect(podSpec.Spec.Containers[0].Resources.Requests.StorageEphemeral().String()).To(Equal("100Mi"))
+				Expect(podSpec.Spec.Containers[0].Resources.Limits.StorageEphemeral().String()).To(Equal("1Gi"))
+			})
+		})
 	})
 
 	Describe("setPodSecurityContext", func() {
@@ -2358,6 +4248,69 @@ var _ = Describe("Transform", func() {
 				k.setPodSecurityContext(projectService, podSecContext)
 				Expect(podSecContext.FSGroup).To(Equal(&fsGroup))
 			})
+
+			It("leaves FSGroupChangePolicy unset by default", func() {
+				k.setPodSecurityContext(projectService, podSecContext)
+				Expect(podSecContext.FSGroupChangePolicy).To(BeNil())
+			})
+		})
+
+		When("fsGroupChangePolicy is specified in a k8s extension", func() {
+			BeforeEach(func() {
+				svcK8sConfig := config.DefaultSvcK8sConfig()
+				svcK8sConfig.Workload.PodSecurity.FsGroupChangePolicy = "OnRootMismatch"
+
+				m, err := svcK8sConfig.Map()
+				Expect(err).NotTo(HaveOccurred())
+
+				projectService.Extensions = map[string]interface{}{config.K8SExtensionKey: m}
+
+				projectService, err = NewProjectService(projectService.ServiceConfig)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("adds FSGroupChangePolicy into pod security context as expected", func() {
+				k.setPodSecurityContext(projectService, podSecContext)
+				expected := v1.FSGroupChangeOnRootMismatch
+				Expect(podSecContext.FSGroupChangePolicy).To(Equal(&expected))
+			})
+		})
+
+		When("runAsNonRoot and seccomp profile are specified in a k8s extension", func() {
+			runAsNonRoot := true
+
+			BeforeEach(func() {
+				svcK8sConfig := config.DefaultSvcK8sConfig()
+				svcK8sConfig.Workload.PodSecurity.RunAsNonRoot = &runAsNonRoot
+				svcK8sConfig.Workload.PodSecurity.SeccompProfile = "localhost/profiles/audit.json"
+
+				m, err := svcK8sConfig.Map()
+				Expect(err).NotTo(HaveOccurred())
+
+				projectService.Extensions = map[string]interface{}{config.K8SExtensionKey: m}
+
+				projectService, err = NewProjectService(projectService.ServiceConfig)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("adds RunAsNonRoot and SeccompProfile into pod security context as expected", func() {
+				k.setPodSecurityContext(projectService, podSecContext)
+				Expect(podSecContext.RunAsNonRoot).To(Equal(&runAsNonRoot))
+
+				profilePath := "profiles/audit.json"
+				Expect(podSecContext.SeccompProfile).To(Equal(&v1.SeccompProfile{
+					Type:             v1.SeccompProfileTypeLocalhost,
+					LocalhostProfile: &profilePath,
+				}))
+			})
+		})
+
+		When("runAsNonRoot and seccomp profile aren't specified", func() {
+			It("leaves RunAsNonRoot and SeccompProfile unset", func() {
+				k.setPodSecurityContext(projectService, podSecContext)
+				Expect(podSecContext.RunAsNonRoot).To(BeNil())
+				Expect(podSecContext.SeccompProfile).To(BeNil())
+			})
 		})
 
 		When("group_add is specified in project service spec", func() {
@@ -2456,5 +4409,43 @@ var _ = Describe("Transform", func() {
 				Expect(secContext.Capabilities).To(Equal(caps))
 			})
 		})
+
+		When("container security hardening flags are configured via extension", func() {
+			BeforeEach(func() {
+				projectService.SvcK8sConfig.Workload.ContainerSecurity = config.ContainerSecurity{
+					ReadOnlyRootFilesystem:   "true",
+					AllowPrivilegeEscalation: "false",
+				}
+			})
+
+			It("sets them in container security context as expected", func() {
+				readOnly, allowEscalation := true, false
+				k.setSecurityContext(projectService, caps, secContext)
+				Expect(secContext.ReadOnlyRootFilesystem).To(Equal(&readOnly))
+				Expect(secContext.AllowPrivilegeEscalation).To(Equal(&allowEscalation))
+			})
+		})
+
+		When("container security hardening flags are not boolean", func() {
+			BeforeEach(func() {
+				projectService.SvcK8sConfig.Workload.ContainerSecurity = config.ContainerSecurity{
+					ReadOnlyRootFilesystem: "sometimes",
+				}
+			})
+
+			It("logs a warning and doesn't set them in container security context", func() {
+				k.setSecurityContext(projectService, caps, secContext)
+				Expect(secContext.ReadOnlyRootFilesystem).To(BeNil())
+				Expect(secContext.AllowPrivilegeEscalation).To(BeNil())
+			})
+		})
+
+		When("container security hardening flags are not configured", func() {
+			It("leaves them unset", func() {
+				k.setSecurityContext(projectService, caps, secContext)
+				Expect(secContext.ReadOnlyRootFilesystem).To(BeNil())
+				Expect(secContext.AllowPrivilegeEscalation).To(BeNil())
+			})
+		})
 	})
 })
@@ -22,6 +22,7 @@ package kubernetes
 
 import (
 	"github.com/appvia/kev/pkg/kev/config"
+	"github.com/appvia/kev/pkg/kev/log"
 	composego "github.com/compose-spec/compose-go/types"
 )
 
@@ -35,6 +36,7 @@ type ConvertOptions struct {
 	InputFiles   []string // Compose files to be processed
 	OutFile      string   // If Directory output will be split into individual files
 	YAMLIndent   int      // YAML Indentation in resultant K8s manifests
+	ExposeAPI    string   // API used to expose services ("ingress"|"traefik") (default "ingress")
 }
 
 // Volumes holds the container volume struct
@@ -56,4 +58,7 @@ type Volumes struct {
 type ProjectService struct {
 	composego.ServiceConfig
 	SvcK8sConfig config.SvcK8sConfig
+
+	// warn reports the service conversion warnings to the converter, they're only logged when not set
+	warn func(fields log.Fields, msg string)
 }
@@ -34,17 +34,23 @@ import (
 	"strings"
 	"text/template"
 	"time"
+	"unicode/utf8"
 
+	"github.com/appvia/kev/pkg/kev/config"
 	"github.com/appvia/kev/pkg/kev/log"
 	composego "github.com/compose-spec/compose-go/types"
 	"github.com/pkg/errors"
 	"gopkg.in/yaml.v3"
 	v1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
 	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
@@ -74,6 +80,19 @@ func (env EnvSort) Swap(i, j int) {
 	env[i], env[j] = env[j], env[i]
 }
 
+// progress returns a progress indicator for the current item out of total items, e.g. "3/12".
+// Once at least one item has been processed, it includes the estimated time remaining
+// based on the average time spent on already processed items.
+func progress(current, total int, elapsed time.Duration) string {
+	done := current - 1
+	if done <= 0 || current > total {
+		return fmt.Sprintf("%d/%d", current, total)
+	}
+
+	remaining := elapsed / time.Duration(done) * time.Duration(total-done)
+	return fmt.Sprintf("%d/%d, ~%s remaining", current, total, remaining.Round(100*time.Millisecond))
+}
+
 // PrintList prints k8s objects
 // @orig: https://github.com/kubernetes/kompose/blob/master/pkg/transformer/kubernetes/k8sutils.go#L153
 func PrintList(objects []runtime.Object, opt ConvertOptions, rendered map[string][]byte) error {
@@ -116,21 +135,7 @@ func PrintList(objects []runtime.Object, opt ConvertOptions, rendered map[string
 
 	// @step print to stdout, or to a single file - it will return a list object
 	if opt.ToStdout || f != nil {
-		list := &v1.List{}
-		// convert objects to versioned and add them to list
-		for _, object := range objects {
-			versionedObject, err := convertToVersion(object, schema.GroupVersion{})
-			if err != nil {
-				return err
-			}
-
-			list.Items = append(list.Items, runtime.RawExtension{Object: versionedObject})
-		}
-		// version list itself
-		listVersion := schema.GroupVersion{Group: "", Version: "v1"}
-		list.Kind = "List"
-		list.APIVersion = "v1"
-		convertedList, err := convertToVersion(list, listVersion)
+		convertedList, err := toVersionedList(objects)
 		if err != nil {
 			return err
 		}
@@ -178,28 +183,7 @@ func PrintList(objects []runtime.Object, opt ConvertOptions, rendered map[string
 				return err
 			}
 
-			var typeMeta meta.TypeMeta
-			var objectMeta meta.ObjectMeta
-
-			if us, ok := v.(*unstructured.Unstructured); ok {
-				typeMeta = meta.TypeMeta{
-					Kind:       us.GetKind(),
-					APIVersion: us.GetAPIVersion(),
-				}
-				objectMeta = meta.ObjectMeta{
-					Name: us.GetName(),
-				}
-			} else {
-				val := reflect.ValueOf(v).Elem()
-				// Use reflect to access TypeMeta struct inside runtime.Object.
-				// cast it to correct type - meta.TypeMeta
-				typeMeta = val.FieldByName("TypeMeta").Interface().(meta.TypeMeta)
-
-				// Use reflect to access ObjectMeta struct inside runtime.Object.
-				// cast it to correct type - meta.ObjectMeta
-				objectMeta = val.FieldByName("ObjectMeta").Interface().(meta.ObjectMeta)
-
-			}
+			typeMeta, objectMeta := objectMetas(v)
 
 			file, err = print(objectMeta.Name, finalDirName, strings.ToLower(typeMeta.Kind), data, opt.ToStdout, opt.GenerateJSON, f)
 			if err != nil {
@@ -222,15 +206,85 @@ func PrintList(objects []runtime.Object, opt ConvertOptions, rendered map[string
 	return nil
 }
 
+// MarshalList marshals objects as a YAML or JSON List, matching the single file output
+func MarshalList(objects []runtime.Object, generateJSON bool) ([]byte, error) {
+	list, err := toVersionedList(objects)
+	if err != nil {
+		return nil, err
+	}
+
+	return marshal(list, generateJSON, 2)
+}
+
+// MarshalFiles marshals objects individually as YAML or JSON keyed by their file name, matching the multiple files output
+func MarshalFiles(objects []runtime.Object, generateJSON bool) (map[string][]byte, error) {
+	out := map[string][]byte{}
+	for _, v := range objects {
+		versionedObject, err := convertToVersion(v, schema.GroupVersion{})
+		if err != nil {
+			return nil, err
+		}
+		data, err := marshal(versionedObject, generateJSON, 2)
+		if err != nil {
+			return nil, err
+		}
+
+		typeMeta, objectMeta := objectMetas(v)
+		out[manifestFileName(objectMeta.Name, strings.ToLower(typeMeta.Kind), generateJSON)] = data
+	}
+	return out, nil
+}
+
+// manifestFileName returns the name of the file an object's manifest is written to in the multiple files output
+func manifestFileName(name, trailing string, generateJSON bool) string {
+	if generateJSON {
+		return fmt.Sprintf("%s-%s.json", name, trailing)
+	}
+	return fmt.Sprintf("%s-%s.yaml", name, trailing)
+}
+
+// objectMetas returns an object's type & object metadata
+func objectMetas(v runtime.Object) (meta.TypeMeta, meta.ObjectMeta) {
+	if us, ok := v.(*unstructured.Unstructured); ok {
+		return meta.TypeMeta{Kind: us.GetKind(), APIVersion: us.GetAPIVersion()},
+			meta.ObjectMeta{Name: us.GetName()}
+	}
+
+	val := reflect.ValueOf(v).Elem()
+	// Use reflect to access TypeMeta struct inside runtime.Object.
+	// cast it to correct type - meta.TypeMeta
+	typeMeta := val.FieldByName("TypeMeta").Interface().(meta.TypeMeta)
+
+	// Use reflect to access ObjectMeta struct inside runtime.Object.
+	// cast it to correct type - meta.ObjectMeta
+	objectMeta := val.FieldByName("ObjectMeta").Interface().(meta.ObjectMeta)
+
+	return typeMeta, objectMeta
+}
+
+// toVersionedList converts objects to versioned ones and wraps them in a versioned List
+func toVersionedList(objects []runtime.Object) (runtime.Object, error) {
+	list := &v1.List{}
+	// convert objects to versioned and add them to list
+	for _, object := range objects {
+		versionedObject, err := convertToVersion(object, schema.GroupVersion{})
+		if err != nil {
+			return nil, err
+		}
+
+		list.Items = append(list.Items, runtime.RawExtension{Object: versionedObject})
+	}
+	// version list itself
+	listVersion := schema.GroupVersion{Group: "", Version: "v1"}
+	list.Kind = "List"
+	list.APIVersion = "v1"
+	return convertToVersion(list, listVersion)
+}
+
 // print either renders to stdout or to file/s
 // @orig: https://github.com/kubernetes/kompose/blob/master/pkg/transformer/utils.go#L176
 func print(name, path string, trailing string, data []byte, toStdout, generateJSON bool, f *os.File) (string, error) {
-	file := ""
-	if generateJSON {
-		file = fmt.Sprintf("%s-%s.json", name, trailing)
-	} else {
-		file = fmt.Sprintf("%s-%s.yaml", name, trailing)
-	}
+	file := manifestFileName(name, trailing, generateJSON)
 	if toStdout {
 		_, _ = fmt.Fprintf(os.Stdout, "%s\n", string(data))
 		return "", nil
@@ -426,6 +480,8 @@ func marshalWithIndent(o interface{}, indent int) ([]byte, error) {
 
 // convertToVersion converts object to a versioned object
 // if groupVersion is  empty (schema.GroupVersion{}), use version from original object (obj)
+// Types registered with the converter's runtime scheme are converted using that scheme, which also
+// allows objects without type meta to be versioned. Any other types get converted ad-hoc.
 // @orig: https://github.com/kubernetes/kompose/blob/master/pkg/transformer/kubernetes/k8sutils.go#L324
 func convertToVersion(obj runtime.Object, groupVersion schema.GroupVersion) (runtime.Object, error) {
 
@@ -443,6 +499,16 @@ func convertToVersion(obj runtime.Object, groupVersion schema.GroupVersion) (run
 		version = groupVersion
 	}
 
+	if gvks, err := objectKinds(obj); err == nil {
+		if version.Empty() {
+			version = gvks[0].GroupVersion()
+		}
+
+		if convertedObject, err := convertWithScheme(obj, version); err == nil {
+			return convertedObject, nil
+		}
+	}
+
 	s := runtime.NewScheme()
 	s.AddKnownTypes(version, obj)
 	convertedObject, err := s.ConvertToVersion(obj, version)
@@ -483,6 +549,24 @@ func getRestartPolicy(projectServiceName, restart string) (v1.RestartPolicy, err
 	}
 }
 
+// enforceRestartPolicy ensures the pod spec uses a restart policy supported by the workload kind.
+// An unsupported restart policy is replaced with the first supported one.
+func (k *Kubernetes) enforceRestartPolicy(kind, name string, spec *v1.PodSpec, supported ...v1.RestartPolicy) {
+	for _, rp := range supported {
+		if spec.RestartPolicy == rp {
+			return
+		}
+	}
+
+	if spec.RestartPolicy != "" {
+		k.warnfWithFields(log.Fields{
+			strings.ToLower(kind): name,
+		}, "Restart policy %s is not supported by %s. Using %s instead",
+			spec.RestartPolicy, kind, supported[0])
+	}
+	spec.RestartPolicy = supported[0]
+}
+
 // sortServices sorts all compose project services by name
 func sortServices(project *composego.Project) {
 	sort.Slice(project.Services, func(i, j int) bool {
@@ -517,6 +601,22 @@ func getContentFromFile(file string) (string, error) {
 	return string(fileBytes), nil
 }
 
+// binaryDataToConfigMapBinaryData moves ConfigMap data values that aren't valid UTF-8, e.g. binary file contents,
+// from Data to BinaryData, which gets base64 encoded when the ConfigMap is serialised.
+func binaryDataToConfigMapBinaryData(cm *v1.ConfigMap) *v1.ConfigMap {
+	for key, value := range cm.Data {
+		if utf8.ValidString(value) {
+			continue
+		}
+		if cm.BinaryData == nil {
+			cm.BinaryData = map[string][]byte{}
+		}
+		cm.BinaryData[key] = []byte(value)
+		delete(cm.Data, key)
+	}
+	return cm
+}
+
 // rfc1123
 // NOTE: only accept alphanumeric chars (specifically excluding dots)
 // https://kubernetes.io/docs/concepts/overview/working-with-objects/names/
@@ -572,21 +672,119 @@ func configAllLabels(projectService ProjectService) map[string]string {
 			base[k] = v
 		}
 	}
+	for k, v := range configCustomLabels(projectService) {
+		base[k] = v
+	}
+	return base
+}
+
+// configObjectLabels creates labels with the selector label and the custom labels of the project service
+func configObjectLabels(projectService ProjectService) map[string]string {
+	base := configLabels(projectService.Name)
+	for k, v := range configCustomLabels(projectService) {
+		base[k] = v
+	}
 	return base
 }
 
+// configCustomLabels returns the labels set with the custom label prefix on the project service, without the prefix.
+// The selector label is never overridden as it ties services to their workloads.
+func configCustomLabels(projectService ProjectService) map[string]string {
+	out := map[string]string{}
+	for k, v := range projectService.Labels {
+		if !strings.HasPrefix(k, CustomLabelPrefix) {
+			continue
+		}
+		key := strings.TrimPrefix(k, CustomLabelPrefix)
+		if key == Selector {
+			projectService.warnfWithFields(log.Fields{
+				"project-service": projectService.Name,
+				"label":           k,
+			}, "Ignoring custom label as it would override the %s selector label", Selector)
+			continue
+		}
+		out[key] = v
+	}
+	return out
+}
+
 // configAnnotations creates annotations to be used where they are required,
 // @orig: https://github.com/kubernetes/kompose/blob/master/pkg/transformer/utils.go#L152
+// Keys prefixed with the custom annotation label prefix are added without the prefix, taking precedence
+// over a key of the same name in the same source. Later sources take precedence over earlier ones.
 func configAnnotations(src ...map[string]string) map[string]string {
 	out := map[string]string{}
 	for _, m := range src {
+		custom := map[string]string{}
 		for key, val := range m {
+			if strings.HasPrefix(key, CustomAnnotationLabelPrefix) {
+				custom[strings.TrimPrefix(key, CustomAnnotationLabelPrefix)] = val
+				continue
+			}
+			out[key] = val
+		}
+		for key, val := range custom {
 			out[key] = val
 		}
 	}
 	return out
 }
 
+// metadataPlaceholder matches ${name} placeholders in extra labels & annotations values
+var metadataPlaceholder = regexp.MustCompile(`\$\{([^}]*)\}`)
+
+// expandPlaceholders expands ${name} placeholders in value with the matching vars.
+// Unknown placeholders result in an error to catch typos, as do placeholders without a value
+// which would otherwise silently expand to a blank string.
+func expandPlaceholders(value string, vars map[string]string) (string, error) {
+	var unknown, blank []string
+	out := metadataPlaceholder.ReplaceAllStringFunc(value, func(m string) string {
+		name := metadataPlaceholder.FindStringSubmatch(m)[1]
+		v, ok := vars[name]
+		if !ok {
+			unknown = append(unknown, m)
+			return m
+		}
+		if v == "" {
+			blank = append(blank, m)
+		}
+		return v
+	})
+
+	if len(unknown) > 0 {
+		supported := make([]string, 0, len(vars))
+		for name := range vars {
+			supported = append(supported, "${"+name+"}")
+		}
+		sort.Strings(supported)
+		return "", errors.Errorf("unknown placeholder %s in %q, supported placeholders are: %s",
+			strings.Join(unknown, ", "), value, strings.Join(supported, ", "))
+	}
+
+	if len(blank) > 0 {
+		return "", errors.Errorf("placeholder %s in %q has no value", strings.Join(blank, ", "), value)
+	}
+
+	return out, nil
+}
+
+// expandPlaceholdersInMap returns a copy of m with placeholders expanded in all its values
+func expandPlaceholdersInMap(m map[string]string, vars map[string]string) (map[string]string, error) {
+	if m == nil {
+		return nil, nil
+	}
+
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		expanded, err := expandPlaceholders(v, vars)
+		if err != nil {
+			return nil, errors.Wrapf(err, "key %s", k)
+		}
+		out[k] = expanded
+	}
+	return out, nil
+}
+
 // parseIngressPath parses the path for ingress.
 // eg. example.com/org -> example.com org
 // @orig: https://github.com/kubernetes/kompose/blob/master/pkg/transformer/utils.go#L109
@@ -644,10 +842,10 @@ func configLabelsWithNetwork(projectService ProjectService) map[string]string {
 	return labels
 }
 
-// findByName selects compose project service by name
+// findByName selects compose project service by name, comparing names normalised as they are when rendered
 func findByName(projectServices composego.Services, name string) *composego.ServiceConfig {
 	for _, ps := range projectServices {
-		if rfc1123dns(ps.Name) == name {
+		if rfc1123dns(ps.Name) == rfc1123dns(name) {
 			return &ps
 		}
 	}
@@ -760,6 +958,60 @@ func parseVols(volNames []string, svcName string) ([]Volumes, error) {
 	return volumes, nil
 }
 
+// tmpfsSize matches docker style tmpfs sizes, e.g. 64m, 1g, 512kb or a number of bytes
+var tmpfsSize = regexp.MustCompile(`^(\d+)([kmg]?)b?$`)
+
+// parseTmpfs parses a compose tmpfs entry, which might be container_path[:option[,option...]].
+// Supported options are `size` mapped to a size limit & `ro` mounting the tmpfs read only, others are ignored.
+func parseTmpfs(entry string) (mountPath string, sizeLimit *resource.Quantity, readOnly bool, err error) {
+	parts := strings.SplitN(strings.TrimSpace(entry), ":", 2)
+	mountPath = parts[0]
+	if mountPath == "" {
+		return "", nil, false, fmt.Errorf("invalid tmpfs %q, a mount path is required", entry)
+	}
+
+	if len(parts) == 1 {
+		return mountPath, nil, false, nil
+	}
+
+	for _, opt := range strings.Split(parts[1], ",") {
+		kv := strings.SplitN(strings.TrimSpace(opt), "=", 2)
+		switch kv[0] {
+		case "ro":
+			readOnly = true
+		case "size":
+			if len(kv) != 2 {
+				return "", nil, false, fmt.Errorf("invalid tmpfs %q, size option requires a value", entry)
+			}
+			if sizeLimit, err = parseTmpfsSize(kv[1]); err != nil {
+				return "", nil, false, errors.Wrapf(err, "invalid tmpfs %q", entry)
+			}
+		}
+	}
+
+	return mountPath, sizeLimit, readOnly, nil
+}
+
+// parseTmpfsSize converts a docker style tmpfs size, using binary units, to a K8s quantity.
+// K8s quantities, e.g. 64Mi, are also accepted.
+func parseTmpfsSize(size string) (*resource.Quantity, error) {
+	m := tmpfsSize.FindStringSubmatch(strings.ToLower(size))
+	if m == nil {
+		q, err := resource.ParseQuantity(size)
+		if err != nil {
+			return nil, fmt.Errorf("unsupported size %q", size)
+		}
+		return &q, nil
+	}
+
+	suffixes := map[string]string{"": "", "k": "Ki", "m": "Mi", "g": "Gi"}
+	q, err := resource.ParseQuantity(m[1] + suffixes[m[2]])
+	if err != nil {
+		return nil, fmt.Errorf("unsupported size %q", size)
+	}
+	return &q, nil
+}
+
 // parseVolume parses a given volume, which might be [name:][host:]container[:access_mode]
 // @orig: https://github.com/kubernetes/kompose/blob/ca75c31df8257206d4c50d1cca23f78040bb98ca/pkg/transformer/utils.go#L58
 func parseVolume(volume string) (name, host, container, mode string, err error) {
@@ -856,6 +1108,16 @@ func loadVolumes(volumes []composego.ServiceVolumeConfig) []string {
 	return volArray
 }
 
+// serviceVolumeByTarget returns the compose service volume mounted at the target path, if any
+func serviceVolumeByTarget(volumes []composego.ServiceVolumeConfig, target string) *composego.ServiceVolumeConfig {
+	for i := range volumes {
+		if volumes[i].Target == target {
+			return &volumes[i]
+		}
+	}
+	return nil
+}
+
 // getVol for dependent volumes, returns true and the respective volume if mountpath are the same
 // @orig: https://github.com/kubernetes/kompose/blob/e7f05588bf8bd645000612faa136b1b6aa0d5bb6/pkg/loader/compose/v1v2.go#L427
 func getVol(v Volumes, volumes []Volumes) (bool, Volumes) {
@@ -894,40 +1156,164 @@ func useSubPathMount(cm *v1.ConfigMap) bool {
 	return true
 }
 
+// configMapKeys returns the sorted keys of both the ConfigMap data and binary data
+func configMapKeys(cm *v1.ConfigMap) []string {
+	var keys []string
+	for key := range cm.Data {
+		keys = append(keys, key)
+	}
+	for key := range cm.BinaryData {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // loadPlacement parses placement information from composego
 // @orig: https://github.com/kubernetes/kompose/blob/e7f05588bf8bd645000612faa136b1b6aa0d5bb6/pkg/loader/compose/v3.go#L136
-func loadPlacement(constraints []string) map[string]string {
+func (p *ProjectService) loadPlacement(constraints []string) map[string]string {
 	placement := make(map[string]string)
 
 	errMsg := "Constraint in placement is not supported. Only 'node.hostname==...', 'node.role==worker', 'node.role==manager', 'engine.labels.operatingsystem' and 'node.labels.(...)' (ex: node.labels.something==anything) is supported as a constraint"
 
 	for _, c := range constraints {
-		p := strings.Split(strings.Replace(c, " ", "", -1), "==")
+		parts := strings.Split(strings.Replace(c, " ", "", -1), "==")
 
-		if len(p) < 2 {
-			log.WarnWithFields(log.Fields{"placement": p[0]}, errMsg)
+		if len(parts) < 2 {
+			p.warnWithFields(log.Fields{"placement": parts[0]}, errMsg)
 			continue
 		}
 
-		if p[0] == "node.role" && p[1] == "worker" {
+		if parts[0] == "node.role" && parts[1] == "worker" {
 			placement["node-role.kubernetes.io/worker"] = "true"
-		} else if p[0] == "node.role" && p[1] == "manager" {
+		} else if parts[0] == "node.role" && parts[1] == "manager" {
 			placement["node-role.kubernetes.io/master"] = "true"
-		} else if p[0] == "node.hostname" {
-			placement["kubernetes.io/hostname"] = p[1]
-		} else if p[0] == "engine.labels.operatingsystem" {
-			placement["beta.kubernetes.io/os"] = p[1]
-		} else if strings.HasPrefix(p[0], "node.labels.") {
-			label := strings.TrimPrefix(p[0], "node.labels.")
-			placement[label] = p[1]
+		} else if parts[0] == "node.hostname" {
+			placement["kubernetes.io/hostname"] = parts[1]
+		} else if parts[0] == "engine.labels.operatingsystem" {
+			placement["beta.kubernetes.io/os"] = parts[1]
+		} else if strings.HasPrefix(parts[0], "node.labels.") {
+			label := strings.TrimPrefix(parts[0], "node.labels.")
+			placement[label] = parts[1]
 		} else {
-			log.WarnWithFields(log.Fields{"placement": p[0]}, errMsg)
+			p.warnWithFields(log.Fields{"placement": parts[0]}, errMsg)
 		}
 	}
 
 	return placement
 }
 
+// nodeSelectorRequirements expands a node affinity expression written in k8s label selector syntax,
+// e.g. "disktype in (ssd,nvme),kubernetes.io/arch=amd64", into node selector requirements.
+// All requirements of the expression must be satisfied for a node to be selected.
+func nodeSelectorRequirements(expr string) ([]v1.NodeSelectorRequirement, error) {
+	selector, err := labels.Parse(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	reqs, _ := selector.Requirements()
+
+	var requirements []v1.NodeSelectorRequirement
+	for _, r := range reqs {
+		var op v1.NodeSelectorOperator
+		switch r.Operator() {
+		case selection.In, selection.Equals, selection.DoubleEquals:
+			op = v1.NodeSelectorOpIn
+		case selection.NotIn, selection.NotEquals:
+			op = v1.NodeSelectorOpNotIn
+		case selection.Exists:
+			op = v1.NodeSelectorOpExists
+		case selection.DoesNotExist:
+			op = v1.NodeSelectorOpDoesNotExist
+		case selection.GreaterThan:
+			op = v1.NodeSelectorOpGt
+		case selection.LessThan:
+			op = v1.NodeSelectorOpLt
+		default:
+			return nil, fmt.Errorf("unsupported node affinity operator %q", r.Operator())
+		}
+
+		requirement := v1.NodeSelectorRequirement{
+			Key:      r.Key(),
+			Operator: op,
+		}
+		if op != v1.NodeSelectorOpExists && op != v1.NodeSelectorOpDoesNotExist {
+			requirement.Values = r.Values().List()
+		}
+
+		requirements = append(requirements, requirement)
+	}
+
+	return requirements, nil
+}
+
+// loadPlacementPreferences translates compose placement spread preferences into preferred pod anti-affinity
+// terms, spreading the service's pods across the topology domains identified by the preference's label.
+// Preferences are listed in descending order of precedence and are weighted accordingly.
+func (p *ProjectService) loadPlacementPreferences(preferences []composego.PlacementPreferences) *v1.Affinity {
+	var terms []v1.WeightedPodAffinityTerm
+
+	for i, pref := range preferences {
+		spread := strings.TrimSpace(pref.Spread)
+
+		var topologyKey string
+		switch {
+		case spread == "node.hostname":
+			topologyKey = "kubernetes.io/hostname"
+		case strings.HasPrefix(spread, "node.labels."):
+			topologyKey = strings.TrimPrefix(spread, "node.labels.")
+		default:
+			p.warnWithFields(log.Fields{
+				"project-service": p.Name,
+				"spread":          spread,
+			}, "Placement preference has no Kubernetes equivalent. Only 'node.hostname' and 'node.labels.(...)' spread preferences are supported")
+			continue
+		}
+
+		weight := int32(100 - i)
+		if weight < 1 {
+			weight = 1
+		}
+
+		terms = append(terms, v1.WeightedPodAffinityTerm{
+			Weight: weight,
+			PodAffinityTerm: v1.PodAffinityTerm{
+				LabelSelector: &meta.LabelSelector{
+					MatchLabels: configLabels(p.Name),
+				},
+				TopologyKey: topologyKey,
+			},
+		})
+	}
+
+	if len(terms) == 0 {
+		return nil
+	}
+
+	return &v1.Affinity{
+		PodAntiAffinity: &v1.PodAntiAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: terms,
+		},
+	}
+}
+
+// antiAffinityTerm returns a pod affinity term matching the service's own pods
+// within the supplied anti-affinity topology, i.e. node or zone
+func antiAffinityTerm(name, topology string) v1.PodAffinityTerm {
+	topologyKey := "kubernetes.io/hostname"
+	if topology == config.ZoneAntiAffinityTopology {
+		topologyKey = "topology.kubernetes.io/zone"
+	}
+
+	return v1.PodAffinityTerm{
+		LabelSelector: &meta.LabelSelector{
+			MatchLabels: configLabels(name),
+		},
+		TopologyKey: topologyKey,
+	}
+}
+
 // contains returns true of slice of strings contains a given string
 func contains(strs []string, s string) bool {
 	sort.Strings(strs)
@@ -935,6 +1321,24 @@ func contains(strs []string, s string) bool {
 	return i < len(strs) && strs[i] == s
 }
 
+// podSpecPaths maps workload kinds to the location of their pod spec in an unstructured object
+var podSpecPaths = map[string][]string{
+	"Deployment":  {"spec", "template", "spec"},
+	"StatefulSet": {"spec", "template", "spec"},
+	"DaemonSet":   {"spec", "template", "spec"},
+	"Job":         {"spec", "template", "spec"},
+	"CronJob":     {"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+// PodSpecPath returns the location of a workload's pod spec in its unstructured representation
+func PodSpecPath(kind string) ([]string, bool) {
+	path, ok := podSpecPaths[kind]
+	if !ok {
+		return nil, false
+	}
+	return append([]string{}, path...), true
+}
+
 // ToUnstructured converts runtime.Object to unstructured map[string]interface{}
 func ToUnstructured(o runtime.Object) (map[string]interface{}, error) {
 	raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(o)
@@ -962,7 +1366,38 @@ func hasDefaultIngressBackendKeyword(v []string) bool {
 }
 
 // createIngressRule creates an ingress rule using a set of parameters.
-func createIngressRule(host, path, serviceName string, port int32) networkingv1beta1.IngressRule {
+// Path defaults to the root path as networking.k8s.io/v1 requires an absolute path.
+func createIngressRule(host, path, serviceName string, port int32) networking.IngressRule {
+	if path == "" {
+		path = "/"
+	}
+	pathType := networking.PathTypePrefix
+
+	return networking.IngressRule{
+		Host: host,
+		IngressRuleValue: networking.IngressRuleValue{
+			HTTP: &networking.HTTPIngressRuleValue{
+				Paths: []networking.HTTPIngressPath{
+					{
+						Path:     path,
+						PathType: &pathType,
+						Backend: networking.IngressBackend{
+							Service: &networking.IngressServiceBackend{
+								Name: serviceName,
+								Port: networking.ServiceBackendPort{
+									Number: port,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// createLegacyIngressRule creates a networking.k8s.io/v1beta1 ingress rule using a set of parameters.
+func createLegacyIngressRule(host, path, serviceName string, port int32) networkingv1beta1.IngressRule {
 	return networkingv1beta1.IngressRule{
 		Host: host,
 		IngressRuleValue: networkingv1beta1.IngressRuleValue{
@@ -18,6 +18,7 @@ package kubernetes
 
 import (
 	"fmt"
+	"time"
 
 	composego "github.com/compose-spec/compose-go/types"
 	. "github.com/onsi/ginkgo"
@@ -27,11 +28,33 @@ import (
 	"k8s.io/api/extensions/v1beta1"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
+// customResource is a minimal custom resource type used to exercise scheme registration
+type customResource struct {
+	meta.TypeMeta
+	meta.ObjectMeta
+}
+
+func (c *customResource) DeepCopyObject() runtime.Object {
+	out := *c
+	return &out
+}
+
 var _ = Describe("Utils", func() {
 
+	Describe("progress", func() {
+		It("reports only the count for the first item", func() {
+			Expect(progress(1, 4, 0)).To(Equal("1/4"))
+		})
+
+		It("reports estimated time remaining based on already processed items", func() {
+			Expect(progress(3, 4, 2*time.Second)).To(Equal("3/4, ~2s remaining"))
+		})
+	})
+
 	Describe("convertToVersion", func() {
 
 		Context("with unstructured object", func() {
@@ -89,6 +112,43 @@ var _ = Describe("Utils", func() {
 					Expect(err).ToNot(HaveOccurred())
 				})
 			})
+
+			Context("when object type is registered with the scheme and has no type meta", func() {
+				o := &v1.ConfigMap{
+					ObjectMeta: meta.ObjectMeta{
+						Name: "my-config",
+					},
+				}
+				gv := schema.GroupVersion{}
+
+				It("infers version information from the scheme", func() {
+					versioned, err := convertToVersion(o, gv)
+					Expect(err).ToNot(HaveOccurred())
+
+					info := versioned.GetObjectKind().GroupVersionKind()
+					Expect(info.Kind).To(Equal("ConfigMap"))
+					Expect(info.Version).To(Equal("v1"))
+					Expect(info.Group).To(Equal(""))
+				})
+			})
+		})
+	})
+
+	Describe("AddToScheme", func() {
+		gv := schema.GroupVersion{Group: "kev.appvia.io", Version: "v1alpha1"}
+
+		It("registers custom types with the converter scheme", func() {
+			err := AddToScheme(func(s *runtime.Scheme) error {
+				s.AddKnownTypes(gv, &customResource{})
+				return nil
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			versioned, err := convertToVersion(&customResource{}, schema.GroupVersion{})
+			Expect(err).ToNot(HaveOccurred())
+
+			info := versioned.GetObjectKind().GroupVersionKind()
+			Expect(info).To(Equal(gv.WithKind("customResource")))
 		})
 	})
 
@@ -462,36 +522,37 @@ var _ = Describe("Utils", func() {
 	})
 
 	Describe("loadPlacement", func() {
+		projectService := &ProjectService{}
 
 		Context("for supported compose placement constraint", func() {
 
 			Context("node.hostname==xyz...", func() {
 				It("returns expected kubernetes node selector", func() {
-					Expect(loadPlacement([]string{"node.hostname==myhost"})).To(HaveKeyWithValue("kubernetes.io/hostname", "myhost"))
+					Expect(projectService.loadPlacement([]string{"node.hostname==myhost"})).To(HaveKeyWithValue("kubernetes.io/hostname", "myhost"))
 				})
 			})
 
 			Context("node.role==worker", func() {
 				It("returns expected kubernetes node selector", func() {
-					Expect(loadPlacement([]string{"node.role==worker"})).To(HaveKeyWithValue("node-role.kubernetes.io/worker", "true"))
+					Expect(projectService.loadPlacement([]string{"node.role==worker"})).To(HaveKeyWithValue("node-role.kubernetes.io/worker", "true"))
 				})
 			})
 
 			Context("node.role==manager", func() {
 				It("returns expected kubernetes node selector", func() {
-					Expect(loadPlacement([]string{"node.role==manager"})).To(HaveKeyWithValue("node-role.kubernetes.io/master", "true"))
+					Expect(projectService.loadPlacement([]string{"node.role==manager"})).To(HaveKeyWithValue("node-role.kubernetes.io/master", "true"))
 				})
 			})
 
 			Context("engine.labels.operatingsystem==linux", func() {
 				It("returns expected kubernetes node selector", func() {
-					Expect(loadPlacement([]string{"engine.labels.operatingsystem==linux"})).To(HaveKeyWithValue("beta.kubernetes.io/os", "linux"))
+					Expect(projectService.loadPlacement([]string{"engine.labels.operatingsystem==linux"})).To(HaveKeyWithValue("beta.kubernetes.io/os", "linux"))
 				})
 			})
 
 			Context("node.labels.(...)", func() {
 				It("returns expected kubernetes node selector", func() {
-					Expect(loadPlacement([]string{"node.labels.key==value"})).To(HaveKeyWithValue("key", "value"))
+					Expect(projectService.loadPlacement([]string{"node.labels.key==value"})).To(HaveKeyWithValue("key", "value"))
 				})
 			})
 		})
@@ -500,7 +561,7 @@ var _ = Describe("Utils", func() {
 			placement := "invalid==value"
 
 			It("warns user and ignores placement constraint", func() {
-				Expect(loadPlacement([]string{placement})).To(HaveLen(0))
+				Expect(projectService.loadPlacement([]string{placement})).To(HaveLen(0))
 
 				assertLog(logrus.WarnLevel,
 					"Constraint in placement is not supported. Only 'node.hostname==...', 'node.role==worker', 'node.role==manager', 'engine.labels.operatingsystem' and 'node.labels.(...)' (ex: node.labels.something==anything) is supported as a constraint",
@@ -572,5 +633,106 @@ var _ = Describe("Utils", func() {
 			Expect(annotations).To(HaveKeyWithValue("info.kev.io/annotation-db", "|\n{{- with secret \"database/creds/db-app\" -}}\n\tpostgres://{{ .Data.username }}:{{ .Data.password }}@postgres:5432/mydb?sslmode=disable\n{{- end }}"))
 		})
 
+		Context("with custom annotation labels", func() {
+			BeforeEach(func() {
+				projectService.Labels["kev.annotation.prometheus.io/scrape"] = "true"
+				projectService.Labels["kev.annotation.FOO"] = "CUSTOM"
+				projectService.Labels["kev.annotation.info.kev.io/annotation1"] = "custom"
+			})
+
+			It("adds them without the prefix, preserving dots in their keys", func() {
+				annotations := configAnnotations(projectService.Labels)
+				Expect(annotations).To(HaveKeyWithValue("prometheus.io/scrape", "true"))
+				Expect(annotations).NotTo(HaveKey("kev.annotation.prometheus.io/scrape"))
+			})
+
+			It("takes precedence over labels of the same name", func() {
+				annotations := configAnnotations(projectService.Labels)
+				Expect(annotations).To(HaveKeyWithValue("FOO", "CUSTOM"))
+				Expect(annotations).To(HaveKeyWithValue("BAR", "BAZ"))
+			})
+
+			It("gets overridden by annotations from later sources", func() {
+				annotations := configAnnotations(projectService.Labels, projectService.podAnnotations())
+				Expect(annotations).To(HaveKeyWithValue("info.kev.io/annotation1", "app/role/value1"))
+			})
+		})
+
+	})
+
+	Describe("expandPlaceholders", func() {
+		vars := map[string]string{
+			"service": "api",
+			"env":     "staging",
+			"project": "shop",
+		}
+
+		It("expands known placeholders", func() {
+			out, err := expandPlaceholders("${service}-team/${env}/${project}", vars)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal("api-team/staging/shop"))
+		})
+
+		It("leaves values without placeholders untouched", func() {
+			out, err := expandPlaceholders("{{ .Data.username }}", vars)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal("{{ .Data.username }}"))
+		})
+
+		It("errors on unknown placeholders", func() {
+			_, err := expandPlaceholders("${servcie}-team", vars)
+			Expect(err).To(MatchError(ContainSubstring("unknown placeholder ${servcie}")))
+		})
+	})
+
+	Describe("expandMetadataPlaceholders", func() {
+		var (
+			k              Kubernetes
+			projectService ProjectService
+			deploy         *composego.DeployConfig
+			err            error
+		)
+
+		BeforeEach(func() {
+			deploy = &composego.DeployConfig{
+				Labels: composego.Labels{"team": "${service}-team"},
+			}
+
+			projectService, err = NewProjectService(composego.ServiceConfig{
+				Name:   "api",
+				Deploy: deploy,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			projectService.SvcK8sConfig.Workload.Annotations = map[string]string{"owner": "${project}-${env}"}
+			projectService.SvcK8sConfig.Service.Expose.IngressAnnotations = map[string]string{"host": "${service}.${env}"}
+
+			k = Kubernetes{
+				Project:     &composego.Project{Name: "shop"},
+				Environment: "dev",
+			}
+		})
+
+		It("expands placeholders in extra labels & annotations", func() {
+			Expect(k.expandMetadataPlaceholders(&projectService)).To(Succeed())
+			Expect(configAllLabels(projectService)).To(HaveKeyWithValue("team", "api-team"))
+			Expect(projectService.podAnnotations()).To(HaveKeyWithValue("owner", "shop-dev"))
+			Expect(projectService.ingressAnnotations()).To(HaveKeyWithValue("host", "api.dev"))
+		})
+
+		It("leaves the compose deploy labels untouched", func() {
+			Expect(k.expandMetadataPlaceholders(&projectService)).To(Succeed())
+			Expect(deploy.Labels).To(HaveKeyWithValue("team", "${service}-team"))
+		})
+
+		It("errors on unknown placeholders", func() {
+			projectService.SvcK8sConfig.Service.Annotations = map[string]string{"tier": "${tier}"}
+			Expect(k.expandMetadataPlaceholders(&projectService)).To(MatchError(ContainSubstring("unknown placeholder ${tier}")))
+		})
+
+		It("errors on placeholders without a value", func() {
+			k.Environment = ""
+			Expect(k.expandMetadataPlaceholders(&projectService)).To(MatchError(ContainSubstring("placeholder ${env} in \"${project}-${env}\" has no value")))
+		})
 	})
 })
@@ -0,0 +1,150 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"reflect"
+
+	"github.com/appvia/kev/pkg/kev/log"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// VPAModeLabel is the compose service label enabling a vertical pod autoscaler for the service workload
+	// in the given update mode, e.g. `kev.workload.vpa-mode: "Off"` to only gather resources recommendations
+	VPAModeLabel = "kev.workload.vpa-mode"
+
+	// VPAUpdateModeOff only provides resources recommendations without ever changing pods resources
+	VPAUpdateModeOff = "Off"
+
+	// VPAUpdateModeInitial only assigns recommended resources to pods on creation
+	VPAUpdateModeInitial = "Initial"
+
+	// VPAUpdateModeRecreate assigns recommended resources to pods on creation and evicts pods to update them
+	VPAUpdateModeRecreate = "Recreate"
+
+	// VPAUpdateModeAuto assigns recommended resources to pods using the best available update method
+	VPAUpdateModeAuto = "Auto"
+)
+
+// VPAUpdateModes lists the supported vertical pod autoscaler update modes
+var VPAUpdateModes = []string{VPAUpdateModeOff, VPAUpdateModeInitial, VPAUpdateModeRecreate, VPAUpdateModeAuto}
+
+// VPAGroupVersion is the group version of Vertical Pod Autoscaler custom resources
+var VPAGroupVersion = schema.GroupVersion{Group: "autoscaling.k8s.io", Version: "v1"}
+
+// AddVPAToScheme registers Vertical Pod Autoscaler custom resources with the supplied scheme
+func AddVPAToScheme(s *runtime.Scheme) error {
+	s.AddKnownTypes(VPAGroupVersion, &VerticalPodAutoscaler{})
+	return nil
+}
+
+// VerticalPodAutoscaler is a custom resource recommending, and optionally setting, a workload's pods resources
+type VerticalPodAutoscaler struct {
+	meta.TypeMeta   `json:",inline"`
+	meta.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec VerticalPodAutoscalerSpec `json:"spec"`
+}
+
+// VerticalPodAutoscalerSpec defines the workload targeted by a VerticalPodAutoscaler and how its pods are updated
+type VerticalPodAutoscalerSpec struct {
+	TargetRef    *autoscalingv1.CrossVersionObjectReference `json:"targetRef"`
+	UpdatePolicy *VerticalPodAutoscalerUpdatePolicy         `json:"updatePolicy,omitempty"`
+}
+
+// VerticalPodAutoscalerUpdatePolicy defines whether recommended resources are applied to pods
+type VerticalPodAutoscalerUpdatePolicy struct {
+	UpdateMode string `json:"updateMode,omitempty"`
+}
+
+// DeepCopyInto copies the receiver into out
+func (in *VerticalPodAutoscaler) DeepCopyInto(out *VerticalPodAutoscaler) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+
+	if in.Spec.TargetRef != nil {
+		ref := *in.Spec.TargetRef
+		out.Spec.TargetRef = &ref
+	}
+
+	if in.Spec.UpdatePolicy != nil {
+		policy := *in.Spec.UpdatePolicy
+		out.Spec.UpdatePolicy = &policy
+	}
+}
+
+// DeepCopy returns a deep copy of the VerticalPodAutoscaler
+func (in *VerticalPodAutoscaler) DeepCopy() *VerticalPodAutoscaler {
+	if in == nil {
+		return nil
+	}
+	out := new(VerticalPodAutoscaler)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject implements runtime.Object
+func (in *VerticalPodAutoscaler) DeepCopyObject() runtime.Object {
+	return in.DeepCopy()
+}
+
+// initVpa initialises a vertical pod autoscaler targeting the project service workload, if enabled.
+// Only long running workloads, i.e. Deployments, StatefulSets & DaemonSets, are supported.
+func (k *Kubernetes) initVpa(projectService ProjectService, target runtime.Object) (*VerticalPodAutoscaler, error) {
+	mode, err := projectService.vpaMode()
+	if err != nil || mode == "" {
+		return nil, err
+	}
+
+	t := reflect.ValueOf(target).Elem()
+	typeMeta := t.FieldByName("TypeMeta").Interface().(meta.TypeMeta)
+	if !contains([]string{"Deployment", "StatefulSet", "DaemonSet"}, typeMeta.Kind) {
+		k.warnWithFields(log.Fields{
+			"project-service": projectService.Name,
+			"kind":            typeMeta.Kind,
+		}, "Unsupported target kind for Vertical Pod Autoscaler. Skipping ...")
+
+		return nil, nil
+	}
+
+	return &VerticalPodAutoscaler{
+		TypeMeta: meta.TypeMeta{
+			Kind:       "VerticalPodAutoscaler",
+			APIVersion: VPAGroupVersion.String(),
+		},
+		ObjectMeta: meta.ObjectMeta{
+			Name:        projectService.Name,
+			Labels:      configObjectLabels(projectService),
+			Annotations: configAnnotations(projectService.Labels),
+		},
+		Spec: VerticalPodAutoscalerSpec{
+			TargetRef: &autoscalingv1.CrossVersionObjectReference{
+				Kind:       typeMeta.Kind,
+				APIVersion: typeMeta.APIVersion,
+				Name:       projectService.Name,
+			},
+			UpdatePolicy: &VerticalPodAutoscalerUpdatePolicy{
+				UpdateMode: mode,
+			},
+		},
+	}, nil
+}
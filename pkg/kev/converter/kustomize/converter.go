@@ -0,0 +1,163 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kustomize
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/appvia/kev/pkg/kev/converter/kubernetes"
+	"github.com/appvia/kev/pkg/kev/log"
+	kmd "github.com/appvia/komando"
+	composego "github.com/compose-spec/compose-go/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	// Name of the converter
+	Name = "kustomize"
+
+	// MultiFileSubDir is default output directory name for kustomize bases & overlays
+	MultiFileSubDir = "kustomize"
+
+	baseSubDir     = "base"
+	overlaysSubDir = "overlays"
+)
+
+// Kustomize is a Kustomize base & overlays converter.
+// It renders objects common to all environments into a shared base,
+// and an overlay for each environment capturing its differences.
+type Kustomize struct {
+	UI   kmd.UI
+	opts []kubernetes.Option
+}
+
+// New return a Kustomize converter
+func New(opts ...kubernetes.Option) *Kustomize {
+	return &Kustomize{UI: kmd.NoOpUI(), opts: opts}
+}
+
+// NewWithUI return a Kustomize converter using the supplied UI
+func NewWithUI(ui kmd.UI, opts ...kubernetes.Option) *Kustomize {
+	return &Kustomize{UI: ui, opts: opts}
+}
+
+// Render generates a Kustomize base and an overlay for each environment.
+// These are placed in <dir> or <workDir>/kustomize when no output directory is specified.
+// The singleFile option isn't applicable as output follows the Kustomize directory structure.
+func (c *Kustomize) Render(singleFile bool,
+	dir, workDir string,
+	projects map[string]*composego.Project,
+	files map[string][]string,
+	rendered map[string][]byte,
+	excluded map[string][]string) (map[string]string, error) {
+
+	outDir := filepath.Join(workDir, MultiFileSubDir)
+	if dir != "" {
+		outDir = dir
+	}
+
+	// @step transform each environment's project to the Kubernetes objects the manifests converter renders
+	k := kubernetes.NewWithUI(c.UI, c.opts...)
+	envObjects := map[string]objects{}
+	envNamespaces := map[string]string{}
+	for _, env := range sortedEnvs(projects) {
+		log.Debugf("Rendering environment [%s] as a Kustomize overlay", env)
+
+		envFile := files[env][len(files[env])-1]
+		c.UI.Output(fmt.Sprintf("%s: %s", env, envFile))
+
+		transformed, err := k.TransformEnv(env, projects[env], files[env], excluded[env])
+		if err != nil {
+			return nil, err
+		}
+
+		objs, err := newObjects(transformed)
+		if err != nil {
+			return nil, err
+		}
+		envNamespaces[env] = liftEnvironment(objs)
+		envObjects[env] = objs
+	}
+
+	// @step split objects into a shared base and per environment overlays
+	base, overlays, err := split(envObjects)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Could not build %s base & overlays", Name)
+	}
+
+	if err := base.write(filepath.Join(outDir, baseSubDir), rendered); err != nil {
+		return nil, errors.Wrapf(err, "Could not render %s base to disk, details:\n", Name)
+	}
+
+	renderOutputPaths := map[string]string{}
+	for env, overlay := range overlays {
+		// @step overlays set back their environment's label and namespace
+		overlay.namespace = envNamespaces[env]
+		overlay.labels = map[string]string{kubernetes.EnvironmentLabel: env}
+
+		overlayDir := filepath.Join(outDir, overlaysSubDir, env)
+		if err := overlay.write(overlayDir, rendered); err != nil {
+			return nil, errors.Wrapf(err, "Could not render %s overlay to disk, details:\n", Name)
+		}
+		renderOutputPaths[env] = overlayDir
+	}
+
+	return renderOutputPaths, nil
+}
+
+// write writes the kustomization and its files to the supplied directory,
+// replacing any previously rendered content
+func (k *kustomization) write(dir string, rendered map[string][]byte) error {
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	files, err := k.files()
+	if err != nil {
+		return err
+	}
+
+	for name, data := range files {
+		file := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(file, data, 0644); err != nil {
+			log.ErrorWithFields(log.Fields{
+				"file": file,
+			}, "Failed to write content to a file")
+			return err
+		}
+		rendered[file] = data
+	}
+
+	return nil
+}
+
+func sortedEnvs(projects map[string]*composego.Project) []string {
+	var out []string
+	for env := range projects {
+		out = append(out, env)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,352 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kustomize
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/appvia/kev/pkg/kev/converter/kubernetes"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	kustomizationFileName = "kustomization.yaml"
+	kustomizationAPI      = "kustomize.config.k8s.io/v1beta1"
+	kustomizationKind     = "Kustomization"
+
+	// baseRef is the relative location of the base from an overlay directory
+	baseRef = "../../" + baseSubDir
+)
+
+// objects holds unstructured Kubernetes objects keyed by their manifest file name
+type objects map[string]map[string]interface{}
+
+// kustomization holds the content of a base or an overlay
+type kustomization struct {
+	bases     []string
+	resources objects
+	patches   objects
+	namespace string
+	labels    map[string]string
+}
+
+// kustomizationFile is the kustomization.yaml file content
+type kustomizationFile struct {
+	APIVersion            string                `yaml:"apiVersion"`
+	Kind                  string                `yaml:"kind"`
+	Namespace             string                `yaml:"namespace,omitempty"`
+	Labels                []kustomizationLabels `yaml:"labels,omitempty"`
+	Resources             []string              `yaml:"resources,omitempty"`
+	PatchesStrategicMerge []string              `yaml:"patchesStrategicMerge,omitempty"`
+}
+
+// kustomizationLabels is a kustomization labels entry, labelling objects and their pod templates but not selectors
+type kustomizationLabels struct {
+	Pairs            map[string]string `yaml:"pairs"`
+	IncludeTemplates bool              `yaml:"includeTemplates,omitempty"`
+}
+
+// newObjects converts Kubernetes objects to unstructured objects keyed by their manifest file name
+func newObjects(in []runtime.Object) (objects, error) {
+	out := objects{}
+	for _, o := range in {
+		u, err := kubernetes.ToUnstructured(o)
+		if err != nil {
+			return nil, err
+		}
+
+		obj := unstructured.Unstructured{Object: u}
+		out[fmt.Sprintf("%s-%s.yaml", obj.GetName(), strings.ToLower(obj.GetKind()))] = u
+	}
+	return out, nil
+}
+
+// liftEnvironment removes the environment label and namespace from an environment's objects, and their pod templates,
+// so that objects common to all environments can be placed in the base. It returns the namespace for the
+// environment's overlay to set back, empty when objects aren't placed in a namespace.
+func liftEnvironment(objs objects) string {
+	namespace := ""
+	for _, u := range objs {
+		obj := unstructured.Unstructured{Object: u}
+		if ns := obj.GetNamespace(); ns != "" && obj.GetKind() != "Namespace" {
+			namespace = ns
+		}
+		unstructured.RemoveNestedField(u, "metadata", "namespace")
+		removeLabel(u, kubernetes.EnvironmentLabel, "metadata", "labels")
+
+		if path, ok := kubernetes.PodSpecPath(obj.GetKind()); ok {
+			template := append(path[:len(path)-1], "metadata", "labels")
+			removeLabel(u, kubernetes.EnvironmentLabel, template...)
+		}
+	}
+	return namespace
+}
+
+// removeLabel removes a label from the labels at the supplied path, dropping them once empty
+func removeLabel(u map[string]interface{}, key string, fields ...string) {
+	labels, found, err := unstructured.NestedMap(u, fields...)
+	if err != nil || !found {
+		return
+	}
+
+	delete(labels, key)
+	if len(labels) == 0 {
+		unstructured.RemoveNestedField(u, fields...)
+		return
+	}
+	_ = unstructured.SetNestedMap(u, labels, fields...)
+}
+
+// split splits environment objects into a base and an overlay for each environment.
+// Objects present in all environments which differ only by their replicas, main container
+// image and resources are placed in the base, using the first environment's object.
+// Overlays patch these with their environment's values. All other objects are
+// environment specific and are placed in their environment's overlay as they are.
+func split(envObjects map[string]objects) (*kustomization, map[string]*kustomization, error) {
+	var envs []string
+	for env := range envObjects {
+		envs = append(envs, env)
+	}
+	sort.Strings(envs)
+
+	base := &kustomization{resources: objects{}, patches: objects{}}
+	overlays := map[string]*kustomization{}
+
+	if len(envs) == 0 {
+		return base, overlays, nil
+	}
+
+	// @step establish objects common to all environments
+	for file, ref := range envObjects[envs[0]] {
+		refVariant, err := invariant(ref)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		common := true
+		for _, env := range envs[1:] {
+			o, ok := envObjects[env][file]
+			if !ok {
+				common = false
+				break
+			}
+
+			v, err := invariant(o)
+			if err != nil {
+				return nil, nil, err
+			}
+			if !reflect.DeepEqual(refVariant, v) {
+				common = false
+				break
+			}
+		}
+
+		if common {
+			base.resources[file] = ref
+		}
+	}
+
+	// @step build environment overlays
+	for _, env := range envs {
+		overlay := &kustomization{bases: []string{baseRef}, resources: objects{}, patches: objects{}}
+
+		for file, o := range envObjects[env] {
+			b, ok := base.resources[file]
+			if !ok {
+				overlay.resources[file] = o
+				continue
+			}
+
+			p, changed, err := patch(b, o)
+			if err != nil {
+				return nil, nil, err
+			}
+			if changed {
+				overlay.patches[strings.TrimSuffix(file, ".yaml")+"-patch.yaml"] = p
+			}
+		}
+
+		overlays[env] = overlay
+	}
+
+	return base, overlays, nil
+}
+
+// invariant returns a copy of the object without the fields overlays are allowed to patch
+func invariant(u map[string]interface{}) (map[string]interface{}, error) {
+	c := runtime.DeepCopyJSON(u)
+	unstructured.RemoveNestedField(c, "spec", "replicas")
+
+	main, err := mainContainer(c)
+	if err != nil {
+		return nil, err
+	}
+	if main != nil {
+		delete(main, "image")
+		delete(main, "resources")
+	}
+
+	return c, nil
+}
+
+// patch builds a strategic merge patch for the replicas, main container image and resources
+// of an environment object that differ from its base object
+func patch(base, env map[string]interface{}) (map[string]interface{}, bool, error) {
+	obj := unstructured.Unstructured{Object: env}
+	p := map[string]interface{}{
+		"apiVersion": obj.GetAPIVersion(),
+		"kind":       obj.GetKind(),
+		"metadata": map[string]interface{}{
+			"name": obj.GetName(),
+		},
+	}
+	changed := false
+
+	envReplicas, found, err := unstructured.NestedFieldNoCopy(env, "spec", "replicas")
+	if err != nil {
+		return nil, false, err
+	}
+	baseReplicas, _, err := unstructured.NestedFieldNoCopy(base, "spec", "replicas")
+	if err != nil {
+		return nil, false, err
+	}
+	if found && !reflect.DeepEqual(envReplicas, baseReplicas) {
+		if err := unstructured.SetNestedField(p, envReplicas, "spec", "replicas"); err != nil {
+			return nil, false, err
+		}
+		changed = true
+	}
+
+	envMain, err := mainContainer(env)
+	if err != nil {
+		return nil, false, err
+	}
+	baseMain, err := mainContainer(base)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if envMain != nil && baseMain != nil {
+		containerPatch := map[string]interface{}{"name": envMain["name"]}
+
+		if !reflect.DeepEqual(envMain["image"], baseMain["image"]) {
+			containerPatch["image"] = envMain["image"]
+		}
+
+		if !reflect.DeepEqual(envMain["resources"], baseMain["resources"]) {
+			// replace rather than merge resources so that requests & limits removed in the environment don't linger
+			resources := map[string]interface{}{}
+			if r, ok := envMain["resources"].(map[string]interface{}); ok {
+				resources = runtime.DeepCopyJSON(r)
+			}
+			resources["$patch"] = "replace"
+			containerPatch["resources"] = resources
+		}
+
+		if len(containerPatch) > 1 {
+			path, _ := kubernetes.PodSpecPath(obj.GetKind())
+			if err := unstructured.SetNestedSlice(p, []interface{}{containerPatch}, append(path, "containers")...); err != nil {
+				return nil, false, err
+			}
+			changed = true
+		}
+	}
+
+	return p, changed, nil
+}
+
+// mainContainer returns the workload's main container, i.e. the first one, or nil for non workload objects
+func mainContainer(u map[string]interface{}) (map[string]interface{}, error) {
+	obj := unstructured.Unstructured{Object: u}
+	path, ok := kubernetes.PodSpecPath(obj.GetKind())
+	if !ok {
+		return nil, nil
+	}
+
+	containers, found, err := unstructured.NestedFieldNoCopy(u, append(path, "containers")...)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	if c, ok := containers.([]interface{}); ok && len(c) > 0 {
+		if main, ok := c[0].(map[string]interface{}); ok {
+			return main, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// files returns the kustomization files content keyed by file name
+func (k *kustomization) files() (map[string][]byte, error) {
+	out := map[string][]byte{}
+
+	kf := kustomizationFile{
+		APIVersion: kustomizationAPI,
+		Kind:       kustomizationKind,
+		Namespace:  k.namespace,
+		Resources:  append([]string{}, k.bases...),
+	}
+	if len(k.labels) > 0 {
+		kf.Labels = []kustomizationLabels{{Pairs: k.labels, IncludeTemplates: true}}
+	}
+
+	for _, set := range []struct {
+		objs  objects
+		names *[]string
+	}{
+		{k.resources, &kf.Resources},
+		{k.patches, &kf.PatchesStrategicMerge},
+	} {
+		var names []string
+		for name, o := range set.objs {
+			data, err := marshal(o)
+			if err != nil {
+				return nil, err
+			}
+			out[name] = data
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		*set.names = append(*set.names, names...)
+	}
+
+	data, err := marshal(kf)
+	if err != nil {
+		return nil, err
+	}
+	out[kustomizationFileName] = data
+
+	return out, nil
+}
+
+// marshal marshals a value as YAML
+func marshal(v interface{}) ([]byte, error) {
+	var b bytes.Buffer
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
@@ -0,0 +1,181 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kustomize
+
+import (
+	"github.com/appvia/kev/pkg/kev/converter/kubernetes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v3"
+	v1apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var _ = Describe("Kustomization", func() {
+
+	deployment := func(replicas int32, image, memory string) *v1apps.Deployment {
+		return &v1apps.Deployment{
+			TypeMeta:   meta.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+			ObjectMeta: meta.ObjectMeta{Name: "web"},
+			Spec: v1apps.DeploymentSpec{
+				Replicas: &replicas,
+				Template: v1.PodTemplateSpec{
+					Spec: v1.PodSpec{
+						Containers: []v1.Container{
+							{
+								Name:  "web",
+								Image: image,
+								Resources: v1.ResourceRequirements{
+									Requests: v1.ResourceList{
+										v1.ResourceMemory: resource.MustParse(memory),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	service := func(port int32) *v1.Service {
+		return &v1.Service{
+			TypeMeta:   meta.TypeMeta{Kind: "Service", APIVersion: "v1"},
+			ObjectMeta: meta.ObjectMeta{Name: "web"},
+			Spec: v1.ServiceSpec{
+				Ports: []v1.ServicePort{{Port: port}},
+			},
+		}
+	}
+
+	var envObjects map[string]objects
+
+	BeforeEach(func() {
+		dev, err := newObjects([]runtime.Object{deployment(1, "web:dev", "10Mi"), service(80)})
+		Expect(err).NotTo(HaveOccurred())
+		stage, err := newObjects([]runtime.Object{deployment(3, "web:stage", "10Mi"), service(8080)})
+		Expect(err).NotTo(HaveOccurred())
+
+		envObjects = map[string]objects{"dev": dev, "stage": stage}
+	})
+
+	Describe("split", func() {
+		It("places objects differing only by patchable fields in the base", func() {
+			base, _, err := split(envObjects)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(base.resources).To(HaveLen(1))
+			Expect(base.resources).To(HaveKey("web-deployment.yaml"))
+		})
+
+		It("places other objects in environment overlays", func() {
+			_, overlays, err := split(envObjects)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(overlays["dev"].resources).To(HaveKey("web-service.yaml"))
+			Expect(overlays["stage"].resources).To(HaveKey("web-service.yaml"))
+		})
+
+		It("patches environment differences", func() {
+			_, overlays, err := split(envObjects)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(overlays["dev"].patches).To(BeEmpty())
+			Expect(overlays["stage"].patches).To(HaveKey("web-deployment-patch.yaml"))
+
+			p := overlays["stage"].patches["web-deployment-patch.yaml"]
+			Expect(p["spec"]).To(HaveKeyWithValue("replicas", int64(3)))
+
+			container := p["spec"].(map[string]interface{})["template"].(map[string]interface{})["spec"].(map[string]interface{})["containers"].([]interface{})[0]
+			Expect(container).To(HaveKeyWithValue("name", "web"))
+			Expect(container).To(HaveKeyWithValue("image", "web:stage"))
+			Expect(container).NotTo(HaveKey("resources"))
+		})
+	})
+
+	Describe("liftEnvironment", func() {
+		It("removes the environment label and namespace, returning the namespace", func() {
+			d := deployment(1, "web:dev", "10Mi")
+			d.Namespace = "team-dev"
+			d.Labels = map[string]string{kubernetes.EnvironmentLabel: "dev", "app": "web"}
+			d.Spec.Template.Labels = map[string]string{kubernetes.EnvironmentLabel: "dev"}
+
+			objs, err := newObjects([]runtime.Object{d})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(liftEnvironment(objs)).To(Equal("team-dev"))
+
+			u := objs["web-deployment.yaml"]
+			metadata := u["metadata"].(map[string]interface{})
+			Expect(metadata).NotTo(HaveKey("namespace"))
+			Expect(metadata["labels"]).To(Equal(map[string]interface{}{"app": "web"}))
+
+			template := u["spec"].(map[string]interface{})["template"].(map[string]interface{})
+			Expect(template["metadata"]).NotTo(HaveKey("labels"))
+		})
+
+		It("places objects of environments differing only by their label in the base", func() {
+			for env, objs := range envObjects {
+				for _, u := range objs {
+					Expect(unstructured.SetNestedField(u, env, "metadata", "labels", kubernetes.EnvironmentLabel)).To(Succeed())
+				}
+				liftEnvironment(objs)
+			}
+
+			base, _, err := split(envObjects)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(base.resources).To(HaveKey("web-deployment.yaml"))
+		})
+	})
+
+	Describe("files", func() {
+		It("sets the overlay environment namespace and labels, including pod templates", func() {
+			overlay := &kustomization{
+				bases:     []string{baseRef},
+				namespace: "team-dev",
+				labels:    map[string]string{kubernetes.EnvironmentLabel: "dev"},
+			}
+
+			files, err := overlay.files()
+			Expect(err).NotTo(HaveOccurred())
+
+			var kf kustomizationFile
+			Expect(yaml.Unmarshal(files[kustomizationFileName], &kf)).To(Succeed())
+			Expect(kf.Namespace).To(Equal("team-dev"))
+			Expect(kf.Labels).To(Equal([]kustomizationLabels{{
+				Pairs:            map[string]string{kubernetes.EnvironmentLabel: "dev"},
+				IncludeTemplates: true,
+			}}))
+		})
+
+		It("references the base and lists resources & patches in the overlay kustomization", func() {
+			_, overlays, err := split(envObjects)
+			Expect(err).NotTo(HaveOccurred())
+
+			files, err := overlays["stage"].files()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(HaveKey("web-service.yaml"))
+			Expect(files).To(HaveKey("web-deployment-patch.yaml"))
+
+			var kf kustomizationFile
+			Expect(yaml.Unmarshal(files[kustomizationFileName], &kf)).To(Succeed())
+			Expect(kf.Kind).To(Equal("Kustomization"))
+			Expect(kf.Resources).To(Equal([]string{"../../base", "web-service.yaml"}))
+			Expect(kf.PatchesStrategicMerge).To(Equal([]string{"web-deployment-patch.yaml"}))
+		})
+	})
+})
@@ -22,8 +22,10 @@ import (
 	"io"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"regexp"
 	"syscall"
+	"time"
 
 	"github.com/appvia/kev/pkg/kev/log"
 	kmd "github.com/appvia/komando"
@@ -32,11 +34,15 @@ import (
 	"github.com/pkg/errors"
 )
 
+// DefaultDevDebounce is the default quiet period collapsing a burst of file changes into a single re-render
+const DefaultDevDebounce = 500 * time.Millisecond
+
 // NewDevRunner creates a render runner instance
 func NewDevRunner(workingDir string, opts ...Options) *DevRunner {
 	runner := &DevRunner{
 		Project: &Project{
 			WorkingDir: workingDir,
+			config:     &runConfig{DevDebounce: DefaultDevDebounce},
 			eventHandler: func(e RunnerEvent, r Runner) error {
 				return nil
 			},
@@ -83,6 +89,7 @@ func (r *DevRunner) Run() error {
 			r.WorkingDir,
 			WithEventHandler(r.eventHandler),
 			WithEnvs(envs),
+			WithFilterServices(r.config.FilterServices),
 			WithUI(kmd.NoOpUI()),
 		)
 		if _, err := renderRunner.Run(); err != nil {
@@ -134,6 +141,12 @@ func (r *DevRunner) Run() error {
 		defer pw.Close()
 		defer pr.Close()
 
+		if r.config.SkaffoldDefaultRepo == "" {
+			if manifest, err := LoadManifest(r.WorkingDir); err == nil {
+				r.config.SkaffoldDefaultRepo = manifest.SkaffoldDefaultRepo
+			}
+		}
+
 		profileName := r.config.Envs[0] + EnvProfileNameSuffix
 		go RunSkaffoldDev(ctx, pw, skaffoldConfigPath, []string{profileName}, r.config)
 		go r.displayLogs(pr, ctx)
@@ -160,22 +173,15 @@ func (r *DevRunner) Run() error {
 				return newEventError(err, DevLoopIterated)
 			}
 
+			// bursts of changes are debounced by the watcher, so each change warrants a re-render cycle
 			_ = runPreCommands([]string{env})
-
-			// empty the buffer as we only ever do one re-render cycle per a batch of changes
-			if len(change) > 0 {
-				for range change {
-					if len(change) == 0 {
-						break
-					}
-				}
-			}
 		}
 	}
 }
 
 // Watch continuously watches source compose files & configured environment overrides
-// notifying changes to a channel
+// notifying changes to a channel. Files written, created or renamed over, e.g. by editors
+// saving atomically, are all notified.
 func (r *DevRunner) Watch(change chan<- string) error {
 	sg := r.UI.StepGroup()
 	defer sg.Done()
@@ -195,8 +201,35 @@ func (r *DevRunner) Watch(change chan<- string) error {
 	defer watcher.Close()
 
 	done := make(chan bool)
+	writes := make(chan string, 50)
+
+	go debounce(writes, change, r.config.DevDebounce)
+
+	files := manifest.GetSourcesFiles()
+	filteredEnvs, err := manifest.GetEnvironments(r.config.Envs)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range filteredEnvs {
+		files = append(files, e.File)
+	}
+
+	// watch the parent directories of tracked files rather than the files themselves,
+	// as atomic saves (write to a temp file then rename) replace the watched file & drop its watch
+	tracked, dirs, err := watchTargets(files)
+	if err != nil {
+		return err
+	}
+
+	for d := range dirs {
+		if err := watcher.Add(d); err != nil {
+			return err
+		}
+	}
 
 	go func() {
+		defer close(writes)
 		for {
 			select {
 			case event, ok := <-watcher.Events:
@@ -204,8 +237,25 @@ func (r *DevRunner) Watch(change chan<- string) error {
 					return
 				}
 
-				if event.Op&fsnotify.Write == fsnotify.Write {
-					change <- event.Name
+				if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+					continue
+				}
+
+				name, err := filepath.Abs(event.Name)
+				if err != nil {
+					continue
+				}
+
+				// re-add the watch of a tracked file's directory that got replaced
+				if event.Op&fsnotify.Create == fsnotify.Create && dirs[name] {
+					if err := watcher.Add(name); err != nil {
+						log.Error(err)
+					}
+				}
+
+				// only tracked files, or their successors replacing them, trigger a re-render
+				if tracked[name] {
+					writes <- event.Name
 				}
 			case err, ok := <-watcher.Errors:
 				if !ok {
@@ -217,26 +267,69 @@ func (r *DevRunner) Watch(change chan<- string) error {
 		}
 	}()
 
-	files := manifest.GetSourcesFiles()
-	filteredEnvs, err := manifest.GetEnvironments(r.config.Envs)
-	if err != nil {
-		return err
-	}
+	<-done
 
-	for _, e := range filteredEnvs {
-		files = append(files, e.File)
-	}
+	return nil
+}
+
+// watchTargets returns absolute paths of the tracked files and of their parent directories
+func watchTargets(files []string) (map[string]bool, map[string]bool, error) {
+	tracked := map[string]bool{}
+	dirs := map[string]bool{}
 
 	for _, f := range files {
-		err = watcher.Add(f)
+		abs, err := filepath.Abs(f)
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
+		tracked[abs] = true
+		dirs[filepath.Dir(abs)] = true
 	}
 
-	<-done
+	return tracked, dirs, nil
+}
 
-	return nil
+// debounce forwards a changed file name to the out channel once no further changes have been
+// received for the window duration, collapsing a burst of changes into a single notification.
+// Changes are forwarded immediately when the window isn't positive.
+func debounce(in <-chan string, out chan<- string, window time.Duration) {
+	var (
+		last  string
+		timer *time.Timer
+		quiet <-chan time.Time
+	)
+
+	for {
+		select {
+		case name, ok := <-in:
+			if !ok {
+				if quiet != nil {
+					out <- last
+				}
+				return
+			}
+
+			if window <= 0 {
+				out <- name
+				continue
+			}
+
+			// reset the quiet period on each change
+			last = name
+			if timer == nil {
+				timer = time.NewTimer(window)
+			} else {
+				if !timer.Stop() && quiet != nil {
+					<-timer.C
+				}
+				timer.Reset(window)
+			}
+			quiet = timer.C
+		case <-quiet:
+			out <- last
+			quiet = nil
+		}
+	}
 }
 
 // DisplaySkaffoldOptionsIfAvailable displays Skaffold related flags and
@@ -0,0 +1,114 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kev_test
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/appvia/kev/pkg/kev"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Dev", func() {
+	Describe("watchTargets", func() {
+		It("tracks files by their absolute path", func() {
+			tracked, _, err := kev.WatchTargets([]string{"compose.yaml", "compose.env-dev.yaml"})
+			Expect(err).NotTo(HaveOccurred())
+
+			compose, err := filepath.Abs("compose.yaml")
+			Expect(err).NotTo(HaveOccurred())
+			override, err := filepath.Abs("compose.env-dev.yaml")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(tracked).To(Equal(map[string]bool{compose: true, override: true}))
+		})
+
+		It("watches each parent directory once", func() {
+			_, dirs, err := kev.WatchTargets([]string{
+				"/app/compose.yaml",
+				"/app/compose.env-dev.yaml",
+				"/app/overrides/compose.env-stage.yaml",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dirs).To(Equal(map[string]bool{"/app": true, "/app/overrides": true}))
+		})
+
+		It("doesn't track anything without files", func() {
+			tracked, dirs, err := kev.WatchTargets(nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tracked).To(BeEmpty())
+			Expect(dirs).To(BeEmpty())
+		})
+	})
+
+	Describe("debounce", func() {
+		var (
+			in  chan string
+			out chan string
+		)
+
+		BeforeEach(func() {
+			in = make(chan string, 10)
+			out = make(chan string, 10)
+		})
+
+		It("coalesces a burst of changes into a single notification of the last change", func() {
+			go kev.Debounce(in, out, 100*time.Millisecond)
+
+			in <- "compose.yaml"
+			in <- "compose.env-dev.yaml"
+			in <- "compose.yaml"
+
+			Eventually(out).Should(Receive(Equal("compose.yaml")))
+			Consistently(out, 300*time.Millisecond).ShouldNot(Receive())
+			close(in)
+		})
+
+		It("notifies separate bursts separately", func() {
+			go kev.Debounce(in, out, 50*time.Millisecond)
+
+			in <- "compose.yaml"
+			Eventually(out).Should(Receive(Equal("compose.yaml")))
+
+			in <- "compose.env-dev.yaml"
+			Eventually(out).Should(Receive(Equal("compose.env-dev.yaml")))
+			close(in)
+		})
+
+		It("forwards every change when the window isn't positive", func() {
+			go kev.Debounce(in, out, 0)
+
+			in <- "compose.yaml"
+			in <- "compose.env-dev.yaml"
+
+			Eventually(out).Should(Receive(Equal("compose.yaml")))
+			Eventually(out).Should(Receive(Equal("compose.env-dev.yaml")))
+			close(in)
+		})
+
+		It("flushes a pending change when the input is closed", func() {
+			go kev.Debounce(in, out, time.Hour)
+
+			in <- "compose.yaml"
+			close(in)
+
+			Eventually(out).Should(Receive(Equal("compose.yaml")))
+		})
+	})
+})
@@ -0,0 +1,299 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kev
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/appvia/kev/pkg/kev/converter/kubernetes"
+	kmd "github.com/appvia/komando"
+	"github.com/pkg/errors"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// diffContextLines is the number of unchanged lines shown around each change
+const diffContextLines = 3
+
+// NewDiffRunner creates a diff runner instance
+func NewDiffRunner(workingDir string, opts ...Options) *DiffRunner {
+	runner := &DiffRunner{
+		Project: &Project{
+			WorkingDir:   workingDir,
+			eventHandler: func(s RunnerEvent, r Runner) error { return nil },
+		},
+	}
+	runner.Init(opts...)
+	return runner
+}
+
+// Run renders the project's Kubernetes manifests in memory and compares them with the manifests
+// currently on disk in the output directory. Neither environment files nor manifests are modified.
+// It returns the unified diffs of changed manifests keyed by environment name.
+func (r *DiffRunner) Run() (map[string][]string, error) {
+	if r.LogVerbose() {
+		cancelFunc, pr, pw := r.pipeLogsToUI()
+		defer cancelFunc()
+		defer pw.Close()
+		defer pr.Close()
+	}
+
+	if format := r.config.ManifestFormat; format != "" && format != kubernetes.Name {
+		return nil, errors.Errorf("diff supports %s manifests only, got format %s", kubernetes.Name, format)
+	}
+
+	if r.config.AllEnvsSingleFile {
+		return nil, errors.New("diff doesn't support a single file for all environments")
+	}
+
+	if err := (&RenderRunner{Project: r.Project}).LoadProject(); err != nil {
+		return nil, err
+	}
+
+	if err := r.InjectValuesFromEnv(); err != nil {
+		return nil, err
+	}
+
+	convOpts, err := r.converterOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	// reconcile summaries are not relevant here, environments are reconciled in memory only
+	ui := r.manifest.UI
+	r.manifest.UI = kmd.NoOpUI()
+	defer func() { r.manifest.UI = ui }()
+
+	if _, err := r.manifest.ReconcileConfig(r.config.Envs...); err != nil {
+		return nil, err
+	}
+
+	if err := r.ApplyValuesFile(); err != nil {
+		return nil, err
+	}
+
+	if err := r.ApplyServicesFilter(); err != nil {
+		return nil, err
+	}
+
+	if _, err := r.manifest.CalculateSourcesBaseOverride(); err != nil {
+		return nil, err
+	}
+
+	envs, err := r.manifest.GetEnvironments(r.config.Envs)
+	if err != nil {
+		return nil, err
+	}
+
+	r.UI.Header("Comparing rendered manifests...")
+	sg := r.UI.StepGroup()
+	defer sg.Done()
+
+	k := kubernetes.New(convOpts...)
+	out := map[string][]string{}
+	for _, env := range envs {
+		step := sg.Add(fmt.Sprintf("Comparing environment: %s", env.Name))
+
+		diffs, err := r.diffEnv(k, env)
+		if err != nil {
+			renderStepError(r.UI, step, renderStepRenderGeneral, err)
+			return nil, err
+		}
+		out[env.Name] = diffs
+
+		if len(diffs) == 0 {
+			step.Success(fmt.Sprintf("No differences in environment: %s", env.Name))
+			continue
+		}
+		step.Warning(fmt.Sprintf("Found differences in %d manifest(s) in environment: %s", len(diffs), env.Name))
+
+		for _, d := range diffs {
+			printDiff(r.UI, d)
+		}
+	}
+
+	return out, nil
+}
+
+// diffEnv renders an environment's manifests in memory and returns their unified diffs against the manifests on disk
+func (r *DiffRunner) diffEnv(k *kubernetes.K8s, env *Environment) ([]string, error) {
+	p, err := r.manifest.MergeEnvIntoSources(env)
+	if err != nil {
+		return nil, errors.Wrapf(err, "environment %s, details:\n", env.Name)
+	}
+
+	files := append(append([]string{}, r.manifest.GetSourcesFiles()...), env.File)
+	outDir, rendered, err := k.RenderEnvToMemory(
+		r.config.ManifestsAsSingleFile,
+		r.config.OutputDir,
+		r.manifest.getWorkingDir(),
+		env.Name,
+		p.Project,
+		files,
+		r.config.ExcludeServicesByEnv[env.Name],
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "environment %s, details:\n", env.Name)
+	}
+
+	existing, err := readManifests(outDir)
+	if err != nil {
+		return nil, err
+	}
+	if r.config.ManifestsAsSingleFile {
+		// rendering a single file only overwrites the files it writes, other files in the directory are kept
+		for path := range existing {
+			if _, ok := rendered[path]; !ok {
+				delete(existing, path)
+			}
+		}
+	}
+
+	paths := map[string]bool{}
+	for path := range rendered {
+		paths[path] = true
+	}
+	for path := range existing {
+		paths[path] = true
+	}
+
+	var sorted []string
+	for path := range paths {
+		sorted = append(sorted, path)
+	}
+	sort.Strings(sorted)
+
+	var out []string
+	for _, path := range sorted {
+		d, err := unifiedDiff(path, existing[path], rendered[path])
+		if err != nil {
+			return nil, err
+		}
+		if d != "" {
+			out = append(out, d)
+		}
+	}
+
+	return out, nil
+}
+
+// readManifests reads the files found in a directory, i.e. manifests and their index, keyed by their path.
+// Rendering multiple files replaces the directory, so any file in it is compared. A missing directory has no manifests.
+func readManifests(dir string) (map[string][]byte, error) {
+	out := map[string][]byte{}
+
+	entries, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return out, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+
+		path := filepath.Join(dir, e.Name())
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		out[path] = data
+	}
+
+	return out, nil
+}
+
+// unifiedDiff returns the unified diff between a manifest on disk and its rendered version,
+// empty when they match. A nil manifest is a manifest which doesn't exist.
+func unifiedDiff(path string, current, rendered []byte) (string, error) {
+	if string(current) == string(rendered) && (current == nil) == (rendered == nil) {
+		return "", nil
+	}
+
+	from, to := path, path
+	if current == nil {
+		from = os.DevNull
+	}
+	if rendered == nil {
+		to = os.DevNull
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(current)),
+		B:        difflib.SplitLines(string(rendered)),
+		FromFile: from,
+		ToFile:   to,
+		Context:  diffContextLines,
+	})
+}
+
+// printDiff outputs a unified diff, highlighting additions and removals
+func printDiff(ui kmd.UI, diff string) {
+	ui.Output("")
+	for _, line := range strings.Split(strings.TrimRight(diff, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"), strings.HasPrefix(line, "@@"):
+			ui.Output(line, kmd.WithStyle(kmd.LogStyle))
+		case strings.HasPrefix(line, "+"):
+			ui.Output(line, kmd.WithStyle(kmd.SuccessStyle))
+		case strings.HasPrefix(line, "-"):
+			ui.Output(line, kmd.WithErrorStyle())
+		default:
+			ui.Output(line)
+		}
+	}
+}
+
+func printDiffProjectWithOptionsError(appName string, ui kmd.UI) {
+	ui.Output("")
+	ui.Output("Project had errors during diff.\n"+
+		fmt.Sprintf("'%s' experienced some errors during project diff. The output\n", appName)+
+		"above should contain the failure messages. Please correct these errors and\n"+
+		fmt.Sprintf("run '%s diff' again.", appName),
+		kmd.WithErrorBoldStyle(),
+		kmd.WithIndentChar(kmd.ErrorIndentChar),
+	)
+}
+
+func printDiffProjectWithOptionsSummary(r *DiffRunner, diffs map[string][]string) error {
+	var changed int
+	for _, envDiffs := range diffs {
+		changed += len(envDiffs)
+	}
+
+	ui := r.GetUI()
+	ui.Output("")
+
+	if changed > 0 {
+		ui.Output(
+			fmt.Sprintf("Found differences in %d manifest(s). Run '%s render' to update them.", changed, r.AppName),
+			kmd.WithErrorBoldStyle(),
+			kmd.WithIndentChar(kmd.ErrorIndentChar),
+		)
+		return errors.Errorf("found differences in %d manifest(s)", changed)
+	}
+
+	ui.Output("Rendered manifests are up to date.", kmd.WithStyle(kmd.SuccessBoldStyle))
+	return nil
+}
@@ -0,0 +1,191 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kev_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/appvia/kev/pkg/kev"
+	kmd "github.com/appvia/komando"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Diff", func() {
+	var (
+		outDir string
+		diffs  map[string][]string
+		err    error
+	)
+
+	BeforeEach(func() {
+		var tmpErr error
+		outDir, tmpErr = ioutil.TempDir("", "kev-diff")
+		Expect(tmpErr).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		_ = os.RemoveAll(outDir)
+	})
+
+	JustBeforeEach(func() {
+		r := kev.NewDiffRunner("testdata/validate-envs",
+			kev.WithUI(kmd.NoOpUI()),
+			kev.WithEnvs([]string{"dev"}),
+			kev.WithOutputDir(outDir),
+		)
+		diffs, err = r.Run()
+	})
+
+	Context("without manifests on disk", func() {
+		It("should not error", func() {
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("reports all rendered manifests as additions", func() {
+			Expect(diffs["dev"]).NotTo(BeEmpty())
+			for _, d := range diffs["dev"] {
+				Expect(d).To(ContainSubstring("--- " + os.DevNull))
+			}
+		})
+
+		It("doesn't write any manifests", func() {
+			_, statErr := os.Stat(filepath.Join(outDir, "dev"))
+			Expect(os.IsNotExist(statErr)).To(BeTrue())
+		})
+	})
+
+	Context("with stale manifests on disk", func() {
+		BeforeEach(func() {
+			envDir := filepath.Join(outDir, "dev")
+			Expect(os.MkdirAll(envDir, os.ModePerm)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(envDir, "wordpress-deployment.yaml"), []byte("kind: Deployment\n"), 0644)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(envDir, "removed-service.yaml"), []byte("kind: Service\n"), 0644)).To(Succeed())
+		})
+
+		It("reports changed manifests", func() {
+			Expect(diffs["dev"]).To(ContainElement(And(
+				ContainSubstring("--- "+filepath.Join(outDir, "dev", "wordpress-deployment.yaml")),
+				ContainSubstring("-kind: Deployment"),
+			)))
+		})
+
+		It("reports manifests which are no longer rendered as removals", func() {
+			Expect(diffs["dev"]).To(ContainElement(And(
+				ContainSubstring("+++ "+os.DevNull),
+				ContainSubstring("-kind: Service"),
+			)))
+		})
+
+		It("leaves manifests on disk untouched", func() {
+			data, readErr := ioutil.ReadFile(filepath.Join(outDir, "dev", "wordpress-deployment.yaml"))
+			Expect(readErr).NotTo(HaveOccurred())
+			Expect(string(data)).To(Equal("kind: Deployment\n"))
+		})
+	})
+
+	Describe("after rendering", func() {
+		var (
+			projectDir string
+			opts       []kev.Options
+		)
+
+		BeforeEach(func() {
+			var tmpErr error
+			projectDir, tmpErr = ioutil.TempDir("", "kev-diff-project")
+			Expect(tmpErr).NotTo(HaveOccurred())
+
+			// render writes reconciled environments, the project is copied to leave the fixture untouched
+			for _, f := range []string{"docker-compose.yaml", "docker-compose.env.dev.yaml"} {
+				data, readErr := ioutil.ReadFile(filepath.Join("testdata/validate-envs", f))
+				Expect(readErr).NotTo(HaveOccurred())
+				Expect(ioutil.WriteFile(filepath.Join(projectDir, f), data, 0644)).To(Succeed())
+			}
+			manifest := "id: 2c6d8f0a-7e41-4b9c-a3d5-91f0e6b2c847\n" +
+				"compose:\n  - " + filepath.Join(projectDir, "docker-compose.yaml") + "\n" +
+				"environments:\n  dev: " + filepath.Join(projectDir, "docker-compose.env.dev.yaml") + "\n"
+			Expect(ioutil.WriteFile(filepath.Join(projectDir, kev.ManifestFilename), []byte(manifest), 0644)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			_ = os.RemoveAll(projectDir)
+		})
+
+		JustBeforeEach(func() {
+			common := []kev.Options{
+				kev.WithUI(kmd.NoOpUI()),
+				kev.WithEnvs([]string{"dev"}),
+				kev.WithOutputDir(outDir),
+			}
+
+			_, renderErr := kev.NewRenderRunner(projectDir, append(common, opts...)...).Run()
+			Expect(renderErr).NotTo(HaveOccurred())
+
+			diffs, err = kev.NewDiffRunner(projectDir, append(common, opts...)...).Run()
+		})
+
+		Context("as multiple files", func() {
+			BeforeEach(func() {
+				opts = nil
+			})
+
+			It("reports no changes", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(diffs["dev"]).To(BeEmpty())
+			})
+		})
+
+		Context("as a single JSON file with an index", func() {
+			BeforeEach(func() {
+				opts = []kev.Options{
+					kev.WithManifestsAsSingleFile(true),
+					kev.WithOutput("json"),
+					kev.WithIndex(true),
+				}
+			})
+
+			It("reports no changes", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(diffs["dev"]).To(BeEmpty())
+			})
+		})
+
+		Context("with different options", func() {
+			BeforeEach(func() {
+				opts = []kev.Options{kev.WithIndex(true)}
+			})
+
+			It("reports the changes", func() {
+				Expect(err).NotTo(HaveOccurred())
+
+				r := kev.NewDiffRunner(projectDir,
+					kev.WithUI(kmd.NoOpUI()),
+					kev.WithEnvs([]string{"dev"}),
+					kev.WithOutputDir(outDir),
+				)
+				withoutIndex, diffErr := r.Run()
+				Expect(diffErr).NotTo(HaveOccurred())
+				Expect(withoutIndex["dev"]).To(ConsistOf(And(
+					ContainSubstring("--- "+filepath.Join(outDir, "dev", "MANIFEST.md")),
+					ContainSubstring("+++ "+os.DevNull),
+				)))
+			})
+		})
+	})
+})
@@ -0,0 +1,335 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kev
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+
+	"github.com/appvia/kev/pkg/kev/config"
+	kmd "github.com/appvia/komando"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// DoctorError is the severity of a finding preventing the project from rendering
+	DoctorError = "error"
+	// DoctorWarning is the severity of a finding which may cause unexpected results
+	DoctorWarning = "warning"
+
+	// skaffoldBinary is the Skaffold executable name
+	skaffoldBinary = "skaffold"
+)
+
+// DoctorFinding is a setup problem found in a project, along with how to fix it
+type DoctorFinding struct {
+	Severity string
+	Check    string
+	Message  string
+	Fix      string
+}
+
+// doctorManifest is the raw manifest content, loaded without parsing the files it references
+type doctorManifest struct {
+	ID           string            `yaml:"id"`
+	Compose      []string          `yaml:"compose"`
+	Environments map[string]string `yaml:"environments"`
+	Skaffold     string            `yaml:"skaffold"`
+}
+
+// NewDoctorRunner creates a doctor runner instance
+func NewDoctorRunner(workingDir string, opts ...Options) *DoctorRunner {
+	runner := &DoctorRunner{
+		Project: &Project{
+			WorkingDir:   workingDir,
+			eventHandler: func(s RunnerEvent, r Runner) error { return nil },
+		},
+	}
+	runner.Init(opts...)
+	return runner
+}
+
+// Run checks the working directory for common setup problems and returns all findings.
+// Checks carry on after a failure whenever possible so that all problems are reported at once.
+func (r *DoctorRunner) Run() ([]DoctorFinding, error) {
+	r.UI.Header("Diagnosing project...")
+	sg := r.UI.StepGroup()
+	defer sg.Done()
+
+	var findings []DoctorFinding
+
+	step := sg.Add("Checking manifest")
+	m, manifestFindings := r.checkManifest()
+	reportDoctorStep(r.UI, step, "Manifest is valid", manifestFindings)
+	findings = append(findings, manifestFindings...)
+	if m == nil {
+		return findings, nil
+	}
+
+	step = sg.Add("Checking compose sources")
+	version, sourcesFindings := checkSources(m.Compose)
+	reportDoctorStep(r.UI, step, "Compose sources are valid", sourcesFindings)
+	findings = append(findings, sourcesFindings...)
+
+	var envs []string
+	for env := range m.Environments {
+		envs = append(envs, env)
+	}
+	sort.Strings(envs)
+
+	for _, env := range envs {
+		step = sg.Add(fmt.Sprintf("Checking environment: %s", env))
+		envFindings := checkEnvironment(env, m.Environments[env], version)
+		reportDoctorStep(r.UI, step, fmt.Sprintf("Environment %s is valid", env), envFindings)
+		findings = append(findings, envFindings...)
+	}
+
+	if m.Skaffold != "" {
+		step = sg.Add("Checking Skaffold")
+		skaffoldFindings := checkSkaffold(m.Skaffold)
+		reportDoctorStep(r.UI, step, "Skaffold is available", skaffoldFindings)
+		findings = append(findings, skaffoldFindings...)
+	}
+
+	return findings, nil
+}
+
+// checkManifest checks the manifest is present and parseable.
+// The manifest is nil when it can't be used for further checks.
+func (r *DoctorRunner) checkManifest() (*doctorManifest, []DoctorFinding) {
+	path := filepath.Join(r.WorkingDir, ManifestFilename)
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, []DoctorFinding{{
+			Severity: DoctorError,
+			Check:    "manifest",
+			Message:  fmt.Sprintf("Manifest %s not found", path),
+			Fix:      fmt.Sprintf("Run '%s init' to initialise the project", r.AppName),
+		}}
+	}
+
+	var m doctorManifest
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, []DoctorFinding{{
+			Severity: DoctorError,
+			Check:    "manifest",
+			Message:  fmt.Sprintf("Manifest %s can't be parsed: %s", path, err),
+			Fix:      fmt.Sprintf("Correct the manifest or remove it and run '%s init' again", r.AppName),
+		}}
+	}
+
+	var findings []DoctorFinding
+	if m.ID == "" {
+		findings = append(findings, DoctorFinding{
+			Severity: DoctorWarning,
+			Check:    "manifest",
+			Message:  "Manifest has no project id, it may be stale or was not created by init",
+			Fix:      fmt.Sprintf("Remove the manifest and run '%s init' again", r.AppName),
+		})
+	}
+	if len(m.Compose) == 0 {
+		findings = append(findings, DoctorFinding{
+			Severity: DoctorError,
+			Check:    "manifest",
+			Message:  "Manifest tracks no compose sources",
+			Fix:      "Add the project's compose files to the manifest compose list",
+		})
+	}
+	if len(m.Environments) == 0 {
+		findings = append(findings, DoctorFinding{
+			Severity: DoctorWarning,
+			Check:    "manifest",
+			Message:  "Manifest has no deployment environments, nothing will be rendered",
+			Fix:      "Add deployment environments and their override files to the manifest environments",
+		})
+	}
+
+	return &m, findings
+}
+
+// checkSources checks the compose sources exist and are parseable.
+// It returns the sources compose version, empty when it can't be determined.
+func checkSources(files []string) (string, []DoctorFinding) {
+	var findings []DoctorFinding
+	for _, file := range files {
+		if _, err := os.Stat(file); err != nil {
+			findings = append(findings, DoctorFinding{
+				Severity: DoctorError,
+				Check:    "sources",
+				Message:  fmt.Sprintf("Compose source %s not found", file),
+				Fix:      "Restore the file or remove it from the manifest compose list",
+			})
+		}
+	}
+
+	if len(findings) > 0 || len(files) == 0 {
+		return "", findings
+	}
+
+	p, err := NewComposeProject(files)
+	if err != nil {
+		return "", append(findings, DoctorFinding{
+			Severity: DoctorError,
+			Check:    "sources",
+			Message:  fmt.Sprintf("Compose sources can't be parsed: %s", err),
+			Fix:      "Correct the compose sources, e.g. validate them with 'docker-compose config'",
+		})
+	}
+
+	return p.GetVersion(), findings
+}
+
+// checkEnvironment checks an environment override exists, is parseable
+// and has been reconciled with the compose sources version.
+func checkEnvironment(name, file, sourcesVersion string) []DoctorFinding {
+	if _, err := os.Stat(file); err != nil {
+		return []DoctorFinding{{
+			Severity: DoctorError,
+			Check:    "environment",
+			Message:  fmt.Sprintf("Environment %s override %s not found", name, file),
+			Fix:      "Restore the file or remove the environment from the manifest",
+		}}
+	}
+
+	env, err := loadEnvironment(name, file)
+	if err != nil {
+		return []DoctorFinding{{
+			Severity: DoctorError,
+			Check:    "environment",
+			Message:  fmt.Sprintf("Environment %s override can't be parsed: %s", name, err),
+			Fix:      fmt.Sprintf("Correct the override file %s", file),
+		}}
+	}
+
+	var findings []DoctorFinding
+	for _, svc := range env.GetServices() {
+		if _, ok := svc.Extensions[config.K8SExtensionKey]; !ok {
+			continue
+		}
+		if _, err := config.ParseSvcK8sConfigFromMap(svc.Extensions, config.SkipValidation()); err != nil {
+			findings = append(findings, DoctorFinding{
+				Severity: DoctorError,
+				Check:    "environment",
+				Message:  fmt.Sprintf("Environment %s service %s extension can't be parsed: %s", name, svc.Name, errors.Cause(err)),
+				Fix:      fmt.Sprintf("Correct the service %s extension in %s", config.K8SExtensionKey, file),
+			})
+		}
+	}
+
+	if sourcesVersion != "" && env.GetVersion() != sourcesVersion {
+		findings = append(findings, DoctorFinding{
+			Severity: DoctorWarning,
+			Check:    "environment",
+			Message:  fmt.Sprintf("Environment %s override version %s differs from compose sources version %s", name, env.GetVersion(), sourcesVersion),
+			Fix:      "Render the project to reconcile the environment with its compose sources",
+		})
+	}
+
+	return findings
+}
+
+// checkSkaffold checks the Skaffold config is present and parseable, and the Skaffold binary is available
+func checkSkaffold(path string) []DoctorFinding {
+	var findings []DoctorFinding
+
+	if !ManifestExistsForPath(path) {
+		findings = append(findings, DoctorFinding{
+			Severity: DoctorError,
+			Check:    "skaffold",
+			Message:  fmt.Sprintf("Skaffold config %s not found", path),
+			Fix:      "Restore the file or remove the skaffold entry from the manifest",
+		})
+	} else if _, err := LoadSkaffoldManifest(path); err != nil {
+		findings = append(findings, DoctorFinding{
+			Severity: DoctorError,
+			Check:    "skaffold",
+			Message:  fmt.Sprintf("Skaffold config %s can't be parsed: %s", path, err),
+			Fix:      fmt.Sprintf("Correct the Skaffold config %s", path),
+		})
+	}
+
+	if _, err := exec.LookPath(skaffoldBinary); err != nil {
+		findings = append(findings, DoctorFinding{
+			Severity: DoctorWarning,
+			Check:    "skaffold",
+			Message:  "Skaffold binary not found in PATH",
+			Fix:      "Install Skaffold, see https://skaffold.dev/docs/install/",
+		})
+	}
+
+	return findings
+}
+
+// reportDoctorStep completes a doctor check step and outputs its findings
+func reportDoctorStep(ui kmd.UI, step kmd.Step, success string, findings []DoctorFinding) {
+	errs, warnings := countDoctorFindings(findings)
+	switch {
+	case errs > 0:
+		step.Error(fmt.Sprintf("Found %d error(s) and %d warning(s)", errs, warnings))
+	case warnings > 0:
+		step.Warning(fmt.Sprintf("Found %d warning(s)", warnings))
+	default:
+		step.Success(success)
+		return
+	}
+
+	for _, f := range findings {
+		ui.Output(
+			fmt.Sprintf("[%s] %s\n  fix: %s", f.Severity, f.Message, f.Fix),
+			kmd.WithStyle(kmd.LogStyle),
+			kmd.WithIndentChar(kmd.LogIndentChar),
+			kmd.WithIndent(3),
+		)
+	}
+}
+
+// countDoctorFindings returns the number of error and warning findings
+func countDoctorFindings(findings []DoctorFinding) (errs int, warnings int) {
+	for _, f := range findings {
+		if f.Severity == DoctorError {
+			errs++
+		} else {
+			warnings++
+		}
+	}
+	return errs, warnings
+}
+
+func printDoctorProjectWithOptionsSummary(r *DoctorRunner, findings []DoctorFinding) error {
+	errs, warnings := countDoctorFindings(findings)
+
+	ui := r.GetUI()
+	ui.Output("")
+
+	if errs > 0 {
+		ui.Output(
+			fmt.Sprintf("Doctor found %d error(s) and %d warning(s).", errs, warnings),
+			kmd.WithErrorBoldStyle(),
+			kmd.WithIndentChar(kmd.ErrorIndentChar),
+		)
+		return errors.Errorf("doctor found %d error(s)", errs)
+	}
+
+	ui.Output(fmt.Sprintf("No setup problems found, %d warning(s).", warnings), kmd.WithStyle(kmd.SuccessBoldStyle))
+	return nil
+}
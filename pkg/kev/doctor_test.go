@@ -0,0 +1,83 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kev_test
+
+import (
+	"github.com/appvia/kev/pkg/kev"
+	kmd "github.com/appvia/komando"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+)
+
+var _ = Describe("Doctor", func() {
+	var (
+		workingDir string
+		findings   []kev.DoctorFinding
+		err        error
+	)
+
+	JustBeforeEach(func() {
+		r := kev.NewDoctorRunner(workingDir, kev.WithUI(kmd.NoOpUI()), kev.WithAppName("kev"))
+		findings, err = r.Run()
+	})
+
+	finding := func(severity, check, message string) OmegaMatcher {
+		return MatchFields(IgnoreExtras, Fields{
+			"Severity": Equal(severity),
+			"Check":    Equal(check),
+			"Message":  ContainSubstring(message),
+		})
+	}
+
+	Context("without a manifest", func() {
+		BeforeEach(func() {
+			workingDir = "testdata/does-not-exist"
+		})
+
+		It("should not error", func() {
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("reports the missing manifest with how to fix it", func() {
+			Expect(findings).To(ConsistOf(finding(kev.DoctorError, "manifest", "not found")))
+			Expect(findings[0].Fix).To(ContainSubstring("kev init"))
+		})
+	})
+
+	Context("with setup problems", func() {
+		BeforeEach(func() {
+			workingDir = "testdata/doctor-problems"
+		})
+
+		It("should not error", func() {
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("reports missing environment overrides", func() {
+			Expect(findings).To(ContainElement(finding(kev.DoctorError, "environment", "Environment stage override")))
+		})
+
+		It("reports unparseable environment extensions", func() {
+			Expect(findings).To(ContainElement(finding(kev.DoctorError, "environment", "Environment prod service web extension")))
+		})
+
+		It("reports environments not reconciled with the compose sources", func() {
+			Expect(findings).To(ContainElement(finding(kev.DoctorWarning, "environment", "Environment dev override version 3.5")))
+		})
+	})
+})
@@ -19,8 +19,10 @@ package kev
 import (
 	"encoding/json"
 	"io"
+	"path/filepath"
 	"sort"
 
+	"github.com/appvia/kev/pkg/kev/config"
 	"github.com/appvia/kev/pkg/kev/log"
 	"github.com/imdario/mergo"
 	"github.com/pkg/errors"
@@ -183,8 +185,14 @@ func (e *Environment) WriteTo(w io.Writer) (n int64, err error) {
 	return int64(written), err
 }
 
+// EnvFile returns the path of the environment specific env file, located next to the environment's override file.
+// Its variables take precedence over the shared .env file's when interpolating the environment's compose files.
+func (e *Environment) EnvFile() string {
+	return filepath.Join(filepath.Dir(e.File), EnvFilePrefix+e.Name)
+}
+
 func (e *Environment) loadOverride() (*Environment, error) {
-	p, err := NewComposeProject([]string{e.File})
+	p, err := newEnvComposeProject([]string{e.File}, e.EnvFile(), e.interpolationValues)
 	if err != nil {
 		return nil, errors.Errorf("%s\nsee compose file: %s", err.Error(), e.File)
 	}
@@ -210,14 +218,64 @@ func (e *Environment) loadOverride() (*Environment, error) {
 		}
 		volumes[volName] = volumeConfig
 	}
+	extensions, err := getComposeExtensions(e.File)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Cannot load environment [%s] extensions", e.Name)
+	}
+	if _, err := config.ParseEnvK8sConfigFromMap(extensions); err != nil {
+		return nil, errors.Wrapf(err, "Cannot load environment [%s] configuration", e.Name)
+	}
 	e.override = &composeOverride{
 		Version:  p.GetVersion(),
 		Services: services,
 		Volumes:  volumes,
 	}
+	if len(extensions) > 0 {
+		e.override.Extensions = extensions
+	}
 	return e, nil
 }
 
+// K8sConfig returns the environment wide K8s configuration set in the override's top level x-k8s extension.
+func (e *Environment) K8sConfig() (config.EnvK8sConfig, error) {
+	return config.ParseEnvK8sConfigFromMap(e.override.Extensions, config.SkipValidation())
+}
+
+// AddPatch appends a patch to the environment's top level x-k8s patches, applied to its rendered objects.
+// Other environment wide configuration is left as is.
+func (e *Environment) AddPatch(patch config.Patch) error {
+	k8sConfig, err := e.K8sConfig()
+	if err != nil {
+		return err
+	}
+
+	k8sConfig.Patches = append(k8sConfig.Patches, patch)
+	if err := k8sConfig.Validate(); err != nil {
+		return err
+	}
+
+	bs, err := yaml.Marshal(k8sConfig.Patches)
+	if err != nil {
+		return err
+	}
+	var patches []interface{}
+	if err := yaml.Unmarshal(bs, &patches); err != nil {
+		return err
+	}
+
+	ext, _ := e.override.Extensions[config.K8SExtensionKey].(map[string]interface{})
+	if ext == nil {
+		ext = map[string]interface{}{}
+	}
+	ext["patches"] = patches
+
+	if e.override.Extensions == nil {
+		e.override.Extensions = map[string]interface{}{}
+	}
+	e.override.Extensions[config.K8SExtensionKey] = ext
+	return nil
+}
+
 func (e *Environment) mergeInto(p *ComposeProject) error {
 	return e.override.mergeInto(p)
 }
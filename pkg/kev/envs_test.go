@@ -18,6 +18,7 @@ package kev_test
 
 import (
 	"github.com/appvia/kev/pkg/kev"
+	"github.com/appvia/kev/pkg/kev/config"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
@@ -83,5 +84,42 @@ var _ = Describe("Environment", func() {
 				Expect(err).To(HaveOccurred())
 			})
 		})
+
+		Context("add patch", func() {
+			var patch config.Patch
+
+			BeforeEach(func() {
+				patch = config.Patch{
+					Target:         config.PatchTarget{Kind: "Deployment", Name: "db"},
+					StrategicMerge: "spec:\n  minReadySeconds: 10\n",
+				}
+			})
+
+			It("adds the patch to the environment's k8s config", func() {
+				Expect(env.AddPatch(patch)).To(Succeed())
+
+				k8sConfig, err := env.K8sConfig()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(k8sConfig.Patches).To(Equal([]config.Patch{patch}))
+			})
+
+			It("appends to existing patches", func() {
+				other := config.Patch{
+					Target:   config.PatchTarget{Kind: "Service", Name: "db"},
+					JSON6902: "- op: remove\n  path: /spec/clusterIP\n",
+				}
+				Expect(env.AddPatch(patch)).To(Succeed())
+				Expect(env.AddPatch(other)).To(Succeed())
+
+				k8sConfig, err := env.K8sConfig()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(k8sConfig.Patches).To(Equal([]config.Patch{patch, other}))
+			})
+
+			It("rejects invalid patches", func() {
+				patch.Target.Name = ""
+				Expect(env.AddPatch(patch)).To(MatchError("patches[0].target requires both a kind and a name"))
+			})
+		})
 	})
 })
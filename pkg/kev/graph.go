@@ -0,0 +1,273 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kev
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	composego "github.com/compose-spec/compose-go/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	// GraphFormatDOT outputs a project graph in Graphviz DOT format
+	GraphFormatDOT = "dot"
+	// GraphFormatMermaid outputs a project graph as a mermaid flowchart
+	GraphFormatMermaid = "mermaid"
+
+	graphService = "service"
+	graphVolume  = "volume"
+	graphConfig  = "config"
+	graphSecret  = "secret"
+)
+
+// defaultGraphName names graphs of compose sources not merged with an environment
+const defaultGraphName = "compose"
+
+var mermaidIDUnsafeChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// NewGraphRunner creates a graph runner instance
+func NewGraphRunner(workingDir string, opts ...Options) *GraphRunner {
+	runner := &GraphRunner{
+		Project: &Project{
+			WorkingDir:   workingDir,
+			config:       &runConfig{GraphFormat: GraphFormatDOT},
+			eventHandler: func(s RunnerEvent, r Runner) error { return nil },
+		},
+	}
+	runner.Init(opts...)
+	return runner
+}
+
+// Run builds a graph of the project's compose sources, merged with an environment when one is specified,
+// and renders it in the configured format. No UI output is produced so the graph can be piped to other tools.
+func (r *GraphRunner) Run() (string, error) {
+	if len(r.config.Envs) > 1 {
+		return "", errors.Errorf("a single environment can be graphed at a time, got: %v", r.config.Envs)
+	}
+
+	if !ManifestExistsForPath(filepath.Join(r.WorkingDir, ManifestFilename)) {
+		return "", errors.Errorf("Missing project manifest: %s", ManifestFilename)
+	}
+
+	manifest, err := LoadManifest(r.WorkingDir)
+	if err != nil {
+		return "", err
+	}
+	r.manifest = manifest
+
+	name := defaultGraphName
+	var p *ComposeProject
+
+	if len(r.config.Envs) == 0 {
+		p, err = manifest.SourcesToComposeProject()
+		if err != nil {
+			return "", err
+		}
+	} else {
+		name = r.config.Envs[0]
+
+		if _, err := manifest.CalculateSourcesBaseOverride(); err != nil {
+			return "", err
+		}
+
+		env, err := manifest.GetEnvironment(name)
+		if err != nil {
+			return "", err
+		}
+
+		p, err = manifest.MergeEnvIntoSources(env)
+		if err != nil {
+			return "", errors.Wrapf(err, "environment %s, details:\n", name)
+		}
+	}
+
+	return NewProjectGraph(name, p.Project).Render(r.config.GraphFormat)
+}
+
+// GraphNode is a project's service, volume, config or secret
+type GraphNode struct {
+	Kind string
+	Name string
+}
+
+// GraphEdge is a relationship between a service and another project node, i.e. a mount or a dependency
+type GraphEdge struct {
+	From  GraphNode
+	To    GraphNode
+	Label string
+}
+
+// ProjectGraph is a graph of a compose project's services, volumes, configs, secrets and their relationships
+type ProjectGraph struct {
+	Name  string
+	Nodes []GraphNode
+	Edges []GraphEdge
+}
+
+// NewProjectGraph builds a graph of the supplied compose project.
+// Nodes are sorted by kind and name, edges by their source service.
+func NewProjectGraph(name string, p *composego.Project) *ProjectGraph {
+	g := &ProjectGraph{Name: name}
+	seen := map[GraphNode]bool{}
+
+	addNode := func(n GraphNode) {
+		if !seen[n] {
+			seen[n] = true
+			g.Nodes = append(g.Nodes, n)
+		}
+	}
+
+	// declared top level elements are graphed even when unused by any service
+	for name := range p.Volumes {
+		addNode(GraphNode{Kind: graphVolume, Name: name})
+	}
+	for name := range p.Configs {
+		addNode(GraphNode{Kind: graphConfig, Name: name})
+	}
+	for name := range p.Secrets {
+		addNode(GraphNode{Kind: graphSecret, Name: name})
+	}
+
+	services := append(composego.Services{}, p.Services...)
+	sort.Slice(services, func(i, j int) bool { return services[i].Name < services[j].Name })
+
+	for _, svc := range services {
+		from := GraphNode{Kind: graphService, Name: svc.Name}
+		addNode(from)
+
+		deps := append([]string{}, svc.DependsOn...)
+		sort.Strings(deps)
+		for _, dep := range deps {
+			g.Edges = append(g.Edges, GraphEdge{From: from, To: GraphNode{Kind: graphService, Name: dep}, Label: "depends_on"})
+		}
+
+		for _, v := range svc.Volumes {
+			// bind mounts & anonymous volumes aren't part of the project
+			if v.Type != composego.VolumeTypeVolume || v.Source == "" {
+				continue
+			}
+			to := GraphNode{Kind: graphVolume, Name: v.Source}
+			addNode(to)
+			g.Edges = append(g.Edges, GraphEdge{From: from, To: to, Label: v.Target})
+		}
+
+		for _, c := range svc.Configs {
+			to := GraphNode{Kind: graphConfig, Name: c.Source}
+			addNode(to)
+			g.Edges = append(g.Edges, GraphEdge{From: from, To: to, Label: c.Target})
+		}
+
+		for _, s := range svc.Secrets {
+			to := GraphNode{Kind: graphSecret, Name: s.Source}
+			addNode(to)
+			g.Edges = append(g.Edges, GraphEdge{From: from, To: to, Label: s.Target})
+		}
+	}
+
+	order := map[string]int{graphService: 0, graphVolume: 1, graphConfig: 2, graphSecret: 3}
+	sort.SliceStable(g.Nodes, func(i, j int) bool {
+		if g.Nodes[i].Kind != g.Nodes[j].Kind {
+			return order[g.Nodes[i].Kind] < order[g.Nodes[j].Kind]
+		}
+		return g.Nodes[i].Name < g.Nodes[j].Name
+	})
+
+	return g
+}
+
+// Render renders the graph in the supplied format
+func (g *ProjectGraph) Render(format string) (string, error) {
+	switch format {
+	case GraphFormatDOT:
+		return g.DOT(), nil
+	case GraphFormatMermaid:
+		return g.Mermaid(), nil
+	default:
+		return "", errors.Errorf("unsupported graph format %q, supported formats: %s, %s", format, GraphFormatDOT, GraphFormatMermaid)
+	}
+}
+
+// DOT renders the graph in Graphviz DOT format
+func (g *ProjectGraph) DOT() string {
+	shapes := map[string]string{
+		graphService: "box",
+		graphVolume:  "cylinder",
+		graphConfig:  "note",
+		graphSecret:  "octagon",
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %q {\n", g.Name)
+	b.WriteString("  rankdir=LR;\n")
+	for _, n := range g.Nodes {
+		fmt.Fprintf(&b, "  %q [label=%q, shape=%s];\n", n.id(), n.label(), shapes[n.Kind])
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "  %q -> %q", e.From.id(), e.To.id())
+		if e.Label != "" {
+			fmt.Fprintf(&b, " [label=%q]", e.Label)
+		}
+		b.WriteString(";\n")
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Mermaid renders the graph as a mermaid flowchart
+func (g *ProjectGraph) Mermaid() string {
+	shapes := map[string]string{
+		graphService: `[%q]`,
+		graphVolume:  `[(%q)]`,
+		graphConfig:  `>%q]`,
+		graphSecret:  `{{%q}}`,
+	}
+
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+	for _, n := range g.Nodes {
+		fmt.Fprintf(&b, "  %s"+shapes[n.Kind]+"\n", n.mermaidID(), n.label())
+	}
+	for _, e := range g.Edges {
+		if e.Label != "" {
+			fmt.Fprintf(&b, "  %s -->|%q| %s\n", e.From.mermaidID(), e.Label, e.To.mermaidID())
+			continue
+		}
+		fmt.Fprintf(&b, "  %s --> %s\n", e.From.mermaidID(), e.To.mermaidID())
+	}
+	return b.String()
+}
+
+func (n GraphNode) id() string {
+	return n.Kind + "/" + n.Name
+}
+
+func (n GraphNode) label() string {
+	if n.Kind == graphService {
+		return n.Name
+	}
+	return fmt.Sprintf("%s: %s", n.Kind, n.Name)
+}
+
+// mermaidID returns the node's id restricted to the characters mermaid allows in node ids
+func (n GraphNode) mermaidID() string {
+	return mermaidIDUnsafeChars.ReplaceAllString(n.Kind+"_"+n.Name, "_")
+}
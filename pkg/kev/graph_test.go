@@ -0,0 +1,108 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kev_test
+
+import (
+	"github.com/appvia/kev/pkg/kev"
+	composego "github.com/compose-spec/compose-go/types"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Graph", func() {
+	var (
+		project *composego.Project
+		graph   *kev.ProjectGraph
+	)
+
+	BeforeEach(func() {
+		project = &composego.Project{
+			Services: composego.Services{
+				{
+					Name:      "web",
+					DependsOn: []string{"db"},
+					Configs:   []composego.ServiceConfigObjConfig{{Source: "nginx-conf", Target: "/etc/nginx/nginx.conf"}},
+					Volumes:   []composego.ServiceVolumeConfig{{Type: composego.VolumeTypeBind, Source: "./src", Target: "/src"}},
+				},
+				{
+					Name:    "db",
+					Secrets: []composego.ServiceSecretConfig{{Source: "db-password"}},
+					Volumes: []composego.ServiceVolumeConfig{{Type: composego.VolumeTypeVolume, Source: "db_data", Target: "/var/lib/mysql"}},
+				},
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		graph = kev.NewProjectGraph("dev", project)
+	})
+
+	It("contains sorted nodes for services and the volumes, configs & secrets they use", func() {
+		Expect(graph.Nodes).To(Equal([]kev.GraphNode{
+			{Kind: "service", Name: "db"},
+			{Kind: "service", Name: "web"},
+			{Kind: "volume", Name: "db_data"},
+			{Kind: "config", Name: "nginx-conf"},
+			{Kind: "secret", Name: "db-password"},
+		}))
+	})
+
+	It("contains edges for mounts and dependencies", func() {
+		Expect(graph.Edges).To(ConsistOf(
+			kev.GraphEdge{From: kev.GraphNode{Kind: "service", Name: "web"}, To: kev.GraphNode{Kind: "service", Name: "db"}, Label: "depends_on"},
+			kev.GraphEdge{From: kev.GraphNode{Kind: "service", Name: "web"}, To: kev.GraphNode{Kind: "config", Name: "nginx-conf"}, Label: "/etc/nginx/nginx.conf"},
+			kev.GraphEdge{From: kev.GraphNode{Kind: "service", Name: "db"}, To: kev.GraphNode{Kind: "volume", Name: "db_data"}, Label: "/var/lib/mysql"},
+			kev.GraphEdge{From: kev.GraphNode{Kind: "service", Name: "db"}, To: kev.GraphNode{Kind: "secret", Name: "db-password"}},
+		))
+	})
+
+	It("renders in DOT format", func() {
+		out, err := graph.Render(kev.GraphFormatDOT)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(HavePrefix(`digraph "dev" {`))
+		Expect(out).To(ContainSubstring(`"volume/db_data" [label="volume: db_data", shape=cylinder];`))
+		Expect(out).To(ContainSubstring(`"service/web" -> "service/db" [label="depends_on"];`))
+		Expect(out).To(ContainSubstring(`"service/db" -> "secret/db-password";`))
+	})
+
+	It("renders in mermaid format", func() {
+		out, err := graph.Render(kev.GraphFormatMermaid)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(HavePrefix("graph LR\n"))
+		Expect(out).To(ContainSubstring(`config_nginx_conf>"config: nginx-conf"]`))
+		Expect(out).To(ContainSubstring(`service_db -->|"/var/lib/mysql"| volume_db_data`))
+	})
+
+	It("rejects unsupported formats", func() {
+		_, err := graph.Render("svg")
+		Expect(err).To(MatchError(ContainSubstring(`unsupported graph format "svg"`)))
+	})
+
+	Describe("runner", func() {
+		It("graphs the compose sources merged with an environment", func() {
+			out, err := kev.NewGraphRunner("testdata/in-cluster-wordpress", kev.WithEnvs([]string{"dev"})).Run()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(HavePrefix(`digraph "dev" {`))
+			Expect(out).To(ContainSubstring(`"service/db" -> "volume/db_data" [label="/var/lib/mysql"];`))
+		})
+
+		It("fails when more than one environment is requested", func() {
+			_, err := kev.NewGraphRunner("testdata/in-cluster-wordpress", kev.WithEnvs([]string{"dev", "stage"})).Run()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -164,6 +164,8 @@ func (r *InitRunner) CreateManifestAndEnvironmentOverrides(sources *Sources) err
 
 	r.manifest = NewManifest(sources)
 	r.manifest.UI = r.UI
+	r.manifest.DefaultServiceType = r.config.DefaultServiceType
+	r.manifest.TraefikLabels = r.config.TraefikLabels
 
 	sg := r.UI.StepGroup()
 	defer sg.Done()
@@ -197,6 +199,11 @@ func (r *InitRunner) CreateOrUpdateSkaffoldManifest() (*SkaffoldManifest, error)
 	sg := r.UI.StepGroup()
 	defer sg.Done()
 
+	if err := r.config.SkaffoldBuild.Validate(); err != nil {
+		initStepError(r.UI, sg.Add(""), initStepConfig, err)
+		return nil, err
+	}
+
 	composeProject, err := r.manifest.SourcesToComposeProject()
 	if err != nil {
 		initStepError(r.UI, sg.Add(""), initStepParsingComposeConfig, err)
@@ -222,6 +229,15 @@ func (r *InitRunner) CreateOrUpdateSkaffoldManifest() (*SkaffoldManifest, error)
 		createStep.Success()
 	}
 
+	if err := skManifest.SetBuildOptions(r.config.SkaffoldBuild); err != nil {
+		initStepError(r.UI, sg.Add(""), initStepConfig, err)
+		return nil, err
+	}
+
+	if r.config.SkaffoldDefaultRepo != "" {
+		r.manifest.SkaffoldDefaultRepo = r.config.SkaffoldDefaultRepo
+	}
+
 	r.manifest.Skaffold = SkaffoldFileName
 
 	if err := r.eventHandler(PostCreateOrUpdateSkaffoldManifest, r); err != nil {
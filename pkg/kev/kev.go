@@ -16,6 +16,14 @@
 
 package kev
 
+import (
+	"fmt"
+	"io"
+
+	"github.com/appvia/kev/pkg/kev/log"
+	kmd "github.com/appvia/komando"
+)
+
 const (
 	// SandboxEnv is a default environment name
 	SandboxEnv = "dev"
@@ -53,12 +61,25 @@ func RenderProjectWithOptions(workingDir string, opts ...Options) error {
 	runner := NewRenderRunner(workingDir, opts...)
 	ui := runner.UI
 
+	// @step collect warnings emitted during reconcile & render to fail on them, if requested
+	var collector *log.WarningCollector
+	if runner.config.FailOnWarn {
+		collector = log.CollectWarnings()
+		defer collector.Stop()
+	}
+
 	results, err := runner.Run()
 	if err != nil {
 		printRenderProjectWithOptionsError(runner.AppName, ui)
 		return err
 	}
 
+	if collector != nil {
+		if warnings := collector.Warnings(); len(warnings) > 0 {
+			return printRenderProjectWithOptionsWarnings(runner.AppName, ui, warnings)
+		}
+	}
+
 	envs, err := runner.Manifest().GetEnvironments(runner.config.Envs)
 	if err != nil {
 		return err
@@ -67,6 +88,124 @@ func RenderProjectWithOptions(workingDir string, opts ...Options) error {
 	return printRenderProjectWithOptionsSuccess(runner, results, envs, runner.config.ManifestFormat)
 }
 
+// RenderProjectToMemory renders a kev project's environments as Kubernetes manifests without touching
+// the filesystem, using the provided options (if any). Manifests are keyed by environment name.
+// No UI output is produced unless a UI is provided via the options.
+func RenderProjectToMemory(workingDir string, opts ...Options) (map[string][]byte, error) {
+	runner := NewRenderRunner(workingDir, append([]Options{WithUI(kmd.NoOpUI())}, opts...)...)
+	return runner.RenderToMemory()
+}
+
+// LintProjectWithOptions renders a kev project in memory and reports Kubernetes best-practice
+// issues found in the output using the provided options (if any). It fails when any errors are found.
+func LintProjectWithOptions(workingDir string, opts ...Options) error {
+	runner := NewLintRunner(workingDir, opts...)
+
+	findings, err := runner.Run()
+	if err != nil {
+		printLintProjectWithOptionsError(runner.AppName, runner.UI)
+		return err
+	}
+
+	return printLintProjectWithOptionsSummary(runner, findings)
+}
+
+// ValidateProjectWithOptions reconciles and transforms a kev project's environments in memory and reports
+// all configuration errors found using the provided options (if any). It fails when any environment is invalid.
+func ValidateProjectWithOptions(workingDir string, opts ...Options) error {
+	runner := NewValidateRunner(workingDir, opts...)
+
+	results, err := runner.Run()
+	if err != nil {
+		printValidateProjectWithOptionsError(runner.AppName, runner.UI)
+		return err
+	}
+
+	return printValidateProjectWithOptionsSummary(runner, results)
+}
+
+// DiffProjectWithOptions renders a kev project's Kubernetes manifests in memory and reports their differences
+// with the manifests on disk using the provided options (if any). It fails when any differences are found.
+func DiffProjectWithOptions(workingDir string, opts ...Options) error {
+	runner := NewDiffRunner(workingDir, opts...)
+
+	diffs, err := runner.Run()
+	if err != nil {
+		printDiffProjectWithOptionsError(runner.AppName, runner.UI)
+		return err
+	}
+
+	return printDiffProjectWithOptionsSummary(runner, diffs)
+}
+
+// DoctorProjectWithOptions checks a kev project's working directory for common setup problems
+// and reports actionable findings using the provided options (if any). It fails when any errors are found.
+func DoctorProjectWithOptions(workingDir string, opts ...Options) error {
+	runner := NewDoctorRunner(workingDir, opts...)
+
+	findings, err := runner.Run()
+	if err != nil {
+		return err
+	}
+
+	return printDoctorProjectWithOptionsSummary(runner, findings)
+}
+
+// GraphProjectWithOptions writes a graph of a kev project's services, volumes, configs, secrets
+// and their relationships to the supplied writer using the provided options (if any).
+// The graph is built from the compose sources, merged with an environment when one is specified.
+func GraphProjectWithOptions(workingDir string, out io.Writer, opts ...Options) error {
+	runner := NewGraphRunner(workingDir, opts...)
+
+	graph, err := runner.Run()
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(out, graph)
+	return err
+}
+
+// PatchProjectWithOptions adds a patch to a kev project environment's rendered objects using the provided options (if any).
+// The patch is recorded in the environment's override file and applied whenever the environment is rendered.
+func PatchProjectWithOptions(workingDir string, out io.Writer, opts ...Options) error {
+	runner := NewPatchRunner(workingDir, opts...)
+
+	results, err := runner.Run()
+	if err != nil {
+		return err
+	}
+
+	if err := results.Write(); err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(out, "Added %s patch to environment %s: %s\n",
+		runner.config.Patch.Target, runner.config.Envs[0], results[0].FilePath)
+	return err
+}
+
+// SkaffoldProjectWithOptions creates or updates an initialised kev project's Skaffold config
+// with profiles for its deployment environments using the provided options (if any).
+func SkaffoldProjectWithOptions(workingDir string, opts ...Options) error {
+	runner := NewSkaffoldRunner(workingDir, opts...)
+	ui := runner.UI
+
+	results, err := runner.Run()
+	if err != nil {
+		printSkaffoldProjectWithOptionsError(runner.AppName, ui)
+		return err
+	}
+
+	if err := results.Write(); err != nil {
+		printSkaffoldProjectWithOptionsError(runner.AppName, ui)
+		return err
+	}
+
+	printSkaffoldProjectWithOptionsSuccess(runner)
+	return nil
+}
+
 // DevWithOptions runs a continuous development cycle detecting project updates and
 // re-rendering compose files to Kubernetes manifests.
 func DevWithOptions(workingDir string, opts ...Options) error {
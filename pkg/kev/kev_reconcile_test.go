@@ -139,6 +139,59 @@ var _ = Describe("Reconcile", func() {
 				})
 			})
 		})
+
+		Context("with configured conflict strategies", func() {
+			BeforeEach(func() {
+				workingDir = "testdata/reconcile-conflicts"
+				overrideFiles = []string{workingDir + "/docker-compose.env.dev.yaml"}
+			})
+
+			It("confirms the values pre reconciliation", func() {
+				s, err := override.GetService("db")
+				Expect(err).NotTo(HaveOccurred())
+
+				svcK8sConfig, err := config.ParseSvcK8sConfigFromMap(s.Extensions, config.SkipValidation())
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(svcK8sConfig.Workload.Replicas).To(Equal(5))
+				Expect(svcK8sConfig.Workload.Resource.MaxMemory).To(Equal("500Mi"))
+			})
+
+			It("replaces conflicting values for source-wins categories", func() {
+				s, err := env.GetService("db")
+				Expect(err).NotTo(HaveOccurred())
+
+				svcK8sConfig, err := config.ParseSvcK8sConfigFromMap(s.Extensions, config.SkipValidation())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(svcK8sConfig.Workload.Replicas).To(Equal(2))
+			})
+
+			It("keeps customised values the sources didn't change since the last reconcile", func() {
+				s, err := env.GetService("db")
+				Expect(err).NotTo(HaveOccurred())
+
+				svcK8sConfig, err := config.ParseSvcK8sConfigFromMap(s.Extensions, config.SkipValidation())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(svcK8sConfig.Workload.Resource.MaxMemory).To(Equal("500Mi"))
+				Expect(loggedMessages).NotTo(ContainSubstring("conflict on workload.resource.maxMemory"))
+			})
+
+			It("should create a change summary reporting which side won each conflict", func() {
+				Expect(loggedMessages).To(ContainSubstring("replicas conflict on workload.replicas in service db: source won (previous source: 1, source: 2, override: 5)"))
+			})
+
+			It("records the sources values the environment was reconciled against", func() {
+				Expect(manifest.ReconciledSources["dev"]["db"]).To(HaveKeyWithValue("workload.replicas", "2"))
+				Expect(manifest.ReconciledSources["dev"]["db"]).To(HaveKeyWithValue("workload.resource.maxMemory", "200Mi"))
+			})
+
+			It("doesn't report conflicts again once reconciled", func() {
+				hook.Reset()
+				_, err := manifest.ReconcileConfig()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(testutil.GetLoggedMsgs(hook)).NotTo(ContainSubstring("conflict on"))
+			})
+		})
 	})
 
 	Describe("Reconciling changes from sources", func() {
@@ -501,6 +554,38 @@ var _ = Describe("Reconcile", func() {
 			})
 		})
 
+		Context("when an environment uses deprecated label keys", func() {
+			BeforeEach(func() {
+				workingDir = "testdata/reconcile-label-migration"
+				overrideFiles = []string{workingDir + "/docker-compose.env.dev.yaml"}
+			})
+
+			It("confirms the deprecated labels pre reconciliation", func() {
+				s, _ := override.GetService("db")
+				Expect(s.Labels).To(HaveKey("kev.test.deprecated"))
+			})
+
+			It("migrates deprecated label keys to their current equivalents", func() {
+				s, err := env.GetService("db")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(s.Labels).NotTo(HaveKey("kev.test.deprecated"))
+				Expect(s.Labels).To(HaveKeyWithValue("kev.test.current", "true"))
+				Expect(s.Labels).To(HaveKeyWithValue("team", "data"))
+			})
+
+			It("keeps labels already using the current key", func() {
+				s, err := env.GetService("cache")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(s.Labels).NotTo(HaveKey("kev.test.deprecated"))
+				Expect(s.Labels).To(HaveKeyWithValue("kev.test.current", "annotation"))
+			})
+
+			It("should log the change summary using the debug level", func() {
+				Expect(loggedMessages).To(ContainSubstring("migrated label: kev.test.deprecated to kev.test.current in service db"))
+				Expect(loggedMessages).To(ContainSubstring("removed deprecated label: kev.test.deprecated from service cache"))
+			})
+		})
+
 		Context("when compose or override env var is not assigned a value", func() {
 			BeforeEach(func() {
 				workingDir = "testdata/reconcile-env-var-unassigned"
@@ -17,6 +17,10 @@
 package kev_test
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/appvia/kev/pkg/kev"
@@ -53,3 +57,57 @@ func TestCanLoadAManifest(t *testing.T) {
 		t.Fatalf("actual does not match expected:\n%s", diff)
 	}
 }
+
+func TestRenderProjectToMemory(t *testing.T) {
+	workingDir := "testdata/in-cluster-wordpress"
+	envFile := filepath.Join(workingDir, "docker-compose.env.dev.yaml")
+
+	before, err := ioutil.ReadFile(envFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manifests, err := kev.RenderProjectToMemory(workingDir, kev.WithEnvs([]string{"dev"}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if _, ok := manifests["dev"]; !ok || len(manifests) != 1 {
+		t.Fatalf("Expected manifests for the dev environment only, got: %v", manifests)
+	}
+
+	// objects go through the same transformation as rendered manifests, e.g. they're labelled with their environment
+	for _, s := range []string{"kind: List", "name: db", "name: wordpress", "kev.appvia.io/environment: dev"} {
+		if !strings.Contains(string(manifests["dev"]), s) {
+			t.Errorf("Expected manifests to contain %q", s)
+		}
+	}
+
+	after, err := ioutil.ReadFile(envFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(string(before), string(after)); diff != "" {
+		t.Errorf("Expected environment file to be left untouched, diff:\n%s", diff)
+	}
+
+	if _, err := os.Stat(filepath.Join(workingDir, "k8s")); !os.IsNotExist(err) {
+		t.Errorf("Expected no manifests to be written to disk")
+	}
+}
+
+func TestRenderProjectToMemoryAsJSON(t *testing.T) {
+	manifests, err := kev.RenderProjectToMemory("testdata/in-cluster-wordpress",
+		kev.WithEnvs([]string{"dev"}),
+		kev.WithOutput("json"),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	for _, s := range []string{`"kind": "List"`, `"name": "wordpress"`} {
+		if !strings.Contains(string(manifests["dev"]), s) {
+			t.Errorf("Expected manifests to contain %q", s)
+		}
+	}
+}
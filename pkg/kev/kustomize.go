@@ -0,0 +1,343 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kev
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/appvia/kev/pkg/kev/log"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// KustomizedFileName is the file holding the kustomized result of manifests rendered into a directory
+	KustomizedFileName = "kustomized.yaml"
+
+	// kustomizeRenderedSubDir is where rendered manifests are placed relative to a copy of the kustomize overlay
+	kustomizeRenderedSubDir = "kev-rendered"
+)
+
+// kustomizationFileNames are the file names kustomize recognises as a kustomization
+var kustomizationFileNames = []string{"kustomization.yaml", "kustomization.yml", "Kustomization"}
+
+// ApplyKustomizeOverlay runs each environment's rendered manifests through the configured kustomize overlay
+// as a final render step. Rendered manifests are replaced with the kustomized result.
+func (r *RenderRunner) ApplyKustomizeOverlay(results map[string]string) error {
+	overlay := r.config.KustomizeOverlay
+	if overlay == "" {
+		return nil
+	}
+
+	r.UI.Header(fmt.Sprintf("Applying kustomize overlay: %s...", overlay))
+	sg := r.UI.StepGroup()
+	defer sg.Done()
+
+	// @step environments rendered into a single bundle share their output
+	var outputs []string
+	seen := map[string]bool{}
+	for _, output := range results {
+		if !seen[output] {
+			outputs = append(outputs, output)
+			seen[output] = true
+		}
+	}
+	sort.Strings(outputs)
+
+	for _, output := range outputs {
+		step := sg.Add(fmt.Sprintf("Kustomizing: %s", output))
+		if err := kustomizeRendered(overlay, output); err != nil {
+			renderStepError(r.UI, step, renderStepKustomizeOverlay, err)
+			return err
+		}
+		step.Success()
+	}
+
+	return nil
+}
+
+// kustomizeRendered builds the rendered manifests at the output path, a file or a directory, with a kustomize overlay.
+// The overlay is copied to a temporary directory and the rendered manifests are added to its resources,
+// leaving the overlay itself untouched. Kustomizations referenced outside the overlay, e.g. `../base`,
+// are resolved relative to the original overlay directory.
+func kustomizeRendered(overlay, output string) error {
+	files, err := renderedManifests(output)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempDir("", "kev-kustomize-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	if err := copyDir(overlay, tmp); err != nil {
+		return errors.Wrapf(err, "cannot copy kustomize overlay %s", overlay)
+	}
+
+	// @step add rendered manifests to the overlay resources
+	renderedDir := filepath.Join(tmp, kustomizeRenderedSubDir)
+	if err := os.MkdirAll(renderedDir, os.ModePerm); err != nil {
+		return err
+	}
+
+	var resources []string
+	for _, f := range files {
+		if err := copyFile(f, filepath.Join(renderedDir, filepath.Base(f))); err != nil {
+			return err
+		}
+		resources = append(resources, path.Join(kustomizeRenderedSubDir, filepath.Base(f)))
+	}
+
+	if err := addKustomizationResources(tmp, overlay, resources); err != nil {
+		return errors.Wrapf(err, "kustomize overlay %s", overlay)
+	}
+
+	kustomized, err := kustomizeBuild(tmp)
+	if err != nil {
+		return err
+	}
+
+	// @step replace rendered manifests with the kustomized result
+	target := output
+	if info, err := os.Stat(output); err == nil && info.IsDir() {
+		for _, f := range files {
+			if err := os.Remove(f); err != nil {
+				return err
+			}
+		}
+		target = filepath.Join(output, KustomizedFileName)
+	}
+
+	log.Debugf("Writing kustomized manifests to %s", target)
+	return ioutil.WriteFile(target, kustomized, 0644)
+}
+
+// renderedManifests returns the manifest files rendered at the output path, a file or a directory
+func renderedManifests(output string) ([]string, error) {
+	info, err := os.Stat(output)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		return []string{output}, nil
+	}
+
+	entries, err := ioutil.ReadDir(output)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []string
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		switch filepath.Ext(e.Name()) {
+		case ".yaml", ".yml", ".json":
+			out = append(out, filepath.Join(output, e.Name()))
+		}
+	}
+	return out, nil
+}
+
+// addKustomizationResources appends resources to the kustomization found in the supplied directory, a copy of the
+// overlay directory. Kustomizations referenced outside the overlay are rewritten to their absolute path, as they'd
+// otherwise be resolved relative to the copy. Files referenced outside the overlay are rejected up front,
+// as kustomize only loads files within the kustomization directory.
+func addKustomizationResources(dir, overlay string, resources []string) error {
+	var file string
+	for _, name := range kustomizationFileNames {
+		if fileExists(filepath.Join(dir, name)) {
+			file = filepath.Join(dir, name)
+			break
+		}
+	}
+	if file == "" {
+		return errors.Errorf("no kustomization file found, expected one of: %s", strings.Join(kustomizationFileNames, ", "))
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	kustomization := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &kustomization); err != nil {
+		return errors.Wrapf(err, "cannot parse %s", filepath.Base(file))
+	}
+
+	if err := checkKustomizationFiles(kustomization); err != nil {
+		return err
+	}
+
+	absOverlay, err := filepath.Abs(overlay)
+	if err != nil {
+		return err
+	}
+
+	// @step point kustomizations referenced outside the overlay at their original location
+	for _, field := range []string{"resources", "bases", "components"} {
+		refs, _ := kustomization[field].([]interface{})
+		for i, ref := range refs {
+			if p, ok := ref.(string); ok && outsideDir(p) {
+				refs[i] = filepath.Join(absOverlay, p)
+			}
+		}
+	}
+
+	existing, _ := kustomization["resources"].([]interface{})
+	for _, r := range resources {
+		existing = append(existing, r)
+	}
+	kustomization["resources"] = existing
+
+	out, err := yaml.Marshal(kustomization)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, out, 0644)
+}
+
+// checkKustomizationFiles returns an error when a kustomization references patch or generator files
+// outside of its directory
+func checkKustomizationFiles(kustomization map[string]interface{}) error {
+	var files []string
+
+	// patchesStrategicMerge entries are either a file path or an inline patch
+	patches, _ := kustomization["patchesStrategicMerge"].([]interface{})
+	for _, patch := range patches {
+		if p, ok := patch.(string); ok && !strings.Contains(p, "\n") {
+			files = append(files, p)
+		}
+	}
+
+	for _, field := range []string{"patches", "patchesJson6902"} {
+		patches, _ := kustomization[field].([]interface{})
+		for _, patch := range patches {
+			if m, ok := patch.(map[string]interface{}); ok {
+				if p, ok := m["path"].(string); ok {
+					files = append(files, p)
+				}
+			}
+		}
+	}
+
+	// generator files entries are either a file path or a `key=path` pair
+	for _, field := range []string{"configMapGenerator", "secretGenerator"} {
+		generators, _ := kustomization[field].([]interface{})
+		for _, generator := range generators {
+			m, ok := generator.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if p, ok := m["env"].(string); ok {
+				files = append(files, p)
+			}
+			for _, key := range []string{"files", "envs"} {
+				entries, _ := m[key].([]interface{})
+				for _, entry := range entries {
+					if p, ok := entry.(string); ok {
+						files = append(files, p[strings.Index(p, "=")+1:])
+					}
+				}
+			}
+		}
+	}
+
+	for _, f := range files {
+		if outsideDir(f) {
+			return errors.Errorf("references %s outside of the overlay directory, "+
+				"kustomize only loads patch and generator files within the kustomization directory", f)
+		}
+	}
+	return nil
+}
+
+// outsideDir checks whether a relative path leads outside of the directory it's relative to
+func outsideDir(p string) bool {
+	p = filepath.Clean(p)
+	return p == ".." || strings.HasPrefix(p, ".."+string(filepath.Separator))
+}
+
+// kustomizeBuild builds the kustomization in the supplied directory using kustomize, or kubectl when
+// kustomize isn't installed, returning the kustomized manifests
+func kustomizeBuild(dir string) ([]byte, error) {
+	var cmd *exec.Cmd
+	switch {
+	case commandExists("kustomize"):
+		cmd = exec.Command("kustomize", "build", dir)
+	case commandExists("kubectl"):
+		cmd = exec.Command("kubectl", "kustomize", dir)
+	default:
+		return nil, errors.New("a kustomize overlay requires either kustomize or kubectl to be installed")
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Errorf("kustomize build failed: %s", strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// commandExists checks whether an executable can be found in PATH
+func commandExists(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
+
+// copyDir recursively copies the files of the src directory into the dst directory
+func copyDir(src, dst string) error {
+	return filepath.Walk(src, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		if info.IsDir() {
+			return os.MkdirAll(target, os.ModePerm)
+		}
+		return copyFile(p, target)
+	})
+}
+
+// copyFile copies the src file to dst
+func copyFile(src, dst string) error {
+	data, err := ioutil.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(dst, data, 0644)
+}
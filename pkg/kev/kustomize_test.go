@@ -0,0 +1,99 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kev_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/appvia/kev/pkg/kev"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v3"
+)
+
+var _ = Describe("Kustomize overlay", func() {
+	var (
+		dir     string
+		overlay string
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "kev-kustomize-test-")
+		Expect(err).NotTo(HaveOccurred())
+
+		overlay = filepath.Join("testdata", "overlays", "prod")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	writeKustomization := func(content string) {
+		Expect(ioutil.WriteFile(filepath.Join(dir, "kustomization.yaml"), []byte(content), 0644)).To(Succeed())
+	}
+
+	readKustomization := func() map[string][]string {
+		data, err := ioutil.ReadFile(filepath.Join(dir, "kustomization.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+
+		out := map[string][]string{}
+		Expect(yaml.Unmarshal(data, &out)).To(Succeed())
+		return out
+	}
+
+	It("appends the rendered manifests to the overlay resources", func() {
+		writeKustomization("resources:\n  - namespace.yaml\n")
+
+		Expect(kev.AddKustomizationResources(dir, overlay, []string{"kev-rendered/web.yaml"})).To(Succeed())
+		Expect(readKustomization()["resources"]).To(Equal([]string{"namespace.yaml", "kev-rendered/web.yaml"}))
+	})
+
+	It("resolves kustomizations referenced outside the overlay relative to the original overlay", func() {
+		writeKustomization("resources:\n  - ../base\n  - github.com/org/repo//deploy?ref=v1\ncomponents:\n  - ../../components/policies\n")
+
+		Expect(kev.AddKustomizationResources(dir, overlay, nil)).To(Succeed())
+
+		abs, err := filepath.Abs(overlay)
+		Expect(err).NotTo(HaveOccurred())
+
+		kustomization := readKustomization()
+		Expect(kustomization["resources"]).To(Equal([]string{
+			filepath.Join(filepath.Dir(abs), "base"),
+			"github.com/org/repo//deploy?ref=v1",
+		}))
+		Expect(kustomization["components"]).To(Equal([]string{
+			filepath.Join(filepath.Dir(filepath.Dir(abs)), "components", "policies"),
+		}))
+	})
+
+	It("rejects patch files outside the overlay", func() {
+		writeKustomization("patchesStrategicMerge:\n  - ../patches/security.yaml\n")
+
+		err := kev.AddKustomizationResources(dir, overlay, nil)
+		Expect(err).To(MatchError(ContainSubstring("references ../patches/security.yaml outside of the overlay directory")))
+	})
+
+	It("rejects generator files outside the overlay", func() {
+		writeKustomization("configMapGenerator:\n  - name: app\n    files:\n      - config.json=../shared/config.json\n")
+
+		err := kev.AddKustomizationResources(dir, overlay, nil)
+		Expect(err).To(MatchError(ContainSubstring("references ../shared/config.json outside of the overlay directory")))
+	})
+})
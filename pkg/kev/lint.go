@@ -0,0 +1,174 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kev
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/appvia/kev/pkg/kev/converter/kubernetes"
+	kmd "github.com/appvia/komando"
+	"github.com/pkg/errors"
+)
+
+// NewLintRunner creates a lint runner instance
+func NewLintRunner(workingDir string, opts ...Options) *LintRunner {
+	runner := &LintRunner{
+		Project: &Project{
+			WorkingDir:   workingDir,
+			eventHandler: func(s RunnerEvent, r Runner) error { return nil },
+		},
+	}
+	runner.Init(opts...)
+	return runner
+}
+
+// Run renders the project in memory and returns lint findings keyed by environment name
+func (r *LintRunner) Run() (map[string][]kubernetes.LintFinding, error) {
+	if r.LogVerbose() {
+		cancelFunc, pr, pw := r.pipeLogsToUI()
+		defer cancelFunc()
+		defer pw.Close()
+		defer pr.Close()
+	}
+
+	if err := validateLintChecks(r.config.LintErrorOn); err != nil {
+		return nil, err
+	}
+
+	if err := (&RenderRunner{Project: r.Project}).LoadProject(); err != nil {
+		return nil, err
+	}
+
+	r.UI.Header("Linting rendered output...")
+	sg := r.UI.StepGroup()
+	defer sg.Done()
+
+	convOpts, err := r.converterOptions()
+	if err != nil {
+		renderStepError(r.UI, sg.Add(""), renderStepRenderGeneral, err)
+		return nil, err
+	}
+
+	envObjects, err := r.manifest.RenderObjects(r.config.Envs, r.config.ExcludeServicesByEnv, convOpts...)
+	if err != nil {
+		renderStepError(r.UI, sg.Add(""), renderStepRenderGeneral, err)
+		return nil, err
+	}
+
+	var envs []string
+	for env := range envObjects {
+		envs = append(envs, env)
+	}
+	sort.Strings(envs)
+
+	findings := map[string][]kubernetes.LintFinding{}
+	for _, env := range envs {
+		step := sg.Add(fmt.Sprintf("Linting environment: %s", env))
+		envFindings := kubernetes.Lint(envObjects[env], r.config.LintErrorOn)
+		findings[env] = envFindings
+
+		errs, warnings := countLintFindings(envFindings)
+		switch {
+		case errs > 0:
+			step.Error(fmt.Sprintf("Found %d error(s) and %d warning(s) in environment: %s", errs, warnings, env))
+		case warnings > 0:
+			step.Warning(fmt.Sprintf("Found %d warning(s) in environment: %s", warnings, env))
+		default:
+			step.Success(fmt.Sprintf("No issues found in environment: %s", env))
+		}
+
+		for _, f := range envFindings {
+			r.UI.Output(
+				fmt.Sprintf("[%s] %s: %s (%s)", f.Severity, f.Object, f.Message, f.Check),
+				kmd.WithStyle(kmd.LogStyle),
+				kmd.WithIndentChar(kmd.LogIndentChar),
+				kmd.WithIndent(3),
+			)
+		}
+	}
+
+	return findings, nil
+}
+
+// validateLintChecks ensures all supplied lint checks are supported
+func validateLintChecks(checks []string) error {
+	for _, c := range checks {
+		if c == kubernetes.LintAll {
+			continue
+		}
+
+		supported := false
+		for _, s := range kubernetes.LintChecks {
+			if c == s {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			return errors.Errorf("unsupported lint check %q, supported checks: %s, %v", c, kubernetes.LintAll, kubernetes.LintChecks)
+		}
+	}
+	return nil
+}
+
+// countLintFindings returns the number of error and warning findings
+func countLintFindings(findings []kubernetes.LintFinding) (errs int, warnings int) {
+	for _, f := range findings {
+		if f.Severity == kubernetes.LintError {
+			errs++
+		} else {
+			warnings++
+		}
+	}
+	return errs, warnings
+}
+
+func printLintProjectWithOptionsError(appName string, ui kmd.UI) {
+	ui.Output("")
+	ui.Output("Project had errors during lint.\n"+
+		fmt.Sprintf("'%s' experienced some errors during project lint. The output\n", appName)+
+		"above should contain the failure messages. Please correct these errors and\n"+
+		fmt.Sprintf("run '%s lint' again.", appName),
+		kmd.WithErrorBoldStyle(),
+		kmd.WithIndentChar(kmd.ErrorIndentChar),
+	)
+}
+
+func printLintProjectWithOptionsSummary(r *LintRunner, findings map[string][]kubernetes.LintFinding) error {
+	var errs, warnings int
+	for _, envFindings := range findings {
+		e, w := countLintFindings(envFindings)
+		errs += e
+		warnings += w
+	}
+
+	ui := r.GetUI()
+	ui.Output("")
+
+	if errs > 0 {
+		ui.Output(
+			fmt.Sprintf("Lint found %d error(s) and %d warning(s).", errs, warnings),
+			kmd.WithErrorBoldStyle(),
+			kmd.WithIndentChar(kmd.ErrorIndentChar),
+		)
+		return errors.Errorf("lint found %d error(s)", errs)
+	}
+
+	ui.Output(fmt.Sprintf("Lint passed with %d warning(s).", warnings), kmd.WithStyle(kmd.SuccessBoldStyle))
+	return nil
+}
@@ -0,0 +1,77 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package log
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// WarningCollector collects the warnings logged while it's registered with the logger,
+// e.g. to fail a command once it's done when any warnings were emitted.
+type WarningCollector struct {
+	mu       sync.Mutex
+	warnings []string
+}
+
+// CollectWarnings registers a new warning collector with the logger.
+// Warnings are collected until the collector is stopped.
+func CollectWarnings() *WarningCollector {
+	c := &WarningCollector{}
+	logger.AddHook(c)
+	return c
+}
+
+// Levels returns the log levels the collector collects entries of
+func (c *WarningCollector) Levels() []logrus.Level {
+	return []logrus.Level{logrus.WarnLevel}
+}
+
+// Fire collects a logged warning, prefixed with the project service it concerns, if any
+func (c *WarningCollector) Fire(entry *logrus.Entry) error {
+	msg := entry.Message
+	if svc, ok := entry.Data["project-service"]; ok {
+		msg = fmt.Sprintf("%v: %s", svc, msg)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.warnings = append(c.warnings, msg)
+	return nil
+}
+
+// Warnings returns the warnings collected so far in the order they were logged
+func (c *WarningCollector) Warnings() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string{}, c.warnings...)
+}
+
+// Stop unregisters the collector from the logger. Warnings collected so far are kept.
+func (c *WarningCollector) Stop() {
+	hooks := make(logrus.LevelHooks)
+	for level, levelHooks := range logger.Hooks {
+		for _, h := range levelHooks {
+			if h != c {
+				hooks[level] = append(hooks[level], h)
+			}
+		}
+	}
+	logger.ReplaceHooks(hooks)
+}
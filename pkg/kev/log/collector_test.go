@@ -0,0 +1,62 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package log_test
+
+import (
+	"io/ioutil"
+
+	"github.com/appvia/kev/pkg/kev/log"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WarningCollector", func() {
+	var collector *log.WarningCollector
+
+	BeforeEach(func() {
+		log.SetOutput(ioutil.Discard)
+		collector = log.CollectWarnings()
+	})
+
+	AfterEach(func() {
+		collector.Stop()
+	})
+
+	It("collects logged warnings in order", func() {
+		log.Warn("first")
+		log.Warnf("second %d", 2)
+		Expect(collector.Warnings()).To(Equal([]string{"first", "second 2"}))
+	})
+
+	It("prefixes warnings with the project service they concern", func() {
+		log.WarnWithFields(log.Fields{"project-service": "web"}, "risky config")
+		Expect(collector.Warnings()).To(Equal([]string{"web: risky config"}))
+	})
+
+	It("ignores other log levels", func() {
+		log.Info("info")
+		log.Error("error")
+		Expect(collector.Warnings()).To(BeEmpty())
+	})
+
+	It("stops collecting once stopped", func() {
+		log.Warn("collected")
+		collector.Stop()
+		log.Warn("not collected")
+		Expect(collector.Warnings()).To(Equal([]string{"collected"}))
+	})
+})
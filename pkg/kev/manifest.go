@@ -22,16 +22,19 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 
 	"github.com/appvia/kev/pkg/kev/config"
 	"github.com/appvia/kev/pkg/kev/converter"
+	"github.com/appvia/kev/pkg/kev/converter/kubernetes"
 	"github.com/appvia/kev/pkg/kev/log"
 	kmd "github.com/appvia/komando"
 	composego "github.com/compose-spec/compose-go/types"
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
 	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // Infix component of generated environment override filenames
@@ -116,6 +119,15 @@ func (m *Manifest) GetEnvironmentsNames() []string {
 
 // CalculateSourcesBaseOverride extracts the base override from the manifest's docker-compose source files.
 func (m *Manifest) CalculateSourcesBaseOverride(opts ...BaseOverrideOpts) (*Manifest, error) {
+	if m.DefaultServiceType != "" {
+		svcType, err := config.DefaultServiceTypeFromValue(m.DefaultServiceType)
+		if err != nil {
+			return nil, err
+		}
+		m.Sources.defaultServiceType = svcType
+	}
+	m.Sources.traefikLabels = m.TraefikLabels
+
 	if err := m.Sources.CalculateBaseOverride(opts...); err != nil {
 		return nil, err
 	}
@@ -181,6 +193,14 @@ func (m *Manifest) ReconcileConfig(envs ...string) (*Manifest, error) {
 		return nil, err
 	}
 
+	strategies, err := conflictStrategiesFromValues(m.ReconcileConflicts)
+	if err != nil {
+		sg := m.UI.StepGroup()
+		defer sg.Done()
+		renderStepError(m.UI, sg.Add(""), renderStepReconcileDetect, err)
+		return nil, err
+	}
+
 	sourcesOverride := m.getSourcesOverride()
 	filteredEnvs, err := m.GetEnvironments(envs)
 	if err != nil {
@@ -190,6 +210,7 @@ func (m *Manifest) ReconcileConfig(envs ...string) (*Manifest, error) {
 		return nil, err
 	}
 
+	summary := reconcileSummary{}
 	for _, e := range filteredEnvs {
 		if err := validateEnvExtensions(e, sourcesOverride); err != nil {
 			sg := m.UI.StepGroup()
@@ -202,14 +223,24 @@ func (m *Manifest) ReconcileConfig(envs ...string) (*Manifest, error) {
 
 		m.UI.Output(fmt.Sprintf("%s: %s", e.Name, e.File))
 
-		if err := sourcesOverride.diffAndPatch(e.override); err != nil {
+		reconciled, err := sourcesOverride.diffAndPatch(e.override, strategies, m.ReconciledSources[e.Name])
+		if err != nil {
 			sg := m.UI.StepGroup()
 			renderStepError(m.UI, sg.Add(""), renderStepReconcileApply, err)
 			sg.Done()
 			return nil, err
 		}
+		if previous := m.ReconciledSources[e.Name]; (len(previous) > 0 || len(reconciled) > 0) && !reflect.DeepEqual(previous, reconciled) {
+			if m.ReconciledSources == nil {
+				m.ReconciledSources = map[string]sourceValues{}
+			}
+			m.ReconciledSources[e.Name] = reconciled
+			m.reconciledSourcesChanged = true
+		}
+		summary.add(e.Name, e.override.applied)
 	}
 
+	summary.output(m.UI)
 	return m, nil
 }
 
@@ -270,17 +301,74 @@ func validateEnvExtensions(e *Environment, base *composeOverride) error {
 
 // MergeEnvIntoSources merges an environment into a parsed instance of the tracked docker-compose sources.
 // It returns the merged ComposeProject.
+// Sources are interpolated with the environment specific env file variables, if any.
+// Values loaded from a values file, if any, are merged last.
+// Only services enabled by the environment compose profiles, if any, are kept.
+// Compose services annotations are merged into the services workload annotations.
 func (m *Manifest) MergeEnvIntoSources(e *Environment) (*ComposeProject, error) {
-	p, err := m.SourcesToComposeProject()
+	p, err := newEnvComposeProject(m.GetSourcesFiles(), e.EnvFile(), m.interpolationValues)
 	if err != nil {
 		return nil, err
 	}
+	if err := m.warnUnresolvedVariables(e); err != nil {
+		return nil, err
+	}
 	if err := e.mergeInto(p); err != nil {
 		return nil, err
 	}
+	if err := mergeValues(p, m.values); err != nil {
+		return nil, err
+	}
+	if err := filterServicesByProfiles(p, m.GetSourcesFiles(), e); err != nil {
+		return nil, err
+	}
+
+	options, err := newProjectOptions(m.GetSourcesFiles(), e.EnvFile(), m.interpolationValues)
+	if err != nil {
+		return nil, err
+	}
+	if err := mergeServicesAnnotations(p, m.GetSourcesFiles(), options.Environment); err != nil {
+		return nil, err
+	}
 	return p, nil
 }
 
+// warnUnresolvedVariables warns about variables referenced by the sources and an environment's override file without
+// a default value, that aren't set in the environment they're interpolated with, as they will be interpolated with
+// a blank string. Each variable is only warned about once per file.
+func (m *Manifest) warnUnresolvedVariables(e *Environment) error {
+	paths := append(append([]string{}, m.GetSourcesFiles()...), e.File)
+
+	options, err := newProjectOptions(paths, e.EnvFile(), m.interpolationValues)
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		names, err := unresolvedVariables(path, options.Environment)
+		if err != nil {
+			return err
+		}
+
+		for _, name := range names {
+			key := path + ":" + name
+			if m.warnedVariables[key] {
+				continue
+			}
+			if m.warnedVariables == nil {
+				m.warnedVariables = map[string]bool{}
+			}
+			m.warnedVariables[key] = true
+
+			log.WarnfWithFields(log.Fields{
+				"file":     path,
+				"variable": name,
+			}, "Variable %s isn't set in the environment nor in env files. Interpolating with a blank string", name)
+		}
+	}
+	return nil
+}
+
 // RenderWithConvertor renders K8s manifests with specific converter
 func (m *Manifest) RenderWithConvertor(c converter.Converter, outputDir string, singleFile bool, envs []string, excluded map[string][]string) (map[string]string, error) {
 	errSg := m.UI.StepGroup()
@@ -345,6 +433,40 @@ func (m *Manifest) RenderWithConvertor(c converter.Converter, outputDir string,
 	return outputPaths, nil
 }
 
+// RenderObjects renders Kubernetes objects in memory for the specified environments (all by default)
+// without writing them to disk. Objects are keyed by their environment name.
+// Objects go through the same transformation as rendered manifests, configured with the converter options.
+func (m *Manifest) RenderObjects(envs []string, excluded map[string][]string, convOpts ...kubernetes.Option) (map[string][]runtime.Object, error) {
+	if _, err := m.CalculateSourcesBaseOverride(); err != nil {
+		return nil, err
+	}
+
+	filteredEnvs, err := m.GetEnvironments(envs)
+	if err != nil {
+		return nil, err
+	}
+
+	k := kubernetes.New(convOpts...)
+	sourcesFiles := m.GetSourcesFiles()
+
+	out := map[string][]runtime.Object{}
+	for _, env := range filteredEnvs {
+		p, err := m.MergeEnvIntoSources(env)
+		if err != nil {
+			return nil, errors.Wrapf(err, "environment %s, details:\n", env.Name)
+		}
+
+		files := append(append([]string{}, sourcesFiles...), env.File)
+		objects, err := k.TransformEnv(env.Name, p.Project, files, excluded[env.Name])
+		if err != nil {
+			return nil, errors.Wrapf(err, "environment %s, details:\n", env.Name)
+		}
+		out[env.Name] = objects
+	}
+
+	return out, nil
+}
+
 // GetSourcesFiles gets the sources tracked docker-compose files.
 func (m *Manifest) GetSourcesFiles() []string {
 	return m.Sources.Files
@@ -19,6 +19,7 @@ package kev_test
 import (
 	"github.com/appvia/kev/pkg/kev"
 	"github.com/appvia/kev/pkg/kev/config"
+	composego "github.com/compose-spec/compose-go/types"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
@@ -117,6 +118,122 @@ var _ = Describe("Manifest", func() {
 				Expect(mergeErr).NotTo(HaveOccurred())
 			})
 		})
+
+		Context("with environment specific env files", func() {
+			var manifest *kev.Manifest
+
+			BeforeEach(func() {
+				var err error
+				manifest, err = kev.LoadManifest("testdata/env-files")
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			mergedService := func(envName string) composego.ServiceConfig {
+				env, err := manifest.GetEnvironment(envName)
+				Expect(err).NotTo(HaveOccurred())
+
+				merged, err := manifest.MergeEnvIntoSources(env)
+				Expect(err).NotTo(HaveOccurred())
+
+				svc, err := merged.GetService("web")
+				Expect(err).NotTo(HaveOccurred())
+				return svc
+			}
+
+			It("interpolates variables from the shared env file for environments without one", func() {
+				Expect(mergedService("dev").Image).To(Equal("nginx:1.20"))
+			})
+
+			It("interpolates variables from the environment env file in preference to the shared one", func() {
+				svc := mergedService("prod")
+				Expect(svc.Image).To(Equal("nginx:1.21"))
+
+				greeting := "hello"
+				Expect(svc.Environment["GREETING"]).To(Equal(&greeting))
+			})
+		})
+
+		Context("with compose profiles", func() {
+			var manifest *kev.Manifest
+
+			BeforeEach(func() {
+				var err error
+				manifest, err = kev.LoadManifest("testdata/profiles")
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			mergedServiceNames := func(envName string) []string {
+				env, err := manifest.GetEnvironment(envName)
+				Expect(err).NotTo(HaveOccurred())
+
+				merged, err := manifest.MergeEnvIntoSources(env)
+				Expect(err).NotTo(HaveOccurred())
+				return merged.ServiceNames()
+			}
+
+			It("keeps services enabled by the environment profiles and services without profiles", func() {
+				Expect(mergedServiceNames("web")).To(ConsistOf("db", "web"))
+				Expect(mergedServiceNames("worker")).To(ConsistOf("db", "worker"))
+			})
+
+			It("keeps all services when the environment enables no profiles", func() {
+				Expect(mergedServiceNames("all")).To(ConsistOf("db", "web", "worker"))
+			})
+
+			It("lists the compose profiles and the services they enable", func() {
+				profiles, err := manifest.ComposeProfiles()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(profiles).To(Equal(map[string][]string{
+					"web":    {"web"},
+					"worker": {"worker"},
+					"batch":  {"worker"},
+				}))
+			})
+		})
+
+		Context("with compose services annotations", func() {
+			var merged *kev.ComposeProject
+
+			BeforeEach(func() {
+				manifest, err := kev.LoadManifest("testdata/annotations")
+				Expect(err).NotTo(HaveOccurred())
+
+				env, err := manifest.GetEnvironment("dev")
+				Expect(err).NotTo(HaveOccurred())
+
+				merged, err = manifest.MergeEnvIntoSources(env)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			workloadAnnotations := func(name string) map[string]string {
+				svc, err := merged.GetService(name)
+				Expect(err).NotTo(HaveOccurred())
+
+				svcK8sConfig, err := config.ParseSvcK8sConfigFromMap(svc.Extensions, config.SkipValidation())
+				Expect(err).NotTo(HaveOccurred())
+				return svcK8sConfig.Workload.Annotations
+			}
+
+			It("maps the annotations onto the workload annotations", func() {
+				Expect(workloadAnnotations("web")).To(HaveKeyWithValue("prometheus.io/scrape", "true"))
+				Expect(workloadAnnotations("worker")).To(Equal(map[string]string{
+					"team":                    "payments",
+					"sidecar.istio.io/inject": "false",
+				}))
+			})
+
+			It("interpolates the annotations values", func() {
+				Expect(workloadAnnotations("web")).To(HaveKeyWithValue("team", "platform"))
+			})
+
+			It("gives precedence to the x-k8s workload annotations", func() {
+				Expect(workloadAnnotations("web")).To(HaveKeyWithValue("prometheus.io/port", "8080"))
+			})
+
+			It("doesn't override reserved kev annotations", func() {
+				Expect(workloadAnnotations("web")).NotTo(HaveKey("kev.appvia.io/source-file"))
+			})
+		})
 	})
 
 	Describe("GetEnvironmentFileNameTemplate", func() {
@@ -49,6 +49,9 @@ func (o *composeOverride) getVolume(name string) (VolumeConfig, error) {
 // diffAndPatch detects and patches all changes between a destination override and the current override.
 // A change is either a create, update or delete event.
 // A change targets an override's version, services or volumes and its properties will depend on the actual target.
+// Changes applied to the destination override are recorded for the reconcile summary.
+// It returns the sources values of conflict prone attributes the destination got reconciled against,
+// which conflicts are detected from on the next reconcile.
 // Example: here's a Change that creates a new service:
 // {
 //    Type: "create",   //string
@@ -60,30 +63,40 @@ func (o *composeOverride) getVolume(name string) (VolumeConfig, error) {
 // - A changeset will ONLY REMOVE an env var if it is removed from a project's docker-compose env vars.
 // - A changeset will NOT update or create env vars in an environment specific docker compose override file.
 // - To create useful diffs the project's base docker-compose env vars will be taken into account.
-func (o *composeOverride) diffAndPatch(dst *composeOverride) error {
+func (o *composeOverride) diffAndPatch(dst *composeOverride, strategies conflictStrategies, previous sourceValues) (sourceValues, error) {
+	dst.applied = nil
 	o.detectAndPatchVersionUpdate(dst)
 
 	if err := o.detectAndPatchServicesCreate(dst); err != nil {
-		return err
+		return nil, err
 	}
 
 	if err := o.detectAndPatchServicesDelete(dst); err != nil {
-		return err
+		return nil, err
 	}
 
 	if err := o.detectAndPatchServicesEnvironmentDelete(dst); err != nil {
-		return err
+		return nil, err
+	}
+
+	if err := o.detectAndPatchDeprecatedLabels(dst); err != nil {
+		return nil, err
+	}
+
+	current, err := o.detectAndPatchServicesConflicts(dst, strategies, previous)
+	if err != nil {
+		return nil, err
 	}
 
 	if err := o.detectAndPatchVolumesCreate(dst); err != nil {
-		return err
+		return nil, err
 	}
 
 	if err := o.detectAndPatchVolumesDelete(dst); err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return current, nil
 }
 
 func (o *composeOverride) detectAndPatchVersionUpdate(dst *composeOverride) {
@@ -221,6 +234,43 @@ func (o *composeOverride) detectAndPatchServicesEnvironmentDelete(dst *composeOv
 	return nil
 }
 
+func (o *composeOverride) detectAndPatchDeprecatedLabels(dst *composeOverride) error {
+	sg := o.UI.StepGroup()
+	defer sg.Done()
+	step := sg.Add("Detecting deprecated labels")
+
+	cset := changeset{}
+	for index, dstSvc := range dst.Services {
+		for _, key := range dstSvc.deprecatedLabels() {
+			cset.services = append(cset.services, change{
+				Type:   UPDATE,
+				Index:  index,
+				Parent: "labels",
+				Target: key,
+				Value:  deprecatedLabelKeys[key],
+			})
+		}
+	}
+
+	if cset.HasNoPatches() {
+		step.Success("No deprecated labels detected")
+		return nil
+	}
+
+	msgs, err := cset.applyServicesPatchesIfAny(dst)
+	if err != nil {
+		return err
+	}
+
+	step.Success("Migrated deprecated labels")
+	for _, msg := range msgs {
+		o.UI.Output(msg, kmd.WithStyle(kmd.LogStyle),
+			kmd.WithIndentChar(kmd.LogIndentChar),
+			kmd.WithIndent(3))
+	}
+	return nil
+}
+
 func (o *composeOverride) detectAndPatchVolumesCreate(dst *composeOverride) error {
 	sg := o.UI.StepGroup()
 	defer sg.Done()
@@ -320,6 +370,12 @@ func (o *composeOverride) mergeServicesInto(p *ComposeProject) error {
 		if err := mergo.Merge(&base.Environment, &override.Environment, mergo.WithOverride); err != nil {
 			return errors.Wrapf(err, "cannot merge env vars for service %s", override.Name)
 		}
+		if len(override.Labels) > 0 && base.Labels == nil {
+			base.Labels = composego.Labels{}
+		}
+		for key, value := range override.Labels {
+			base.Labels[key] = value
+		}
 		overridden = append(overridden, base)
 	}
 	p.Services = overridden
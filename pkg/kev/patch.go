@@ -0,0 +1,64 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kev
+
+import (
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// NewPatchRunner creates a patch runner instance
+func NewPatchRunner(workingDir string, opts ...Options) *PatchRunner {
+	runner := &PatchRunner{
+		Project: &Project{
+			WorkingDir:   workingDir,
+			eventHandler: func(s RunnerEvent, r Runner) error { return nil },
+		},
+	}
+	runner.Init(opts...)
+	return runner
+}
+
+// Run adds the configured patch to the environment's x-k8s patches.
+// It returns the environment's override file, which is yet to be written out.
+func (r *PatchRunner) Run() (WritableResults, error) {
+	if len(r.config.Envs) != 1 {
+		return nil, errors.Errorf("a single environment can be patched at a time, got: %v", r.config.Envs)
+	}
+
+	if !ManifestExistsForPath(filepath.Join(r.WorkingDir, ManifestFilename)) {
+		return nil, errors.Errorf("Missing project manifest: %s", ManifestFilename)
+	}
+
+	manifest, err := LoadManifest(r.WorkingDir)
+	if err != nil {
+		return nil, err
+	}
+	r.manifest = manifest
+
+	env, err := manifest.GetEnvironment(r.config.Envs[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if err := env.AddPatch(r.config.Patch); err != nil {
+		return nil, errors.Wrapf(err, "environment %s", env.Name)
+	}
+
+	return Environments{env}.toWritableResults(), nil
+}
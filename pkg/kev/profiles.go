@@ -0,0 +1,119 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kev
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/appvia/kev/pkg/kev/log"
+	composego "github.com/compose-spec/compose-go/types"
+	"github.com/pkg/errors"
+)
+
+// filterServicesByProfiles keeps the project services enabled by the environment's compose profiles.
+// Services without profiles are always kept. All services are kept when the environment enables no profiles.
+func filterServicesByProfiles(p *ComposeProject, files []string, e *Environment) error {
+	envK8sConfig, err := e.K8sConfig()
+	if err != nil {
+		return err
+	}
+
+	if len(envK8sConfig.Profiles) == 0 {
+		return nil
+	}
+
+	profiles, err := getComposeServicesProfiles(files)
+	if err != nil {
+		return errors.Wrap(err, "cannot read services profiles")
+	}
+
+	var enabled composego.Services
+	for _, svc := range p.Services {
+		if !profilesEnabled(profiles[svc.Name], envK8sConfig.Profiles) {
+			log.DebugfWithFields(log.Fields{
+				"env":     e.Name,
+				"service": svc.Name,
+			}, "Service not enabled by environment profiles %v", envK8sConfig.Profiles)
+			continue
+		}
+		enabled = append(enabled, svc)
+	}
+	p.Services = enabled
+
+	return nil
+}
+
+// profilesEnabled returns true when a service has no profiles or one of its profiles is enabled
+func profilesEnabled(svcProfiles, enabled []string) bool {
+	if len(svcProfiles) == 0 {
+		return true
+	}
+
+	for _, profile := range svcProfiles {
+		if contains(enabled, profile) {
+			return true
+		}
+	}
+	return false
+}
+
+// ComposeProfiles returns the compose profiles declared by the project's compose sources services,
+// keyed by profile name, along with the sorted names of the services each profile enables.
+func (m *Manifest) ComposeProfiles() (map[string][]string, error) {
+	servicesProfiles, err := getComposeServicesProfiles(m.Sources.Files)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot read services profiles")
+	}
+
+	out := map[string][]string{}
+	for svc, profiles := range servicesProfiles {
+		for _, profile := range profiles {
+			out[profile] = append(out[profile], svc)
+		}
+	}
+	for profile := range out {
+		sort.Strings(out[profile])
+	}
+	return out, nil
+}
+
+// validateEnvProfiles checks the compose profiles enabled by an environment are declared by the compose sources
+func validateEnvProfiles(e *Environment, profiles map[string][]string) error {
+	envK8sConfig, err := e.K8sConfig()
+	if err != nil {
+		return err
+	}
+
+	for _, profile := range envK8sConfig.Profiles {
+		if _, ok := profiles[profile]; ok {
+			continue
+		}
+
+		known := make([]string, 0, len(profiles))
+		for name := range profiles {
+			known = append(known, name)
+		}
+		sort.Strings(known)
+
+		if len(known) == 0 {
+			return errors.Errorf("environment %s enables compose profile %s but no compose services declare profiles", e.Name, profile)
+		}
+		return errors.Errorf("environment %s enables unknown compose profile %s, known profiles: %s", e.Name, profile, strings.Join(known, ", "))
+	}
+	return nil
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/appvia/kev/pkg/kev/config"
 	"github.com/appvia/kev/pkg/kev/log"
@@ -226,6 +227,46 @@ func WithSkaffold(c bool) Options {
 	}
 }
 
+// WithDefaultServiceType configures a project's run config with the service type
+// inferred for compose services publishing ports.
+func WithDefaultServiceType(c string) Options {
+	return func(project *Project, cfg *runConfig) {
+		cfg.DefaultServiceType = c
+	}
+}
+
+// WithTraefikLabels configures a project's run config to infer compose services
+// expose configuration from their traefik labels
+func WithTraefikLabels(c bool) Options {
+	return func(project *Project, cfg *runConfig) {
+		cfg.TraefikLabels = c
+	}
+}
+
+// WithSkaffoldBuildStrategy configures a project's run config with the build strategy
+// of generated Skaffold manifests, one of: local, kaniko, buildpacks.
+func WithSkaffoldBuildStrategy(c string) Options {
+	return func(project *Project, cfg *runConfig) {
+		cfg.SkaffoldBuild.Strategy = c
+	}
+}
+
+// WithSkaffoldTagPolicy configures a project's run config with the image tagging policy
+// of generated Skaffold manifests, one of: git, sha256, datetime.
+func WithSkaffoldTagPolicy(c string) Options {
+	return func(project *Project, cfg *runConfig) {
+		cfg.SkaffoldBuild.TagPolicy = c
+	}
+}
+
+// WithSkaffoldDefaultRepo configures a project's run config with the image registry
+// Skaffold pushes built images to.
+func WithSkaffoldDefaultRepo(c string) Options {
+	return func(project *Project, cfg *runConfig) {
+		cfg.SkaffoldDefaultRepo = c
+	}
+}
+
 // WithManifestFormat configures a project's run config with a K8s manifest format for rendering.
 func WithManifestFormat(c string) Options {
 	return func(project *Project, cfg *runConfig) {
@@ -241,6 +282,14 @@ func WithManifestsAsSingleFile(c bool) Options {
 	}
 }
 
+// WithAllEnvsSingleFile configures a project's run config with whether all environments should be rendered
+// into a single multi-document manifests bundle.
+func WithAllEnvsSingleFile(c bool) Options {
+	return func(project *Project, cfg *runConfig) {
+		cfg.AllEnvsSingleFile = c
+	}
+}
+
 // WithOutputDir configures a project's run config with a location to render a project's K8s manifests.
 func WithOutputDir(c string) Options {
 	return func(project *Project, cfg *runConfig) {
@@ -248,6 +297,158 @@ func WithOutputDir(c string) Options {
 	}
 }
 
+// WithAnnotateSourceFile configures a project's run config with whether rendered objects should be annotated
+// with the compose source file their service originated from.
+func WithAnnotateSourceFile(c bool) Options {
+	return func(project *Project, cfg *runConfig) {
+		cfg.AnnotateSourceFile = c
+	}
+}
+
+// WithResourceQuota configures a project's run config to render a resource quota
+// and limit range for each environment namespace
+func WithResourceQuota(c bool) Options {
+	return func(project *Project, cfg *runConfig) {
+		cfg.ResourceQuota = c
+	}
+}
+
+// WithQuotaHeadroom configures a project's run config with the factor applied to
+// environment resources when rendering a resource quota
+func WithQuotaHeadroom(c float64) Options {
+	return func(project *Project, cfg *runConfig) {
+		cfg.QuotaHeadroom = c
+	}
+}
+
+// WithExposeAPI configures a project's run config with the API used to expose services
+func WithExposeAPI(c string) Options {
+	return func(project *Project, cfg *runConfig) {
+		cfg.ExposeAPI = c
+	}
+}
+
+// WithOutput configures a project's run config with the encoding of rendered Kubernetes manifests
+func WithOutput(c string) Options {
+	return func(project *Project, cfg *runConfig) {
+		cfg.Output = c
+	}
+}
+
+// WithKubeVersion configures a project's run config with the target Kubernetes version
+// selecting rendered objects apiVersions
+func WithKubeVersion(c string) Options {
+	return func(project *Project, cfg *runConfig) {
+		cfg.KubeVersion = c
+	}
+}
+
+// WithFailOnWarn configures a project's run config to fail rendering when any warnings were emitted
+func WithFailOnWarn(c bool) Options {
+	return func(project *Project, cfg *runConfig) {
+		cfg.FailOnWarn = c
+	}
+}
+
+// WithNodePortRange configures a project's run config with the range NodePort services node ports must be within
+func WithNodePortRange(c string) Options {
+	return func(project *Project, cfg *runConfig) {
+		cfg.NodePortRange = c
+	}
+}
+
+// WithValuesFile configures a project's run config with a file of services x-k8s configuration values
+// merged into environments at render time.
+func WithValuesFile(path string) Options {
+	return func(project *Project, cfg *runConfig) {
+		cfg.ValuesFile = path
+	}
+}
+
+// WithWarningAnnotations configures a project's run config to record service conversion warnings
+// as annotations on the rendered objects they concern
+func WithWarningAnnotations(c bool) Options {
+	return func(project *Project, cfg *runConfig) {
+		cfg.WarningAnnotations = c
+	}
+}
+
+// WithPruneEmpty configures a project's run config to drop rendered objects
+// carrying no meaningful configuration
+func WithPruneEmpty(c bool) Options {
+	return func(project *Project, cfg *runConfig) {
+		cfg.PruneEmpty = c
+	}
+}
+
+// WithIndex configures a project's run config to write a markdown index
+// summarising the rendered objects of each environment
+func WithIndex(c bool) Options {
+	return func(project *Project, cfg *runConfig) {
+		cfg.Index = c
+	}
+}
+
+// WithFieldManager configures a project's run config with the server-side apply field manager name
+// rendered objects get annotated with.
+func WithFieldManager(name string) Options {
+	return func(project *Project, cfg *runConfig) {
+		cfg.FieldManager = name
+	}
+}
+
+// WithValuesFromEnv configures a project's run config with a prefix used to select environment variables
+// injected into compose files interpolation.
+func WithValuesFromEnv(prefix string) Options {
+	return func(project *Project, cfg *runConfig) {
+		cfg.ValuesFromEnvPrefix = prefix
+	}
+}
+
+// WithKustomizeOverlay configures a project's run config with a kustomization directory
+// rendered manifests are run through as a final render step.
+func WithKustomizeOverlay(dir string) Options {
+	return func(project *Project, cfg *runConfig) {
+		cfg.KustomizeOverlay = dir
+	}
+}
+
+// WithConcurrency configures a project's run config with the maximum number of environments rendered concurrently
+func WithConcurrency(c int) Options {
+	return func(project *Project, cfg *runConfig) {
+		cfg.Concurrency = c
+	}
+}
+
+// WithDebounce configures a project's run config with the quiet period collapsing a burst of
+// file changes into a single re-render during dev.
+func WithDebounce(d time.Duration) Options {
+	return func(project *Project, cfg *runConfig) {
+		cfg.DevDebounce = d
+	}
+}
+
+// WithLintErrorOn configures a project's run config with lint checks whose findings are reported as errors.
+func WithLintErrorOn(checks []string) Options {
+	return func(project *Project, cfg *runConfig) {
+		cfg.LintErrorOn = checks
+	}
+}
+
+// WithGraphFormat configures a project's run config with the format of its dependency graph.
+func WithGraphFormat(format string) Options {
+	return func(project *Project, cfg *runConfig) {
+		cfg.GraphFormat = format
+	}
+}
+
+// WithPatch configures a project's run config with a patch to add to an environment's rendered objects.
+func WithPatch(patch config.Patch) Options {
+	return func(project *Project, cfg *runConfig) {
+		cfg.Patch = patch
+	}
+}
+
 // WithK8sNamespace configures a project's run config with a K8s namespace
 // (used mostly during dev when Skaffold is enabled).
 func WithK8sNamespace(c string) Options {
@@ -296,6 +497,13 @@ func WithExcludeServicesByEnv(c map[string][]string) Options {
 	}
 }
 
+// WithFilterServices configures a project's run config to only render the named services.
+func WithFilterServices(c []string) Options {
+	return func(project *Project, cfg *runConfig) {
+		cfg.FilterServices = c
+	}
+}
+
 // WithLogVerbose configures a project's run config to enable or disable verbose
 // logging at a debug log level.
 func WithLogVerbose(c bool) Options {
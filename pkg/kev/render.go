@@ -18,10 +18,15 @@ package kev
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/appvia/kev/pkg/kev/config"
 	"github.com/appvia/kev/pkg/kev/converter"
+	"github.com/appvia/kev/pkg/kev/converter/kubernetes"
+	"github.com/appvia/kev/pkg/kev/converter/kustomize"
 	kmd "github.com/appvia/komando"
 	"github.com/pkg/errors"
 )
@@ -51,6 +56,10 @@ func (r *RenderRunner) Run() (map[string]string, error) {
 		return nil, err
 	}
 
+	if err := r.InjectValuesFromEnv(); err != nil {
+		return nil, err
+	}
+
 	if err := r.ValidateSources(r.manifest.Sources, config.SecretMatchers); err != nil {
 		sg := r.UI.StepGroup()
 		defer sg.Done()
@@ -70,9 +79,85 @@ func (r *RenderRunner) Run() (map[string]string, error) {
 		return nil, err
 	}
 
+	if err := r.ApplyValuesFile(); err != nil {
+		sg := r.UI.StepGroup()
+		defer sg.Done()
+		renderStepError(r.UI, sg.Add(""), renderStepValuesFile, err)
+		return nil, err
+	}
+
+	if err := r.ApplyServicesFilter(); err != nil {
+		sg := r.UI.StepGroup()
+		defer sg.Done()
+		renderStepError(r.UI, sg.Add(""), renderStepFilterServices, err)
+		return nil, err
+	}
+
 	results, err := r.RenderFromComposeToK8sManifests()
+	if err != nil {
+		return nil, err
+	}
 
-	return results, err
+	if err := r.ApplyKustomizeOverlay(results); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// RenderToMemory renders the project's environments as Kubernetes manifests held in memory, keyed by environment name.
+// Each environment's manifests are a List matching the single file output of `kev render`, as they go through the same transformation.
+// Environments are reconciled in memory only, neither environment files nor manifests are written to disk.
+func (r *RenderRunner) RenderToMemory() (map[string][]byte, error) {
+	if err := r.LoadProject(); err != nil {
+		return nil, err
+	}
+
+	if err := r.InjectValuesFromEnv(); err != nil {
+		return nil, err
+	}
+
+	if err := r.ValidateSources(r.manifest.Sources, config.SecretMatchers); err != nil {
+		return nil, err
+	}
+
+	if err := r.ValidateEnvSources(config.SecretMatchers); err != nil {
+		return nil, err
+	}
+
+	if _, err := r.manifest.ReconcileConfig(r.config.Envs...); err != nil {
+		return nil, err
+	}
+
+	if err := r.ApplyValuesFile(); err != nil {
+		return nil, err
+	}
+
+	if err := r.ApplyServicesFilter(); err != nil {
+		return nil, err
+	}
+
+	convOpts, err := r.converterOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	envObjects, err := r.manifest.RenderObjects(r.config.Envs, r.config.ExcludeServicesByEnv, convOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	out := map[string][]byte{}
+	for env, objects := range envObjects {
+		data, err := kubernetes.MarshalList(objects, r.config.Output == kubernetes.OutputJSON)
+		if err != nil {
+			return nil, errors.Wrapf(err, "environment %s, details:\n", env)
+		}
+		// single file output is terminated with a new line
+		out[env] = append(data, '\n')
+	}
+
+	return out, nil
 }
 
 // LoadProject loads the project into memory including the kev manifest and related deployment environments.
@@ -187,12 +272,22 @@ func (r *RenderRunner) ReconcileEnvsAndWriteUpdates() error {
 		return err
 	}
 
-	if err := r.manifest.Environments.Write(); err != nil {
+	results := r.manifest.Environments.toWritableResults()
+	if r.manifest.reconciledSourcesChanged {
+		// the manifest records the sources values environments were reconciled against
+		results = append(results, WritableResult{
+			WriterTo: r.manifest,
+			FilePath: filepath.Join(r.WorkingDir, ManifestFilename),
+		})
+	}
+
+	if err := results.Write(); err != nil {
 		sg := r.UI.StepGroup()
 		defer sg.Done()
 		renderStepError(r.UI, sg.Add(""), renderStepReconcileWrite, err)
 		return err
 	}
+	r.manifest.reconciledSourcesChanged = false
 
 	if err := r.eventHandler(PostReconcileEnvs, r); err != nil {
 		return newEventError(err, PostReconcileEnvs)
@@ -212,8 +307,16 @@ func (r *RenderRunner) RenderFromComposeToK8sManifests() (map[string]string, err
 	manifestFormat := r.config.ManifestFormat
 	r.UI.Header(fmt.Sprintf("Rendering manifests, format: %s...", manifestFormat))
 
+	convOpts, err := r.converterOptions()
+	if err != nil {
+		sg := r.UI.StepGroup()
+		defer sg.Done()
+		renderStepError(r.UI, sg.Add(""), renderStepRenderGeneral, err)
+		return nil, err
+	}
+
 	results, err := r.manifest.RenderWithConvertor(
-		converter.Factory(manifestFormat, r.UI),
+		converter.Factory(manifestFormat, r.UI, convOpts...),
 		r.config.OutputDir,
 		r.config.ManifestsAsSingleFile,
 		r.config.Envs,
@@ -229,6 +332,166 @@ func (r *RenderRunner) RenderFromComposeToK8sManifests() (map[string]string, err
 	return results, err
 }
 
+// ApplyServicesFilter limits rendering to the services selected with the configured services filter, if any,
+// by excluding all other services from each environment. Project secrets are rendered regardless.
+// It errors when the filter names a service the project doesn't define.
+func (p *Project) ApplyServicesFilter() error {
+	if len(p.config.FilterServices) == 0 {
+		return nil
+	}
+
+	sources, err := p.manifest.SourcesToComposeProject()
+	if err != nil {
+		return err
+	}
+	known := sources.ServiceNames()
+	sort.Strings(known)
+
+	for _, name := range p.config.FilterServices {
+		if !contains(known, name) {
+			return errors.Errorf("unknown service %s, known services: %s", name, strings.Join(known, ", "))
+		}
+	}
+
+	excluded := map[string][]string{}
+	for env, services := range p.config.ExcludeServicesByEnv {
+		excluded[env] = append([]string{}, services...)
+	}
+
+	for _, env := range p.manifest.GetEnvironmentsNames() {
+		for _, name := range known {
+			if !contains(p.config.FilterServices, name) && !contains(excluded[env], name) {
+				excluded[env] = append(excluded[env], name)
+			}
+		}
+	}
+	p.config.ExcludeServicesByEnv = excluded
+
+	return nil
+}
+
+// converterOptions returns the Kubernetes manifests converter options configured for the project
+func (p *Project) converterOptions() ([]kubernetes.Option, error) {
+	convOpts := []kubernetes.Option{
+		kubernetes.WithAllEnvsSingleFile(p.config.AllEnvsSingleFile),
+		kubernetes.WithPruneEmpty(p.config.PruneEmpty),
+		kubernetes.WithIndex(p.config.Index),
+		kubernetes.WithWarningAnnotations(p.config.WarningAnnotations),
+	}
+
+	if p.config.AnnotateSourceFile {
+		sourceFiles, err := p.manifest.Sources.ServiceSourceFiles()
+		if err != nil {
+			return nil, err
+		}
+		convOpts = append(convOpts, kubernetes.WithServiceSourceFiles(sourceFiles))
+	}
+
+	if p.config.FieldManager != "" {
+		convOpts = append(convOpts, kubernetes.WithFieldManager(p.config.FieldManager))
+	}
+
+	if p.config.ResourceQuota {
+		headroom := p.config.QuotaHeadroom
+		if headroom == 0 {
+			headroom = kubernetes.DefaultQuotaHeadroom
+		}
+		if headroom < 1 {
+			return nil, fmt.Errorf("quota headroom must be at least 1, got %v", headroom)
+		}
+		convOpts = append(convOpts, kubernetes.WithResourceQuota(headroom))
+	}
+
+	namespaceConfigs := map[string]config.NamespaceConfig{}
+	envPatches := map[string][]config.Patch{}
+	for _, env := range p.manifest.Environments {
+		envK8sConfig, err := env.K8sConfig()
+		if err != nil {
+			return nil, err
+		}
+		if !envK8sConfig.Namespace.IsEmpty() {
+			namespaceConfigs[env.Name] = envK8sConfig.Namespace
+		}
+		if len(envK8sConfig.Patches) > 0 {
+			envPatches[env.Name] = envK8sConfig.Patches
+		}
+	}
+	if len(namespaceConfigs) > 0 {
+		convOpts = append(convOpts, kubernetes.WithNamespaceConfigs(namespaceConfigs))
+	}
+	if len(envPatches) > 0 {
+		convOpts = append(convOpts, kubernetes.WithEnvPatches(envPatches))
+	}
+
+	switch p.config.ExposeAPI {
+	case "", kubernetes.ExposeAPIIngress:
+	case kubernetes.ExposeAPITraefik:
+		convOpts = append(convOpts, kubernetes.WithExposeAPI(p.config.ExposeAPI))
+	default:
+		return nil, fmt.Errorf("expose api must be one of: %s, %s, got %s",
+			kubernetes.ExposeAPIIngress, kubernetes.ExposeAPITraefik, p.config.ExposeAPI)
+	}
+
+	if p.config.KubeVersion != "" {
+		if _, err := kubernetes.ParseKubeVersion(p.config.KubeVersion); err != nil {
+			return nil, err
+		}
+		convOpts = append(convOpts, kubernetes.WithKubeVersion(p.config.KubeVersion))
+	}
+
+	if p.config.Concurrency < 0 {
+		return nil, fmt.Errorf("concurrency must be at least 1, got %d", p.config.Concurrency)
+	}
+	if p.config.Concurrency > 1 {
+		convOpts = append(convOpts, kubernetes.WithConcurrency(p.config.Concurrency))
+	}
+
+	if p.config.NodePortRange != "" {
+		if _, _, err := kubernetes.ParseNodePortRange(p.config.NodePortRange); err != nil {
+			return nil, err
+		}
+		convOpts = append(convOpts, kubernetes.WithNodePortRange(p.config.NodePortRange))
+	}
+
+	if p.config.ManifestFormat == kustomize.Name && len(p.config.Envs) > 0 {
+		envs, err := p.manifest.GetEnvironments(p.config.Envs)
+		if err != nil {
+			return nil, err
+		}
+		// the base shared by all environments is derived from every environment's objects
+		if len(envs) < len(p.manifest.Environments) {
+			return nil, fmt.Errorf("the %s format renders a base shared by all environments, all environments must be rendered together", kustomize.Name)
+		}
+	}
+
+	if overlay := p.config.KustomizeOverlay; overlay != "" {
+		if format := p.config.ManifestFormat; format != "" && format != kubernetes.Name {
+			return nil, fmt.Errorf("kustomize overlay is only supported by the %s format, got %s", kubernetes.Name, format)
+		}
+		if p.config.Output == kubernetes.OutputJSON {
+			return nil, fmt.Errorf("kustomize overlay is only supported with %s output", kubernetes.OutputYAML)
+		}
+		if info, err := os.Stat(overlay); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("kustomize overlay %s must be an existing directory", overlay)
+		}
+	}
+
+	switch p.config.Output {
+	case "", kubernetes.OutputYAML:
+	case kubernetes.OutputJSON:
+		if format := p.config.ManifestFormat; format != "" && format != kubernetes.Name {
+			return nil, fmt.Errorf("%s output is only supported by the %s format, got %s",
+				p.config.Output, kubernetes.Name, format)
+		}
+		convOpts = append(convOpts, kubernetes.WithOutput(p.config.Output))
+	default:
+		return nil, fmt.Errorf("output must be one of: %s, %s, got %s",
+			kubernetes.OutputYAML, kubernetes.OutputJSON, p.config.Output)
+	}
+
+	return convOpts, nil
+}
+
 func printRenderProjectWithOptionsError(appName string, ui kmd.UI) {
 	ui.Output("")
 	ui.Output("Project had errors during render.\n"+
@@ -240,6 +503,26 @@ func printRenderProjectWithOptionsError(appName string, ui kmd.UI) {
 	)
 }
 
+func printRenderProjectWithOptionsWarnings(appName string, ui kmd.UI, warnings []string) error {
+	ui.Output("")
+	ui.Output(
+		fmt.Sprintf("Project render emitted %d warning(s):", len(warnings)),
+		kmd.WithErrorBoldStyle(),
+		kmd.WithIndentChar(kmd.ErrorIndentChar),
+	)
+	for _, w := range warnings {
+		ui.Output(w, kmd.WithIndentChar("-"), kmd.WithIndent(1))
+	}
+	ui.Output("")
+	ui.Output(
+		fmt.Sprintf("'%s' was run with --fail-on-warn. Please address the warnings above\n", appName)+
+			fmt.Sprintf("and run '%s render' again.", appName),
+		kmd.WithErrorBoldStyle(),
+		kmd.WithIndentChar(kmd.ErrorIndentChar),
+	)
+	return fmt.Errorf("render emitted %d warning(s)", len(warnings))
+}
+
 func printRenderProjectWithOptionsSuccess(r *RenderRunner, results map[string]string, envs Environments, manifestFormat string) error {
 	var namedValues []kmd.NamedValue
 	for _, env := range envs {
@@ -270,7 +553,11 @@ func printRenderProjectWithOptionsSuccess(r *RenderRunner, results map[string]st
 	ui.Output("To test locally:")
 	ui.Output("Ensure you have a local cluster up and running with a configured context.", kmd.WithIndentChar("-"), kmd.WithIndent(1))
 	ui.Output("Create a namespace: `kubectl create ns ns-example`.", kmd.WithIndentChar("-"), kmd.WithIndent(1))
-	ui.Output("Apply the manifests to the cluster: `kubectl apply -f <manifests-dir>/<env> -n ns-example`.", kmd.WithIndentChar("-"), kmd.WithIndent(1))
+	if fm := r.config.FieldManager; fm != "" {
+		ui.Output(fmt.Sprintf("Apply the manifests to the cluster: `kubectl apply --server-side --field-manager=%s -f <manifests-dir>/<env> -n ns-example`.", fm), kmd.WithIndentChar("-"), kmd.WithIndent(1))
+	} else {
+		ui.Output("Apply the manifests to the cluster: `kubectl apply -f <manifests-dir>/<env> -n ns-example`.", kmd.WithIndentChar("-"), kmd.WithIndent(1))
+	}
 	ui.Output("Discover the main service: `kubectl get svc -n ns-example`.", kmd.WithIndentChar("-"), kmd.WithIndent(1))
 	ui.Output("Port forward to the main service: `kubectl port-forward service/<service_name> <service_port>:<destination_port> -n ns-example`.", kmd.WithIndentChar("-"), kmd.WithIndent(1))
 
@@ -35,6 +35,9 @@ const (
 	renderStepRenderGeneral
 	renderStepValidatingSources
 	renderStepRenderOverlay
+	renderStepKustomizeOverlay
+	renderStepValuesFile
+	renderStepFilterServices
 )
 
 var renderStepStrings = map[renderStepType]struct {
@@ -83,6 +86,18 @@ This is important as it ensures that project rendered manifests will have
 environment specific settings.
 `,
 	},
+
+	renderStepKustomizeOverlay: {
+		Error: "Cannot apply the kustomize overlay to rendered manifests!",
+	},
+
+	renderStepValuesFile: {
+		Error: "Cannot apply the values file to environments!",
+	},
+
+	renderStepFilterServices: {
+		Error: "Cannot filter the services to render!",
+	},
 }
 
 func renderStepError(ui kmd.UI, s kmd.Step, step renderStepType, err error) {
@@ -0,0 +1,87 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kev_test
+
+import (
+	"github.com/appvia/kev/pkg/kev"
+	kmd "github.com/appvia/komando"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ApplyServicesFilter", func() {
+	var (
+		filter []string
+		runner *kev.RenderRunner
+	)
+
+	JustBeforeEach(func() {
+		runner = kev.NewRenderRunner("testdata/profiles", kev.WithUI(kmd.NoOpUI()), kev.WithFilterServices(filter))
+		Expect(runner.LoadProject()).To(Succeed())
+	})
+
+	Context("without a services filter", func() {
+		BeforeEach(func() {
+			filter = nil
+		})
+
+		It("doesn't exclude any services", func() {
+			Expect(runner.ApplyServicesFilter()).To(Succeed())
+			Expect(runner.GetConfig().ExcludeServicesByEnv).To(BeEmpty())
+		})
+	})
+
+	Context("with known services", func() {
+		BeforeEach(func() {
+			filter = []string{"web"}
+		})
+
+		It("excludes all other services from each environment", func() {
+			Expect(runner.ApplyServicesFilter()).To(Succeed())
+
+			excluded := runner.GetConfig().ExcludeServicesByEnv
+			for _, env := range []string{"all", "web", "worker"} {
+				Expect(excluded[env]).To(ConsistOf("db", "worker"))
+			}
+		})
+	})
+
+	Context("with an unknown service", func() {
+		BeforeEach(func() {
+			filter = []string{"web", "unknown"}
+		})
+
+		It("returns an error listing the known services", func() {
+			err := runner.ApplyServicesFilter()
+			Expect(err).To(MatchError("unknown service unknown, known services: db, web, worker"))
+		})
+	})
+})
+
+var _ = Describe("Rendering the kustomize format", func() {
+	It("rejects rendering a subset of environments as they share a base", func() {
+		_, err := kev.RenderProjectToMemory("testdata/profiles", kev.WithManifestFormat("kustomize"), kev.WithEnvs([]string{"web"}))
+		Expect(err).To(MatchError("the kustomize format renders a base shared by all environments, all environments must be rendered together"))
+	})
+})
+
+var _ = Describe("Rendering concurrently", func() {
+	It("rejects a negative concurrency", func() {
+		_, err := kev.RenderProjectToMemory("testdata/profiles", kev.WithConcurrency(-1))
+		Expect(err).To(MatchError("concurrency must be at least 1, got -1"))
+	})
+})
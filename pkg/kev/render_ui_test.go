@@ -97,3 +97,43 @@ var _ = Describe("RenderRunner UI", func() {
 		})
 	})
 })
+
+var _ = Describe("Reconcile UI", func() {
+	var (
+		ui  kmd.UI
+		log kmd.UILog
+	)
+
+	BeforeEach(func() {
+		ui, log = kmd.FakeUIAndLog()
+	})
+
+	AfterEach(func() {
+		log.Reset()
+	})
+
+	Context("Reconciling environments", func() {
+		It("displays a change summary grouped by change type, environment and object", func() {
+			runner := kev.NewRenderRunner("./testdata/reconcile-service-removal", kev.WithUI(ui))
+			err := runner.LoadProject()
+			Expect(err).NotTo(HaveOccurred())
+			log.Reset()
+
+			_, err = runner.Manifest().ReconcileConfig()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(log.NextHeader()).To(HaveKeyWithValue("Reconcile summary...", []string{}))
+
+			var outputs []map[string][]string
+			for i := 0; i < 6; i++ {
+				outputs = append(outputs, log.NextOutput())
+			}
+			Expect(outputs[2:]).To(Equal([]map[string][]string{
+				{"Deleted (1)": {}},
+				{"dev (1)": {"2"}},
+				{"service wordpress (1)": {"4"}},
+				{"removed service: wordpress": {"6", "|", "log"}},
+			}))
+		})
+	})
+})
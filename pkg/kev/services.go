@@ -19,16 +19,34 @@ package kev
 import (
 	"encoding/json"
 	"regexp"
+	"sort"
 
 	"github.com/appvia/kev/pkg/kev/config"
 	composego "github.com/compose-spec/compose-go/types"
 )
 
+// deprecatedLabelKeys maps renamed kev label keys to their current equivalents.
+// Reconcile migrates deprecated keys found in environment overrides using this table.
+// No kev label key has been renamed yet, add an entry here when one is.
+var deprecatedLabelKeys = map[string]string{}
+
 func newServiceConfig(s composego.ServiceConfig) (ServiceConfig, error) {
-	config := ServiceConfig{Name: s.Name, Environment: s.Environment, Extensions: s.Extensions}
+	config := ServiceConfig{Name: s.Name, Environment: s.Environment, Labels: s.Labels, Extensions: s.Extensions}
 	return config, nil
 }
 
+// deprecatedLabels returns the service's deprecated label keys in a stable order
+func (s ServiceConfig) deprecatedLabels() []string {
+	var out []string
+	for key := range s.Labels {
+		if _, ok := deprecatedLabelKeys[key]; ok {
+			out = append(out, key)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
 // MarshalYAML makes Services implement yaml.Marshaller.
 func (s Services) MarshalYAML() (interface{}, error) {
 	services := map[string]ServiceConfig{}
@@ -70,8 +70,53 @@ const (
 
 	// DefaultSkaffoldNamespace is a default namespace to which Skaffold will deploy manifests
 	DefaultSkaffoldNamespace = "default"
+
+	// SkaffoldBuildStrategyLocal builds images with the local docker daemon (default)
+	SkaffoldBuildStrategyLocal = "local"
+	// SkaffoldBuildStrategyKaniko builds images in cluster with kaniko
+	SkaffoldBuildStrategyKaniko = "kaniko"
+	// SkaffoldBuildStrategyBuildpacks builds images locally with Cloud Native Buildpacks
+	SkaffoldBuildStrategyBuildpacks = "buildpacks"
+
+	// SkaffoldTagPolicyGit tags images with git tags (default)
+	SkaffoldTagPolicyGit = "git"
+	// SkaffoldTagPolicySha256 tags images with their content digest
+	SkaffoldTagPolicySha256 = "sha256"
+	// SkaffoldTagPolicyDateTime tags images with the build timestamp
+	SkaffoldTagPolicyDateTime = "datetime"
+
+	// DefaultBuildpacksBuilder is the builder used by buildpacks artifacts
+	DefaultBuildpacksBuilder = "paketobuildpacks/builder:base"
 )
 
+// SkaffoldBuildOptions configure the build section of a generated Skaffold manifest.
+// Blank options leave the manifest build configuration unchanged.
+type SkaffoldBuildOptions struct {
+	// Strategy is the build strategy, one of: local, kaniko, buildpacks
+	Strategy string
+	// TagPolicy is the image tagging policy, one of: git, sha256, datetime
+	TagPolicy string
+}
+
+// Validate ensures build options hold supported values
+func (o SkaffoldBuildOptions) Validate() error {
+	switch o.Strategy {
+	case "", SkaffoldBuildStrategyLocal, SkaffoldBuildStrategyKaniko, SkaffoldBuildStrategyBuildpacks:
+	default:
+		return fmt.Errorf("unsupported Skaffold build strategy %q, supported strategies: %s, %s, %s",
+			o.Strategy, SkaffoldBuildStrategyLocal, SkaffoldBuildStrategyKaniko, SkaffoldBuildStrategyBuildpacks)
+	}
+
+	switch o.TagPolicy {
+	case "", SkaffoldTagPolicyGit, SkaffoldTagPolicySha256, SkaffoldTagPolicyDateTime:
+	default:
+		return fmt.Errorf("unsupported Skaffold tag policy %q, supported policies: %s, %s, %s",
+			o.TagPolicy, SkaffoldTagPolicyGit, SkaffoldTagPolicySha256, SkaffoldTagPolicyDateTime)
+	}
+
+	return nil
+}
+
 var (
 	disabled = false
 	enabled  = true
@@ -355,7 +400,7 @@ func (s *SkaffoldManifest) SetBuildArtifacts(analysis *Analysis, project *Compos
 			// no Dockerfiles detected, set `buildpacks` as build strategy for the artifact
 			artifact.ArtifactType = latest.ArtifactType{
 				BuildpackArtifact: &latest.BuildpackArtifact{
-					Builder: "paketobuildpacks/builder:base",
+					Builder: DefaultBuildpacksBuilder,
 				},
 			}
 		}
@@ -365,6 +410,40 @@ func (s *SkaffoldManifest) SetBuildArtifacts(analysis *Analysis, project *Compos
 	s.Build.Artifacts = artifacts
 }
 
+// SetBuildOptions applies build strategy & tagging policy options to the manifest's build section.
+// Options left blank keep the current build configuration.
+func (s *SkaffoldManifest) SetBuildOptions(opts SkaffoldBuildOptions) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	switch opts.Strategy {
+	case SkaffoldBuildStrategyLocal:
+		s.Build.BuildType = latest.BuildType{LocalBuild: &latest.LocalBuild{}}
+	case SkaffoldBuildStrategyKaniko:
+		s.Build.BuildType = latest.BuildType{Cluster: &latest.ClusterDetails{}}
+		for _, a := range s.Build.Artifacts {
+			a.ArtifactType = latest.ArtifactType{KanikoArtifact: &latest.KanikoArtifact{}}
+		}
+	case SkaffoldBuildStrategyBuildpacks:
+		s.Build.BuildType = latest.BuildType{LocalBuild: &latest.LocalBuild{}}
+		for _, a := range s.Build.Artifacts {
+			a.ArtifactType = latest.ArtifactType{BuildpackArtifact: &latest.BuildpackArtifact{Builder: DefaultBuildpacksBuilder}}
+		}
+	}
+
+	switch opts.TagPolicy {
+	case SkaffoldTagPolicyGit:
+		s.Build.TagPolicy = latest.TagPolicy{GitTagger: &latest.GitTagger{Variant: "Tags"}}
+	case SkaffoldTagPolicySha256:
+		s.Build.TagPolicy = latest.TagPolicy{ShaTagger: &latest.ShaTagger{}}
+	case SkaffoldTagPolicyDateTime:
+		s.Build.TagPolicy = latest.TagPolicy{DateTimeTagger: &latest.DateTimeTagger{}}
+	}
+
+	return nil
+}
+
 // collectBuildArtfacts returns a map of build contexts to corresponding image names
 func collectBuildArtifacts(analysis *Analysis, project *ComposeProject) map[string]string {
 	buildArtifacts := map[string]string{}
@@ -565,6 +644,12 @@ func RunSkaffoldDev(ctx context.Context, out io.Writer, skaffoldFile string, pro
 		},
 	}
 
+	if runCfg.SkaffoldDefaultRepo != "" {
+		if err := skaffoldOpts.DefaultRepo.Set(runCfg.SkaffoldDefaultRepo); err != nil {
+			return errors.Wrap(err, "Skaffold dev failed")
+		}
+	}
+
 	runCtx, cfg, err := runContext(skaffoldOpts, profiles, out)
 	if err != nil {
 		return errors.Wrap(err, "Skaffold dev failed")
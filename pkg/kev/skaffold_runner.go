@@ -0,0 +1,109 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kev
+
+import (
+	"fmt"
+	"path/filepath"
+
+	kmd "github.com/appvia/komando"
+)
+
+// NewSkaffoldRunner creates a skaffold runner instance
+func NewSkaffoldRunner(workingDir string, opts ...Options) *SkaffoldRunner {
+	runner := &SkaffoldRunner{
+		Project: &Project{
+			WorkingDir:   workingDir,
+			eventHandler: func(s RunnerEvent, r Runner) error { return nil },
+		},
+	}
+	runner.Init(opts...)
+	return runner
+}
+
+// Run creates or updates the Skaffold config of an initialised project with profiles for all its
+// deployment environments. Profiles already defined in an existing Skaffold config are skipped.
+// It returns results that can be written to disk.
+func (r *SkaffoldRunner) Run() (WritableResults, error) {
+	if r.LogVerbose() {
+		cancelFunc, pr, pw := r.pipeLogsToUI()
+		defer cancelFunc()
+		defer pw.Close()
+		defer pr.Close()
+	}
+
+	if err := (&RenderRunner{Project: r.Project}).LoadProject(); err != nil {
+		return nil, err
+	}
+
+	existing := map[string]bool{}
+	skPath := filepath.Join(r.WorkingDir, SkaffoldFileName)
+	if ManifestExistsForPath(skPath) {
+		current, err := LoadSkaffoldManifest(skPath)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range current.ProfilesNames() {
+			existing[name] = true
+		}
+	}
+
+	r.UI.Header("Generating Skaffold config...")
+	skManifest, err := (&InitRunner{Project: r.Project}).CreateOrUpdateSkaffoldManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	r.UI.Header("Skaffold profiles...")
+	sg := r.UI.StepGroup()
+	defer sg.Done()
+	for _, name := range skManifest.ProfilesNames() {
+		if existing[name] {
+			sg.Add(fmt.Sprintf("Skipped profile: %s (already defined)", name)).Success()
+			continue
+		}
+		sg.Add(fmt.Sprintf("Added profile: %s", name)).Success()
+	}
+
+	// deployment environment files are left untouched
+	return WritableResults{
+		{WriterTo: r.manifest, FilePath: filepath.Join(r.WorkingDir, ManifestFilename)},
+		{WriterTo: skManifest, FilePath: filepath.Join(r.WorkingDir, SkaffoldFileName)},
+	}, nil
+}
+
+func printSkaffoldProjectWithOptionsError(appName string, ui kmd.UI) {
+	ui.Output("")
+	ui.Output("Project had errors during Skaffold config generation.\n"+
+		fmt.Sprintf("'%s' experienced some errors while generating the Skaffold config. The output\n", appName)+
+		"above should contain the failure messages. Please correct these errors and\n"+
+		fmt.Sprintf("run '%s skaffold' again.", appName),
+		kmd.WithErrorBoldStyle(),
+		kmd.WithIndentChar(kmd.ErrorIndentChar),
+	)
+}
+
+func printSkaffoldProjectWithOptionsSuccess(r *SkaffoldRunner) {
+	ui := r.GetUI()
+	ui.Output("")
+	ui.Output("Skaffold config ready!", kmd.WithStyle(kmd.SuccessBoldStyle))
+	ui.Output(fmt.Sprintf("Skaffold config with deployment environment profiles written to: %s", r.manifest.Skaffold),
+		kmd.WithStyle(kmd.SuccessStyle),
+	)
+	ui.Output("")
+	ui.Output(fmt.Sprintf("You may now call `%s dev` to start developing against your deployment environments.", r.AppName))
+}
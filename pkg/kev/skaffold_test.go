@@ -24,6 +24,7 @@ import (
 	"github.com/appvia/kev/pkg/kev"
 	"github.com/appvia/kev/pkg/kev/converter/kubernetes"
 	"github.com/appvia/kev/pkg/kev/log"
+	kmd "github.com/appvia/komando"
 	composego "github.com/compose-spec/compose-go/types"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -696,4 +697,144 @@ var _ = Describe("Skaffold", func() {
 			})
 		})
 	})
+
+	Describe("SetBuildOptions", func() {
+
+		var (
+			skaffoldManifest *kev.SkaffoldManifest
+			opts             kev.SkaffoldBuildOptions
+			err              error
+		)
+
+		BeforeEach(func() {
+			skaffoldManifest = kev.BaseSkaffoldManifest()
+			skaffoldManifest.Build.Artifacts = []*latest.Artifact{
+				{
+					ImageName: "myservice",
+					Workspace: "src/myservice",
+					ArtifactType: latest.ArtifactType{
+						DockerArtifact: &latest.DockerArtifact{DockerfilePath: "Dockerfile"},
+					},
+				},
+			}
+			opts = kev.SkaffoldBuildOptions{}
+		})
+
+		JustBeforeEach(func() {
+			err = skaffoldManifest.SetBuildOptions(opts)
+		})
+
+		Context("with no build options", func() {
+			It("leaves the build configuration unchanged", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(skaffoldManifest.Build.LocalBuild).NotTo(BeNil())
+				Expect(skaffoldManifest.Build.TagPolicy.GitTagger).NotTo(BeNil())
+				Expect(skaffoldManifest.Build.Artifacts[0].DockerArtifact).NotTo(BeNil())
+			})
+		})
+
+		Context("with kaniko build strategy", func() {
+			BeforeEach(func() {
+				opts.Strategy = kev.SkaffoldBuildStrategyKaniko
+			})
+
+			It("builds artifacts in cluster with kaniko", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(skaffoldManifest.Build.LocalBuild).To(BeNil())
+				Expect(skaffoldManifest.Build.Cluster).NotTo(BeNil())
+				Expect(skaffoldManifest.Build.Artifacts[0].KanikoArtifact).NotTo(BeNil())
+				Expect(skaffoldManifest.Build.Artifacts[0].DockerArtifact).To(BeNil())
+			})
+		})
+
+		Context("with buildpacks build strategy", func() {
+			BeforeEach(func() {
+				opts.Strategy = kev.SkaffoldBuildStrategyBuildpacks
+			})
+
+			It("builds artifacts locally with the default buildpacks builder", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(skaffoldManifest.Build.LocalBuild).NotTo(BeNil())
+				Expect(skaffoldManifest.Build.Artifacts[0].BuildpackArtifact).NotTo(BeNil())
+				Expect(skaffoldManifest.Build.Artifacts[0].BuildpackArtifact.Builder).To(Equal(kev.DefaultBuildpacksBuilder))
+			})
+		})
+
+		Context("with sha256 tag policy", func() {
+			BeforeEach(func() {
+				opts.TagPolicy = kev.SkaffoldTagPolicySha256
+			})
+
+			It("tags images with their content digest", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(skaffoldManifest.Build.TagPolicy.ShaTagger).NotTo(BeNil())
+				Expect(skaffoldManifest.Build.TagPolicy.GitTagger).To(BeNil())
+			})
+		})
+
+		Context("with datetime tag policy", func() {
+			BeforeEach(func() {
+				opts.TagPolicy = kev.SkaffoldTagPolicyDateTime
+			})
+
+			It("tags images with the build timestamp", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(skaffoldManifest.Build.TagPolicy.DateTimeTagger).NotTo(BeNil())
+			})
+		})
+
+		Context("with unsupported build strategy", func() {
+			BeforeEach(func() {
+				opts.Strategy = "jib"
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError(ContainSubstring(`unsupported Skaffold build strategy "jib"`)))
+			})
+		})
+
+		Context("with unsupported tag policy", func() {
+			BeforeEach(func() {
+				opts.TagPolicy = "latest"
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError(ContainSubstring(`unsupported Skaffold tag policy "latest"`)))
+			})
+		})
+	})
+
+	Describe("SkaffoldRunner", func() {
+		var (
+			results kev.WritableResults
+			err     error
+		)
+
+		JustBeforeEach(func() {
+			results, err = kev.NewSkaffoldRunner("testdata/skaffold-update", kev.WithUI(kmd.NoOpUI())).Run()
+		})
+
+		It("updates the existing skaffold config and the project manifest", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(results).To(HaveLen(2))
+			Expect(results[0].FilePath).To(Equal(filepath.Join("testdata/skaffold-update", kev.ManifestFilename)))
+			Expect(results[1].FilePath).To(Equal(filepath.Join("testdata/skaffold-update", kev.SkaffoldFileName)))
+		})
+
+		It("adds profiles for new environments, skipping existing ones", func() {
+			Expect(err).NotTo(HaveOccurred())
+			skaffoldManifest := results[1].WriterTo.(*kev.SkaffoldManifest)
+			Expect(skaffoldManifest.ProfilesNames()).To(ConsistOf(
+				"dev-env",
+				"staging-env",
+				"ci-local-build-no-push",
+				"ci-local-build-and-push",
+			))
+		})
+
+		It("tracks the skaffold config in the project manifest", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(results[0].WriterTo.(*kev.Manifest).Skaffold).To(Equal(kev.SkaffoldFileName))
+		})
+	})
 })
@@ -18,6 +18,7 @@ package kev
 
 import (
 	"encoding/json"
+	"io/ioutil"
 	"path/filepath"
 
 	"github.com/appvia/kev/pkg/kev/config"
@@ -30,7 +31,7 @@ type BaseOverrideOpts func(s *Sources, c *ComposeProject) error
 
 // CalculateBaseOverride calculates the extensions deduced from a group of compose sources.
 func (s *Sources) CalculateBaseOverride(opts ...BaseOverrideOpts) error {
-	ready, err := NewComposeProject(s.Files, WithTransforms)
+	ready, err := newEnvComposeProject(s.Files, "", s.interpolationValues, WithTransforms)
 	if err != nil {
 		return errors.Errorf("%s\nsee compose files: %v", err.Error(), s.Files)
 	}
@@ -50,7 +51,15 @@ func (s *Sources) CalculateBaseOverride(opts ...BaseOverrideOpts) error {
 			Extensions: svc.Extensions,
 		}
 
-		k8sConf, err := config.SvcK8sConfigFromCompose(&svc)
+		var svcOpts []config.K8sExtensionOption
+		if s.defaultServiceType != "" {
+			svcOpts = append(svcOpts, config.WithDefaultServiceType(s.defaultServiceType))
+		}
+		if s.traefikLabels {
+			svcOpts = append(svcOpts, config.WithTraefikLabels())
+		}
+
+		k8sConf, err := config.SvcK8sConfigFromCompose(&svc, svcOpts...)
 		if err != nil {
 			return err
 		}
@@ -155,6 +164,34 @@ func (s *Sources) MarshalJSON() ([]byte, error) {
 	return json.MarshalIndent(data, "", "  ")
 }
 
+// ServiceSourceFiles maps each compose service name to the compose source file it originated from.
+// When a service is defined in multiple source files, the first file defining it is used.
+func (s *Sources) ServiceSourceFiles() (map[string]string, error) {
+	out := map[string]string{}
+
+	for _, file := range s.Files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		var src struct {
+			Services map[string]interface{} `yaml:"services"`
+		}
+		if err := yaml.Unmarshal(data, &src); err != nil {
+			return nil, errors.Wrapf(err, "cannot parse compose file %s", file)
+		}
+
+		for name := range src.Services {
+			if _, ok := out[name]; !ok {
+				out[name] = file
+			}
+		}
+	}
+
+	return out, nil
+}
+
 func (s *Sources) getWorkingDir() string {
 	if len(s.Files) < 1 {
 		return ""
@@ -163,5 +200,5 @@ func (s *Sources) getWorkingDir() string {
 }
 
 func (s *Sources) toComposeProject() (*ComposeProject, error) {
-	return NewComposeProject(s.Files)
+	return newEnvComposeProject(s.Files, "", s.interpolationValues)
 }
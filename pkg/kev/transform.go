@@ -18,6 +18,7 @@ package kev
 
 import (
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/appvia/kev/pkg/kev/config"
@@ -34,6 +35,7 @@ func augmentOrAddDeploy(x *ComposeProject) error {
 	var updated composego.Services
 	err := x.WithServices(x.ServiceNames(), func(svc composego.ServiceConfig) error {
 		deploy := createDeploy()
+		applyServiceResources(svc, &deploy)
 
 		if svc.Deploy != nil {
 			if err := mergo.Merge(&deploy, svc.Deploy, mergo.WithOverride); err != nil {
@@ -108,6 +110,20 @@ func createDeploy() composego.DeployConfig {
 	}
 }
 
+// applyServiceResources applies service level (compose v2 style) mem_limit, mem_reservation
+// and cpus resources to the deploy block. Resources set in the service deploy block take precedence.
+func applyServiceResources(svc composego.ServiceConfig, deploy *composego.DeployConfig) {
+	if svc.MemLimit > 0 {
+		deploy.Resources.Limits.MemoryBytes = svc.MemLimit
+	}
+	if svc.CPUS > 0 {
+		deploy.Resources.Limits.NanoCPUs = strconv.FormatFloat(float64(svc.CPUS), 'f', -1, 32)
+	}
+	if svc.MemReservation > 0 {
+		deploy.Resources.Reservations.MemoryBytes = svc.MemReservation
+	}
+}
+
 // createHealthCheck returns a healthcheck block with configured placeholders.
 func createHealthCheck() composego.HealthCheckConfig {
 	testMsg := fmt.Sprintf(config.DefaultLivenessProbeCommand[1])
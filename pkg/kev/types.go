@@ -19,7 +19,9 @@ package kev
 import (
 	"context"
 	"io"
+	"time"
 
+	"github.com/appvia/kev/pkg/kev/config"
 	kmd "github.com/appvia/komando"
 	composego "github.com/compose-spec/compose-go/types"
 )
@@ -30,6 +32,7 @@ type runConfig struct {
 	Envs                  []string
 	ManifestFormat        string
 	ManifestsAsSingleFile bool
+	AllEnvsSingleFile     bool
 	OutputDir             string
 	K8sNamespace          string
 	Kubecontext           string
@@ -40,8 +43,60 @@ type runConfig struct {
 	// ExcludeServicesByEnv is used to exclude an environment's set of services from processing.
 	// Primary use is during render.
 	ExcludeServicesByEnv map[string][]string
+	// FilterServices limits rendering to the named services. All services are rendered when empty.
+	FilterServices []string
 	// LogVerbose enables/disables verbose logging at a debug log level.
 	LogVerbose bool
+	// FailOnWarn fails rendering when any warnings were emitted during reconcile or render.
+	FailOnWarn bool
+	// AnnotateSourceFile annotates rendered objects with the compose source file their service originated from.
+	AnnotateSourceFile bool
+	// FieldManager is the server-side apply field manager name rendered objects get annotated with.
+	FieldManager string
+	// ResourceQuota renders a resource quota and limit range for each environment namespace.
+	ResourceQuota bool
+	// QuotaHeadroom is the factor applied to environment resources when rendering a resource quota.
+	QuotaHeadroom float64
+	// ExposeAPI is the API used to expose services, one of: ingress, traefik.
+	ExposeAPI string
+	// PruneEmpty drops rendered objects carrying no meaningful configuration.
+	PruneEmpty bool
+	// Index writes a markdown index summarising the rendered objects of each environment.
+	Index bool
+	// Output is the encoding of rendered Kubernetes manifests, one of: yaml, json.
+	Output string
+	// KubeVersion is the target Kubernetes version selecting rendered objects apiVersions, e.g. 1.24.
+	KubeVersion string
+	// NodePortRange is the range NodePort services node ports must be within, e.g. 30000-32767.
+	NodePortRange string
+	// ValuesFromEnvPrefix selects environment variables to inject into compose files interpolation.
+	// Matching variables are made available under their names stripped of the prefix.
+	ValuesFromEnvPrefix string
+	// WarningAnnotations records service conversion warnings as annotations on the rendered objects they concern.
+	WarningAnnotations bool
+	// ValuesFile is a file of services x-k8s configuration values merged into environments at render time.
+	ValuesFile string
+	// KustomizeOverlay is a kustomization directory rendered manifests are run through as a final render step.
+	KustomizeOverlay string
+	// Concurrency is the maximum number of environments rendered concurrently. Environments are rendered sequentially by default.
+	Concurrency int
+	// DevDebounce is the quiet period collapsing a burst of file changes into a single re-render during dev.
+	// Changes trigger re-renders immediately when it isn't positive.
+	DevDebounce time.Duration
+	// LintErrorOn lists lint checks whose findings are reported as errors rather than warnings.
+	LintErrorOn []string
+	// GraphFormat is the format of a project's dependency graph, one of: dot, mermaid.
+	GraphFormat string
+	// DefaultServiceType is the service type inferred for compose services publishing ports.
+	DefaultServiceType string
+	// TraefikLabels infers compose services expose configuration from their traefik labels.
+	TraefikLabels bool
+	// SkaffoldBuild configures the build section of generated Skaffold manifests.
+	SkaffoldBuild SkaffoldBuildOptions
+	// SkaffoldDefaultRepo is the image registry Skaffold pushes built images to.
+	SkaffoldDefaultRepo string
+	// Patch is the patch added to an environment's rendered objects configuration.
+	Patch config.Patch
 }
 
 // Options helps configure running project commands
@@ -88,6 +143,41 @@ type RenderRunner struct {
 	*Project
 }
 
+// LintRunner runs the required sequences to lint a project's rendered output.
+type LintRunner struct {
+	*Project
+}
+
+// ValidateRunner runs the required sequences to validate a project's environments without writing any files.
+type ValidateRunner struct {
+	*Project
+}
+
+// DiffRunner runs the required sequences to compare a project's rendered manifests with the ones on disk.
+type DiffRunner struct {
+	*Project
+}
+
+// DoctorRunner runs the required sequences to diagnose a project's setup problems.
+type DoctorRunner struct {
+	*Project
+}
+
+// GraphRunner runs the required sequences to graph a project's services and their dependencies.
+type GraphRunner struct {
+	*Project
+}
+
+// PatchRunner runs the required sequences to add a patch to a project environment's rendered objects.
+type PatchRunner struct {
+	*Project
+}
+
+// SkaffoldRunner runs the required sequences to create or update a project's Skaffold config.
+type SkaffoldRunner struct {
+	*Project
+}
+
 // DevRunner runs the required sequences to use dev with a project.
 type DevRunner struct {
 	*Project
@@ -95,17 +185,38 @@ type DevRunner struct {
 
 // Manifest contains the tracked project's docker-compose sources and deployment environments
 type Manifest struct {
-	Id           string       `yaml:"id,omitempty" json:"id,omitempty"`
-	Sources      *Sources     `yaml:"compose,omitempty" json:"compose,omitempty"`
-	Environments Environments `yaml:"environments,omitempty" json:"environments,omitempty"`
-	Skaffold     string       `yaml:"skaffold,omitempty" json:"skaffold,omitempty"`
-	UI           kmd.UI       `yaml:"-" json:"-"`
+	Id                  string            `yaml:"id,omitempty" json:"id,omitempty"`
+	Sources             *Sources          `yaml:"compose,omitempty" json:"compose,omitempty"`
+	Environments        Environments      `yaml:"environments,omitempty" json:"environments,omitempty"`
+	Skaffold            string            `yaml:"skaffold,omitempty" json:"skaffold,omitempty"`
+	DefaultServiceType  string            `yaml:"defaultServiceType,omitempty" json:"defaultServiceType,omitempty"`
+	SkaffoldDefaultRepo string            `yaml:"skaffoldDefaultRepo,omitempty" json:"skaffoldDefaultRepo,omitempty"`
+	TraefikLabels       bool              `yaml:"traefikLabels,omitempty" json:"traefikLabels,omitempty"`
+	ReconcileConflicts  map[string]string `yaml:"reconcileConflicts,omitempty" json:"reconcileConflicts,omitempty"`
+	// ReconciledSources are the sources values each environment was last reconciled against, keyed by environment name.
+	// Only values changed in the sources since then may conflict with an environment's overrides.
+	ReconciledSources map[string]sourceValues `yaml:"reconciledSources,omitempty" json:"reconciledSources,omitempty"`
+	UI                kmd.UI                  `yaml:"-" json:"-"`
+	// reconciledSourcesChanged is set when reconciling updated the reconciled sources, which then need writing out
+	reconciledSourcesChanged bool
+	// values are services x-k8s configuration values merged into environments when rendering
+	values map[string]config.SvcK8sConfig
+	// interpolationValues are variables made available to compose files interpolation, e.g. values from env
+	interpolationValues map[string]string
+	// warnedVariables are the unresolved compose files variables already warned about, keyed by file and variable name
+	warnedVariables map[string]bool
 }
 
 // Sources tracks a project's docker-compose sources
 type Sources struct {
 	Files    []string `yaml:"-" json:"-"`
 	override *composeOverride
+	// defaultServiceType is the project's default service type for services publishing ports
+	defaultServiceType config.ServiceType
+	// traefikLabels infers services expose configuration from their traefik labels
+	traefikLabels bool
+	// interpolationValues are variables made available to compose files interpolation
+	interpolationValues map[string]string
 }
 
 // Environments tracks a project's deployment environments
@@ -116,6 +227,8 @@ type Environment struct {
 	Name     string `yaml:"-" json:"-"`
 	File     string `yaml:"-" json:"-"`
 	override *composeOverride
+	// interpolationValues are variables made available to compose files interpolation
+	interpolationValues map[string]string
 }
 
 // composeOverride augments a compose project with an extension and env vars to produce
@@ -124,7 +237,10 @@ type composeOverride struct {
 	Version  string   `yaml:"version,omitempty" json:"version,omitempty" diff:"version"`
 	Services Services `json:"services" diff:"services"`
 	Volumes  Volumes  `yaml:",omitempty" json:"volumes,omitempty" diff:"volumes"`
-	UI       kmd.UI   `yaml:"-" json:"-"`
+	// Extensions are the override's top level extensions, e.g. environment wide x-k8s configuration
+	Extensions map[string]interface{} `yaml:",inline" json:"-"`
+	UI         kmd.UI                 `yaml:"-" json:"-"`
+	applied    []appliedChange
 }
 
 // ComposeProject wrapper around a compose-go Project. It also provides the original
@@ -138,6 +254,7 @@ type ComposeProject struct {
 type ServiceConfig struct {
 	Name        string                      `yaml:"-" json:"-" diff:"name"`
 	Environment composego.MappingWithEquals `yaml:",omitempty" json:"environment,omitempty" diff:"environment"`
+	Labels      composego.Labels            `yaml:",omitempty" json:"labels,omitempty" diff:"labels"`
 	Extensions  map[string]interface{}      `yaml:",inline" json:"-"`
 }
 
@@ -176,6 +293,16 @@ type change struct {
 	Index  interface{}
 }
 
+// appliedChange records a change applied to an override's object during reconciliation.
+type appliedChange struct {
+	Type   string
+	Object string
+	Detail string
+}
+
+// reconcileSummary groups applied changes by change type, environment and object.
+type reconcileSummary map[string]map[string]map[string][]string
+
 // WritableResults is a collection of WritableResult
 type WritableResults []WritableResult
 
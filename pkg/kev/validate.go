@@ -0,0 +1,177 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kev
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/appvia/kev/pkg/kev/converter/kubernetes"
+	kmd "github.com/appvia/komando"
+	"github.com/pkg/errors"
+)
+
+// NewValidateRunner creates a validate runner instance
+func NewValidateRunner(workingDir string, opts ...Options) *ValidateRunner {
+	runner := &ValidateRunner{
+		Project: &Project{
+			WorkingDir:   workingDir,
+			eventHandler: func(s RunnerEvent, r Runner) error { return nil },
+		},
+	}
+	runner.Init(opts...)
+	return runner
+}
+
+// Run reconciles and transforms each environment in memory without writing any files.
+// It returns the validation error found in each environment keyed by environment name, nil for valid environments.
+// All environments are validated, a failing environment doesn't stop the validation of the others.
+func (r *ValidateRunner) Run() (map[string]error, error) {
+	if r.LogVerbose() {
+		cancelFunc, pr, pw := r.pipeLogsToUI()
+		defer cancelFunc()
+		defer pw.Close()
+		defer pr.Close()
+	}
+
+	if err := (&RenderRunner{Project: r.Project}).LoadProject(); err != nil {
+		return nil, err
+	}
+
+	if err := r.InjectValuesFromEnv(); err != nil {
+		return nil, err
+	}
+
+	envs, err := r.manifest.GetEnvironments(r.config.Envs)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles, err := r.manifest.ComposeProfiles()
+	if err != nil {
+		return nil, err
+	}
+
+	convOpts, err := r.converterOptions()
+	if err != nil {
+		return nil, err
+	}
+	r.printComposeProfiles(profiles)
+
+	r.UI.Header("Validating...")
+	sg := r.UI.StepGroup()
+	defer sg.Done()
+
+	// reconcile and transform summaries are not relevant here, only their errors
+	ui := r.manifest.UI
+	r.manifest.UI = kmd.NoOpUI()
+	defer func() { r.manifest.UI = ui }()
+
+	results := map[string]error{}
+	for _, env := range envs {
+		step := sg.Add(fmt.Sprintf("Validating environment: %s", env.Name))
+
+		err := r.validateEnv(env, profiles, convOpts)
+		results[env.Name] = err
+		if err != nil {
+			step.Error(fmt.Sprintf("Invalid environment: %s", env.Name))
+			r.UI.Output(err.Error(),
+				kmd.WithStyle(kmd.LogStyle),
+				kmd.WithIndentChar(kmd.LogIndentChar),
+				kmd.WithIndent(3))
+			continue
+		}
+		step.Success(fmt.Sprintf("Valid environment: %s", env.Name))
+	}
+
+	return results, nil
+}
+
+// validateEnv checks an environment's compose profiles exist, then reconciles and transforms it in memory
+// the same way it is rendered, configured with the converter options
+func (r *ValidateRunner) validateEnv(env *Environment, profiles map[string][]string, convOpts []kubernetes.Option) error {
+	if err := validateEnvProfiles(env, profiles); err != nil {
+		return errors.Wrap(err, "profiles")
+	}
+
+	if _, err := r.manifest.ReconcileConfig(env.Name); err != nil {
+		return errors.Wrap(err, "reconcile")
+	}
+
+	if _, err := r.manifest.RenderObjects([]string{env.Name}, r.config.ExcludeServicesByEnv, convOpts...); err != nil {
+		return errors.Wrap(err, "transform")
+	}
+
+	return nil
+}
+
+// printComposeProfiles lists the compose profiles declared by the compose sources and the services they enable
+func (r *ValidateRunner) printComposeProfiles(profiles map[string][]string) {
+	if len(profiles) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	r.UI.Header("Compose profiles...")
+	for _, name := range names {
+		r.UI.Output(fmt.Sprintf("%s: %s", name, strings.Join(profiles[name], ", ")),
+			kmd.WithStyle(kmd.LogStyle),
+			kmd.WithIndentChar(kmd.LogIndentChar),
+			kmd.WithIndent(3))
+	}
+}
+
+func printValidateProjectWithOptionsError(appName string, ui kmd.UI) {
+	ui.Output("")
+	ui.Output("Project had errors during validation.\n"+
+		fmt.Sprintf("'%s' experienced some errors during project validation. The output\n", appName)+
+		"above should contain the failure messages. Please correct these errors and\n"+
+		fmt.Sprintf("run '%s validate' again.", appName),
+		kmd.WithErrorBoldStyle(),
+		kmd.WithIndentChar(kmd.ErrorIndentChar),
+	)
+}
+
+func printValidateProjectWithOptionsSummary(r *ValidateRunner, results map[string]error) error {
+	var invalid int
+	for _, err := range results {
+		if err != nil {
+			invalid++
+		}
+	}
+
+	ui := r.GetUI()
+	ui.Output("")
+
+	if invalid > 0 {
+		ui.Output(
+			fmt.Sprintf("Validation failed for %d of %d environment(s).", invalid, len(results)),
+			kmd.WithErrorBoldStyle(),
+			kmd.WithIndentChar(kmd.ErrorIndentChar),
+		)
+		return errors.Errorf("validation failed for %d environment(s)", invalid)
+	}
+
+	ui.Output(fmt.Sprintf("Validation passed for %d environment(s).", len(results)), kmd.WithStyle(kmd.SuccessBoldStyle))
+	return nil
+}
@@ -0,0 +1,97 @@
+/**
+ * Copyright 2020 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kev_test
+
+import (
+	"github.com/appvia/kev/pkg/kev"
+	kmd "github.com/appvia/komando"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Validate", func() {
+	var (
+		envs    []string
+		results map[string]error
+		err     error
+	)
+
+	JustBeforeEach(func() {
+		r := kev.NewValidateRunner("testdata/validate-envs", kev.WithUI(kmd.NoOpUI()), kev.WithEnvs(envs))
+		results, err = r.Run()
+	})
+
+	Context("for all environments", func() {
+		BeforeEach(func() {
+			envs = nil
+		})
+
+		It("should not error", func() {
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("validates every environment", func() {
+			Expect(results).To(HaveLen(4))
+		})
+
+		It("reports valid environments", func() {
+			Expect(results["dev"]).NotTo(HaveOccurred())
+		})
+
+		It("reports errors for all invalid environments", func() {
+			Expect(results["stage"]).To(MatchError(ContainSubstring("MaxMemory")))
+			Expect(results["prod"]).To(MatchError(ContainSubstring("SeccompProfile")))
+		})
+
+		It("reports errors raised by the rendered objects post processing", func() {
+			Expect(results["patched"]).To(MatchError(ContainSubstring("targets Deployment/missing which isn't among the rendered objects")))
+		})
+	})
+
+	Context("for a specific environment", func() {
+		BeforeEach(func() {
+			envs = []string{"dev"}
+		})
+
+		It("validates only that environment", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(results).To(HaveLen(1))
+			Expect(results["dev"]).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("with compose profiles", func() {
+		var profilesResults map[string]error
+
+		BeforeEach(func() {
+			r := kev.NewValidateRunner("testdata/profiles", kev.WithUI(kmd.NoOpUI()), kev.WithEnvs([]string{"worker", "typo"}))
+
+			var err error
+			profilesResults, err = r.Run()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("reports environments enabling existing profiles as valid", func() {
+			Expect(profilesResults["worker"]).NotTo(HaveOccurred())
+		})
+
+		It("reports environments enabling unknown profiles", func() {
+			Expect(profilesResults["typo"]).To(MatchError(ContainSubstring(
+				"environment typo enables unknown compose profile wrker, known profiles: batch, web, worker")))
+		})
+	})
+})
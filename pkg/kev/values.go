@@ -0,0 +1,193 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kev
+
+import (
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/appvia/kev/pkg/kev/config"
+	"github.com/appvia/kev/pkg/kev/log"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// valuesFile holds services x-k8s configuration values keyed by service name under a top level `services` key
+type valuesFile struct {
+	Services map[string]map[string]interface{} `yaml:"services"`
+}
+
+// valuesFromEnv collects environment variables with names starting with the given prefix.
+// The prefix is stripped from the names of the returned values.
+func valuesFromEnv(prefix string, environ []string) map[string]string {
+	out := map[string]string{}
+	if prefix == "" {
+		return out
+	}
+
+	for _, kv := range environ {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 || !strings.HasPrefix(parts[0], prefix) {
+			continue
+		}
+
+		name := strings.TrimPrefix(parts[0], prefix)
+		if name == "" {
+			continue
+		}
+		out[name] = parts[1]
+	}
+
+	return out
+}
+
+// setInterpolationValues makes the supplied values available to the interpolation of the compose sources
+// and environment overrides. Environments are reloaded as their overrides were interpolated when loaded.
+// Values are passed to the compose loader, the process environment is left untouched.
+func (m *Manifest) setInterpolationValues(values map[string]string) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	m.interpolationValues = values
+	if m.Sources != nil {
+		m.Sources.interpolationValues = values
+	}
+
+	for _, e := range m.Environments {
+		e.interpolationValues = values
+		if _, err := e.loadOverride(); err != nil {
+			return err
+		}
+	}
+
+	for name := range values {
+		log.Debugf("Injected interpolation value [%s]", name)
+	}
+	return nil
+}
+
+// InjectValuesFromEnv makes environment variables prefixed with the configured prefix
+// available to compose files interpolation, under their names stripped of the prefix.
+// It must be called once the project is loaded.
+func (p *Project) InjectValuesFromEnv() error {
+	prefix := p.config.ValuesFromEnvPrefix
+	if prefix == "" {
+		return nil
+	}
+
+	return p.manifest.setInterpolationValues(valuesFromEnv(prefix, os.Environ()))
+}
+
+// loadValuesFile loads the services x-k8s configuration values of a values file, keyed by service name
+func loadValuesFile(path string) (map[string]config.SvcK8sConfig, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot read values file %s", path)
+	}
+
+	var values valuesFile
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, errors.Wrapf(err, "cannot parse values file %s", path)
+	}
+
+	out := map[string]config.SvcK8sConfig{}
+	for name, v := range values.Services {
+		svcK8sConfig, err := config.ParseSvcK8sConfigFromMap(map[string]interface{}{config.K8SExtensionKey: v}, config.SkipValidation())
+		if err != nil {
+			return nil, errors.Wrapf(err, "values file %s, service %s", path, name)
+		}
+		out[name] = svcK8sConfig
+	}
+	return out, nil
+}
+
+// ApplyValuesFile loads the configured values file, deep merged into the x-k8s configuration of each
+// environment's services when rendering. Values take precedence over the environments configuration.
+// Environment files are left untouched.
+func (p *Project) ApplyValuesFile() error {
+	path := p.config.ValuesFile
+	if path == "" {
+		return nil
+	}
+
+	values, err := loadValuesFile(path)
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	envs, err := p.manifest.GetEnvironments(p.config.Envs)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range envs {
+		for _, name := range names {
+			if _, err := e.GetService(name); err != nil {
+				return errors.Errorf("values file %s references unknown service %s in environment %s", path, name, e.Name)
+			}
+		}
+	}
+
+	p.manifest.values = values
+	return nil
+}
+
+// mergeValues deep merges services x-k8s configuration values into the matching project services.
+// Values take precedence over the services configuration.
+func mergeValues(p *ComposeProject, values map[string]config.SvcK8sConfig) error {
+	for i, svc := range p.Services {
+		v, ok := values[svc.Name]
+		if !ok {
+			continue
+		}
+
+		var svcK8sConfig config.SvcK8sConfig
+		if _, ok := svc.Extensions[config.K8SExtensionKey]; ok {
+			parsed, err := config.ParseSvcK8sConfigFromMap(svc.Extensions, config.SkipValidation())
+			if err != nil {
+				return errors.Wrapf(err, "service %s", svc.Name)
+			}
+			svcK8sConfig = parsed
+		}
+
+		merged, err := svcK8sConfig.Merge(v)
+		if err != nil {
+			return errors.Wrapf(err, "cannot merge values into service %s", svc.Name)
+		}
+
+		m, err := merged.Map()
+		if err != nil {
+			return err
+		}
+
+		if p.Services[i].Extensions == nil {
+			p.Services[i].Extensions = map[string]interface{}{}
+		}
+		p.Services[i].Extensions[config.K8SExtensionKey] = m
+		log.Debugf("Merged values into service [%s]", svc.Name)
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kev_test
+
+import (
+	"os"
+
+	"github.com/appvia/kev/pkg/kev"
+	"github.com/appvia/kev/pkg/kev/config"
+	"github.com/appvia/kev/pkg/kev/log"
+	kmd "github.com/appvia/komando"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ApplyValuesFile", func() {
+	var (
+		workingDir = "testdata/env-files"
+		valuesFile string
+		runner     *kev.RenderRunner
+	)
+
+	JustBeforeEach(func() {
+		runner = kev.NewRenderRunner(workingDir, kev.WithUI(kmd.NoOpUI()), kev.WithValuesFile(valuesFile))
+		Expect(runner.LoadProject()).To(Succeed())
+	})
+
+	Context("with values for a known service", func() {
+		BeforeEach(func() {
+			valuesFile = workingDir + "/values.yaml"
+		})
+
+		It("merges the values into each environment's service configuration", func() {
+			Expect(runner.ApplyValuesFile()).To(Succeed())
+
+			for _, name := range []string{"dev", "prod"} {
+				env, err := runner.Manifest().GetEnvironment(name)
+				Expect(err).NotTo(HaveOccurred())
+
+				merged, err := runner.Manifest().MergeEnvIntoSources(env)
+				Expect(err).NotTo(HaveOccurred())
+
+				svc, err := merged.GetService("web")
+				Expect(err).NotTo(HaveOccurred())
+
+				svcK8sConfig, err := config.ParseSvcK8sConfigFromMap(svc.Extensions, config.SkipValidation())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(svcK8sConfig.Workload.Replicas).To(Equal(5))
+			}
+		})
+	})
+
+	Context("with values for an unknown service", func() {
+		BeforeEach(func() {
+			valuesFile = workingDir + "/values-unknown-service.yaml"
+		})
+
+		It("returns an error", func() {
+			err := runner.ApplyValuesFile()
+			Expect(err).To(MatchError(ContainSubstring("references unknown service unknown")))
+		})
+	})
+})
+
+var _ = Describe("InjectValuesFromEnv", func() {
+	var (
+		workingDir = "testdata/values-from-env"
+		prefix     string
+		runner     *kev.RenderRunner
+	)
+
+	BeforeEach(func() {
+		Expect(os.Setenv("KEVTEST_KEV_VALUES_IMAGE_TAG", "1.21")).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(os.Unsetenv("KEVTEST_KEV_VALUES_IMAGE_TAG")).To(Succeed())
+	})
+
+	JustBeforeEach(func() {
+		runner = kev.NewRenderRunner(workingDir, kev.WithUI(kmd.NoOpUI()), kev.WithValuesFromEnv(prefix))
+		Expect(runner.LoadProject()).To(Succeed())
+		Expect(runner.InjectValuesFromEnv()).To(Succeed())
+	})
+
+	image := func() string {
+		env, err := runner.Manifest().GetEnvironment("dev")
+		Expect(err).NotTo(HaveOccurred())
+
+		merged, err := runner.Manifest().MergeEnvIntoSources(env)
+		Expect(err).NotTo(HaveOccurred())
+
+		svc, err := merged.GetService("web")
+		Expect(err).NotTo(HaveOccurred())
+		return svc.Image
+	}
+
+	Context("with a prefix", func() {
+		BeforeEach(func() {
+			prefix = "KEVTEST_"
+		})
+
+		It("interpolates compose files with the prefixed env vars stripped of the prefix", func() {
+			Expect(image()).To(Equal("nginx:1.21"))
+		})
+
+		It("leaves the process environment untouched", func() {
+			_ = image()
+			_, ok := os.LookupEnv("KEV_VALUES_IMAGE_TAG")
+			Expect(ok).To(BeFalse())
+		})
+
+		It("doesn't warn about variables supplied with values or referenced in comments", func() {
+			collector := log.CollectWarnings()
+			defer collector.Stop()
+
+			_ = image()
+			Expect(collector.Warnings()).To(BeEmpty())
+		})
+	})
+
+	Context("without a prefix", func() {
+		BeforeEach(func() {
+			prefix = ""
+		})
+
+		It("doesn't inject any values", func() {
+			Expect(image()).To(Equal("nginx:"))
+		})
+
+		It("warns about each unresolved variable once", func() {
+			collector := log.CollectWarnings()
+			defer collector.Stop()
+
+			_ = image()
+			_ = image()
+			Expect(collector.Warnings()).To(Equal([]string{
+				"Variable KEV_VALUES_IMAGE_TAG isn't set in the environment nor in env files. Interpolating with a blank string",
+			}))
+		})
+	})
+})